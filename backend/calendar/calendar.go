@@ -0,0 +1,203 @@
+package calendar
+
+import (
+	"bamort/database"
+	"bamort/models"
+	"fmt"
+)
+
+// Month ist ein Monat der Kalenderdefinition
+type Month struct {
+	Name string `json:"name"`
+	Days int    `json:"days"`
+}
+
+// Definition beschreibt den Kalender eines Spielsystems
+// Tag 0 ist der erste Tag des ersten Monats im Jahr 1; frühere Tage haben negative Nummern.
+type Definition struct {
+	Name         string   `json:"name"`
+	Months       []Month  `json:"months"`
+	Weekdays     []string `json:"weekdays"`
+	Era          string   `json:"era"`           // Zusatz der Jahreszählung, z.B. "nL"
+	FirstWeekday int      `json:"first_weekday"` // Wochentag (Index) von Tag 0
+}
+
+// Date ist ein Datum im Spielkalender (Monat und Tag beginnen bei 1)
+type Date struct {
+	Year  int `json:"year"`
+	Month int `json:"month"`
+	Day   int `json:"day"`
+}
+
+// defaultDefinition ist der Kalender des M5-Systems
+var defaultDefinition = Definition{
+	Name: "Midgard-Kalender",
+	Months: []Month{
+		{Name: "Eismond", Days: 30},
+		{Name: "Schneemond", Days: 30},
+		{Name: "Tauwind", Days: 30},
+		{Name: "Saatmond", Days: 30},
+		{Name: "Blütenmond", Days: 30},
+		{Name: "Sonnmond", Days: 30},
+		{Name: "Heumond", Days: 30},
+		{Name: "Erntemond", Days: 30},
+		{Name: "Weinmond", Days: 30},
+		{Name: "Nebelmond", Days: 30},
+		{Name: "Sturmmond", Days: 30},
+		{Name: "Julmond", Days: 30},
+	},
+	Weekdays: []string{"Mondtag", "Feuertag", "Wassertag", "Windtag", "Erdtag", "Lichttag", "Sonnentag"},
+	Era:      "nL",
+}
+
+// Definitions liefert die Kalender aller aktiven Spielsysteme nach Spielsystem-Code
+func Definitions() (map[string]Definition, error) {
+	var systems []models.GameSystem
+	if err := database.DB.Where("is_active = ?", true).Find(&systems).Error; err != nil {
+		return nil, err
+	}
+	definitions := make(map[string]Definition, len(systems))
+	for i := range systems {
+		definitions[systems[i].Code] = models.GameSystemRules(&systems[i], "calendar", defaultDefinition)
+	}
+	return definitions, nil
+}
+
+// GetDefinition liefert den Kalender eines Spielsystems
+// Ohne eigenen Regelsatz "calendar" verwendet das Spielsystem den Midgard-Kalender.
+func GetDefinition(gameSystem string) (Definition, bool) {
+	gs := models.GetGameSystem(0, gameSystem)
+	if gs == nil || gs.ID == 0 {
+		return Definition{}, false
+	}
+	return models.GameSystemRules(gs, "calendar", defaultDefinition), true
+}
+
+// DaysPerYear liefert die Länge eines Jahres in Tagen
+func (d Definition) DaysPerYear() int {
+	days := 0
+	for _, m := range d.Months {
+		days += m.Days
+	}
+	return days
+}
+
+// floorDiv teilt mit Abrundung, damit auch Tage vor dem Jahr 1 richtig zugeordnet werden
+func floorDiv(a, b int) (int, int) {
+	q, r := a/b, a%b
+	if r < 0 {
+		q--
+		r += b
+	}
+	return q, r
+}
+
+// Validate prüft, ob das Datum im Kalender existiert
+func (d Definition) Validate(date Date) error {
+	if date.Month < 1 || date.Month > len(d.Months) {
+		return fmt.Errorf("ungültiger Monat %d (1-%d)", date.Month, len(d.Months))
+	}
+	if days := d.Months[date.Month-1].Days; date.Day < 1 || date.Day > days {
+		return fmt.Errorf("ungültiger Tag %d im %s (1-%d)", date.Day, d.Months[date.Month-1].Name, days)
+	}
+	return nil
+}
+
+// ToDay rechnet ein Datum in die fortlaufende Tagesnummer um
+func (d Definition) ToDay(date Date) (int, error) {
+	if err := d.Validate(date); err != nil {
+		return 0, err
+	}
+	day := (date.Year-1)*d.DaysPerYear() + date.Day - 1
+	for _, m := range d.Months[:date.Month-1] {
+		day += m.Days
+	}
+	return day, nil
+}
+
+// FromDay rechnet eine Tagesnummer in ein Datum um
+func (d Definition) FromDay(day int) Date {
+	year, rest := floorDiv(day, d.DaysPerYear())
+	date := Date{Year: year + 1, Month: 1}
+	for _, m := range d.Months {
+		if rest < m.Days {
+			break
+		}
+		rest -= m.Days
+		date.Month++
+	}
+	date.Day = rest + 1
+	return date
+}
+
+// Weekday liefert den Namen des Wochentags einer Tagesnummer
+func (d Definition) Weekday(day int) string {
+	if len(d.Weekdays) == 0 {
+		return ""
+	}
+	_, index := floorDiv(day+d.FirstWeekday, len(d.Weekdays))
+	return d.Weekdays[index]
+}
+
+// Format gibt eine Tagesnummer lesbar aus, z.B. "3. Saatmond 2424 nL"
+func (d Definition) Format(day int) string {
+	date := d.FromDay(day)
+	text := fmt.Sprintf("%d. %s %d", date.Day, d.Months[date.Month-1].Name, date.Year)
+	if d.Era != "" {
+		text += " " + d.Era
+	}
+	return text
+}
+
+// FormatLong gibt eine Tagesnummer mit Wochentag aus, z.B. "Windtag, 3. Saatmond 2424 nL"
+func (d Definition) FormatLong(day int) string {
+	if weekday := d.Weekday(day); weekday != "" {
+		return weekday + ", " + d.Format(day)
+	}
+	return d.Format(day)
+}
+
+// AgeAt berechnet das Alter in vollen Jahren am Tag day für jemanden, der am Tag birthDay geboren wurde
+func (d Definition) AgeAt(birthDay, day int) int {
+	birth, now := d.FromDay(birthDay), d.FromDay(day)
+	age := now.Year - birth.Year
+	if now.Month < birth.Month || (now.Month == birth.Month && now.Day < birth.Day) {
+		age--
+	}
+	return age
+}
+
+// BirthDayForAge schätzt den Geburtstag aus einem Alter: Geburtstag ist der aktuelle Tag vor age Jahren
+func (d Definition) BirthDayForAge(age, day int) int {
+	return day - age*d.DaysPerYear()
+}
+
+// DateInfo ist die Kalenderansicht einer Tagesnummer für API und Charakterbogen
+type DateInfo struct {
+	DayNumber int    `json:"day_number"`
+	Date      Date   `json:"date"`
+	Weekday   string `json:"weekday"`
+	Formatted string `json:"formatted"`
+}
+
+// Describe liefert Datum, Wochentag und Textform einer Tagesnummer
+func (d Definition) Describe(day int) DateInfo {
+	return DateInfo{DayNumber: day, Date: d.FromDay(day), Weekday: d.Weekday(day), Formatted: d.Format(day)}
+}
+
+// CharacterCalendar lädt den Spielkalender eines Charakters samt Definition
+// Liefert ok=false, wenn der Charakter keinem Kalender zugeordnet ist.
+func CharacterCalendar(char *models.Char) (*models.GameCalendar, Definition, bool) {
+	if char.CalendarID == 0 || database.DB == nil {
+		return nil, Definition{}, false
+	}
+	var cal models.GameCalendar
+	if err := database.DB.First(&cal, char.CalendarID).Error; err != nil {
+		return nil, Definition{}, false
+	}
+	def, ok := GetDefinition(cal.GameSystem)
+	if !ok {
+		return nil, Definition{}, false
+	}
+	return &cal, def, true
+}
@@ -0,0 +1,42 @@
+package calendar
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDateConversion(t *testing.T) {
+	def := defaultDefinition
+	require.Equal(t, 360, def.DaysPerYear())
+
+	day, err := def.ToDay(Date{Year: 2424, Month: 4, Day: 3})
+	require.NoError(t, err)
+	assert.Equal(t, Date{Year: 2424, Month: 4, Day: 3}, def.FromDay(day))
+	assert.Equal(t, "3. Saatmond 2424 nL", def.Format(day))
+	assert.Equal(t, def.Weekday(day-7), def.Weekday(day))
+	assert.Equal(t, def.Weekday(day)+", 3. Saatmond 2424 nL", def.FormatLong(day))
+
+	// Tage vor dem Jahr 1
+	assert.Equal(t, Date{Year: 0, Month: 12, Day: 30}, def.FromDay(-1))
+	assert.Equal(t, "Sonnentag", def.Weekday(-1))
+
+	_, err = def.ToDay(Date{Year: 2424, Month: 13, Day: 1})
+	assert.Error(t, err)
+	_, err = def.ToDay(Date{Year: 2424, Month: 2, Day: 31})
+	assert.Error(t, err)
+}
+
+func TestAgeAt(t *testing.T) {
+	def := defaultDefinition
+	birth, err := def.ToDay(Date{Year: 2400, Month: 6, Day: 15})
+	require.NoError(t, err)
+
+	dayBefore, _ := def.ToDay(Date{Year: 2424, Month: 6, Day: 14})
+	birthday, _ := def.ToDay(Date{Year: 2424, Month: 6, Day: 15})
+	assert.Equal(t, 23, def.AgeAt(birth, dayBefore))
+	assert.Equal(t, 24, def.AgeAt(birth, birthday))
+
+	assert.Equal(t, 24, def.AgeAt(def.BirthDayForAge(24, birthday), birthday))
+}
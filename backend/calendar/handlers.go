@@ -0,0 +1,437 @@
+package calendar
+
+import (
+	"bamort/campaign"
+	"bamort/character"
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CreateCalendarRequest legt einen Spielkalender an
+type CreateCalendarRequest struct {
+	Name       string `json:"name" binding:"required"`
+	GameSystem string `json:"game_system,omitempty"` // Standard: M5
+	Start      Date   `json:"start"`
+}
+
+// AdvanceCalendarRequest lässt Spielzeit vergehen
+type AdvanceCalendarRequest struct {
+	Days int `json:"days" binding:"required,min=1"`
+}
+
+// AttachCharacterRequest ordnet einen Charakter dem Kalender zu
+// Ohne Geburtsdatum wird es aus dem bisherigen Alter des Charakters geschätzt.
+type AttachCharacterRequest struct {
+	CharacterID uint   `json:"character_id" binding:"required"`
+	BirthDate   *Date  `json:"birth_date,omitempty"`
+	JoinCode    string `json:"join_code,omitempty"` // nicht nötig für Charaktere des Spielleiters
+}
+
+// CalendarCharacter ist ein Charakter im Kalender mit Alter und Geburtsdatum
+type CalendarCharacter struct {
+	ID        uint      `json:"id"`
+	Name      string    `json:"name"`
+	UserID    uint      `json:"user_id"`
+	Alter     int       `json:"alter"`
+	BirthDate *DateInfo `json:"birth_date,omitempty"`
+}
+
+// CalendarResponse ist ein Spielkalender mit aktuellem Datum und zugeordneten Charakteren
+type CalendarResponse struct {
+	models.GameCalendar
+	JoinCode   string              `json:"join_code,omitempty"` // nur für den Spielleiter
+	Today      DateInfo            `json:"today"`
+	Definition Definition          `json:"definition"`
+	Characters []CalendarCharacter `json:"characters"`
+}
+
+// AgedCharacter meldet eine Altersänderung beim Vorrücken des Kalenders
+type AgedCharacter struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	OldAlter int    `json:"old_alter"`
+	NewAlter int    `json:"new_alter"`
+}
+
+func respondWithError(c *gin.Context, status int, message string) {
+	logger.Warn("HTTP Fehler %d: %s", status, message)
+	c.JSON(status, gin.H{"error": message})
+}
+
+// canRead prüft, ob der Benutzer den Kalender sehen darf (Spielleiter oder Spieler eines Charakters)
+func canRead(cal *models.GameCalendar, userID uint) bool {
+	if cal.UserID == userID {
+		return true
+	}
+	var count int64
+	database.DB.Model(&models.Char{}).Where("calendar_id = ? AND user_id = ?", cal.ID, userID).Count(&count)
+	return count > 0
+}
+
+// findCalendar lädt einen Kalender samt Definition ohne Rechteprüfung
+func findCalendar(c *gin.Context) (*models.GameCalendar, Definition, bool) {
+	var cal models.GameCalendar
+	if err := database.DB.First(&cal, c.Param("id")).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Calendar not found")
+		return nil, Definition{}, false
+	}
+	def, ok := GetDefinition(cal.GameSystem)
+	if !ok {
+		respondWithError(c, http.StatusInternalServerError, "Unknown calendar for game system "+cal.GameSystem)
+		return nil, Definition{}, false
+	}
+	return &cal, def, true
+}
+
+// loadCalendar lädt einen Kalender samt Definition und prüft die Rechte
+func loadCalendar(c *gin.Context, write bool) (*models.GameCalendar, Definition, bool) {
+	cal, def, ok := findCalendar(c)
+	if !ok {
+		return nil, Definition{}, false
+	}
+	userID := c.GetUint("userID")
+	if write && cal.UserID != userID {
+		respondWithError(c, http.StatusForbidden, "Only the game master can modify this calendar")
+		return nil, Definition{}, false
+	}
+	if !write && !canRead(cal, userID) {
+		respondWithError(c, http.StatusForbidden, "You are not allowed to view this calendar")
+		return nil, Definition{}, false
+	}
+	return cal, def, true
+}
+
+// buildCalendarResponse ergänzt das aktuelle Datum und die Charaktere des Kalenders
+// Den Beitrittscode sieht nur der Spielleiter.
+func buildCalendarResponse(cal *models.GameCalendar, def Definition, userID uint) (CalendarResponse, error) {
+	response := CalendarResponse{GameCalendar: *cal, Today: def.Describe(cal.CurrentDay), Definition: def, Characters: []CalendarCharacter{}}
+	if cal.UserID == userID {
+		response.JoinCode = cal.JoinCode
+	}
+	var chars []models.Char
+	if err := database.DB.Where("calendar_id = ?", cal.ID).Order("name ASC").Find(&chars).Error; err != nil {
+		return response, err
+	}
+	for _, char := range chars {
+		entry := CalendarCharacter{ID: char.ID, Name: char.Name, UserID: char.UserID, Alter: char.Alter}
+		if char.BirthDay != nil {
+			birth := def.Describe(*char.BirthDay)
+			entry.BirthDate = &birth
+		}
+		response.Characters = append(response.Characters, entry)
+	}
+	return response, nil
+}
+
+// ListDefinitions gibt die Kalender aller Spielsysteme zurück
+func ListDefinitions(c *gin.Context) {
+	definitions, err := Definitions()
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve calendar definitions")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"definitions": definitions})
+}
+
+// ConvertDate rechnet zwischen Tagesnummer und Datum im Kalender eines Spielsystems um
+// Entweder ?day=... oder ?year=...&month=...&day_of_month=... angeben.
+func ConvertDate(c *gin.Context) {
+	def, ok := GetDefinition(c.Param("code"))
+	if !ok {
+		respondWithError(c, http.StatusNotFound, "No calendar for game system "+c.Param("code"))
+		return
+	}
+
+	if dayParam := c.Query("day"); dayParam != "" {
+		day, err := strconv.Atoi(dayParam)
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, "Ungültige Tagesnummer: "+dayParam)
+			return
+		}
+		c.JSON(http.StatusOK, def.Describe(day))
+		return
+	}
+
+	var date Date
+	var err error
+	if date.Year, err = strconv.Atoi(c.Query("year")); err != nil {
+		respondWithError(c, http.StatusBadRequest, "Tagesnummer (day) oder Datum (year, month, day_of_month) erforderlich")
+		return
+	}
+	date.Month, _ = strconv.Atoi(c.Query("month"))
+	date.Day, _ = strconv.Atoi(c.Query("day_of_month"))
+	day, err := def.ToDay(date)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, def.Describe(day))
+}
+
+// ListCalendars listet die Kalender, die der Benutzer führt oder in denen seine Charaktere spielen
+func ListCalendars(c *gin.Context) {
+	userID := c.GetUint("userID")
+	var calendars []models.GameCalendar
+	err := database.DB.
+		Where("user_id = ? OR id IN (?)", userID,
+			database.DB.Model(&models.Char{}).Select("calendar_id").Where("user_id = ? AND calendar_id > 0", userID)).
+		Order("name ASC").Find(&calendars).Error
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve calendars")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"calendars": calendars})
+}
+
+// CreateCalendar legt einen Spielkalender mit Startdatum an
+func CreateCalendar(c *gin.Context) {
+	var req CreateCalendarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, "Ungültige Anfrageparameter: "+err.Error())
+		return
+	}
+	if req.GameSystem == "" {
+		req.GameSystem = "M5"
+	}
+	def, ok := GetDefinition(req.GameSystem)
+	if !ok {
+		respondWithError(c, http.StatusBadRequest, "No calendar for game system "+req.GameSystem)
+		return
+	}
+	start, err := def.ToDay(req.Start)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	joinCode, err := campaign.GenerateJoinCode()
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to create calendar")
+		return
+	}
+	cal := models.GameCalendar{Name: req.Name, GameSystem: req.GameSystem, UserID: c.GetUint("userID"), CurrentDay: start, JoinCode: joinCode}
+	if err := database.DB.Create(&cal).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to create calendar")
+		return
+	}
+	logger.Info("Spielkalender %d (%s) angelegt, Start: %s", cal.ID, cal.Name, def.Format(start))
+
+	response, err := buildCalendarResponse(&cal, def, c.GetUint("userID"))
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve calendar")
+		return
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetCalendar gibt einen Kalender mit aktuellem Datum und Charakteren zurück
+func GetCalendar(c *gin.Context) {
+	cal, def, ok := loadCalendar(c, false)
+	if !ok {
+		return
+	}
+	response, err := buildCalendarResponse(cal, def, c.GetUint("userID"))
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve calendar")
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteCalendar löscht einen Kalender; die Charaktere behalten Alter und Geburtstag
+func DeleteCalendar(c *gin.Context) {
+	cal, _, ok := loadCalendar(c, true)
+	if !ok {
+		return
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Char{}).Where("calendar_id = ?", cal.ID).Update("calendar_id", 0).Error; err != nil {
+			return err
+		}
+		return tx.Delete(cal).Error
+	})
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to delete calendar")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Calendar deleted"})
+}
+
+// AdvanceCharacterAgesTx passt das Alter aller Charaktere des Kalenders an den aktuellen Tag an
+// Jede Änderung wird im Audit-Log des Charakters vermerkt.
+func AdvanceCharacterAgesTx(tx *gorm.DB, cal *models.GameCalendar, def Definition, userID uint) ([]AgedCharacter, error) {
+	var chars []models.Char
+	if err := tx.Where("calendar_id = ? AND birth_day IS NOT NULL", cal.ID).Find(&chars).Error; err != nil {
+		return nil, err
+	}
+	aged := []AgedCharacter{}
+	for _, char := range chars {
+		age := def.AgeAt(*char.BirthDay, cal.CurrentDay)
+		if age == char.Alter {
+			continue
+		}
+		if err := tx.Model(&models.Char{}).Where("id = ?", char.ID).Update("alter", age).Error; err != nil {
+			return nil, fmt.Errorf("failed to update age of %s: %w", char.Name, err)
+		}
+		notes := fmt.Sprintf("Kalender %s: %s", cal.Name, def.Format(cal.CurrentDay))
+		if err := character.CreateAuditLogEntryTx(tx, char.ID, "alter", char.Alter, age, character.ReasonAgeing, userID, notes); err != nil {
+			return nil, err
+		}
+		aged = append(aged, AgedCharacter{ID: char.ID, Name: char.Name, OldAlter: char.Alter, NewAlter: age})
+	}
+	return aged, nil
+}
+
+// AdvanceCalendar lässt Spielzeit vergehen und lässt die Charaktere des Kalenders altern
+func AdvanceCalendar(c *gin.Context) {
+	cal, def, ok := loadCalendar(c, true)
+	if !ok {
+		return
+	}
+	var req AdvanceCalendarRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, "Ungültige Anfrageparameter: "+err.Error())
+		return
+	}
+
+	var aged []AgedCharacter
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		cal.CurrentDay += req.Days
+		if err := tx.Save(cal).Error; err != nil {
+			return err
+		}
+		var err error
+		aged, err = AdvanceCharacterAgesTx(tx, cal, def, c.GetUint("userID"))
+		return err
+	})
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to advance calendar: "+err.Error())
+		return
+	}
+	logger.Info("Spielkalender %d um %d Tage vorgerückt auf %s, %d Charaktere gealtert", cal.ID, req.Days, def.Format(cal.CurrentDay), len(aged))
+
+	response, err := buildCalendarResponse(cal, def, c.GetUint("userID"))
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve calendar")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"calendar": response, "aged": aged})
+}
+
+// RenewJoinCode erzeugt einen neuen Beitrittscode; der bisherige wird ungültig (nur Spielleiter)
+func RenewJoinCode(c *gin.Context) {
+	cal, _, ok := loadCalendar(c, true)
+	if !ok {
+		return
+	}
+	joinCode, err := campaign.GenerateJoinCode()
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to renew join code")
+		return
+	}
+	if err := database.DB.Model(cal).Update("join_code", joinCode).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to renew join code")
+		return
+	}
+	logger.Info("Beitrittscode des Spielkalenders %d erneuert", cal.ID)
+	c.JSON(http.StatusOK, gin.H{"join_code": joinCode})
+}
+
+// AttachCharacter ordnet einen eigenen Charakter einem Kalender zu und setzt sein Alter
+// Spieler brauchen dafür den Beitrittscode, den der Spielleiter an sie weitergibt.
+func AttachCharacter(c *gin.Context) {
+	cal, def, ok := findCalendar(c)
+	if !ok {
+		return
+	}
+	var req AttachCharacterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, "Ungültige Anfrageparameter: "+err.Error())
+		return
+	}
+	var char models.Char
+	if err := database.DB.First(&char, req.CharacterID).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return
+	}
+	userID := c.GetUint("userID")
+	if char.UserID != userID {
+		respondWithError(c, http.StatusForbidden, "You are not authorized to modify this character")
+		return
+	}
+	if cal.UserID != userID && (cal.JoinCode == "" || subtle.ConstantTimeCompare([]byte(req.JoinCode), []byte(cal.JoinCode)) != 1) {
+		respondWithError(c, http.StatusForbidden, "Invalid join code")
+		return
+	}
+	if gs := models.GetGameSystem(char.GameSystemId, char.GameSystem); gs != nil && gs.Code != cal.GameSystem {
+		respondWithError(c, http.StatusBadRequest, fmt.Sprintf("Charakter (%s) und Kalender (%s) gehören zu verschiedenen Spielsystemen", gs.Code, cal.GameSystem))
+		return
+	}
+
+	birthDay := def.BirthDayForAge(char.Alter, cal.CurrentDay)
+	if req.BirthDate != nil {
+		day, err := def.ToDay(*req.BirthDate)
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		if day > cal.CurrentDay {
+			respondWithError(c, http.StatusBadRequest, "Das Geburtsdatum liegt nach dem aktuellen Spieltag")
+			return
+		}
+		birthDay = day
+	}
+	age := def.AgeAt(birthDay, cal.CurrentDay)
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		updates := map[string]any{"calendar_id": cal.ID, "birth_day": birthDay, "alter": age}
+		if err := tx.Model(&models.Char{}).Where("id = ?", char.ID).Updates(updates).Error; err != nil {
+			return err
+		}
+		if age == char.Alter {
+			return nil
+		}
+		notes := fmt.Sprintf("Geburtsdatum im Kalender %s: %s", cal.Name, def.Format(birthDay))
+		return character.CreateAuditLogEntryTx(tx, char.ID, "alter", char.Alter, age, character.ReasonAgeing, userID, notes)
+	})
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to attach character")
+		return
+	}
+	logger.Info("Charakter %d dem Spielkalender %d zugeordnet, geboren am %s", char.ID, cal.ID, def.Format(birthDay))
+
+	birth := def.Describe(birthDay)
+	c.JSON(http.StatusOK, CalendarCharacter{ID: char.ID, Name: char.Name, UserID: char.UserID, Alter: age, BirthDate: &birth})
+}
+
+// DetachCharacter löst einen Charakter vom Kalender (Besitzer des Charakters oder Spielleiter)
+// Alter und Geburtstag bleiben erhalten.
+func DetachCharacter(c *gin.Context) {
+	cal, _, ok := findCalendar(c)
+	if !ok {
+		return
+	}
+	var char models.Char
+	if err := database.DB.Where("id = ? AND calendar_id = ?", c.Param("characterId"), cal.ID).First(&char).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found in calendar")
+		return
+	}
+	userID := c.GetUint("userID")
+	if char.UserID != userID && cal.UserID != userID {
+		respondWithError(c, http.StatusForbidden, "You are not authorized to modify this character")
+		return
+	}
+	if err := database.DB.Model(&models.Char{}).Where("id = ?", char.ID).Update("calendar_id", 0).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to detach character")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Character detached"})
+}
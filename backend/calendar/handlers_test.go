@@ -0,0 +1,182 @@
+package calendar
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bamort/character"
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildJSONContext(t *testing.T, method string, body any, userID uint, params map[string]string) (*gin.Context, *httptest.ResponseRecorder) {
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+
+	req, err := http.NewRequest(method, "/", &buf)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+	ctx.Set("userID", userID)
+
+	for k, v := range params {
+		ctx.Params = append(ctx.Params, gin.Param{Key: k, Value: v})
+	}
+
+	return ctx, w
+}
+
+func TestCalendarAgesCharacters(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+
+	require.NoError(t, models.MigrateStructure())
+	require.NoError(t, database.DB.Create(&models.GameSystem{Code: "M5", Name: "midgard", IsActive: true}).Error)
+
+	const gmID, playerID, strangerID uint = 251, 252, 253
+	hero := models.Char{BamortBase: models.BamortBase{Name: "Alwin"}, UserID: playerID, Typ: "Krieger", Rasse: "Mensch", Grad: 1, Alter: 20}
+	require.NoError(t, database.DB.Create(&hero).Error)
+	def := defaultDefinition
+
+	ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"name": "Abenteuer in Alba", "start": Date{Year: 2424, Month: 12, Day: 25}}, gmID, nil)
+	CreateCalendar(ctx)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var cal CalendarResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &cal))
+	assert.Equal(t, "25. Julmond 2424 nL", cal.Today.Formatted)
+	params := map[string]string{"id": fmt.Sprint(cal.ID)}
+
+	require.NotEmpty(t, cal.JoinCode, "the game master sees the join code")
+
+	t.Run("attach with birth date", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"character_id": hero.ID, "join_code": cal.JoinCode}, strangerID, params)
+		AttachCharacter(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		// ohne Beitrittscode kann niemand seinen Charakter in einen fremden Kalender setzen und ihn so einsehen
+		spy := models.Char{BamortBase: models.BamortBase{Name: "Späher"}, UserID: strangerID, Typ: "Krieger", Rasse: "Mensch", Grad: 1, Alter: 30}
+		require.NoError(t, database.DB.Create(&spy).Error)
+		for _, code := range []string{"", "falsch"} {
+			ctx, w = buildJSONContext(t, http.MethodPost, map[string]any{"character_id": spy.ID, "join_code": code}, strangerID, params)
+			AttachCharacter(ctx)
+			assert.Equal(t, http.StatusForbidden, w.Code, code)
+		}
+
+		body := map[string]any{"character_id": hero.ID, "birth_date": Date{Year: 2403, Month: 1, Day: 2}, "join_code": cal.JoinCode}
+		ctx, w = buildJSONContext(t, http.MethodPost, body, playerID, params)
+		AttachCharacter(ctx)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var attached CalendarCharacter
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &attached))
+		assert.Equal(t, 21, attached.Alter)
+		assert.Equal(t, "2. Eismond 2403 nL", attached.BirthDate.Formatted)
+
+		ctx, w = buildJSONContext(t, http.MethodGet, nil, playerID, params)
+		GetCalendar(ctx)
+		require.Equal(t, http.StatusOK, w.Code)
+		var seen CalendarResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &seen))
+		assert.Empty(t, seen.JoinCode, "players do not see the join code")
+		ctx, w = buildJSONContext(t, http.MethodGet, nil, strangerID, params)
+		GetCalendar(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("advancing the calendar ages characters", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"days": 7}, playerID, params)
+		AdvanceCalendar(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		// 25. Julmond + 7 Tage = 2. Eismond 2425, der Geburtstag des Helden
+		ctx, w = buildJSONContext(t, http.MethodPost, map[string]any{"days": 7}, gmID, params)
+		AdvanceCalendar(ctx)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Calendar CalendarResponse `json:"calendar"`
+			Aged     []AgedCharacter  `json:"aged"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, "2. Eismond 2425 nL", response.Calendar.Today.Formatted)
+		require.Len(t, response.Aged, 1)
+		assert.Equal(t, AgedCharacter{ID: hero.ID, Name: "Alwin", OldAlter: 21, NewAlter: 22}, response.Aged[0])
+
+		var reloaded models.Char
+		require.NoError(t, database.DB.First(&reloaded, hero.ID).Error)
+		assert.Equal(t, 22, reloaded.Alter)
+		var entry models.AuditLogEntry
+		require.NoError(t, database.DB.Where("character_id = ? AND field_name = ?", hero.ID, "alter").Order("id DESC").First(&entry).Error)
+		assert.Equal(t, string(character.ReasonAgeing), string(entry.Reason))
+		assert.Equal(t, 22, entry.NewValue)
+		assert.Equal(t, def.AgeAt(*reloaded.BirthDay, response.Calendar.CurrentDay), reloaded.Alter)
+	})
+
+	t.Run("notes default to the current game day", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"title": "Ankunft in Thame"}, playerID, map[string]string{"id": fmt.Sprint(hero.ID)})
+		character.CreateCharacterNote(ctx)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var note models.CharNote
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &note))
+		require.NotNil(t, note.GameDay)
+		assert.Equal(t, "2. Eismond 2425 nL", def.Format(*note.GameDay))
+	})
+
+	t.Run("date conversion endpoint", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodGet, nil, strangerID, map[string]string{"code": "M5"})
+		ctx.Request.URL.RawQuery = "year=2425&month=1&day_of_month=2"
+		ConvertDate(ctx)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var info DateInfo
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &info))
+		var reloaded models.GameCalendar
+		require.NoError(t, database.DB.First(&reloaded, cal.ID).Error)
+		assert.Equal(t, reloaded.CurrentDay, info.DayNumber)
+	})
+
+	t.Run("game systems can define their own calendar", func(t *testing.T) {
+		custom := models.GameSystem{Code: "KAL", Name: "Kalendersystem", IsActive: true, Rules: map[string]json.RawMessage{
+			"calendar": json.RawMessage(`{"name":"Zehnmonatskalender","months":[{"name":"Erster","days":36},{"name":"Zweiter","days":36},{"name":"Dritter","days":36},{"name":"Vierter","days":36},{"name":"Fünfter","days":36},{"name":"Sechster","days":36},{"name":"Siebter","days":36},{"name":"Achter","days":36},{"name":"Neunter","days":36},{"name":"Zehnter","days":36}]}`),
+		}}
+		require.NoError(t, database.DB.Create(&custom).Error)
+
+		ctx, w := buildJSONContext(t, http.MethodGet, nil, strangerID, nil)
+		ListDefinitions(ctx)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Definitions map[string]Definition `json:"definitions"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, def, response.Definitions["M5"])
+		kal := response.Definitions["KAL"]
+		assert.Equal(t, "Zehnmonatskalender", kal.Name)
+		assert.Equal(t, 360, kal.DaysPerYear())
+		assert.Equal(t, def.Weekdays, kal.Weekdays, "nicht hinterlegte Angaben stammen aus dem Midgard-Kalender")
+
+		ctx, w = buildJSONContext(t, http.MethodGet, nil, strangerID, map[string]string{"code": "KAL"})
+		ctx.Request.URL.RawQuery = "day=40"
+		ConvertDate(ctx)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		assert.Contains(t, w.Body.String(), "Zweiter")
+
+		ctx, w = buildJSONContext(t, http.MethodGet, nil, strangerID, map[string]string{"code": "XYZ"})
+		ctx.Request.URL.RawQuery = "day=40"
+		ConvertDate(ctx)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
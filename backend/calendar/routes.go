@@ -0,0 +1,24 @@
+package calendar
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.RouterGroup) {
+	calGrp := r.Group("/calendars")
+	calGrp.GET("", ListCalendars)
+	calGrp.POST("", CreateCalendar)
+
+	// Kalenderdefinitionen der Spielsysteme und Datumsumrechnung
+	calGrp.GET("/definitions", ListDefinitions)
+	calGrp.GET("/definitions/:code/convert", ConvertDate) // ?day=... oder ?year=...&month=...&day_of_month=...
+
+	calGrp.GET("/:id", GetCalendar)
+	calGrp.DELETE("/:id", DeleteCalendar)
+	calGrp.POST("/:id/advance", AdvanceCalendar) // Spielzeit vergehen lassen, Charaktere altern
+	calGrp.POST("/:id/join-code", RenewJoinCode) // Neuer Beitrittscode (nur Spielleiter)
+
+	// Charaktere im Kalender
+	calGrp.POST("/:id/characters", AttachCharacter)
+	calGrp.DELETE("/:id/characters/:characterId", DetachCharacter)
+}
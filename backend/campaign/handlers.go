@@ -0,0 +1,319 @@
+package campaign
+
+import (
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CampaignRequest legt eine Kampagne an oder ändert sie
+type CampaignRequest struct {
+	Name        string `json:"name" binding:"required"`
+	GameSystem  string `json:"game_system,omitempty"` // Standard: M5
+	Description string `json:"description,omitempty"`
+}
+
+// AttachCharacterRequest ordnet einen Charakter der Kampagne zu
+type AttachCharacterRequest struct {
+	CharacterID uint   `json:"character_id" binding:"required"`
+	JoinCode    string `json:"join_code,omitempty"` // nicht nötig für Charaktere des Spielleiters
+}
+
+// CampaignCharacter ist ein Charakter in der Kampagne
+type CampaignCharacter struct {
+	ID     uint   `json:"id"`
+	Name   string `json:"name"`
+	UserID uint   `json:"user_id"`
+	Typ    string `json:"typ"`
+	Grad   int    `json:"grad"`
+}
+
+// CampaignResponse ist eine Kampagne mit ihren Charakteren
+type CampaignResponse struct {
+	models.Campaign
+	JoinCode   string              `json:"join_code,omitempty"` // nur für den Spielleiter
+	Characters []CampaignCharacter `json:"characters"`
+}
+
+func respondWithError(c *gin.Context, status int, message string) {
+	logger.Warn("HTTP Fehler %d: %s", status, message)
+	c.JSON(status, gin.H{"error": message})
+}
+
+// findCampaign lädt eine Kampagne ohne Rechteprüfung
+func findCampaign(c *gin.Context) (*models.Campaign, bool) {
+	var campaign models.Campaign
+	if err := database.DB.First(&campaign, c.Param("id")).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Campaign not found")
+		return nil, false
+	}
+	return &campaign, true
+}
+
+// loadCampaign lädt eine Kampagne und prüft die Rechte; ändern darf nur der Spielleiter
+func loadCampaign(c *gin.Context, write bool) (*models.Campaign, bool) {
+	campaign, ok := findCampaign(c)
+	if !ok {
+		return nil, false
+	}
+	userID := c.GetUint("userID")
+	if write && campaign.UserID != userID {
+		respondWithError(c, http.StatusForbidden, "Only the game master can modify this campaign")
+		return nil, false
+	}
+	if !write && !campaign.IsMember(userID) {
+		respondWithError(c, http.StatusForbidden, "You are not allowed to view this campaign")
+		return nil, false
+	}
+	return campaign, true
+}
+
+// GenerateJoinCode erzeugt einen zufälligen Beitrittscode (auch für Spielkalender)
+func GenerateJoinCode() (string, error) {
+	bytes := make([]byte, 8)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(bytes), nil
+}
+
+// buildCampaignResponse ergänzt die Charaktere der Kampagne; den Beitrittscode sieht nur der Spielleiter
+func buildCampaignResponse(campaign *models.Campaign, userID uint) (CampaignResponse, error) {
+	response := CampaignResponse{Campaign: *campaign, Characters: []CampaignCharacter{}}
+	if campaign.UserID == userID {
+		response.JoinCode = campaign.JoinCode
+	}
+	var chars []models.Char
+	if err := database.DB.Where("campaign_id = ?", campaign.ID).Order("name ASC").Find(&chars).Error; err != nil {
+		return response, err
+	}
+	for _, char := range chars {
+		response.Characters = append(response.Characters, CampaignCharacter{ID: char.ID, Name: char.Name, UserID: char.UserID, Typ: char.Typ, Grad: char.Grad})
+	}
+	return response, nil
+}
+
+// bindCampaignRequest liest Name, Spielsystem und Beschreibung und prüft das Spielsystem
+func bindCampaignRequest(c *gin.Context) (CampaignRequest, bool) {
+	var req CampaignRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, "Ungültige Anfrageparameter: "+err.Error())
+		return req, false
+	}
+	if req.GameSystem == "" {
+		req.GameSystem = "M5"
+	}
+	gs := models.GetGameSystem(0, req.GameSystem)
+	if gs == nil {
+		respondWithError(c, http.StatusBadRequest, "Unknown game system "+req.GameSystem)
+		return req, false
+	}
+	req.GameSystem = gs.Code
+	return req, true
+}
+
+// ListCampaigns listet die Kampagnen, die der Benutzer leitet oder in denen seine Charaktere spielen
+func ListCampaigns(c *gin.Context) {
+	ids, err := models.CampaignIDsForUser(c.GetUint("userID"))
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve campaigns")
+		return
+	}
+	campaigns := []models.Campaign{}
+	if len(ids) > 0 {
+		if err := database.DB.Where("id IN ?", ids).Order("name ASC").Find(&campaigns).Error; err != nil {
+			respondWithError(c, http.StatusInternalServerError, "Failed to retrieve campaigns")
+			return
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"campaigns": campaigns})
+}
+
+// CreateCampaign legt eine Kampagne an; der Benutzer wird ihr Spielleiter
+func CreateCampaign(c *gin.Context) {
+	req, ok := bindCampaignRequest(c)
+	if !ok {
+		return
+	}
+	joinCode, err := GenerateJoinCode()
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to create campaign")
+		return
+	}
+	campaign := models.Campaign{Name: req.Name, GameSystem: req.GameSystem, Description: req.Description, UserID: c.GetUint("userID"), JoinCode: joinCode}
+	if err := database.DB.Create(&campaign).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to create campaign")
+		return
+	}
+	logger.Info("Kampagne %d (%s) angelegt, Spielleiter: %d", campaign.ID, campaign.Name, campaign.UserID)
+
+	response, err := buildCampaignResponse(&campaign, campaign.UserID)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve campaign")
+		return
+	}
+	c.JSON(http.StatusCreated, response)
+}
+
+// GetCampaign gibt eine Kampagne mit ihren Charakteren zurück
+func GetCampaign(c *gin.Context) {
+	campaign, ok := loadCampaign(c, false)
+	if !ok {
+		return
+	}
+	response, err := buildCampaignResponse(campaign, c.GetUint("userID"))
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve campaign")
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// UpdateCampaign ändert Name und Beschreibung; das Spielsystem bleibt fest, solange Charaktere zugeordnet sind
+func UpdateCampaign(c *gin.Context) {
+	campaign, ok := loadCampaign(c, true)
+	if !ok {
+		return
+	}
+	req, ok := bindCampaignRequest(c)
+	if !ok {
+		return
+	}
+	if req.GameSystem != campaign.GameSystem {
+		var count int64
+		database.DB.Model(&models.Char{}).Where("campaign_id = ?", campaign.ID).Count(&count)
+		if count > 0 {
+			respondWithError(c, http.StatusConflict, "Das Spielsystem kann nicht geändert werden, solange Charaktere in der Kampagne spielen")
+			return
+		}
+	}
+	campaign.Name = req.Name
+	campaign.GameSystem = req.GameSystem
+	campaign.Description = req.Description
+	if err := database.DB.Save(campaign).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to update campaign")
+		return
+	}
+	response, err := buildCampaignResponse(campaign, c.GetUint("userID"))
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve campaign")
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// DeleteCampaign löscht eine Kampagne samt Quellenauswahl; die Charaktere werden gelöst und die
+// Hausregeln der Kampagne werden zu Hausregeln des Spielleiters, damit erlernte Fertigkeiten nicht verwaisen
+func DeleteCampaign(c *gin.Context) {
+	campaign, ok := loadCampaign(c, true)
+	if !ok {
+		return
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Char{}).Where("campaign_id = ?", campaign.ID).Update("campaign_id", 0).Error; err != nil {
+			return err
+		}
+		if err := models.TransferCampaignHomebrew(tx, campaign.ID, campaign.UserID); err != nil {
+			return err
+		}
+		if err := tx.Where("campaign_id = ?", campaign.ID).Delete(&models.SourceSelection{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(campaign).Error
+	})
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to delete campaign")
+		return
+	}
+	logger.Info("Kampagne %d (%s) gelöscht, Hausregeln an Benutzer %d übertragen", campaign.ID, campaign.Name, campaign.UserID)
+	c.JSON(http.StatusOK, gin.H{"message": "Campaign deleted"})
+}
+
+// RenewJoinCode erzeugt einen neuen Beitrittscode; der bisherige wird ungültig (nur Spielleiter)
+func RenewJoinCode(c *gin.Context) {
+	campaign, ok := loadCampaign(c, true)
+	if !ok {
+		return
+	}
+	joinCode, err := GenerateJoinCode()
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to renew join code")
+		return
+	}
+	if err := database.DB.Model(campaign).Update("join_code", joinCode).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to renew join code")
+		return
+	}
+	logger.Info("Beitrittscode der Kampagne %d erneuert", campaign.ID)
+	c.JSON(http.StatusOK, gin.H{"join_code": joinCode})
+}
+
+// AttachCharacter ordnet einen eigenen Charakter einer Kampagne zu
+// Spieler brauchen dafür den Beitrittscode, den der Spielleiter an sie weitergibt.
+func AttachCharacter(c *gin.Context) {
+	campaign, ok := findCampaign(c)
+	if !ok {
+		return
+	}
+	var req AttachCharacterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, "Ungültige Anfrageparameter: "+err.Error())
+		return
+	}
+	var char models.Char
+	if err := database.DB.First(&char, req.CharacterID).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return
+	}
+	userID := c.GetUint("userID")
+	if char.UserID != userID {
+		respondWithError(c, http.StatusForbidden, "You are not authorized to modify this character")
+		return
+	}
+	if campaign.UserID != userID && (campaign.JoinCode == "" || subtle.ConstantTimeCompare([]byte(req.JoinCode), []byte(campaign.JoinCode)) != 1) {
+		respondWithError(c, http.StatusForbidden, "Invalid join code")
+		return
+	}
+	if gs := models.GetGameSystem(char.GameSystemId, char.GameSystem); gs != nil && gs.Code != campaign.GameSystem {
+		respondWithError(c, http.StatusBadRequest, fmt.Sprintf("Charakter (%s) und Kampagne (%s) gehören zu verschiedenen Spielsystemen", gs.Code, campaign.GameSystem))
+		return
+	}
+	if err := database.DB.Model(&models.Char{}).Where("id = ?", char.ID).Update("campaign_id", campaign.ID).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to attach character")
+		return
+	}
+	logger.Info("Charakter %d der Kampagne %d zugeordnet", char.ID, campaign.ID)
+	c.JSON(http.StatusOK, CampaignCharacter{ID: char.ID, Name: char.Name, UserID: char.UserID, Typ: char.Typ, Grad: char.Grad})
+}
+
+// DetachCharacter löst einen Charakter aus der Kampagne (Besitzer des Charakters oder Spielleiter)
+// Bereits erlernte Hausregel-Fertigkeiten bleiben am Charakter, neue sind nicht mehr erlernbar.
+func DetachCharacter(c *gin.Context) {
+	campaign, ok := findCampaign(c)
+	if !ok {
+		return
+	}
+	var char models.Char
+	if err := database.DB.Where("id = ? AND campaign_id = ?", c.Param("characterId"), campaign.ID).First(&char).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found in campaign")
+		return
+	}
+	userID := c.GetUint("userID")
+	if char.UserID != userID && campaign.UserID != userID {
+		respondWithError(c, http.StatusForbidden, "You are not authorized to modify this character")
+		return
+	}
+	if err := database.DB.Model(&models.Char{}).Where("id = ?", char.ID).Update("campaign_id", 0).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to detach character")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Character detached"})
+}
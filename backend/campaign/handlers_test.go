@@ -0,0 +1,130 @@
+package campaign
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildJSONContext(t *testing.T, method string, body any, userID uint, params map[string]string) (*gin.Context, *httptest.ResponseRecorder) {
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+
+	req, err := http.NewRequest(method, "/", &buf)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+	ctx.Set("userID", userID)
+
+	for k, v := range params {
+		ctx.Params = append(ctx.Params, gin.Param{Key: k, Value: v})
+	}
+
+	return ctx, w
+}
+
+func TestCampaignMembershipAndHomebrew(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+
+	require.NoError(t, models.MigrateStructure())
+	require.NoError(t, database.DB.Create(&models.GameSystem{Code: "M5", Name: "midgard", IsActive: true}).Error)
+
+	const gmID, playerID, strangerID uint = 265, 266, 267
+	hero := models.Char{BamortBase: models.BamortBase{Name: "Alrik"}, UserID: playerID, Typ: "Krieger", Rasse: "Mensch", Grad: 1}
+	require.NoError(t, database.DB.Create(&hero).Error)
+
+	ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"name": "Die Küstenstaaten", "game_system": "XX"}, gmID, nil)
+	CreateCampaign(ctx)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	ctx, w = buildJSONContext(t, http.MethodPost, map[string]any{"name": "Die Küstenstaaten"}, gmID, nil)
+	CreateCampaign(ctx)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var campaign CampaignResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &campaign))
+	assert.Equal(t, "M5", campaign.GameSystem)
+	params := map[string]string{"id": fmt.Sprint(campaign.ID)}
+
+	t.Run("players join with their characters", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodGet, nil, playerID, params)
+		GetCampaign(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		ctx, w = buildJSONContext(t, http.MethodPost, map[string]any{"character_id": hero.ID}, strangerID, params)
+		AttachCharacter(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		require.NotEmpty(t, campaign.JoinCode, "the game master sees the join code")
+		ctx, w = buildJSONContext(t, http.MethodPost, map[string]any{"character_id": hero.ID}, playerID, params)
+		AttachCharacter(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code, "joining requires the join code")
+
+		ctx, w = buildJSONContext(t, http.MethodPost, map[string]any{"character_id": hero.ID, "join_code": "falsch"}, playerID, params)
+		AttachCharacter(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		ctx, w = buildJSONContext(t, http.MethodPost, map[string]any{"character_id": hero.ID, "join_code": campaign.JoinCode}, playerID, params)
+		AttachCharacter(ctx)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		ctx, w = buildJSONContext(t, http.MethodGet, nil, playerID, params)
+		GetCampaign(ctx)
+		require.Equal(t, http.StatusOK, w.Code)
+		var response CampaignResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		require.Len(t, response.Characters, 1)
+		assert.Equal(t, "Alrik", response.Characters[0].Name)
+		assert.Empty(t, response.JoinCode, "players do not see the join code")
+
+		ids, err := models.CampaignIDsForUser(playerID)
+		require.NoError(t, err)
+		assert.Equal(t, []uint{campaign.ID}, ids)
+
+		ctx, w = buildJSONContext(t, http.MethodPut, map[string]any{"name": "Umbenannt"}, playerID, params)
+		UpdateCampaign(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("deleting hands homebrew to the game master", func(t *testing.T) {
+		spell := models.Spell{Name: "Gischtschild", GameSystem: "midgard", HomebrewScope: models.HomebrewScope{Scope: models.ScopeCampaign, ScopeCampaignID: campaign.ID}}
+		require.NoError(t, database.DB.Create(&spell).Error)
+
+		ctx, w := buildJSONContext(t, http.MethodDelete, nil, playerID, params)
+		DeleteCampaign(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		ctx, w = buildJSONContext(t, http.MethodDelete, nil, gmID, params)
+		DeleteCampaign(ctx)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var char models.Char
+		require.NoError(t, database.DB.First(&char, hero.ID).Error)
+		assert.Zero(t, char.CampaignID)
+
+		var transferred models.Spell
+		require.NoError(t, database.DB.First(&transferred, spell.ID).Error)
+		assert.Equal(t, models.ScopeUser, transferred.Scope)
+		assert.Equal(t, gmID, transferred.ScopeUserID)
+		assert.Zero(t, transferred.ScopeCampaignID)
+	})
+}
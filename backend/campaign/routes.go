@@ -0,0 +1,19 @@
+package campaign
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.RouterGroup) {
+	campGrp := r.Group("/campaigns")
+	campGrp.GET("", ListCampaigns)
+	campGrp.POST("", CreateCampaign)
+	campGrp.GET("/:id", GetCampaign)
+	campGrp.PUT("/:id", UpdateCampaign)
+	campGrp.DELETE("/:id", DeleteCampaign)        // Hausregeln der Kampagne gehen an den Spielleiter über
+	campGrp.POST("/:id/join-code", RenewJoinCode) // Neuer Beitrittscode (nur Spielleiter)
+
+	// Charaktere in der Kampagne
+	campGrp.POST("/:id/characters", AttachCharacter)
+	campGrp.DELETE("/:id/characters/:characterId", DetachCharacter)
+}
@@ -0,0 +1,295 @@
+package character
+
+import (
+	"bamort/gsmaster"
+	"bamort/models"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// maxAdvancementPlanSteps begrenzt die Länge eines Steigerungsplans
+const maxAdvancementPlanSteps = 50
+
+// Ressourcen, die bei einem Planschritt fehlen können
+const (
+	PlanMissingEP   = "ep"
+	PlanMissingGold = "gold"
+	PlanMissingPP   = "pp"
+)
+
+// gültige Belohnungen je Art des Schritts (Zauber zusätzlich mit Spruchrolle)
+var (
+	planSkillRewards = []string{"default", "noGold", "halveep", "halveepnoGold"}
+	planSpellRewards = []string{"default", "noGold", "halveep", "halveepnoGold", "spruchrolle"}
+)
+
+// PlanStepResult enthält die simulierten Kosten eines Planschritts
+// Gold umfasst die Lernkosten in Gold und das Gold, das EP ersetzt.
+type PlanStepResult struct {
+	Index int `json:"index"`
+	models.AdvancementPlanStep
+	FromLevel      int                           `json:"from_level"`
+	ToLevel        int                           `json:"to_level"`
+	EP             int                           `json:"ep"`
+	Gold           int                           `json:"gold"`
+	PP             int                           `json:"pp"`
+	GoldForEP      int                           `json:"gold_for_ep,omitempty"` // davon Gold statt EP
+	TrainingDays   int                           `json:"training_days"`
+	CumulativeEP   int                           `json:"cumulative_ep"`
+	CumulativeGold int                           `json:"cumulative_gold"`
+	CumulativePP   int                           `json:"cumulative_pp"`
+	RemainingEP    int                           `json:"remaining_ep"`
+	RemainingGold  int                           `json:"remaining_gold"`
+	Affordable     bool                          `json:"affordable"`
+	Missing        []string                      `json:"missing,omitempty"` // fehlende Ressourcen (ep, gold, pp)
+	Error          string                        `json:"error,omitempty"`
+	CostDetails    []gsmaster.SkillCostResultNew `json:"cost_details,omitempty"`
+}
+
+// PlanSimulation ist das Ergebnis eines Probelaufs gegen eine Kopie des Charakters
+type PlanSimulation struct {
+	CharacterID   uint             `json:"character_id"`
+	StartEP       int              `json:"start_ep"`
+	StartGold     int              `json:"start_gold"`
+	Steps         []PlanStepResult `json:"steps"`
+	TotalEP       int              `json:"total_ep"`
+	TotalGold     int              `json:"total_gold"`
+	TotalPP       int              `json:"total_pp"`
+	TotalDays     int              `json:"total_training_days"`
+	RemainingEP   int              `json:"remaining_ep"`
+	RemainingGold int              `json:"remaining_gold"`
+	RunsOutAt     *int             `json:"runs_out_at,omitempty"` // erster Schritt, für den die Ressourcen nicht reichen
+	Valid         bool             `json:"valid"`                 // alle Schritte sind regelkonform
+	Feasible      bool             `json:"feasible"`              // gültig und mit den vorhandenen Ressourcen ausführbar
+}
+
+// copyCharacterForPlanning erstellt eine Kopie, an der die Schritte simuliert werden
+func copyCharacterForPlanning(char *models.Char) *models.Char {
+	sim := *char
+	sim.Fertigkeiten = slices.Clone(char.Fertigkeiten)
+	sim.Waffenfertigkeiten = slices.Clone(char.Waffenfertigkeiten)
+	sim.Zauber = slices.Clone(char.Zauber)
+	return &sim
+}
+
+// lookupCharacterSkill sucht eine Fertigkeit oder Waffenfertigkeit des Charakters
+// Zurückgegeben werden die Fertigkeitswerte und das zu speichernde Modell.
+func lookupCharacterSkill(char *models.Char, skillType, name string) (*models.SkFertigkeit, any) {
+	findWeapon := func() (*models.SkFertigkeit, any) {
+		for i := range char.Waffenfertigkeiten {
+			if char.Waffenfertigkeiten[i].Name == name {
+				return &char.Waffenfertigkeiten[i].SkFertigkeit, &char.Waffenfertigkeiten[i]
+			}
+		}
+		return nil, nil
+	}
+	if skillType == "weapon" {
+		return findWeapon()
+	}
+	for i := range char.Fertigkeiten {
+		if char.Fertigkeiten[i].Name == name {
+			return &char.Fertigkeiten[i], &char.Fertigkeiten[i]
+		}
+	}
+	return findWeapon()
+}
+
+// validatePlanStep prüft Aktion, Art, Belohnung und Ressourcenangaben eines Schritts
+func validatePlanStep(step *models.AdvancementPlanStep) error {
+	step.Name = strings.TrimSpace(step.Name)
+	if step.Name == "" {
+		return fmt.Errorf("name fehlt")
+	}
+	if step.Action != "learn" && step.Action != "improve" {
+		return fmt.Errorf("ungültige Aktion '%s' (learn oder improve)", step.Action)
+	}
+	if step.UsePP < 0 || step.UseGold < 0 {
+		return fmt.Errorf("PP und Gold dürfen nicht negativ sein")
+	}
+	rewards := planSkillRewards
+	switch step.Type {
+	case "skill", "weapon":
+		if step.Action == "learn" && step.UsePP > 0 {
+			return fmt.Errorf("praxispunkte können nur beim Verbessern eingesetzt werden")
+		}
+	case "spell":
+		if step.Action != "learn" {
+			return fmt.Errorf("zauber können nur gelernt werden")
+		}
+		if step.UsePP > 0 || step.UseGold > 0 {
+			return fmt.Errorf("beim Lernen von Zaubern werden nur EP berechnet")
+		}
+		rewards = planSpellRewards
+	default:
+		return fmt.Errorf("ungültige Art '%s' (skill, weapon oder spell)", step.Type)
+	}
+	if step.Reward != "" && !slices.Contains(rewards, step.Reward) {
+		return fmt.Errorf("ungültige Belohnung '%s'", step.Reward)
+	}
+	return nil
+}
+
+// checkPlanStepSource prüft, ob ein neu zu lernender Eintrag in der Quellenauswahl des Charakters liegt
+// Den Geltungsbereich der Hausregeln berücksichtigen bereits die Lernkosten-Abfragen.
+func checkPlanStepSource(sim *models.Char, name string, sourceID uint) error {
+	filter, err := characterLearnableFilter(sim)
+	if err != nil {
+		return err
+	}
+	if !filter.sources.Contains(sourceID) {
+		return fmt.Errorf("'%s' ist in der Quellenauswahl dieses Charakters nicht verfügbar", name)
+	}
+	return nil
+}
+
+// calculatePlanStepCosts berechnet die Kosten eines Schritts mit den Funktionen der Lern-Endpunkte
+// So kostet ein ausgeführter Plan genau so viel wie die einzelnen Lern- und Verbesserungsaufrufe.
+func calculatePlanStepCosts(sim *models.Char, result *PlanStepResult) error {
+	step := result.AdvancementPlanStep
+	classCode := characterClassCode(sim)
+	request := gsmaster.LernCostRequest{
+		CharId: sim.ID, Name: step.Name, Type: step.Type, Action: step.Action,
+		TargetLevel: step.TargetLevel, UsePP: step.UsePP, UseGold: step.UseGold,
+	}
+	if step.Reward != "" {
+		request.Reward = &step.Reward
+	}
+
+	var costs []gsmaster.SkillCostResultNew
+	var err error
+	switch {
+	case step.Type == "spell":
+		if hasSpell(sim, step.Name) {
+			return fmt.Errorf("zauber '%s' ist bereits gelernt", step.Name)
+		}
+		spellInfo, infoErr := models.GetSpellLearningInfoNewSystem(step.Name, classCode, models.CharacterContentScope(sim))
+		if infoErr != nil {
+			return fmt.Errorf("zauber '%s' nicht gefunden oder nicht für Klasse '%s' verfügbar", step.Name, classCode)
+		}
+		if err := checkPlanStepSource(sim, step.Name, spellInfo.SourceID); err != nil {
+			return err
+		}
+		result.FromLevel, result.ToLevel = 0, 1
+		costs, result.EP, err = calculateSpellLearningCosts(sim, &request, classCode, spellInfo, 0, 1)
+	case step.Action == "learn":
+		if skill, _ := lookupCharacterSkill(sim, step.Type, step.Name); skill != nil {
+			return fmt.Errorf("fertigkeit '%s' ist bereits auf Wert %d", step.Name, skill.Fertigkeitswert)
+		}
+		skillInfo, infoErr := skillLearningInfo(sim, step.Name, step.Type, classCode)
+		if infoErr != nil {
+			return fmt.Errorf("fertigkeit '%s' nicht gefunden oder nicht für Klasse '%s' verfügbar", step.Name, classCode)
+		}
+		if err := checkPlanStepSource(sim, step.Name, skillInfo.SourceID); err != nil {
+			return err
+		}
+		result.FromLevel, result.ToLevel = 0, max(step.TargetLevel, 1)
+		costs, result.EP, result.Gold, result.PP, err = calculateLearningCosts(sim, &request, classCode, skillInfo, 0, result.ToLevel)
+	default:
+		skill, _ := lookupCharacterSkill(sim, step.Type, step.Name)
+		if skill == nil {
+			return fmt.Errorf("fertigkeit '%s' ist nicht vorhanden und muss zuerst gelernt werden", step.Name)
+		}
+		skillInfo, infoErr := skillLearningInfo(sim, step.Name, step.Type, classCode)
+		if infoErr != nil {
+			return fmt.Errorf("fertigkeit '%s' nicht gefunden oder nicht für Klasse '%s' verfügbar", step.Name, classCode)
+		}
+		result.FromLevel, result.ToLevel = skill.Fertigkeitswert, step.TargetLevel
+		if result.ToLevel == 0 {
+			result.ToLevel = skill.Fertigkeitswert + 1
+		}
+		if result.ToLevel <= result.FromLevel {
+			return fmt.Errorf("zielwert %d liegt nicht über dem aktuellen Wert %d", result.ToLevel, result.FromLevel)
+		}
+		if step.UsePP > skill.Pp {
+			result.Missing = append(result.Missing, PlanMissingPP)
+		}
+		costs, result.EP, result.Gold, result.PP, err = calculateImprovementCosts(sim, &request, classCode, skillInfo, result.FromLevel, result.ToLevel)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, cost := range costs {
+		result.GoldForEP += cost.GoldUsed
+	}
+	result.Gold += result.GoldForEP
+	result.TrainingDays = trainingDays(getTrainingTimeRules(sim), step.Type, step.Action, costs)
+	result.CostDetails = costs
+	return nil
+}
+
+// applyPlanStepToCopy überträgt einen simulierten Schritt auf die Kopie, damit spätere Schritte darauf aufbauen
+func applyPlanStepToCopy(sim *models.Char, result *PlanStepResult) {
+	sim.Erfahrungsschatz.EP -= result.EP
+	sim.Vermoegen.Goldstuecke -= result.Gold
+	if result.Type == "spell" {
+		sim.Zauber = append(sim.Zauber, models.SkZauber{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: result.Name}}})
+		return
+	}
+	if skill, _ := lookupCharacterSkill(sim, result.Type, result.Name); skill != nil {
+		skill.Fertigkeitswert = result.ToLevel
+		skill.Pp -= result.PP
+		return
+	}
+	learned := models.SkFertigkeit{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: result.Name}}, Fertigkeitswert: result.ToLevel, Improvable: true}
+	if result.Type == "weapon" {
+		sim.Waffenfertigkeiten = append(sim.Waffenfertigkeiten, models.SkWaffenfertigkeit{SkFertigkeit: learned})
+	} else {
+		sim.Fertigkeiten = append(sim.Fertigkeiten, learned)
+	}
+}
+
+// SimulateAdvancementPlan spielt die Schritte der Reihe nach an einer Kopie des Charakters durch
+// Spätere Schritte sehen die Werte, PP, EP und das Gold nach den vorherigen Schritten.
+// Fehlerhafte Schritte werden übersprungen, unbezahlbare Schritte werden weiter simuliert.
+func SimulateAdvancementPlan(char *models.Char, steps []models.AdvancementPlanStep) PlanSimulation {
+	sim := copyCharacterForPlanning(char)
+	simulation := PlanSimulation{
+		CharacterID: char.ID,
+		StartEP:     char.Erfahrungsschatz.EP,
+		StartGold:   char.Vermoegen.Goldstuecke,
+		Steps:       make([]PlanStepResult, 0, len(steps)),
+		Valid:       true,
+	}
+
+	for i, step := range steps {
+		result := PlanStepResult{Index: i, AdvancementPlanStep: step}
+		err := validatePlanStep(&result.AdvancementPlanStep)
+		if err == nil {
+			err = calculatePlanStepCosts(sim, &result)
+		}
+		if err != nil {
+			result.Error = err.Error()
+			result.CostDetails = nil
+			simulation.Valid = false
+		} else {
+			applyPlanStepToCopy(sim, &result)
+			simulation.TotalEP += result.EP
+			simulation.TotalGold += result.Gold
+			simulation.TotalPP += result.PP
+			simulation.TotalDays += result.TrainingDays
+			if sim.Erfahrungsschatz.EP < 0 {
+				result.Missing = append(result.Missing, PlanMissingEP)
+			}
+			if sim.Vermoegen.Goldstuecke < 0 {
+				result.Missing = append(result.Missing, PlanMissingGold)
+			}
+			result.Affordable = len(result.Missing) == 0
+			if !result.Affordable && simulation.RunsOutAt == nil {
+				simulation.RunsOutAt = &result.Index
+			}
+		}
+		result.CumulativeEP = simulation.TotalEP
+		result.CumulativeGold = simulation.TotalGold
+		result.CumulativePP = simulation.TotalPP
+		result.RemainingEP = sim.Erfahrungsschatz.EP
+		result.RemainingGold = sim.Vermoegen.Goldstuecke
+		simulation.Steps = append(simulation.Steps, result)
+	}
+
+	simulation.RemainingEP = sim.Erfahrungsschatz.EP
+	simulation.RemainingGold = sim.Vermoegen.Goldstuecke
+	simulation.Feasible = simulation.Valid && simulation.RunsOutAt == nil
+	return simulation
+}
@@ -0,0 +1,401 @@
+package character
+
+import (
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// errPlanAlreadyExecuted meldet, dass ein Plan bereits (ggf. von einer parallelen Anfrage) ausgeführt wurde
+var errPlanAlreadyExecuted = errors.New("advancement plan already executed")
+
+// planInfeasibleError meldet einen Plan, der mit dem aktuellen Stand nicht ausführbar ist
+type planInfeasibleError struct {
+	simulation PlanSimulation
+}
+
+func (e *planInfeasibleError) Error() string {
+	return "advancement plan is not feasible"
+}
+
+// AdvancementPlanRequest enthält die geordneten Schritte eines Steigerungsplans
+type AdvancementPlanRequest struct {
+	Name  string                       `json:"name,omitempty"`
+	Steps []models.AdvancementPlanStep `json:"steps" binding:"required,min=1"`
+}
+
+// ExecuteAdvancementPlanRequest enthält optionale Notizen für das Audit-Log
+type ExecuteAdvancementPlanRequest struct {
+	Notes         string `json:"notes,omitempty"`
+	CheckDowntime bool   `json:"check_downtime,omitempty"` // Lernzeit gegen das Ausfallzeit-Konto prüfen und buchen
+}
+
+// AdvancementPlanResponse enthält einen gespeicherten Plan mit der Simulation gegen den aktuellen Charakter
+type AdvancementPlanResponse struct {
+	Plan       models.AdvancementPlan `json:"plan"`
+	Simulation PlanSimulation         `json:"simulation"`
+}
+
+// bindAdvancementPlanRequest liest und prüft die Schritte aus dem Request
+func bindAdvancementPlanRequest(c *gin.Context) (*AdvancementPlanRequest, bool) {
+	var req AdvancementPlanRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, "Ungültige Anfrageparameter: "+err.Error())
+		return nil, false
+	}
+	if len(req.Steps) > maxAdvancementPlanSteps {
+		respondWithError(c, http.StatusBadRequest, fmt.Sprintf("Ein Plan darf höchstens %d Schritte enthalten", maxAdvancementPlanSteps))
+		return nil, false
+	}
+	return &req, true
+}
+
+// loadAdvancementPlan lädt einen gespeicherten Plan des Charakters aus der URL
+func loadAdvancementPlan(c *gin.Context, char *models.Char) (*models.AdvancementPlan, bool) {
+	var plan models.AdvancementPlan
+	if err := database.DB.Where("id = ? AND character_id = ?", c.Param("planId"), char.ID).First(&plan).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Plan nicht gefunden")
+		return nil, false
+	}
+	return &plan, true
+}
+
+// describePlanStep liefert Audit-Grund und Beschreibung eines Planschritts
+func describePlanStep(step PlanStepResult) (AuditLogReason, string) {
+	switch {
+	case step.Type == "spell":
+		return ReasonSpellLearning, fmt.Sprintf("Zauber '%s' gelernt", step.Name)
+	case step.Action == "learn":
+		return ReasonSkillLearning, fmt.Sprintf("Fertigkeit '%s' bis Level %d gelernt", step.Name, step.ToLevel)
+	default:
+		return ReasonSkillImprovement, fmt.Sprintf("Fertigkeit '%s' von %d auf %d verbessert", step.Name, step.FromLevel, step.ToLevel)
+	}
+}
+
+// executeAdvancementPlanTx überträgt alle simulierten Schritte innerhalb einer Transaktion
+// Jeder Schritt erhält eigene Audit-Einträge für EP und Gold, bei trackDowntime auch eine Buchung der Lernzeit.
+func executeAdvancementPlanTx(tx *gorm.DB, char *models.Char, simulation PlanSimulation, trackDowntime bool, userID uint, notes string) error {
+	ep := char.Erfahrungsschatz.EP
+	gold := char.Vermoegen.Goldstuecke
+	for _, step := range simulation.Steps {
+		reason, summary := describePlanStep(step)
+		if notes != "" {
+			summary += ": " + notes
+		}
+
+		switch {
+		case step.Type == "spell":
+			spell := models.SkZauber{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: step.Name}, CharacterID: char.ID}}
+			if err := tx.Create(&spell).Error; err != nil {
+				return err
+			}
+			char.Zauber = append(char.Zauber, spell)
+		case step.Action == "learn":
+			learned := models.SkFertigkeit{
+				BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: step.Name}, CharacterID: char.ID},
+				Fertigkeitswert: step.ToLevel,
+				Improvable:      true,
+			}
+			if step.Type == "weapon" {
+				weapon := models.SkWaffenfertigkeit{SkFertigkeit: learned}
+				if err := tx.Create(&weapon).Error; err != nil {
+					return err
+				}
+				char.Waffenfertigkeiten = append(char.Waffenfertigkeiten, weapon)
+			} else {
+				if err := tx.Create(&learned).Error; err != nil {
+					return err
+				}
+				char.Fertigkeiten = append(char.Fertigkeiten, learned)
+			}
+		default:
+			skill, model := lookupCharacterSkill(char, step.Type, step.Name)
+			if skill == nil {
+				return fmt.Errorf("fertigkeit '%s' nicht gefunden", step.Name)
+			}
+			skill.Fertigkeitswert = step.ToLevel
+			skill.Pp -= step.PP
+			if err := tx.Model(model).Updates(map[string]any{"fertigkeitswert": skill.Fertigkeitswert, "pp": skill.Pp}).Error; err != nil {
+				return err
+			}
+		}
+
+		if trackDowntime {
+			if err := RecordTrainingTimeTx(tx, char.ID, step.Name, step.TrainingDays, reason, userID, summary); err != nil {
+				return err
+			}
+		}
+		if step.EP > 0 {
+			if err := CreateAuditLogEntryTx(tx, char.ID, "experience_points", ep, ep-step.EP, reason, userID, summary); err != nil {
+				return err
+			}
+			ep -= step.EP
+		}
+		if step.Gold > 0 {
+			if err := CreateAuditLogEntryTx(tx, char.ID, "gold", gold, gold-step.Gold, reason, userID, summary); err != nil {
+				return err
+			}
+			gold -= step.Gold
+		}
+	}
+
+	// relativ abziehen, damit parallele Buchungen nicht überschrieben werden
+	if simulation.TotalEP > 0 {
+		if err := tx.Model(&char.Erfahrungsschatz).Update("ep", gorm.Expr("ep - ?", simulation.TotalEP)).Error; err != nil {
+			return err
+		}
+	}
+	if simulation.TotalGold > 0 {
+		if err := tx.Model(&char.Vermoegen).Update("goldstuecke", gorm.Expr("goldstuecke - ?", simulation.TotalGold)).Error; err != nil {
+			return err
+		}
+	}
+	char.Erfahrungsschatz.EP = ep
+	char.Vermoegen.Goldstuecke = gold
+	return nil
+}
+
+// SimulateAdvancementPlanHandler rechnet einen Plan probeweise durch, ohne etwas zu speichern
+func SimulateAdvancementPlanHandler(c *gin.Context) {
+	char, ok := loadCharacterWithResources(c)
+	if !ok {
+		return
+	}
+	if !CanReadCharacter(char, c.GetUint("userID")) {
+		respondWithError(c, http.StatusForbidden, "You are not authorized to view this character")
+		return
+	}
+	req, ok := bindAdvancementPlanRequest(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, SimulateAdvancementPlan(char, req.Steps))
+}
+
+// ListAdvancementPlans listet die gespeicherten Pläne eines Charakters
+func ListAdvancementPlans(c *gin.Context) {
+	char, ok := loadCharacterWithResources(c)
+	if !ok {
+		return
+	}
+	if !CanReadCharacter(char, c.GetUint("userID")) {
+		respondWithError(c, http.StatusForbidden, "You are not authorized to view this character")
+		return
+	}
+
+	query := database.DB.Where("character_id = ?", char.ID)
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	var plans []models.AdvancementPlan
+	if err := query.Order("updated_at DESC").Find(&plans).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to load plans")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"character_id": char.ID, "plans": plans})
+}
+
+// GetAdvancementPlan liefert einen Plan mit einer Simulation gegen den aktuellen Stand des Charakters
+func GetAdvancementPlan(c *gin.Context) {
+	char, ok := loadCharacterWithResources(c)
+	if !ok {
+		return
+	}
+	if !CanReadCharacter(char, c.GetUint("userID")) {
+		respondWithError(c, http.StatusForbidden, "You are not authorized to view this character")
+		return
+	}
+	plan, ok := loadAdvancementPlan(c, char)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, AdvancementPlanResponse{Plan: *plan, Simulation: SimulateAdvancementPlan(char, plan.Steps)})
+}
+
+// CreateAdvancementPlan speichert einen Plan, dessen Schritte regelkonform sind
+// Ein Plan darf mehr kosten, als der Charakter derzeit besitzt.
+func CreateAdvancementPlan(c *gin.Context) {
+	char, ok := loadCharacterWithResources(c)
+	if !ok {
+		return
+	}
+	if !checkCharacterOwnership(c, char) {
+		return
+	}
+	req, ok := bindAdvancementPlanRequest(c)
+	if !ok {
+		return
+	}
+
+	simulation := SimulateAdvancementPlan(char, req.Steps)
+	if !simulation.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Der Plan enthält ungültige Schritte", "simulation": simulation})
+		return
+	}
+
+	plan := models.AdvancementPlan{
+		CharacterID: char.ID,
+		UserID:      c.GetUint("userID"),
+		Name:        req.Name,
+		Steps:       planStepsFromSimulation(simulation),
+		Status:      models.AdvancementPlanDraft,
+	}
+	if err := database.DB.Create(&plan).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to save plan")
+		return
+	}
+	c.JSON(http.StatusCreated, AdvancementPlanResponse{Plan: plan, Simulation: simulation})
+}
+
+// UpdateAdvancementPlan ersetzt Name und Schritte eines noch nicht ausgeführten Plans
+func UpdateAdvancementPlan(c *gin.Context) {
+	char, ok := loadCharacterWithResources(c)
+	if !ok {
+		return
+	}
+	if !checkCharacterOwnership(c, char) {
+		return
+	}
+	plan, ok := loadAdvancementPlan(c, char)
+	if !ok {
+		return
+	}
+	if plan.Status == models.AdvancementPlanExecuted {
+		respondWithError(c, http.StatusConflict, "Der Plan wurde bereits ausgeführt")
+		return
+	}
+	req, ok := bindAdvancementPlanRequest(c)
+	if !ok {
+		return
+	}
+
+	simulation := SimulateAdvancementPlan(char, req.Steps)
+	if !simulation.Valid {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Der Plan enthält ungültige Schritte", "simulation": simulation})
+		return
+	}
+	plan.Name = req.Name
+	plan.Steps = planStepsFromSimulation(simulation)
+	if err := database.DB.Save(plan).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to save plan")
+		return
+	}
+	c.JSON(http.StatusOK, AdvancementPlanResponse{Plan: *plan, Simulation: simulation})
+}
+
+// DeleteAdvancementPlan löscht einen gespeicherten Plan
+func DeleteAdvancementPlan(c *gin.Context) {
+	char, ok := loadCharacterWithResources(c)
+	if !ok {
+		return
+	}
+	if !checkCharacterOwnership(c, char) {
+		return
+	}
+	plan, ok := loadAdvancementPlan(c, char)
+	if !ok {
+		return
+	}
+	if err := database.DB.Delete(plan).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to delete plan")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Plan gelöscht"})
+}
+
+// ExecuteAdvancementPlan führt alle Schritte eines gespeicherten Plans in einer Transaktion aus
+// Der Plan wird vorher gegen den aktuellen Stand simuliert; reicht etwas nicht, bleibt alles unverändert.
+func ExecuteAdvancementPlan(c *gin.Context) {
+	char, ok := loadCharacterWithResources(c)
+	if !ok {
+		return
+	}
+	if !checkCharacterOwnership(c, char) {
+		return
+	}
+	plan, ok := loadAdvancementPlan(c, char)
+	if !ok {
+		return
+	}
+	if plan.Status == models.AdvancementPlanExecuted {
+		respondWithError(c, http.StatusConflict, "Der Plan wurde bereits ausgeführt")
+		return
+	}
+	var req ExecuteAdvancementPlanRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	userID := c.GetUint("userID")
+	now := time.Now()
+	trackDowntime := downtimeTracked(getTrainingTimeRules(char), req.CheckDowntime)
+	var simulation PlanSimulation
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		// den Plan zuerst für diese Anfrage beanspruchen, damit eine parallele Ausführung scheitert
+		result := tx.Model(&models.AdvancementPlan{}).
+			Where("id = ? AND status <> ?", plan.ID, models.AdvancementPlanExecuted).
+			Updates(map[string]any{"status": models.AdvancementPlanExecuted, "executed_at": &now})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected != 1 {
+			return errPlanAlreadyExecuted
+		}
+		if err := tx.First(plan, plan.ID).Error; err != nil {
+			return err
+		}
+		current, err := loadCharacterForImprovementTx(tx, char.ID)
+		if err != nil {
+			return err
+		}
+		char = current
+
+		simulation = SimulateAdvancementPlan(char, plan.Steps)
+		if !simulation.Feasible {
+			return &planInfeasibleError{simulation: simulation}
+		}
+		return executeAdvancementPlanTx(tx, char, simulation, trackDowntime, userID, req.Notes)
+	})
+	var infeasible *planInfeasibleError
+	switch {
+	case errors.Is(err, errPlanAlreadyExecuted):
+		respondWithError(c, http.StatusConflict, "Der Plan wurde bereits ausgeführt")
+		return
+	case errors.As(err, &infeasible):
+		c.JSON(http.StatusConflict, gin.H{"error": "Der Plan kann mit dem aktuellen Stand nicht ausgeführt werden", "simulation": infeasible.simulation})
+		return
+	case err != nil:
+		logger.Error("Steigerungsplan %d für Charakter %d fehlgeschlagen: %s", plan.ID, char.ID, err.Error())
+		respondDowntimeTxError(c, err, "Fehler beim Ausführen des Plans")
+		return
+	}
+
+	logger.Info("Charakter %d: Steigerungsplan %d mit %d Schritten ausgeführt (%d EP, %d Gold)", char.ID, plan.ID, len(simulation.Steps), simulation.TotalEP, simulation.TotalGold)
+	c.JSON(http.StatusOK, gin.H{
+		"plan":           plan,
+		"simulation":     simulation,
+		"remaining_ep":   char.Erfahrungsschatz.EP,
+		"remaining_gold": char.Vermoegen.Goldstuecke,
+	})
+}
+
+// planStepsFromSimulation übernimmt die geprüften Schritte (mit bereinigten Namen) in den Plan
+func planStepsFromSimulation(simulation PlanSimulation) []models.AdvancementPlanStep {
+	steps := make([]models.AdvancementPlanStep, 0, len(simulation.Steps))
+	for _, step := range simulation.Steps {
+		steps = append(steps, step.AdvancementPlanStep)
+	}
+	return steps
+}
@@ -0,0 +1,180 @@
+package character
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestAdvancementPlanner(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+	require.NoError(t, database.DB.Create(&models.GameSystem{Code: "M5", Name: "midgard", IsActive: true}).Error)
+	seedImprovementCosts(t, map[int]int{12: 2, 13: 3, 14: 4, 15: 5})
+
+	owner := ensureUserExists(t, 245)
+	stranger := ensureUserExists(t, 246)
+	char := createCharacterOwnedBy(t, owner.UserID)
+	seedExperience(t, char, 100)
+	seedWealth(t, char, 100, 0, 0)
+	seedSkill(t, char, "Athletik", 11, 2)
+	params := map[string]string{"id": fmt.Sprint(char.ID)}
+
+	improve := func(target, pp int) map[string]any {
+		return map[string]any{"action": "improve", "type": "skill", "name": "Athletik", "target_level": target, "use_pp": pp}
+	}
+	simulate := func(steps ...map[string]any) PlanSimulation {
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"steps": steps}, owner.UserID, params)
+		SimulateAdvancementPlanHandler(ctx)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var simulation PlanSimulation
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &simulation))
+		return simulation
+	}
+	create := func(userID uint, steps ...map[string]any) (int, AdvancementPlanResponse) {
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"name": "Nächste Abenteuer", "steps": steps}, userID, params)
+		CreateAdvancementPlan(ctx)
+		var response AdvancementPlanResponse
+		if w.Code == http.StatusCreated {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response
+	}
+	execute := func(planID uint) int {
+		ctx, w := buildJSONContext(t, http.MethodPost, nil, owner.UserID, map[string]string{"id": fmt.Sprint(char.ID), "planId": fmt.Sprint(planID)})
+		ExecuteAdvancementPlan(ctx)
+		return w.Code
+	}
+
+	t.Run("simulation builds on earlier steps", func(t *testing.T) {
+		simulation := simulate(improve(13, 2), improve(0, 0))
+		assert.True(t, simulation.Valid)
+		assert.False(t, simulation.Feasible)
+		require.Len(t, simulation.Steps, 2)
+
+		// Wert 12 allein mit PP, Wert 13 kostet 3 TE zu 10 EP und 20 Gold
+		first := simulation.Steps[0]
+		assert.Equal(t, 11, first.FromLevel)
+		assert.Equal(t, 13, first.ToLevel)
+		assert.Equal(t, 2, first.PP)
+		assert.Equal(t, 30, first.EP)
+		assert.Equal(t, 60, first.Gold)
+		assert.True(t, first.Affordable)
+
+		// der zweite Schritt startet bei 13 und überzieht das Gold
+		second := simulation.Steps[1]
+		assert.Equal(t, 13, second.FromLevel)
+		assert.Equal(t, 14, second.ToLevel)
+		assert.Equal(t, 70, second.CumulativeEP)
+		assert.Equal(t, 140, second.CumulativeGold)
+		assert.Equal(t, -40, second.RemainingGold)
+		assert.Equal(t, []string{PlanMissingGold}, second.Missing)
+		require.NotNil(t, simulation.RunsOutAt)
+		assert.Equal(t, 1, *simulation.RunsOutAt)
+
+		// der Charakter selbst bleibt unverändert
+		assert.Equal(t, 2, fetchSkillPp(t, char.ID, "Athletik"))
+		assert.Equal(t, 100, reloadCharacterWithPreloads(t, char.ID).Erfahrungsschatz.EP)
+	})
+
+	t.Run("invalid steps are reported", func(t *testing.T) {
+		simulation := simulate(
+			map[string]any{"action": "improve", "type": "skill", "name": "Gibtsnicht"},
+			map[string]any{"action": "improve", "type": "spell", "name": "Angst"},
+			improve(13, 5),
+		)
+		assert.False(t, simulation.Valid)
+		assert.NotEmpty(t, simulation.Steps[0].Error)
+		assert.NotEmpty(t, simulation.Steps[1].Error)
+		assert.Equal(t, []string{PlanMissingPP}, simulation.Steps[2].Missing)
+
+		code, _ := create(owner.UserID, map[string]any{"action": "improve", "type": "skill", "name": "Gibtsnicht"})
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = create(stranger.UserID, improve(13, 2))
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("unaffordable plan is saved but not executed", func(t *testing.T) {
+		code, response := create(owner.UserID, improve(15, 0))
+		require.Equal(t, http.StatusCreated, code)
+		assert.Equal(t, models.AdvancementPlanDraft, response.Plan.Status)
+		assert.False(t, response.Simulation.Feasible)
+
+		assert.Equal(t, http.StatusConflict, execute(response.Plan.ID))
+		assert.Equal(t, 100, reloadCharacterWithPreloads(t, char.ID).Erfahrungsschatz.EP)
+		var plan models.AdvancementPlan
+		require.NoError(t, database.DB.First(&plan, response.Plan.ID).Error)
+		assert.NotEqual(t, models.AdvancementPlanExecuted, plan.Status, "an infeasible plan stays open")
+	})
+
+	t.Run("saved plan executes in one transaction", func(t *testing.T) {
+		code, response := create(owner.UserID, improve(12, 2), improve(13, 0))
+		require.Equal(t, http.StatusCreated, code)
+		require.True(t, response.Simulation.Feasible)
+
+		require.Equal(t, http.StatusOK, execute(response.Plan.ID))
+		reloaded := reloadCharacterWithPreloads(t, char.ID)
+		assert.Equal(t, 70, reloaded.Erfahrungsschatz.EP)
+		assert.Equal(t, 40, reloaded.Vermoegen.Goldstuecke)
+		var skill models.SkFertigkeit
+		require.NoError(t, database.DB.Where("character_id = ? AND name = ?", char.ID, "Athletik").First(&skill).Error)
+		assert.Equal(t, 13, skill.Fertigkeitswert)
+		assert.Equal(t, 0, skill.Pp)
+
+		var plan models.AdvancementPlan
+		require.NoError(t, database.DB.First(&plan, response.Plan.ID).Error)
+		assert.Equal(t, models.AdvancementPlanExecuted, plan.Status)
+		assert.NotNil(t, plan.ExecutedAt)
+
+		entries, err := GetAuditLogForField(char.ID, "experience_points")
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, string(ReasonSkillImprovement), entries[0].Reason)
+		assert.Equal(t, -30, entries[0].Difference)
+
+		assert.Equal(t, http.StatusConflict, execute(response.Plan.ID))
+	})
+	t.Run("concurrent execution applies the plan once", func(t *testing.T) {
+		require.NoError(t, database.DB.Model(&models.Vermoegen{}).Where("character_id = ?", char.ID).Update("goldstuecke", 100).Error)
+		code, response := create(owner.UserID, improve(14, 0))
+		require.Equal(t, http.StatusCreated, code)
+		require.True(t, response.Simulation.Feasible)
+
+		// eine parallele Anfrage führt den Plan aus, nachdem er hier bereits geladen wurde
+		fired := false
+		executeConcurrently := func(db *gorm.DB) {
+			plan, ok := db.Statement.Dest.(*models.AdvancementPlan)
+			if !ok || fired || plan.ID != response.Plan.ID {
+				return
+			}
+			fired = true
+			db.Session(&gorm.Session{NewDB: true}).Model(&models.AdvancementPlan{}).
+				Where("id = ?", plan.ID).Update("status", models.AdvancementPlanExecuted)
+		}
+		require.NoError(t, database.DB.Callback().Query().After("gorm:query").Register("test:concurrent_execute", executeConcurrently))
+		t.Cleanup(func() { _ = database.DB.Callback().Query().Remove("test:concurrent_execute") })
+		before := reloadCharacterWithPreloads(t, char.ID)
+
+		assert.Equal(t, http.StatusConflict, execute(response.Plan.ID))
+		require.True(t, fired)
+		after := reloadCharacterWithPreloads(t, char.ID)
+		assert.Equal(t, before.Erfahrungsschatz.EP, after.Erfahrungsschatz.EP)
+		assert.Equal(t, before.Vermoegen.Goldstuecke, after.Vermoegen.Goldstuecke)
+		var skill models.SkFertigkeit
+		require.NoError(t, database.DB.Where("character_id = ? AND name = ?", char.ID, "Athletik").First(&skill).Error)
+		assert.Equal(t, 13, skill.Fertigkeitswert)
+	})
+}
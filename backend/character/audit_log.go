@@ -3,6 +3,8 @@ package character
 import (
 	"bamort/database"
 	"bamort/models"
+
+	"gorm.io/gorm"
 )
 
 // AuditLogReason definiert Standard-Gründe für Änderungen
@@ -17,10 +19,23 @@ const (
 	ReasonReward           AuditLogReason = "reward"
 	ReasonCorrection       AuditLogReason = "correction"
 	ReasonImport           AuditLogReason = "import"
+	ReasonDamage           AuditLogReason = "damage"
+	ReasonHealing          AuditLogReason = "healing"
+	ReasonRest             AuditLogReason = "rest"
+	ReasonAPSpent          AuditLogReason = "ap_spent"
+	ReasonEncounter        AuditLogReason = "encounter"
+	ReasonSpecialization   AuditLogReason = "specialization"
+	ReasonPPConversion     AuditLogReason = "pp_conversion"
+	ReasonAgeing           AuditLogReason = "ageing"
 )
 
 // CreateAuditLogEntry erstellt einen neuen Audit-Log-Eintrag
 func CreateAuditLogEntry(characterID uint, fieldName string, oldValue, newValue int, reason AuditLogReason, userID uint, notes string) error {
+	return CreateAuditLogEntryTx(database.DB, characterID, fieldName, oldValue, newValue, reason, userID, notes)
+}
+
+// CreateAuditLogEntryTx erstellt einen Audit-Log-Eintrag innerhalb einer laufenden Transaktion
+func CreateAuditLogEntryTx(tx *gorm.DB, characterID uint, fieldName string, oldValue, newValue int, reason AuditLogReason, userID uint, notes string) error {
 	entry := models.AuditLogEntry{
 		CharacterID: characterID,
 		FieldName:   fieldName,
@@ -32,7 +47,7 @@ func CreateAuditLogEntry(characterID uint, fieldName string, oldValue, newValue
 		Notes:       notes,
 	}
 
-	return database.DB.Create(&entry).Error
+	return tx.Create(&entry).Error
 }
 
 // GetAuditLogForCharacter holt alle Audit-Log-Einträge für einen Charakter
@@ -1,6 +1,7 @@
 package character
 
 import (
+	"bamort/database"
 	"bamort/models"
 	"net/http"
 	"strconv"
@@ -35,10 +36,28 @@ func GetCharacterAuditLog(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
+	response := gin.H{
 		"character_id": uint(id),
 		"entries":      entries,
-	})
+	}
+
+	// Ausfallzeit-Konto optional mitliefern (?include=downtime)
+	if c.Query("include") == "downtime" {
+		downtime, err := GetDowntimeLedger(uint(id), "")
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, "Failed to retrieve downtime")
+			return
+		}
+		balance, err := GetDowntimeBalance(database.DB, uint(id))
+		if err != nil {
+			respondWithError(c, http.StatusInternalServerError, "Failed to retrieve downtime")
+			return
+		}
+		response["downtime"] = downtime
+		response["downtime_balance"] = balance
+	}
+
+	c.JSON(http.StatusOK, response)
 }
 
 // GetAuditLogStats gibt Statistiken über Änderungen zurück
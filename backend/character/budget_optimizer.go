@@ -0,0 +1,422 @@
+package character
+
+import (
+	"bamort/gsmaster"
+	"bamort/models"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Gewichte für die Prioritäten beim Budget-Optimierer
+const (
+	budgetWeightDefault  = 1
+	budgetWeightCategory = 2
+	budgetWeightWeapon   = 2
+	budgetWeightFavorite = 4
+)
+
+// Grenzen und Standardwerte für Vorschläge
+const (
+	defaultBudgetLevelsPerSkill = 3
+	maxBudgetLevelsPerSkill     = 5
+	defaultBudgetOptions        = 20
+	maxBudgetOptions            = 100
+	defaultBudgetBundles        = 3
+)
+
+// Strategien, nach denen Pakete zusammengestellt werden
+const (
+	BudgetStrategyPriority   = "priority"   // wichtigste Fertigkeiten zuerst
+	BudgetStrategyEfficiency = "efficiency" // meiste Priorität je EP
+	BudgetStrategyBreadth    = "breadth"    // möglichst viele günstige Verbesserungen
+)
+
+// Gründe für eine höhere Gewichtung
+const (
+	BudgetReasonFavorite = "favorite"
+	BudgetReasonCategory = "category"
+	BudgetReasonWeapon   = "weapon"
+)
+
+// BudgetPriorities sind die Vorlieben des Spielers
+type BudgetPriorities struct {
+	FavoriteSkills []string `json:"favorite_skills,omitempty"`
+	Categories     []string `json:"categories,omitempty"`
+	WeaponSkills   bool     `json:"weapon_skills,omitempty"`
+}
+
+// BudgetOptimizerRequest beschreibt Budget, Prioritäten und Umfang der Vorschläge
+// Ohne Angabe gelten EP und Gold des Charakters als Budget.
+type BudgetOptimizerRequest struct {
+	EP                *int             `json:"ep,omitempty"`
+	Gold              *int             `json:"gold,omitempty"`
+	UsePP             *bool            `json:"use_pp,omitempty"`        // PP der Fertigkeiten einsetzen (Standard: ja)
+	IncludeLearn      *bool            `json:"include_learn,omitempty"` // neue Fertigkeiten, Waffenfertigkeiten und Zauber vorschlagen (Standard: ja)
+	Priorities        BudgetPriorities `json:"priorities"`
+	MaxLevelsPerSkill int              `json:"max_levels_per_skill,omitempty"`
+	MaxOptions        int              `json:"max_options,omitempty"`
+	MaxBundles        int              `json:"max_bundles,omitempty"`
+}
+
+// BudgetOption ist eine einzelne Lern- oder Verbesserungsmöglichkeit
+type BudgetOption struct {
+	Name      string   `json:"name"`
+	Type      string   `json:"type"`   // skill, weapon oder spell
+	Action    string   `json:"action"` // learn oder improve
+	Category  string   `json:"category,omitempty"`
+	FromLevel int      `json:"from_level"`
+	ToLevel   int      `json:"to_level"`
+	EP        int      `json:"ep"`
+	Gold      int      `json:"gold"`
+	PP        int      `json:"pp"`
+	Weight    int      `json:"weight"`
+	Reasons   []string `json:"reasons,omitempty"`
+}
+
+// BudgetBundle ist eine Auswahl von Möglichkeiten, die zusammen ins Budget passt
+type BudgetBundle struct {
+	Strategy      string                       `json:"strategy"`
+	Items         []BudgetOption               `json:"items"`
+	EP            int                          `json:"ep"`
+	Gold          int                          `json:"gold"`
+	PP            int                          `json:"pp"`
+	Value         int                          `json:"value"` // Summe der Gewichte aller Verbesserungen
+	RemainingEP   int                          `json:"remaining_ep"`
+	RemainingGold int                          `json:"remaining_gold"`
+	Steps         []models.AdvancementPlanStep `json:"steps"` // direkt für den Steigerungsplaner verwendbar
+}
+
+// BudgetSuggestions ist die Antwort des Budget-Optimierers
+type BudgetSuggestions struct {
+	CharacterID uint           `json:"character_id"`
+	BudgetEP    int            `json:"budget_ep"`
+	BudgetGold  int            `json:"budget_gold"`
+	Options     []BudgetOption `json:"options"`
+	Bundles     []BudgetBundle `json:"bundles"`
+}
+
+// budgetCandidate fasst die aufeinander aufbauenden Schritte einer Fertigkeit zusammen
+type budgetCandidate struct {
+	increments []BudgetOption
+}
+
+// budgetCost rechnet EP und Gold in eine Vergleichsgröße um (10 GS entsprechen 1 EP)
+func budgetCost(option BudgetOption) int {
+	return max(option.EP+option.Gold/10, 1)
+}
+
+// budgetWeight gewichtet eine Fertigkeit nach den Prioritäten
+func budgetWeight(priorities BudgetPriorities, name, skillType, category string) (int, []string) {
+	weight := budgetWeightDefault
+	var reasons []string
+	if slices.ContainsFunc(priorities.FavoriteSkills, func(s string) bool { return strings.EqualFold(s, name) }) {
+		weight = max(weight, budgetWeightFavorite)
+		reasons = append(reasons, BudgetReasonFavorite)
+	}
+	if category != "" && slices.ContainsFunc(priorities.Categories, func(s string) bool { return strings.EqualFold(s, category) }) {
+		weight = max(weight, budgetWeightCategory)
+		reasons = append(reasons, BudgetReasonCategory)
+	}
+	if priorities.WeaponSkills && skillType == "weapon" {
+		weight = max(weight, budgetWeightWeapon)
+		reasons = append(reasons, BudgetReasonWeapon)
+	}
+	return weight, reasons
+}
+
+// improvementCandidate berechnet die nächsten Verbesserungen einer vorhandenen Fertigkeit
+// Die PP werden wie beim Verbessern zuerst eingesetzt; es wird nur so weit gerechnet, wie das Budget reicht.
+func improvementCandidate(char *models.Char, skill models.SkFertigkeit, skillType string, skillInfo models.SkillLearningInfo, req *BudgetOptimizerRequest, budgetEP, budgetGold int) (budgetCandidate, bool) {
+	classCode := characterClassCode(char)
+	weight, reasons := budgetWeight(req.Priorities, skill.Name, skillType, skillInfo.CategoryName)
+
+	remainingPP := 0
+	if req.UsePP == nil || *req.UsePP {
+		remainingPP = skill.Pp
+	}
+	noGold := 0
+	var candidate budgetCandidate
+	totalEP, totalGold := 0, 0
+	for level := skill.Fertigkeitswert + 1; len(candidate.increments) < req.MaxLevelsPerSkill; level++ {
+		request := gsmaster.LernCostRequest{CharId: char.ID, Name: skill.Name, Type: skillType, Action: "improve", CurrentLevel: level - 1, TargetLevel: level}
+		result := gsmaster.SkillCostResultNew{CharacterID: fmt.Sprint(char.ID), CharacterClass: classCode, SkillName: skill.Name}
+		if err := CalculateSkillImproveCostNewSystem(&request, &result, level, &remainingPP, &noGold, &skillInfo); err != nil {
+			break // höchster Wert erreicht
+		}
+		if result.PPUsed == 0 && result.LE == 0 {
+			break // keine Kosten für diesen Wert hinterlegt
+		}
+		totalEP += result.EP
+		totalGold += result.GoldCost
+		if totalEP > budgetEP || totalGold > budgetGold {
+			break
+		}
+		candidate.increments = append(candidate.increments, BudgetOption{
+			Name: skill.Name, Type: skillType, Action: "improve", Category: skillInfo.CategoryName,
+			FromLevel: level - 1, ToLevel: level,
+			EP: result.EP, Gold: result.GoldCost, PP: result.PPUsed,
+			Weight: weight, Reasons: reasons,
+		})
+	}
+	return candidate, len(candidate.increments) > 0
+}
+
+// learnCandidate berechnet die Lernkosten einer neuen Fertigkeit oder Waffenfertigkeit wie die Liste der verfügbaren Fertigkeiten
+func learnCandidate(char *models.Char, skillType string, skillInfo models.SkillLearningInfo, req *BudgetOptimizerRequest, budgetEP, budgetGold int) (budgetCandidate, bool) {
+	classCode := characterClassCode(char)
+	request := gsmaster.LernCostRequest{CharId: char.ID, Name: skillInfo.SkillName, Type: skillType, Action: "learn", TargetLevel: 1}
+	result := gsmaster.SkillCostResultNew{CharacterID: fmt.Sprint(char.ID), CharacterClass: classCode, SkillName: skillInfo.SkillName, TargetLevel: 1}
+	noPP, noGold := 0, 0
+	if err := calculateSkillLearnCostNewSystem(&request, &result, &noPP, &noGold, &skillInfo); err != nil {
+		return budgetCandidate{}, false
+	}
+	if result.EP > budgetEP || result.GoldCost > budgetGold {
+		return budgetCandidate{}, false
+	}
+	weight, reasons := budgetWeight(req.Priorities, skillInfo.SkillName, skillType, skillInfo.CategoryName)
+	return budgetCandidate{increments: []BudgetOption{{
+		Name: skillInfo.SkillName, Type: skillType, Action: "learn", Category: skillInfo.CategoryName,
+		FromLevel: 0, ToLevel: 1, EP: result.EP, Gold: result.GoldCost,
+		Weight: weight, Reasons: reasons,
+	}}}, true
+}
+
+// spellCandidate berechnet die Lernkosten eines neuen Zaubers
+// Wie beim Lernen von Zaubern werden nur EP berechnet; die Zauberschule zählt als Kategorie.
+func spellCandidate(char *models.Char, spellInfo models.SpellLearningInfo, req *BudgetOptimizerRequest, budgetEP int) (budgetCandidate, bool) {
+	request := gsmaster.LernCostRequest{CharId: char.ID, Name: spellInfo.SpellName, Type: "spell", Action: "learn", TargetLevel: 1}
+	result := gsmaster.SkillCostResultNew{CharacterID: fmt.Sprint(char.ID), CharacterClass: characterClassCode(char), SkillName: spellInfo.SpellName, TargetLevel: 1}
+	noPP, noGold := 0, 0
+	if err := calculateSpellLearnCostNewSystem(&request, &result, &noPP, &noGold, &spellInfo); err != nil || result.EP > budgetEP {
+		return budgetCandidate{}, false
+	}
+	weight, reasons := budgetWeight(req.Priorities, spellInfo.SpellName, "spell", spellInfo.SchoolName)
+	return budgetCandidate{increments: []BudgetOption{{
+		Name: spellInfo.SpellName, Type: "spell", Action: "learn", Category: spellInfo.SchoolName,
+		FromLevel: 0, ToLevel: 1, EP: result.EP,
+		Weight: weight, Reasons: reasons,
+	}}}, true
+}
+
+// collectBudgetCandidates sammelt alle Verbesserungen und neuen Fertigkeiten, Waffenfertigkeiten und Zauber,
+// die einzeln ins Budget passen
+// Die Lerninformationen werden je Art in einer Abfrage geladen, nicht je Fertigkeit.
+func collectBudgetCandidates(char *models.Char, req *BudgetOptimizerRequest, budgetEP, budgetGold int) ([]budgetCandidate, error) {
+	classCode := characterClassCode(char)
+	scope := models.CharacterContentScope(char)
+	skillInfos, err := models.GetSkillLearningInfosNewSystem(classCode, scope)
+	if err != nil {
+		return nil, err
+	}
+	weaponInfos, err := models.GetWeaponSkillLearningInfosNewSystem(classCode, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []budgetCandidate
+	add := func(candidate budgetCandidate, ok bool) {
+		if ok {
+			candidates = append(candidates, candidate)
+		}
+	}
+	for _, skill := range char.Fertigkeiten {
+		if info, ok := skillInfos[skill.Name]; ok {
+			add(improvementCandidate(char, skill, "skill", info, req, budgetEP, budgetGold))
+		}
+	}
+	for _, skill := range char.Waffenfertigkeiten {
+		if info, ok := weaponInfos[skill.Name]; ok {
+			add(improvementCandidate(char, skill.SkFertigkeit, "weapon", info, req, budgetEP, budgetGold))
+		}
+	}
+
+	if req.IncludeLearn != nil && !*req.IncludeLearn {
+		return candidates, nil
+	}
+	filter, err := characterLearnableFilter(char)
+	if err != nil {
+		return nil, err
+	}
+	spellInfos, err := models.GetSpellLearningInfosNewSystem(classCode, scope)
+	if err != nil {
+		return nil, err
+	}
+	for _, skillType := range []string{"skill", "weapon"} {
+		infos := skillInfos
+		if skillType == "weapon" {
+			infos = weaponInfos
+		}
+		for _, name := range slices.Sorted(maps.Keys(infos)) {
+			info := infos[name]
+			if known, _ := lookupCharacterSkill(char, skillType, name); known != nil || models.IsInnateSkill(name) || !filter.sources.Contains(info.SourceID) {
+				continue
+			}
+			add(learnCandidate(char, skillType, info, req, budgetEP, budgetGold))
+		}
+	}
+	for _, name := range slices.Sorted(maps.Keys(spellInfos)) {
+		info := spellInfos[name]
+		if hasSpell(char, name) || !filter.sources.Contains(info.SourceID) {
+			continue
+		}
+		add(spellCandidate(char, info, req, budgetEP))
+	}
+	return candidates, nil
+}
+
+// budgetBetter vergleicht zwei Schritte nach der Strategie
+func budgetBetter(strategy string, a, b BudgetOption) bool {
+	costA, costB := budgetCost(a), budgetCost(b)
+	switch strategy {
+	case BudgetStrategyPriority:
+		if a.Weight != b.Weight {
+			return a.Weight > b.Weight
+		}
+	case BudgetStrategyEfficiency:
+		if a.Weight*costB != b.Weight*costA {
+			return a.Weight*costB > b.Weight*costA
+		}
+	}
+	if costA != costB {
+		return costA < costB
+	}
+	return a.Name < b.Name
+}
+
+// buildBudgetBundle wählt nach der Strategie so lange den besten bezahlbaren nächsten Schritt, bis nichts mehr passt
+// Verbesserungen einer Fertigkeit werden nur der Reihe nach gewählt.
+func buildBudgetBundle(strategy string, candidates []budgetCandidate, budgetEP, budgetGold int) BudgetBundle {
+	bundle := BudgetBundle{Strategy: strategy, Items: []BudgetOption{}, Steps: []models.AdvancementPlanStep{}}
+	taken := make([]int, len(candidates))
+	remainingEP, remainingGold := budgetEP, budgetGold
+	for {
+		best := -1
+		for i, candidate := range candidates {
+			if taken[i] >= len(candidate.increments) {
+				continue
+			}
+			next := candidate.increments[taken[i]]
+			if next.EP > remainingEP || next.Gold > remainingGold {
+				continue
+			}
+			if best < 0 || budgetBetter(strategy, next, candidates[best].increments[taken[best]]) {
+				best = i
+			}
+		}
+		if best < 0 {
+			break
+		}
+		next := candidates[best].increments[taken[best]]
+		taken[best]++
+		remainingEP -= next.EP
+		remainingGold -= next.Gold
+		bundle.EP += next.EP
+		bundle.Gold += next.Gold
+		bundle.PP += next.PP
+		bundle.Value += next.Weight
+	}
+
+	for i, candidate := range candidates {
+		if taken[i] == 0 {
+			continue
+		}
+		item := candidate.increments[0]
+		for _, increment := range candidate.increments[1:taken[i]] {
+			item.ToLevel = increment.ToLevel
+			item.EP += increment.EP
+			item.Gold += increment.Gold
+			item.PP += increment.PP
+		}
+		bundle.Items = append(bundle.Items, item)
+		step := models.AdvancementPlanStep{Action: item.Action, Type: item.Type, Name: item.Name, TargetLevel: item.ToLevel, UsePP: item.PP}
+		bundle.Steps = append(bundle.Steps, step)
+	}
+	bundle.RemainingEP = remainingEP
+	bundle.RemainingGold = remainingGold
+	return bundle
+}
+
+// bundleKey erkennt Pakete mit derselben Auswahl
+func bundleKey(bundle BudgetBundle) string {
+	parts := make([]string, 0, len(bundle.Items))
+	for _, item := range bundle.Items {
+		parts = append(parts, fmt.Sprintf("%s:%s:%d", item.Type, item.Name, item.ToLevel))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, "|")
+}
+
+// SuggestBudgetImprovements listet bezahlbare Möglichkeiten nach Priorität und stellt die besten Pakete zusammen
+func SuggestBudgetImprovements(char *models.Char, req BudgetOptimizerRequest) (BudgetSuggestions, error) {
+	budgetEP := char.Erfahrungsschatz.EP
+	if req.EP != nil {
+		budgetEP = *req.EP
+	}
+	budgetGold := char.Vermoegen.Goldstuecke
+	if req.Gold != nil {
+		budgetGold = *req.Gold
+	}
+	if req.MaxLevelsPerSkill <= 0 {
+		req.MaxLevelsPerSkill = defaultBudgetLevelsPerSkill
+	}
+	req.MaxLevelsPerSkill = min(req.MaxLevelsPerSkill, maxBudgetLevelsPerSkill)
+	if req.MaxOptions <= 0 {
+		req.MaxOptions = defaultBudgetOptions
+	}
+	req.MaxOptions = min(req.MaxOptions, maxBudgetOptions)
+	if req.MaxBundles <= 0 {
+		req.MaxBundles = defaultBudgetBundles
+	}
+
+	suggestions := BudgetSuggestions{
+		CharacterID: char.ID,
+		BudgetEP:    budgetEP,
+		BudgetGold:  budgetGold,
+		Options:     []BudgetOption{},
+		Bundles:     []BudgetBundle{},
+	}
+	candidates, err := collectBudgetCandidates(char, &req, budgetEP, budgetGold)
+	if err != nil {
+		return suggestions, err
+	}
+
+	// einzelne Möglichkeiten: jeweils der nächste Schritt, nach Gewicht und Effizienz sortiert
+	for _, candidate := range candidates {
+		suggestions.Options = append(suggestions.Options, candidate.increments[0])
+	}
+	sort.SliceStable(suggestions.Options, func(i, j int) bool {
+		a, b := suggestions.Options[i], suggestions.Options[j]
+		if a.Weight != b.Weight {
+			return a.Weight > b.Weight
+		}
+		return budgetBetter(BudgetStrategyEfficiency, a, b)
+	})
+	if len(suggestions.Options) > req.MaxOptions {
+		suggestions.Options = suggestions.Options[:req.MaxOptions]
+	}
+
+	seen := map[string]bool{}
+	for _, strategy := range []string{BudgetStrategyPriority, BudgetStrategyEfficiency, BudgetStrategyBreadth} {
+		bundle := buildBudgetBundle(strategy, candidates, budgetEP, budgetGold)
+		key := bundleKey(bundle)
+		if len(bundle.Items) == 0 || seen[key] {
+			continue
+		}
+		seen[key] = true
+		suggestions.Bundles = append(suggestions.Bundles, bundle)
+	}
+	sort.SliceStable(suggestions.Bundles, func(i, j int) bool {
+		a, b := suggestions.Bundles[i], suggestions.Bundles[j]
+		if a.Value != b.Value {
+			return a.Value > b.Value
+		}
+		return a.EP+a.Gold/10 < b.EP+b.Gold/10
+	})
+	if len(suggestions.Bundles) > req.MaxBundles {
+		suggestions.Bundles = suggestions.Bundles[:req.MaxBundles]
+	}
+	return suggestions, nil
+}
@@ -0,0 +1,41 @@
+package character
+
+import (
+	"bamort/logger"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetBudgetSuggestions schlägt bezahlbare Verbesserungen und neue Fertigkeiten für EP und Gold vor
+// Ohne Body werden EP und Gold des Charakters ohne besondere Prioritäten verwendet.
+func GetBudgetSuggestions(c *gin.Context) {
+	char, ok := loadCharacterWithResources(c)
+	if !ok {
+		return
+	}
+	if !CanReadCharacter(char, c.GetUint("userID")) {
+		respondWithError(c, http.StatusForbidden, "You are not authorized to view this character")
+		return
+	}
+
+	var req BudgetOptimizerRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, http.StatusBadRequest, "Ungültige Anfrageparameter: "+err.Error())
+			return
+		}
+	}
+	if (req.EP != nil && *req.EP < 0) || (req.Gold != nil && *req.Gold < 0) {
+		respondWithError(c, http.StatusBadRequest, "EP und Gold dürfen nicht negativ sein")
+		return
+	}
+
+	suggestions, err := SuggestBudgetImprovements(char, req)
+	if err != nil {
+		logger.Error("Budget-Vorschläge für Charakter %d fehlgeschlagen: %s", char.ID, err.Error())
+		respondWithError(c, http.StatusInternalServerError, "Failed to calculate suggestions")
+		return
+	}
+	c.JSON(http.StatusOK, suggestions)
+}
@@ -0,0 +1,161 @@
+package character
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBudgetSuggestions(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+	require.NoError(t, database.DB.Create(&models.GameSystem{Code: "M5", Name: "midgard", IsActive: true}).Error)
+	seedImprovementCosts(t, map[int]int{12: 2, 13: 3, 14: 4, 15: 5})
+
+	owner := ensureUserExists(t, 247)
+	stranger := ensureUserExists(t, 248)
+	char := createCharacterOwnedBy(t, owner.UserID)
+	seedExperience(t, char, 100)
+	seedWealth(t, char, 1000, 0, 0)
+	seedSkill(t, char, "Athletik", 11, 2)
+	seedSkill(t, char, "Anführen", 11, 0)
+	params := map[string]string{"id": fmt.Sprint(char.ID)}
+
+	suggest := func(userID uint, body map[string]any) (int, BudgetSuggestions) {
+		ctx, w := buildJSONContext(t, http.MethodPost, body, userID, params)
+		GetBudgetSuggestions(ctx)
+		var suggestions BudgetSuggestions
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &suggestions))
+		}
+		return w.Code, suggestions
+	}
+
+	t.Run("favourites are ranked first and bundles fit the budget", func(t *testing.T) {
+		code, suggestions := suggest(owner.UserID, map[string]any{
+			"include_learn": false,
+			"priorities":    map[string]any{"favorite_skills": []string{"anführen"}},
+		})
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 100, suggestions.BudgetEP)
+
+		require.Len(t, suggestions.Options, 2)
+		assert.Equal(t, "Anführen", suggestions.Options[0].Name)
+		assert.Equal(t, budgetWeightFavorite, suggestions.Options[0].Weight)
+		assert.Equal(t, []string{BudgetReasonFavorite}, suggestions.Options[0].Reasons)
+		// Athletik 12 wird allein mit PP bezahlt
+		assert.Equal(t, BudgetOption{Name: "Athletik", Type: "skill", Action: "improve", Category: "Kampf", FromLevel: 11, ToLevel: 12, PP: 2, Weight: budgetWeightDefault}, suggestions.Options[1])
+
+		require.Len(t, suggestions.Bundles, 2)
+		best := suggestions.Bundles[0]
+		assert.Equal(t, 90, best.EP)
+		assert.Equal(t, 180, best.Gold)
+		assert.Equal(t, 10, best.RemainingEP)
+		assert.Equal(t, 3*budgetWeightFavorite+budgetWeightDefault, best.Value)
+		assert.ElementsMatch(t, []models.AdvancementPlanStep{
+			{Action: "improve", Type: "skill", Name: "Athletik", TargetLevel: 12, UsePP: 2},
+			{Action: "improve", Type: "skill", Name: "Anführen", TargetLevel: 14},
+		}, best.Steps)
+
+		breadth := suggestions.Bundles[1]
+		assert.Equal(t, BudgetStrategyBreadth, breadth.Strategy)
+		assert.Equal(t, 80, breadth.EP)
+		assert.Len(t, breadth.Items, 2)
+		for _, bundle := range suggestions.Bundles {
+			assert.LessOrEqual(t, bundle.EP, suggestions.BudgetEP)
+			assert.LessOrEqual(t, bundle.Gold, suggestions.BudgetGold)
+		}
+	})
+
+	t.Run("bundle steps can be simulated by the planner", func(t *testing.T) {
+		_, suggestions := suggest(owner.UserID, map[string]any{"include_learn": false})
+		require.NotEmpty(t, suggestions.Bundles)
+		char, err := loadCharacterForImprovement(char.ID)
+		require.NoError(t, err)
+		simulation := SimulateAdvancementPlan(char, suggestions.Bundles[0].Steps)
+		assert.True(t, simulation.Feasible)
+		assert.Equal(t, suggestions.Bundles[0].EP, simulation.TotalEP)
+	})
+
+	t.Run("without PP and with a larger budget", func(t *testing.T) {
+		_, suggestions := suggest(owner.UserID, map[string]any{"include_learn": false, "use_pp": false})
+		for _, option := range suggestions.Options {
+			assert.Zero(t, option.PP)
+			assert.Equal(t, 20, option.EP)
+		}
+
+		_, suggestions = suggest(owner.UserID, map[string]any{"ep": 1000, "gold": 5000, "max_options": 100, "priorities": map[string]any{"weapon_skills": true}})
+		assert.Equal(t, 1000, suggestions.BudgetEP)
+		learn := map[string]int{}
+		var weapon *BudgetOption
+		for i, option := range suggestions.Options {
+			if option.Action == "learn" {
+				learn[option.Type]++
+				assert.NotContains(t, []string{"Athletik", "Anführen"}, option.Name)
+				assert.LessOrEqual(t, option.EP, 1000)
+				if option.Type == "weapon" && weapon == nil {
+					weapon = &suggestions.Options[i]
+				}
+			}
+		}
+		assert.Positive(t, learn["skill"])
+		require.NotNil(t, weapon, "Waffenfertigkeiten werden ebenfalls zum Lernen vorgeschlagen")
+		assert.Zero(t, learn["spell"], "Krieger lernen keine Zauber")
+
+		// vorgeschlagene Waffenfertigkeiten kann der Planer übernehmen
+		loaded, err := loadCharacterForImprovement(char.ID)
+		require.NoError(t, err)
+		loaded.Erfahrungsschatz.EP = 1000
+		simulation := SimulateAdvancementPlan(loaded, []models.AdvancementPlanStep{{Action: "learn", Type: "weapon", Name: weapon.Name, TargetLevel: 1}})
+		require.True(t, simulation.Valid, simulation)
+		assert.Empty(t, simulation.Steps[0].Error)
+	})
+
+	t.Run("spell casters are offered spells", func(t *testing.T) {
+		mage := createCharacterOwnedBy(t, owner.UserID)
+		require.NoError(t, database.DB.Model(&models.Char{}).Where("id = ?", mage.ID).Update("typ", "Magier").Error)
+		seedExperience(t, mage, 1000)
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"max_options": 100}, owner.UserID, map[string]string{"id": fmt.Sprint(mage.ID)})
+		GetBudgetSuggestions(ctx)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var suggestions BudgetSuggestions
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &suggestions))
+
+		var spell *BudgetOption
+		for i, option := range suggestions.Options {
+			if option.Type == "spell" {
+				spell = &suggestions.Options[i]
+				break
+			}
+		}
+		require.NotNil(t, spell)
+		assert.Equal(t, "learn", spell.Action)
+		assert.Zero(t, spell.Gold)
+		assert.NotEmpty(t, spell.Category)
+
+		loaded, err := loadCharacterForImprovement(mage.ID)
+		require.NoError(t, err)
+		simulation := SimulateAdvancementPlan(loaded, []models.AdvancementPlanStep{{Action: "learn", Type: "spell", Name: spell.Name}})
+		require.True(t, simulation.Feasible, simulation)
+		assert.Equal(t, spell.EP, simulation.TotalEP)
+	})
+
+	t.Run("access and validation", func(t *testing.T) {
+		code, _ := suggest(stranger.UserID, map[string]any{})
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = suggest(owner.UserID, map[string]any{"ep": -1})
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
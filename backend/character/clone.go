@@ -0,0 +1,188 @@
+package character
+
+import (
+	"bamort/database"
+	"bamort/media"
+	"bamort/models"
+	"fmt"
+	"math/rand"
+	"slices"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// CanReadCharacter prüft, ob ein Benutzer einen Charakter einsehen darf
+// (eigener, öffentlicher oder mit ihm geteilter Charakter)
+func CanReadCharacter(char *models.Char, userID uint) bool {
+	if char.UserID == userID || char.Public {
+		return true
+	}
+	var share models.CharShare
+	return database.DB.Where("character_id = ? AND user_id = ?", char.ID, userID).First(&share).Error == nil
+}
+
+// uniqueContainers fasst Behältnisse und Transportmittel zusammen
+// Beide Listen werden aus derselben Tabelle geladen und können dieselben Einträge enthalten.
+func uniqueContainers(char *models.Char) []models.EqContainer {
+	seen := map[uint]bool{}
+	containers := make([]models.EqContainer, 0, len(char.Behaeltnisse)+len(char.Transportmittel))
+	for _, list := range [][]models.EqContainer{char.Behaeltnisse, char.Transportmittel} {
+		for _, container := range list {
+			if container.ID != 0 && seen[container.ID] {
+				continue
+			}
+			seen[container.ID] = true
+			containers = append(containers, container)
+		}
+	}
+	return containers
+}
+
+// CloneCharacter legt eine vollständige Kopie eines Charakters mit allen Eigenschaften,
+// Fertigkeiten, Zaubern und Ausrüstung unter neuem Namen und Besitzer an
+// Verweise auf Behälter (ContainedIn) werden auf die neu angelegten Behälter umgebogen,
+// Verweise auf unbekannte Behälter entfallen. Audit-Log und Würfelhistorie werden nicht kopiert.
+func CloneCharacter(tx *gorm.DB, src *models.Char, newName string, ownerID uint) (*models.Char, error) {
+	clone := *src
+	clone.ID = 0
+	clone.Name = newName
+	clone.UserID = ownerID
+	clone.Public = false
+	clone.Spezialisierung = slices.Clone(src.Spezialisierung)
+	// Kampagne und Spielkalender gehören zur Gruppe des Originals; beitreten geht nur über deren Endpunkte
+	clone.CampaignID = 0
+	clone.CalendarID = 0
+	clone.BirthDay = nil
+
+	clone.Lp = models.Lp{Max: src.Lp.Max, Value: src.Lp.Value}
+	clone.Ap = models.Ap{Max: src.Ap.Max, Value: src.Ap.Value}
+	clone.B = models.B{Max: src.B.Max, Value: src.B.Value}
+	clone.Merkmale.ID, clone.Merkmale.CharacterID, clone.Merkmale.UserID = 0, 0, ownerID
+	clone.Bennies.ID, clone.Bennies.CharacterID, clone.Bennies.UserID = 0, 0, ownerID
+	clone.Vermoegen.ID, clone.Vermoegen.CharacterID, clone.Vermoegen.UserID = 0, 0, ownerID
+	clone.Erfahrungsschatz.ID, clone.Erfahrungsschatz.CharacterID, clone.Erfahrungsschatz.UserID = 0, 0, ownerID
+
+	clone.Eigenschaften = slices.Clone(src.Eigenschaften)
+	for i := range clone.Eigenschaften {
+		clone.Eigenschaften[i].ID, clone.Eigenschaften[i].CharacterID, clone.Eigenschaften[i].UserID = 0, 0, ownerID
+	}
+	clone.Fertigkeiten = slices.Clone(src.Fertigkeiten)
+	for i := range clone.Fertigkeiten {
+		clone.Fertigkeiten[i].ID, clone.Fertigkeiten[i].CharacterID, clone.Fertigkeiten[i].UserID = 0, 0, ownerID
+	}
+	clone.Waffenfertigkeiten = slices.Clone(src.Waffenfertigkeiten)
+	for i := range clone.Waffenfertigkeiten {
+		clone.Waffenfertigkeiten[i].ID, clone.Waffenfertigkeiten[i].CharacterID, clone.Waffenfertigkeiten[i].UserID = 0, 0, ownerID
+	}
+	clone.Zauber = slices.Clone(src.Zauber)
+	for i := range clone.Zauber {
+		clone.Zauber[i].ID, clone.Zauber[i].CharacterID, clone.Zauber[i].UserID = 0, 0, ownerID
+	}
+
+	// Ausrüstung wird erst nach den Behältern angelegt, damit ContainedIn umgebogen werden kann
+	containers := uniqueContainers(src)
+	waffen := slices.Clone(src.Waffen)
+	ausruestung := slices.Clone(src.Ausruestung)
+	clone.Behaeltnisse = nil
+	clone.Transportmittel = nil
+	clone.Waffen = nil
+	clone.Ausruestung = nil
+
+	// Gespeicherte Bilder gehören zum Original und werden nach dem Anlegen kopiert
+	// (Vorlagen haben kein Bild, dort bleibt Image leer)
+	copyPortrait := src.Image != "" && !strings.HasPrefix(src.Image, "data:")
+	if copyPortrait {
+		clone.Image = ""
+	}
+
+	if err := tx.Omit("User").Create(&clone).Error; err != nil {
+		return nil, fmt.Errorf("failed to create character copy: %w", err)
+	}
+	if copyPortrait {
+		url, err := media.CopyPortraitTx(tx, src.ID, clone.ID, ownerID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to copy character image: %w", err)
+		}
+		clone.Image = url
+	}
+
+	// Behälter anlegen und alte auf neue IDs abbilden
+	idMap := map[uint]uint{}
+	for i := range containers {
+		oldID := containers[i].ID
+		containers[i].ID = 0
+		containers[i].CharacterID = clone.ID
+		containers[i].UserID = ownerID
+		containers[i].ExtID = ""
+		if err := tx.Create(&containers[i]).Error; err != nil {
+			return nil, fmt.Errorf("failed to copy container %s: %w", containers[i].Name, err)
+		}
+		idMap[oldID] = containers[i].ID
+	}
+	for i := range containers {
+		if containers[i].ContainedIn == 0 {
+			continue
+		}
+		containers[i].ContainedIn = idMap[containers[i].ContainedIn]
+		if err := tx.Model(&containers[i]).Update("contained_in", containers[i].ContainedIn).Error; err != nil {
+			return nil, fmt.Errorf("failed to remap container %s: %w", containers[i].Name, err)
+		}
+	}
+
+	for i := range waffen {
+		waffen[i].ID, waffen[i].CharacterID, waffen[i].UserID = 0, clone.ID, ownerID
+		waffen[i].ContainedIn = idMap[waffen[i].ContainedIn]
+		if err := tx.Create(&waffen[i]).Error; err != nil {
+			return nil, fmt.Errorf("failed to copy weapon %s: %w", waffen[i].Name, err)
+		}
+	}
+	for i := range ausruestung {
+		ausruestung[i].ID, ausruestung[i].CharacterID, ausruestung[i].UserID = 0, clone.ID, ownerID
+		ausruestung[i].ContainedIn = idMap[ausruestung[i].ContainedIn]
+		if err := tx.Create(&ausruestung[i]).Error; err != nil {
+			return nil, fmt.Errorf("failed to copy equipment %s: %w", ausruestung[i].Name, err)
+		}
+	}
+
+	clone.Waffen = waffen
+	clone.Ausruestung = ausruestung
+	for _, container := range containers {
+		if container.IsTransportation {
+			clone.Transportmittel = append(clone.Transportmittel, container)
+		} else {
+			clone.Behaeltnisse = append(clone.Behaeltnisse, container)
+		}
+	}
+	return &clone, nil
+}
+
+// randomizeAttributes verändert die Grundeigenschaften zufällig um bis zu ±variance
+// und berechnet die davon abhängigen Werte neu
+func randomizeAttributes(char *models.Char, variance int, seed int64) {
+	if variance <= 0 {
+		return
+	}
+	rules := getConsistencyRules(characterGameSystem(char))
+	rng := rand.New(rand.NewSource(seed))
+	for i := range char.Eigenschaften {
+		value := char.Eigenschaften[i].Value + rng.Intn(2*variance+1) - variance
+		char.Eigenschaften[i].Value = min(max(value, rules.MinAttribute), rules.MaxAttribute)
+	}
+
+	static := CalculateStaticFieldsForCharacter(char)
+	char.ResistenzKoerper = static.ResistenzKoerper
+	char.ResistenzGeist = static.ResistenzGeist
+	char.Abwehr = static.Abwehr
+	char.Zaubern = static.Zaubern
+	char.Raufen = static.Raufen
+}
+
+// InstantiateTemplate erzeugt einen neuen Charakter aus einer Vorlage
+// Bei variance > 0 werden die Eigenschaften mit dem angegebenen Seed zufällig variiert.
+func InstantiateTemplate(tx *gorm.DB, template *models.CharTemplate, newName string, ownerID uint, variance int, seed int64) (*models.Char, error) {
+	char := template.Character
+	char.Eigenschaften = slices.Clone(template.Character.Eigenschaften)
+	randomizeAttributes(&char, variance, seed)
+	return CloneCharacter(tx, &char, newName, ownerID)
+}
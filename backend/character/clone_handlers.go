@@ -0,0 +1,222 @@
+package character
+
+import (
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"bamort/user"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// CloneCharacterRequest repräsentiert das Kopieren eines Charakters
+type CloneCharacterRequest struct {
+	Name    string `json:"name" binding:"required"`
+	OwnerID uint   `json:"owner_id,omitempty"` // nur für Maintainer/Admins, Standard: aktueller Benutzer
+}
+
+// CreateTemplateRequest repräsentiert das Speichern eines Charakters als Vorlage
+type CreateTemplateRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description,omitempty"`
+	Public      bool   `json:"public,omitempty"`
+}
+
+// InstantiateTemplateRequest repräsentiert das Erzeugen eines Charakters aus einer Vorlage
+type InstantiateTemplateRequest struct {
+	Name     string `json:"name" binding:"required"`
+	Variance int    `json:"variance,omitempty" binding:"min=0,max=50"` // Eigenschaften zufällig um bis zu ±variance verändern
+	Seed     *int64 `json:"seed,omitempty"`                            // für reproduzierbare Zufallswerte
+}
+
+// resolveCloneOwner bestimmt den Besitzer einer Kopie
+// Andere Benutzer als Besitzer dürfen nur Maintainer und Admins eintragen.
+func resolveCloneOwner(c *gin.Context, requested uint) (uint, bool) {
+	userID := c.GetUint("userID")
+	if requested == 0 || requested == userID {
+		return userID, true
+	}
+
+	var current user.User
+	if err := database.DB.First(&current, "user_id = ?", userID).Error; err != nil || !current.IsMaintainer() {
+		respondWithError(c, http.StatusForbidden, "Only maintainers can create characters for other users")
+		return 0, false
+	}
+	var owner user.User
+	if err := database.DB.First(&owner, "user_id = ?", requested).Error; err != nil {
+		respondWithError(c, http.StatusBadRequest, "Owner not found")
+		return 0, false
+	}
+	return owner.UserID, true
+}
+
+// CloneCharacterHandler kopiert einen Charakter mit allen Fertigkeiten und Ausrüstung
+func CloneCharacterHandler(c *gin.Context) {
+	var source models.Char
+	if err := source.FirstID(c.Param("id")); err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return
+	}
+	if !CanReadCharacter(&source, c.GetUint("userID")) {
+		respondWithError(c, http.StatusForbidden, "You are not allowed to copy this character")
+		return
+	}
+
+	var req CloneCharacterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	ownerID, ok := resolveCloneOwner(c, req.OwnerID)
+	if !ok {
+		return
+	}
+
+	var clone *models.Char
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		clone, err = CloneCharacter(tx, &source, req.Name, ownerID)
+		return err
+	})
+	if err != nil {
+		logger.Error("Fehler beim Kopieren von Charakter %d: %s", source.ID, err.Error())
+		respondWithError(c, http.StatusInternalServerError, "Failed to copy character")
+		return
+	}
+
+	logger.Info("Charakter %d als '%s' (ID %d) für Benutzer %d kopiert", source.ID, clone.Name, clone.ID, ownerID)
+	c.JSON(http.StatusCreated, gin.H{"id": clone.ID, "name": clone.Name, "user_id": clone.UserID})
+}
+
+// CreateCharacterTemplate speichert einen Charakter als wiederverwendbare Vorlage
+func CreateCharacterTemplate(c *gin.Context) {
+	var source models.Char
+	if err := source.FirstID(c.Param("id")); err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return
+	}
+	userID := c.GetUint("userID")
+	if !CanReadCharacter(&source, userID) {
+		respondWithError(c, http.StatusForbidden, "You are not allowed to use this character as template")
+		return
+	}
+
+	var req CreateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	source.User = user.User{}
+	source.Image = ""
+	source.CampaignID = 0
+	source.CalendarID = 0
+	source.BirthDay = nil
+	template := models.CharTemplate{
+		Name:         req.Name,
+		Description:  req.Description,
+		UserID:       userID,
+		Public:       req.Public,
+		GameSystem:   source.GameSystem,
+		GameSystemId: source.GameSystemId,
+		SourceCharID: source.ID,
+		Character:    source,
+	}
+	if err := database.DB.Create(&template).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to create template")
+		return
+	}
+
+	c.JSON(http.StatusCreated, template)
+}
+
+// ListCharacterTemplates listet eigene und öffentliche Vorlagen (ohne Schnappschuss)
+func ListCharacterTemplates(c *gin.Context) {
+	var templates []models.CharTemplate
+	err := database.DB.
+		Omit("snapshot").
+		Where("user_id = ? OR public = ?", c.GetUint("userID"), true).
+		Order("name ASC").
+		Find(&templates).Error
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve templates")
+		return
+	}
+	c.JSON(http.StatusOK, templates)
+}
+
+// loadCharacterTemplate lädt eine Vorlage, die dem Benutzer gehört oder öffentlich ist
+func loadCharacterTemplate(c *gin.Context) (*models.CharTemplate, bool) {
+	var template models.CharTemplate
+	if err := database.DB.First(&template, c.Param("templateId")).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Template not found")
+		return nil, false
+	}
+	if template.UserID != c.GetUint("userID") && !template.Public {
+		respondWithError(c, http.StatusForbidden, "You are not allowed to use this template")
+		return nil, false
+	}
+	return &template, true
+}
+
+// GetCharacterTemplate gibt eine Vorlage mit Schnappschuss zurück
+func GetCharacterTemplate(c *gin.Context) {
+	template, ok := loadCharacterTemplate(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, template)
+}
+
+// DeleteCharacterTemplate löscht eine eigene Vorlage
+func DeleteCharacterTemplate(c *gin.Context) {
+	template, ok := loadCharacterTemplate(c)
+	if !ok {
+		return
+	}
+	if template.UserID != c.GetUint("userID") {
+		respondWithError(c, http.StatusForbidden, "Only the owner can delete this template")
+		return
+	}
+	if err := database.DB.Delete(template).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to delete template")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Template deleted"})
+}
+
+// InstantiateCharacterTemplate erzeugt einen neuen Charakter aus einer Vorlage
+func InstantiateCharacterTemplate(c *gin.Context) {
+	template, ok := loadCharacterTemplate(c)
+	if !ok {
+		return
+	}
+
+	var req InstantiateTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	seed := diceSeedSource()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+
+	userID := c.GetUint("userID")
+	var char *models.Char
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		char, err = InstantiateTemplate(tx, template, req.Name, userID, req.Variance, seed)
+		return err
+	})
+	if err != nil {
+		logger.Error("Fehler beim Erzeugen eines Charakters aus Vorlage %d: %s", template.ID, err.Error())
+		respondWithError(c, http.StatusInternalServerError, "Failed to create character from template")
+		return
+	}
+
+	logger.Info("Charakter '%s' (ID %d) aus Vorlage %d erzeugt", char.Name, char.ID, template.ID)
+	c.JSON(http.StatusCreated, gin.H{"id": char.ID, "name": char.Name, "seed": seed, "eigenschaften": char.Eigenschaften})
+}
@@ -0,0 +1,179 @@
+package character
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func seedCharacterForCloning(t *testing.T, ownerID uint) models.Char {
+	char := createCharacterOwnedBy(t, ownerID)
+	for _, name := range []string{"St", "Gs", "Gw", "Ko", "In", "Zt", "Au"} {
+		require.NoError(t, database.DB.Create(&models.Eigenschaft{CharacterID: char.ID, UserID: ownerID, Name: name, Value: 60}).Error)
+	}
+	require.NoError(t, database.DB.Create(&models.Lp{CharacterID: char.ID, Max: 14, Value: 9}).Error)
+	seedSkill(t, char, "Klettern", 12, 2)
+	require.NoError(t, database.DB.Create(&models.SkZauber{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Licht"}, CharacterID: char.ID, UserID: ownerID}}).Error)
+
+	backpack := models.EqContainer{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Rucksack"}, CharacterID: char.ID, UserID: ownerID}}
+	require.NoError(t, database.DB.Create(&backpack).Error)
+	pouch := models.EqContainer{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Beutel"}, CharacterID: char.ID, UserID: ownerID}, ContainedIn: backpack.ID}
+	require.NoError(t, database.DB.Create(&pouch).Error)
+	require.NoError(t, database.DB.Create(&models.EqAusruestung{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Seil"}, CharacterID: char.ID, UserID: ownerID}, ContainedIn: backpack.ID}).Error)
+	require.NoError(t, database.DB.Create(&models.EqWaffe{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Dolch"}, CharacterID: char.ID, UserID: ownerID}, ContainedIn: pouch.ID}).Error)
+	return char
+}
+
+func TestCloneCharacter(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+
+	require.NoError(t, models.MigrateStructure())
+
+	owner := ensureUserExists(t, 208)
+	source := seedCharacterForCloning(t, owner.UserID)
+
+	ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"name": "Zwilling"}, owner.UserID, map[string]string{"id": fmt.Sprint(source.ID)})
+	CloneCharacterHandler(ctx)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var created struct {
+		ID uint `json:"id"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	require.NotEqual(t, source.ID, created.ID)
+
+	var clone models.Char
+	require.NoError(t, clone.FirstID(fmt.Sprint(created.ID)))
+	assert.Equal(t, "Zwilling", clone.Name)
+	assert.Len(t, clone.Eigenschaften, 7)
+	assert.Equal(t, 9, clone.Lp.Value)
+	require.Len(t, clone.Fertigkeiten, 1)
+	assert.Equal(t, 12, clone.Fertigkeiten[0].Fertigkeitswert)
+	assert.Len(t, clone.Zauber, 1)
+
+	containers := map[string]models.EqContainer{}
+	for _, container := range clone.Behaeltnisse {
+		containers[container.Name] = container
+	}
+	require.Len(t, containers, 2)
+	assert.Equal(t, containers["Rucksack"].ID, containers["Beutel"].ContainedIn, "nested container is remapped")
+	require.Len(t, clone.Ausruestung, 1)
+	assert.Equal(t, containers["Rucksack"].ID, clone.Ausruestung[0].ContainedIn)
+	require.Len(t, clone.Waffen, 1)
+	assert.Equal(t, containers["Beutel"].ID, clone.Waffen[0].ContainedIn)
+
+	var original models.Char
+	require.NoError(t, original.FirstID(fmt.Sprint(source.ID)))
+	assert.Len(t, original.Fertigkeiten, 1, "source stays untouched")
+
+	t.Run("private characters of others cannot be cloned", func(t *testing.T) {
+		stranger := ensureUserExists(t, 209)
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"name": "Dieb"}, stranger.UserID, map[string]string{"id": fmt.Sprint(source.ID)})
+		CloneCharacterHandler(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("standard users cannot clone for others", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"name": "Geschenk", "owner_id": 209}, owner.UserID, map[string]string{"id": fmt.Sprint(source.ID)})
+		CloneCharacterHandler(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("campaign and calendar stay with the original", func(t *testing.T) {
+		require.NoError(t, database.DB.Model(&models.Char{}).Where("id = ?", source.ID).
+			Updates(map[string]any{"public": true, "campaign_id": 7, "calendar_id": 8, "birth_day": 100}).Error)
+		stranger := ensureUserExists(t, 212)
+		params := map[string]string{"id": fmt.Sprint(source.ID)}
+
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"name": "Mitläufer"}, stranger.UserID, params)
+		CloneCharacterHandler(ctx)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		clone := reloadCharacter(t, created.ID)
+		assert.Zero(t, clone.CampaignID)
+		assert.Zero(t, clone.CalendarID)
+		assert.Nil(t, clone.BirthDay)
+
+		ctx, w = buildJSONContext(t, http.MethodPost, map[string]any{"name": "Mitläufer"}, stranger.UserID, params)
+		CreateCharacterTemplate(ctx)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var template models.CharTemplate
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &template))
+		require.NoError(t, database.DB.First(&template, template.ID).Error)
+		assert.Zero(t, template.Character.CampaignID)
+		assert.Zero(t, template.Character.CalendarID)
+		assert.Nil(t, template.Character.BirthDay)
+	})
+}
+
+func TestCharacterTemplates(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+
+	require.NoError(t, models.MigrateStructure())
+
+	owner := ensureUserExists(t, 210)
+	source := seedCharacterForCloning(t, owner.UserID)
+
+	ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"name": "Wache"}, owner.UserID, map[string]string{"id": fmt.Sprint(source.ID)})
+	CreateCharacterTemplate(ctx)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var template models.CharTemplate
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &template))
+
+	// Änderungen am Quellcharakter wirken sich nicht auf die Vorlage aus
+	require.NoError(t, database.DB.Where("character_id = ?", source.ID).Delete(&models.SkFertigkeit{}).Error)
+
+	instantiate := func(userID uint, seed int64) (*http.Response, models.Char) {
+		params := map[string]string{"templateId": fmt.Sprint(template.ID)}
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"name": "Wache 1", "variance": 10, "seed": seed}, userID, params)
+		InstantiateCharacterTemplate(ctx)
+		var created struct {
+			ID uint `json:"id"`
+		}
+		var char models.Char
+		if w.Code == http.StatusCreated {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+			require.NoError(t, char.FirstID(fmt.Sprint(created.ID)))
+		}
+		return w.Result(), char
+	}
+
+	resp, first := instantiate(owner.UserID, 42)
+	require.Equal(t, http.StatusCreated, resp.StatusCode)
+	assert.Len(t, first.Fertigkeiten, 1)
+	assert.Len(t, first.Behaeltnisse, 2)
+
+	changed := false
+	for _, attr := range first.Eigenschaften {
+		assert.InDelta(t, 60, attr.Value, 10)
+		changed = changed || attr.Value != 60
+	}
+	assert.True(t, changed, "attributes are randomised")
+	assert.Equal(t, CalculateStaticFieldsForCharacter(&first).Abwehr, first.Abwehr, "derived values follow the new attributes")
+
+	_, second := instantiate(owner.UserID, 42)
+	for i := range first.Eigenschaften {
+		assert.Equal(t, first.GetAttributeValue(first.Eigenschaften[i].Name), second.GetAttributeValue(first.Eigenschaften[i].Name), "same seed, same attributes")
+	}
+
+	stranger := ensureUserExists(t, 211)
+	resp, _ = instantiate(stranger.UserID, 1)
+	assert.Equal(t, http.StatusForbidden, resp.StatusCode, "private templates are not shared")
+}
@@ -0,0 +1,159 @@
+package character
+
+import (
+	"bamort/models"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Die folgenden Funktionen werden sowohl vom Kampfhelfer als auch vom
+// Charakterbogen (pdfrender) verwendet, damit beide dieselben Werte liefern.
+
+// WeaponAttackBreakdown schlüsselt den Angriffswert einer Waffe auf
+type WeaponAttackBreakdown struct {
+	Skill               string `json:"skill,omitempty"`
+	SkillValue          int    `json:"skill_value"`
+	AngriffsBonus       int    `json:"angriffs_bonus"`
+	WeaponBonus         int    `json:"weapon_bonus"` // Anb der Waffe
+	Specialized         bool   `json:"specialized"`
+	SpecializationBonus int    `json:"specialization_bonus"` // Zuschlag für die Spezialwaffe
+	Total               int    `json:"total"`
+}
+
+// DefenseBreakdown schlüsselt den Abwehrwert auf
+type DefenseBreakdown struct {
+	Abwehr      int    `json:"abwehr"` // Abwehr nach Grad inkl. Abwehrbonus
+	Shield      string `json:"shield,omitempty"`
+	ShieldBonus int    `json:"shield_bonus"` // Abwb des Schildes bzw. der Parierwaffe
+	Total       int    `json:"total"`
+}
+
+// CalculateWeaponAttack berechnet den Angriffswert einer Waffe
+// EW = Waffenfertigkeit.Fertigkeitswert + Angriffsbonus + EqWaffe.Anb (+ Zuschlag für die Spezialwaffe)
+// baseWeapon darf nil sein, wenn die Waffe nicht in den Stammdaten steht.
+func CalculateWeaponAttack(char *models.Char, weapon *models.EqWaffe, baseWeapon *models.Weapon, static StaticFieldsResponse) WeaponAttackBreakdown {
+	breakdown := WeaponAttackBreakdown{
+		AngriffsBonus: static.AngriffsBonus,
+		WeaponBonus:   weapon.Anb,
+	}
+
+	if baseWeapon != nil && baseWeapon.SkillRequired != "" {
+		breakdown.Skill = baseWeapon.SkillRequired
+		for _, skill := range char.Waffenfertigkeiten {
+			if skill.Name == baseWeapon.SkillRequired {
+				breakdown.SkillValue = skill.Fertigkeitswert
+				break
+			}
+		}
+	}
+
+	if isSpecializedIn(char, weapon) {
+		breakdown.Specialized = true
+		breakdown.SpecializationBonus = getSpecializationRules(char).AttackBonus
+	}
+
+	breakdown.Total = breakdown.SkillValue + breakdown.AngriffsBonus + breakdown.WeaponBonus + breakdown.SpecializationBonus
+	return breakdown
+}
+
+// CalculateDefense berechnet den Abwehrwert, optional mit Schild oder Parierwaffe
+func CalculateDefense(static StaticFieldsResponse, shield *models.EqWaffe) DefenseBreakdown {
+	breakdown := DefenseBreakdown{Abwehr: static.Abwehr}
+	if shield != nil {
+		breakdown.Shield = shield.Name
+		breakdown.ShieldBonus = shield.Abwb
+	}
+	breakdown.Total = breakdown.Abwehr + breakdown.ShieldBonus
+	return breakdown
+}
+
+// FormatWeaponDamage erzeugt den Schadensausdruck einer Waffe
+// Format: BaseDamage+TotalBonus, e.g., "1W6+3"
+// TotalBonus = Character's SchadenBonus + Weapon's Schadensbonus (Schb)
+func FormatWeaponDamage(baseWeapon *models.Weapon, schadenBonus int, weaponSchb int) string {
+	if baseWeapon == nil || baseWeapon.Damage == "" {
+		return ""
+	}
+	baseDamage := baseWeapon.Damage
+
+	// Calculate total damage bonus
+	totalBonus := schadenBonus + weaponSchb
+
+	// Format the damage string
+	if totalBonus > 0 {
+		return fmt.Sprintf("%s+%d", baseDamage, totalBonus)
+	} else if totalBonus < 0 {
+		return fmt.Sprintf("%s%d", baseDamage, totalBonus)
+	}
+
+	return baseDamage
+}
+
+// parseWeaponDamage wandelt einen Schadensausdruck wie "1W6+3" in einen Würfelausdruck
+// Zu- und Abschläge wie in "1W6-1+2" werden zusammengefasst, bei Alternativen
+// wie "1W6 / 2W6" (ein-/beidhändig) zählt die erste.
+func parseWeaponDamage(damage string) (DiceExpression, error) {
+	normalized := strings.ReplaceAll(strings.SplitN(damage, "/", 2)[0], " ", "")
+	idx := strings.IndexAny(normalized, "+-")
+	if idx < 0 {
+		return ParseDiceExpression(normalized)
+	}
+
+	expr, err := ParseDiceExpression(normalized[:idx])
+	if err != nil {
+		return DiceExpression{}, err
+	}
+	modifier, err := sumModifiers(normalized[idx:])
+	if err != nil {
+		return DiceExpression{}, fmt.Errorf("ungültiger Schadensausdruck: %s", damage)
+	}
+	expr.Modifier = modifier
+	return expr, nil
+}
+
+// sumModifiers summiert eine Folge von Zu- und Abschlägen wie "+2-1"
+func sumModifiers(s string) (int, error) {
+	total := 0
+	for len(s) > 0 {
+		sign := 1
+		switch s[0] {
+		case '+':
+		case '-':
+			sign = -1
+		default:
+			return 0, fmt.Errorf("unerwartetes Zeichen %q", s[0])
+		}
+		s = s[1:]
+		end := strings.IndexAny(s, "+-")
+		if end < 0 {
+			end = len(s)
+		}
+		value, err := strconv.Atoi(s[:end])
+		if err != nil {
+			return 0, fmt.Errorf("ungültige Zahl in %q", s)
+		}
+		total += sign * value
+		s = s[end:]
+	}
+	return total, nil
+}
+
+// findCharacterWeapon sucht eine Waffe des Charakters anhand ihrer ID
+func findCharacterWeapon(char *models.Char, weaponID uint) (*models.EqWaffe, error) {
+	for i := range char.Waffen {
+		if char.Waffen[i].ID == weaponID {
+			return &char.Waffen[i], nil
+		}
+	}
+	return nil, fmt.Errorf("waffe %d gehört nicht zum Charakter", weaponID)
+}
+
+// loadBaseWeapon lädt die Stammdaten einer Waffe, nil wenn sie unbekannt ist
+func loadBaseWeapon(weapon *models.EqWaffe) *models.Weapon {
+	baseWeapon := &models.Weapon{}
+	if err := baseWeapon.First(weapon.Name); err != nil || baseWeapon.ID == 0 {
+		return nil
+	}
+	return baseWeapon
+}
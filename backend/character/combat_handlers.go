@@ -0,0 +1,277 @@
+package character
+
+import (
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AttackRequest repräsentiert einen Angriff mit einer Waffe des Charakters
+type AttackRequest struct {
+	WeaponID  uint                 `json:"weapon_id" binding:"required"`
+	Modifiers []SkillCheckModifier `json:"modifiers,omitempty"`
+}
+
+// DefenseRequest repräsentiert einen Abwehrwurf
+type DefenseRequest struct {
+	ShieldID     uint                 `json:"shield_id,omitempty"`     // Schild oder Parierwaffe (Abwb)
+	AttackResult int                  `json:"attack_result,omitempty"` // Ergebnis des gegnerischen Angriffs
+	Modifiers    []SkillCheckModifier `json:"modifiers,omitempty"`
+}
+
+// DamageRequest repräsentiert einen Schadenswurf
+type DamageRequest struct {
+	WeaponID uint `json:"weapon_id" binding:"required"`
+	Heavy    bool `json:"heavy"`               // Abwehr misslungen: schwerer Schaden
+	TargetID uint `json:"target_id,omitempty"` // getroffener Charakter (Rüstung aus dessen Ausrüstung)
+	Armor    *int `json:"armor,omitempty"`     // Rüstungsschutz, überschreibt den des Ziels
+	Apply    bool `json:"apply,omitempty"`     // Schaden direkt beim Ziel abziehen
+}
+
+// CombatRollResponse enthält Wurf und Ergebnis eines Angriffs- oder Abwehrwurfs
+type CombatRollResponse struct {
+	CharacterID uint              `json:"character_id"`
+	Roll        *models.DiceRoll  `json:"roll"`
+	Value       int               `json:"value"`     // Erfolgswert inkl. Modifikatoren
+	Modifiers   int               `json:"modifiers"` // Summe der situativen Modifikatoren
+	Result      int               `json:"result"`    // Wurf + Erfolgswert
+	Outcome     SkillCheckOutcome `json:"outcome"`
+	Success     bool              `json:"success"`
+	Attack      any               `json:"attack,omitempty"`
+	Defense     any               `json:"defense,omitempty"`
+	Damage      string            `json:"damage,omitempty"` // Schadensausdruck der Waffe
+}
+
+// DamageResponse enthält den Schadenswurf und die Auswirkung beim Ziel
+type DamageResponse struct {
+	CharacterID uint             `json:"character_id"`
+	Damage      string           `json:"damage"`
+	Roll        *models.DiceRoll `json:"roll"`
+	Armor       int              `json:"armor"`
+	LPLoss      int              `json:"lp_loss"`
+	APLoss      int              `json:"ap_loss"`
+	Applied     bool             `json:"applied"`
+	Target      *VitalsResponse  `json:"target,omitempty"`
+}
+
+// sumSkillCheckModifiers summiert situative Modifikatoren
+func sumSkillCheckModifiers(modifiers []SkillCheckModifier) int {
+	total := 0
+	for _, mod := range modifiers {
+		total += mod.Value
+	}
+	return total
+}
+
+// loadCharacterForCombat lädt den Charakter und prüft den Besitz
+func loadCharacterForCombat(c *gin.Context) (*models.Char, bool) {
+	var char models.Char
+	if err := char.FirstID(c.Param("id")); err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return nil, false
+	}
+	if !checkCharacterOwnership(c, &char) {
+		return nil, false
+	}
+	return &char, true
+}
+
+// CombatAttack würfelt einen Angriff mit einer Waffe des Charakters
+func CombatAttack(c *gin.Context) {
+	char, ok := loadCharacterForCombat(c)
+	if !ok {
+		return
+	}
+
+	var req AttackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	weapon, err := findCharacterWeapon(char, req.WeaponID)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	static := CalculateStaticFieldsForCharacter(char)
+	baseWeapon := loadBaseWeapon(weapon)
+	attack := CalculateWeaponAttack(char, weapon, baseWeapon, static)
+	modifiers := sumSkillCheckModifiers(req.Modifiers)
+
+	rules := getSkillCheckRules(char)
+	roll, err := rollAndRecord(rules.Dice, "Angriff: "+weapon.Name, char.ID, "", c.GetUint("userID"))
+	if err != nil {
+		logger.Error("Fehler beim Angriffswurf für Charakter %d: %v", char.ID, err)
+		respondWithError(c, http.StatusInternalServerError, "Failed to roll attack")
+		return
+	}
+
+	value := attack.Total + modifiers
+	outcome := classifySkillCheck(roll.Total, value, rules)
+	c.JSON(http.StatusOK, CombatRollResponse{
+		CharacterID: char.ID,
+		Roll:        roll,
+		Value:       value,
+		Modifiers:   modifiers,
+		Result:      roll.Total + value,
+		Outcome:     outcome,
+		Success:     outcome == OutcomeSuccess || outcome == OutcomeCriticalSuccess,
+		Attack:      attack,
+		Damage:      FormatWeaponDamage(baseWeapon, static.SchadensBonus, weapon.Schb),
+	})
+}
+
+// CombatDefense würfelt die Abwehr, optional mit Schild und gegen ein Angriffsergebnis
+func CombatDefense(c *gin.Context) {
+	char, ok := loadCharacterForCombat(c)
+	if !ok {
+		return
+	}
+
+	var req DefenseRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var shield *models.EqWaffe
+	if req.ShieldID != 0 {
+		var err error
+		if shield, err = findCharacterWeapon(char, req.ShieldID); err != nil {
+			respondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	defense := CalculateDefense(CalculateStaticFieldsForCharacter(char), shield)
+	modifiers := sumSkillCheckModifiers(req.Modifiers)
+
+	rules := getSkillCheckRules(char)
+	// Gegen einen konkreten Angriff muss dessen Ergebnis erreicht werden
+	if req.AttackResult > 0 {
+		rules.TargetNumber = req.AttackResult
+	}
+
+	roll, err := rollAndRecord(rules.Dice, "Abwehr", char.ID, "", c.GetUint("userID"))
+	if err != nil {
+		logger.Error("Fehler beim Abwehrwurf für Charakter %d: %v", char.ID, err)
+		respondWithError(c, http.StatusInternalServerError, "Failed to roll defense")
+		return
+	}
+
+	value := defense.Total + modifiers
+	outcome := classifySkillCheck(roll.Total, value, rules)
+	c.JSON(http.StatusOK, CombatRollResponse{
+		CharacterID: char.ID,
+		Roll:        roll,
+		Value:       value,
+		Modifiers:   modifiers,
+		Result:      roll.Total + value,
+		Outcome:     outcome,
+		Success:     outcome == OutcomeSuccess || outcome == OutcomeCriticalSuccess,
+		Defense:     defense,
+	})
+}
+
+// CombatDamage würfelt den Schaden einer Waffe und zieht den Rüstungsschutz des Ziels ab
+func CombatDamage(c *gin.Context) {
+	char, ok := loadCharacterForCombat(c)
+	if !ok {
+		return
+	}
+
+	var req DamageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	weapon, err := findCharacterWeapon(char, req.WeaponID)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	baseWeapon := loadBaseWeapon(weapon)
+	if baseWeapon == nil {
+		respondWithError(c, http.StatusBadRequest, fmt.Sprintf("Keine Stammdaten für Waffe '%s'", weapon.Name))
+		return
+	}
+
+	static := CalculateStaticFieldsForCharacter(char)
+	expr, err := parseWeaponDamage(baseWeapon.Damage)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, fmt.Sprintf("Waffe '%s' hat keinen würfelbaren Schaden: %s", weapon.Name, baseWeapon.Damage))
+		return
+	}
+	expr.Modifier += static.SchadensBonus + weapon.Schb
+
+	var target models.Char
+	if req.TargetID != 0 {
+		if err := database.DB.Preload("Lp").Preload("Ap").Preload("Ausruestung").First(&target, req.TargetID).Error; err != nil {
+			respondWithError(c, http.StatusNotFound, "Target character not found")
+			return
+		}
+		if !CanReadCharacter(&target, c.GetUint("userID")) {
+			respondWithError(c, http.StatusForbidden, "You are not authorized to view the target character")
+			return
+		}
+	}
+	if req.Apply && (req.TargetID == 0 || target.UserID != c.GetUint("userID")) {
+		respondWithError(c, http.StatusForbidden, "Damage can only be applied to own characters")
+		return
+	}
+
+	userID := c.GetUint("userID")
+	roll, err := rollAndRecord(expr.String(), "Schaden: "+weapon.Name, char.ID, "", userID)
+	if err != nil {
+		logger.Error("Fehler beim Schadenswurf für Charakter %d: %v", char.ID, err)
+		respondWithError(c, http.StatusInternalServerError, "Failed to roll damage")
+		return
+	}
+
+	armor := 0
+	if req.TargetID != 0 {
+		armor = determineArmorProtection(&target)
+	}
+	if req.Armor != nil {
+		armor = *req.Armor
+	}
+
+	rules := defaultVitalRules
+	if req.TargetID != 0 {
+		rules = getVitalRules(&target)
+	}
+	lpLoss, apLoss := calculateDamageLoss(roll.Total, req.Heavy, armor, rules)
+
+	response := DamageResponse{
+		CharacterID: char.ID,
+		Damage:      FormatWeaponDamage(baseWeapon, static.SchadensBonus, weapon.Schb),
+		Roll:        roll,
+		Armor:       armor,
+		LPLoss:      lpLoss,
+		APLoss:      apLoss,
+	}
+
+	if req.Apply {
+		notes := fmt.Sprintf("%d Schaden durch %s von %s (RS %d)", roll.Total, weapon.Name, char.Name, armor)
+		if err := applyDamageLoss(&target, lpLoss, apLoss, userID, notes); err != nil {
+			logger.Error("Fehler beim Anwenden des Schadens auf Charakter %d: %v", target.ID, err)
+			respondWithError(c, http.StatusInternalServerError, "Failed to apply damage")
+			return
+		}
+		response.Applied = true
+	}
+	if req.TargetID != 0 {
+		vitals := buildVitalsResponse(&target)
+		response.Target = &vitals
+	}
+
+	logger.Info("Charakter %d würfelt Schaden mit %s: %d (RS %d)", char.ID, weapon.Name, roll.Total, armor)
+	c.JSON(http.StatusOK, response)
+}
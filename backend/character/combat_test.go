@@ -0,0 +1,173 @@
+package character
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCalculateWeaponAttackAndDefense(t *testing.T) {
+	char := &models.Char{
+		Waffenfertigkeiten: []models.SkWaffenfertigkeit{
+			{SkFertigkeit: models.SkFertigkeit{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Stichwaffen"}}, Fertigkeitswert: 8}},
+		},
+	}
+	static := StaticFieldsResponse{AngriffsBonus: 1, Abwehr: 12}
+	weapon := &models.EqWaffe{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Dolch"}}, Anb: 2}
+
+	attack := CalculateWeaponAttack(char, weapon, &models.Weapon{SkillRequired: "Stichwaffen"}, static)
+	assert.Equal(t, 8+1+2, attack.Total)
+	assert.Equal(t, "Stichwaffen", attack.Skill)
+
+	unknown := CalculateWeaponAttack(char, weapon, nil, static)
+	assert.Equal(t, 1+2, unknown.Total, "unknown weapons only get the bonuses")
+
+	shield := &models.EqWaffe{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Schild:klein"}}, Abwb: 1}
+	assert.Equal(t, 13, CalculateDefense(static, shield).Total)
+	assert.Equal(t, 12, CalculateDefense(static, nil).Total)
+}
+
+func TestFormatWeaponDamage(t *testing.T) {
+	weapon := &models.Weapon{Damage: "1W6-1"}
+	assert.Equal(t, "1W6-1+2", FormatWeaponDamage(weapon, 1, 1))
+	assert.Equal(t, "1W6-1-1", FormatWeaponDamage(weapon, -1, 0))
+	assert.Equal(t, "1W6-1", FormatWeaponDamage(weapon, 0, 0))
+	assert.Equal(t, "", FormatWeaponDamage(nil, 1, 1))
+}
+
+func TestParseWeaponDamage(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected DiceExpression
+	}{
+		{"1W6", DiceExpression{Count: 1, Sides: 6}},
+		{"2W6-4", DiceExpression{Count: 2, Sides: 6, Modifier: -4}},
+		{"1W6-1+2", DiceExpression{Count: 1, Sides: 6, Modifier: 1}},
+		{"1W6 / 2W6", DiceExpression{Count: 1, Sides: 6}},
+	}
+	for _, tc := range testCases {
+		expr, err := parseWeaponDamage(tc.input)
+		require.NoError(t, err, tc.input)
+		assert.Equal(t, tc.expected, expr, tc.input)
+	}
+
+	for _, invalid := range []string{"0", "check", "1W6+x"} {
+		_, err := parseWeaponDamage(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestCombatHandlers(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+
+	require.NoError(t, models.MigrateStructure())
+
+	baseWeapon := models.Weapon{
+		Equipment:     models.Equipment{Name: "Testklinge"},
+		Damage:        "1W6+2",
+		SkillRequired: "Testwaffen",
+	}
+	require.NoError(t, database.DB.Create(&baseWeapon).Error)
+
+	owner := ensureUserExists(t, 204)
+	attacker := createCharacterOwnedBy(t, owner.UserID)
+	weapon := models.EqWaffe{
+		BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Testklinge"}, CharacterID: attacker.ID, UserID: owner.UserID},
+		Anb:             1,
+		Schb:            1,
+	}
+	require.NoError(t, database.DB.Create(&weapon).Error)
+	require.NoError(t, database.DB.Create(&models.SkWaffenfertigkeit{SkFertigkeit: models.SkFertigkeit{
+		BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Testwaffen"}, CharacterID: attacker.ID, UserID: owner.UserID},
+		Fertigkeitswert: 7,
+	}}).Error)
+
+	target := createCharacterOwnedBy(t, owner.UserID)
+	require.NoError(t, database.DB.Create(&models.Lp{CharacterID: target.ID, Max: 15, Value: 15}).Error)
+	require.NoError(t, database.DB.Create(&models.Ap{CharacterID: target.ID, Max: 20, Value: 20}).Error)
+
+	params := map[string]string{"id": fmt.Sprint(attacker.ID)}
+
+	t.Run("attack uses weapon skill and Anb", func(t *testing.T) {
+		useFixedD20(t, 12)
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"weapon_id": weapon.ID}, owner.UserID, params)
+
+		CombatAttack(ctx)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response CombatRollResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 7+0+1, response.Value)
+		assert.Equal(t, 20, response.Result)
+		assert.True(t, response.Success)
+		// ohne Eigenschaften beträgt der Schadensbonus -3
+		assert.Equal(t, "1W6+2-2", response.Damage)
+	})
+
+	t.Run("defense against a higher attack fails", func(t *testing.T) {
+		useFixedD20(t, 5)
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"attack_result": 30}, owner.UserID, params)
+
+		CombatDefense(ctx)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response CombatRollResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.False(t, response.Success)
+	})
+
+	t.Run("damage is reduced by armour and applied to target", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{
+			"weapon_id": weapon.ID,
+			"heavy":     true,
+			"target_id": target.ID,
+			"armor":     1,
+			"apply":     true,
+		}, owner.UserID, params)
+
+		CombatDamage(ctx)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response DamageResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.True(t, response.Applied)
+		assert.Equal(t, max(response.Roll.Total-1, 0), response.LPLoss)
+		require.NotNil(t, response.Target)
+		assert.Equal(t, 15-response.LPLoss, response.Target.LP.Value)
+	})
+
+	t.Run("damage cannot be applied to foreign characters", func(t *testing.T) {
+		stranger := ensureUserExists(t, 205)
+		foreign := createCharacterOwnedBy(t, stranger.UserID)
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{
+			"weapon_id": weapon.ID,
+			"target_id": foreign.ID,
+			"apply":     true,
+		}, owner.UserID, params)
+
+		CombatDamage(ctx)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		ctx, w = buildJSONContext(t, http.MethodPost, map[string]any{
+			"weapon_id": weapon.ID,
+			"target_id": foreign.ID,
+		}, owner.UserID, params)
+		CombatDamage(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code, "vitals of unreadable targets must not leak")
+		assert.NotContains(t, w.Body.String(), "\"lp\"")
+	})
+}
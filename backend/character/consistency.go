@@ -0,0 +1,526 @@
+package character
+
+import (
+	"bamort/database"
+	"bamort/models"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Kategorien der Konsistenzprüfung
+const (
+	ConsistencyMasterData = "master_data"
+	ConsistencySkillValue = "skill_value"
+	ConsistencyAttribute  = "attribute"
+	ConsistencyDerived    = "derived_value"
+	ConsistencyContainer  = "container"
+	ConsistencyAuditLog   = "audit_log"
+)
+
+// Schweregrade einer Inkonsistenz
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// ConsistencyRules enthält die Grenzwerte eines Spielsystems für die Prüfung
+type ConsistencyRules struct {
+	MinAttribute        int      `json:"min_attribute"`
+	MaxAttribute        int      `json:"max_attribute"`
+	Attributes          []string `json:"attributes"` // Eigenschaften, die jeder Charakter haben muss
+	MaxSkillValue       int      `json:"max_skill_value"`
+	MaxWeaponSkillValue int      `json:"max_weapon_skill_value"`
+}
+
+var defaultConsistencyRules = ConsistencyRules{
+	MinAttribute:        1,
+	MaxAttribute:        100,
+	Attributes:          []string{"St", "Gs", "Gw", "Ko", "In", "Zt", "Au"},
+	MaxSkillValue:       18,
+	MaxWeaponSkillValue: 18,
+}
+
+// auditedFields ordnet Audit-Log-Felder dem aktuellen Wert am Charakter zu
+var auditedFields = map[string]func(char *models.Char) int{
+	"experience_points": func(char *models.Char) int { return char.Erfahrungsschatz.EP },
+	"gold":              func(char *models.Char) int { return char.Vermoegen.Goldstuecke },
+	"silver":            func(char *models.Char) int { return char.Vermoegen.Silberstuecke },
+	"copper":            func(char *models.Char) int { return char.Vermoegen.Kupferstuecke },
+	"lp":                func(char *models.Char) int { return char.Lp.Value },
+	"ap":                func(char *models.Char) int { return char.Ap.Value },
+}
+
+// SuggestedFix beschreibt eine vorgeschlagene Korrektur
+type SuggestedFix struct {
+	ID          string `json:"id"`
+	Description string `json:"description"`
+	Value       any    `json:"value,omitempty"`
+	Automatic   bool   `json:"automatic"` // kann ohne Rückfrage angewendet werden
+
+	apply func(tx *gorm.DB, userID uint) error
+}
+
+// ConsistencyIssue ist eine gefundene Inkonsistenz
+type ConsistencyIssue struct {
+	Category string        `json:"category"`
+	Severity string        `json:"severity"`
+	Item     string        `json:"item,omitempty"` // betroffene Fertigkeit, Eigenschaft, Gegenstand ...
+	Message  string        `json:"message"`
+	Current  any           `json:"current,omitempty"`
+	Expected any           `json:"expected,omitempty"`
+	Fix      *SuggestedFix `json:"fix,omitempty"`
+}
+
+// ConsistencyReport ist das Ergebnis der Prüfung eines Charakters
+type ConsistencyReport struct {
+	CharacterID   uint               `json:"character_id"`
+	CharacterName string             `json:"character_name"`
+	Valid         bool               `json:"valid"` // keine Fehler (Warnungen erlaubt)
+	Errors        int                `json:"errors"`
+	Warnings      int                `json:"warnings"`
+	Issues        []ConsistencyIssue `json:"issues"`
+}
+
+// MasterDataIndex enthält die Namen aller Stammdaten eines Spielsystems
+// Schlüssel ist der normalisierte Name, Wert der Name in den Stammdaten.
+type MasterDataIndex struct {
+	Skills       map[string]string
+	WeaponSkills map[string]string
+	Spells       map[string]string
+	Casters      map[string]bool // Name und Kürzel der Klassen mit Zauberlerneinheiten
+}
+
+func normalizeMasterName(name string) string {
+	return strings.ToLower(strings.TrimSpace(name))
+}
+
+// LoadMasterDataIndex lädt die Namen der Fertigkeiten, Waffenfertigkeiten und Zauber eines Spielsystems
+// Für Stapelprüfungen sollte der Index einmal geladen und wiederverwendet werden.
+func LoadMasterDataIndex(gs *models.GameSystem) (*MasterDataIndex, error) {
+	index := &MasterDataIndex{
+		Skills:       map[string]string{},
+		WeaponSkills: map[string]string{},
+		Spells:       map[string]string{},
+		Casters:      map[string]bool{},
+	}
+	tables := []struct {
+		model  any
+		target map[string]string
+	}{
+		{&models.Skill{}, index.Skills},
+		{&models.WeaponSkill{}, index.WeaponSkills},
+		{&models.Spell{}, index.Spells},
+	}
+	for _, table := range tables {
+		var names []string
+		if err := database.DB.Model(table.model).
+			Where("game_system = ? OR game_system_id = ?", gs.Name, gs.ID).
+			Pluck("name", &names).Error; err != nil {
+			return nil, fmt.Errorf("failed to load master data: %w", err)
+		}
+		for _, name := range names {
+			table.target[normalizeMasterName(name)] = name
+		}
+	}
+
+	var casters []models.CharacterClass
+	if err := database.DB.Joins("JOIN gsm_cc_class_spell_points ON gsm_cc_class_spell_points.character_class_id = gsm_character_classes.id").
+		Where("gsm_cc_class_spell_points.spell_points > 0").
+		Find(&casters).Error; err != nil {
+		return nil, fmt.Errorf("failed to load spellcasting classes: %w", err)
+	}
+	for _, class := range casters {
+		index.Casters[normalizeMasterName(class.Name)] = true
+		index.Casters[normalizeMasterName(class.Code)] = true
+	}
+	return index, nil
+}
+
+// canCast meldet, ob der Charakter zaubern kann: seine Klasse hat Zauberlerneinheiten oder er kennt Zauber
+func (index *MasterDataIndex) canCast(char *models.Char) bool {
+	return index.Casters[normalizeMasterName(char.Typ)] || len(char.Zauber) > 0
+}
+
+// characterGameSystem liefert das Spielsystem des Charakters oder das Standardsystem
+func characterGameSystem(char *models.Char) *models.GameSystem {
+	gs := models.GetGameSystem(char.GameSystemId, char.GameSystem)
+	if gs == nil {
+		gs = models.GetGameSystem(0, "")
+	}
+	return gs
+}
+
+func getConsistencyRules(gs *models.GameSystem) ConsistencyRules {
+	return models.GameSystemRules(gs, "consistency", defaultConsistencyRules)
+}
+
+// ValidateCharacter prüft einen vollständig geladenen Charakter
+// index darf nil sein, dann werden die Stammdaten des Spielsystems geladen.
+func ValidateCharacter(char *models.Char, index *MasterDataIndex) (*ConsistencyReport, error) {
+	gs := characterGameSystem(char)
+	if index == nil {
+		var err error
+		if index, err = LoadMasterDataIndex(gs); err != nil {
+			return nil, err
+		}
+	}
+	rules := getConsistencyRules(gs)
+
+	issues := make([]ConsistencyIssue, 0)
+	issues = append(issues, checkSkillsAgainstMasterData(char, index, rules)...)
+	issues = append(issues, checkAttributes(char, rules)...)
+	issues = append(issues, checkDerivedValues(char, index, rules)...)
+	issues = append(issues, checkContainerReferences(char)...)
+
+	auditIssues, err := checkAuditLog(char)
+	if err != nil {
+		return nil, err
+	}
+	issues = append(issues, auditIssues...)
+
+	report := &ConsistencyReport{
+		CharacterID:   char.ID,
+		CharacterName: char.Name,
+		Issues:        issues,
+	}
+	for _, issue := range issues {
+		if issue.Severity == SeverityError {
+			report.Errors++
+		} else {
+			report.Warnings++
+		}
+	}
+	report.Valid = report.Errors == 0
+	return report, nil
+}
+
+// checkSkillsAgainstMasterData prüft Fertigkeiten, Waffenfertigkeiten und Zauber gegen die Stammdaten
+// sowie die Fertigkeitswerte gegen das Maximum des Spielsystems
+func checkSkillsAgainstMasterData(char *models.Char, index *MasterDataIndex, rules ConsistencyRules) []ConsistencyIssue {
+	issues := make([]ConsistencyIssue, 0)
+
+	checkName := func(kind, name string, id uint, master map[string]string, table any) {
+		canonical, known := master[normalizeMasterName(name)]
+		if known && canonical == name {
+			return
+		}
+		issue := ConsistencyIssue{
+			Category: ConsistencyMasterData,
+			Severity: SeverityError,
+			Item:     name,
+			Message:  fmt.Sprintf("%s '%s' ist in den Stammdaten nicht vorhanden", kind, name),
+		}
+		if known {
+			// Nur Groß-/Kleinschreibung oder Leerzeichen weichen ab
+			issue.Severity = SeverityWarning
+			issue.Message = fmt.Sprintf("%s '%s' weicht in der Schreibweise von den Stammdaten ab", kind, name)
+			issue.Expected = canonical
+			issue.Fix = &SuggestedFix{
+				ID:          fmt.Sprintf("rename:%s:%d", kind, id),
+				Description: fmt.Sprintf("In '%s' umbenennen", canonical),
+				Value:       canonical,
+				Automatic:   true,
+				apply: func(tx *gorm.DB, userID uint) error {
+					return tx.Model(table).Where("id = ?", id).Update("name", canonical).Error
+				},
+			}
+		}
+		issues = append(issues, issue)
+	}
+
+	checkValue := func(kind, name string, id uint, value, maxValue int, table any) {
+		if value >= 0 && value <= maxValue {
+			return
+		}
+		expected := min(max(value, 0), maxValue)
+		issues = append(issues, ConsistencyIssue{
+			Category: ConsistencySkillValue,
+			Severity: SeverityError,
+			Item:     name,
+			Message:  fmt.Sprintf("Fertigkeitswert von %s '%s' liegt außerhalb von 0..%d", kind, name, maxValue),
+			Current:  value,
+			Expected: expected,
+			Fix: &SuggestedFix{
+				ID:          fmt.Sprintf("clamp:%s:%d", kind, id),
+				Description: fmt.Sprintf("Fertigkeitswert auf %d setzen", expected),
+				Value:       expected,
+				Automatic:   true,
+				apply: func(tx *gorm.DB, userID uint) error {
+					return tx.Model(table).Where("id = ?", id).Update("fertigkeitswert", expected).Error
+				},
+			},
+		})
+	}
+
+	for _, skill := range char.Fertigkeiten {
+		checkName("Fertigkeit", skill.Name, skill.ID, index.Skills, &models.SkFertigkeit{})
+		checkValue("Fertigkeit", skill.Name, skill.ID, skill.Fertigkeitswert, rules.MaxSkillValue, &models.SkFertigkeit{})
+	}
+	for _, skill := range char.Waffenfertigkeiten {
+		checkName("Waffenfertigkeit", skill.Name, skill.ID, index.WeaponSkills, &models.SkWaffenfertigkeit{})
+		checkValue("Waffenfertigkeit", skill.Name, skill.ID, skill.Fertigkeitswert, rules.MaxWeaponSkillValue, &models.SkWaffenfertigkeit{})
+	}
+	for _, spell := range char.Zauber {
+		checkName("Zauber", spell.Name, spell.ID, index.Spells, &models.SkZauber{})
+	}
+	return issues
+}
+
+// checkAttributes prüft Vorhandensein und Wertebereich der Eigenschaften
+func checkAttributes(char *models.Char, rules ConsistencyRules) []ConsistencyIssue {
+	issues := make([]ConsistencyIssue, 0)
+	present := map[string]bool{}
+
+	for _, attr := range char.Eigenschaften {
+		present[attr.Name] = true
+		if attr.Value >= rules.MinAttribute && attr.Value <= rules.MaxAttribute {
+			continue
+		}
+		expected := min(max(attr.Value, rules.MinAttribute), rules.MaxAttribute)
+		characterID, name := attr.CharacterID, attr.Name
+		issues = append(issues, ConsistencyIssue{
+			Category: ConsistencyAttribute,
+			Severity: SeverityError,
+			Item:     attr.Name,
+			Message:  fmt.Sprintf("Eigenschaft %s liegt außerhalb von %d..%d", attr.Name, rules.MinAttribute, rules.MaxAttribute),
+			Current:  attr.Value,
+			Expected: expected,
+			Fix: &SuggestedFix{
+				ID:          "attribute:" + attr.Name,
+				Description: fmt.Sprintf("%s auf %d setzen", attr.Name, expected),
+				Value:       expected,
+				Automatic:   true,
+				apply: func(tx *gorm.DB, userID uint) error {
+					return tx.Model(&models.Eigenschaft{}).
+						Where("character_id = ? AND name = ?", characterID, name).
+						Update("value", expected).Error
+				},
+			},
+		})
+	}
+
+	for _, name := range rules.Attributes {
+		if !present[name] {
+			issues = append(issues, ConsistencyIssue{
+				Category: ConsistencyAttribute,
+				Severity: SeverityError,
+				Item:     name,
+				Message:  fmt.Sprintf("Eigenschaft %s fehlt", name),
+			})
+		}
+	}
+	return issues
+}
+
+// checkDerivedValues vergleicht die gespeicherten abgeleiteten Werte mit CalculateStaticFieldsLogic
+// Ohne vollständige Eigenschaften lässt sich nichts sinnvoll berechnen, Zaubern nur bei Klassen, die zaubern können.
+func checkDerivedValues(char *models.Char, index *MasterDataIndex, rules ConsistencyRules) []ConsistencyIssue {
+	issues := make([]ConsistencyIssue, 0)
+	for _, name := range rules.Attributes {
+		if char.GetAttributeValue(name) < rules.MinAttribute {
+			return issues
+		}
+	}
+
+	static := CalculateStaticFieldsForCharacter(char)
+	derived := []struct {
+		field    string
+		column   string
+		current  int
+		expected int
+	}{
+		{"Resistenz Körper", "resistenz_koerper", char.ResistenzKoerper, static.ResistenzKoerper},
+		{"Resistenz Geist", "resistenz_geist", char.ResistenzGeist, static.ResistenzGeist},
+		{"Abwehr", "abwehr", char.Abwehr, static.Abwehr},
+		{"Zaubern", "zaubern", char.Zaubern, static.Zaubern},
+		{"Raufen", "raufen", char.Raufen, static.Raufen},
+	}
+
+	characterID := char.ID
+	for _, d := range derived {
+		if d.current == d.expected || (d.column == "zaubern" && !index.canCast(char)) {
+			continue
+		}
+		column, expected := d.column, d.expected
+		issues = append(issues, ConsistencyIssue{
+			Category: ConsistencyDerived,
+			Severity: SeverityWarning,
+			Item:     d.field,
+			Message:  fmt.Sprintf("%s entspricht nicht dem berechneten Wert", d.field),
+			Current:  d.current,
+			Expected: d.expected,
+			Fix: &SuggestedFix{
+				ID:          "derived:" + d.column,
+				Description: fmt.Sprintf("%s auf %d setzen", d.field, d.expected),
+				Value:       d.expected,
+				Automatic:   true,
+				apply: func(tx *gorm.DB, userID uint) error {
+					return tx.Model(&models.Char{}).Where("id = ?", characterID).UpdateColumn(column, expected).Error
+				},
+			},
+		})
+	}
+	return issues
+}
+
+// checkContainerReferences prüft, ob ContainedIn auf einen Behälter des Charakters verweist
+func checkContainerReferences(char *models.Char) []ConsistencyIssue {
+	issues := make([]ConsistencyIssue, 0)
+
+	containers := map[uint]bool{}
+	for _, c := range char.Behaeltnisse {
+		containers[c.ID] = true
+	}
+	for _, c := range char.Transportmittel {
+		containers[c.ID] = true
+	}
+
+	check := func(kind, name string, id, containedIn uint, table any) {
+		if containedIn == 0 || (containers[containedIn] && !(kind == "Behälter" && containedIn == id)) {
+			return
+		}
+		issues = append(issues, ConsistencyIssue{
+			Category: ConsistencyContainer,
+			Severity: SeverityWarning,
+			Item:     name,
+			Message:  fmt.Sprintf("%s '%s' verweist auf einen ungültigen Behälter (%d)", kind, name, containedIn),
+			Current:  containedIn,
+			Fix: &SuggestedFix{
+				ID:          fmt.Sprintf("container:%s:%d", kind, id),
+				Description: "Verweis auf den Behälter entfernen",
+				Automatic:   true,
+				apply: func(tx *gorm.DB, userID uint) error {
+					return tx.Model(table).Where("id = ?", id).Update("contained_in", 0).Error
+				},
+			},
+		})
+	}
+
+	for _, item := range char.Ausruestung {
+		check("Ausrüstung", item.Name, item.ID, item.ContainedIn, &models.EqAusruestung{})
+	}
+	for _, item := range char.Waffen {
+		check("Waffe", item.Name, item.ID, item.ContainedIn, &models.EqWaffe{})
+	}
+	// Behaeltnisse und Transportmittel stammen aus derselben Tabelle
+	seen := map[uint]bool{}
+	for _, list := range [][]models.EqContainer{char.Behaeltnisse, char.Transportmittel} {
+		for _, item := range list {
+			if seen[item.ID] {
+				continue
+			}
+			seen[item.ID] = true
+			check("Behälter", item.Name, item.ID, item.ContainedIn, &models.EqContainer{})
+		}
+	}
+	return issues
+}
+
+// checkAuditLog prüft die Audit-Log-Einträge je Feld
+// Jeder Eintrag muss in sich stimmig sein, an den vorherigen anschließen und
+// der letzte Eintrag muss dem aktuellen Wert am Charakter entsprechen.
+func checkAuditLog(char *models.Char) ([]ConsistencyIssue, error) {
+	issues := make([]ConsistencyIssue, 0)
+
+	var entries []models.AuditLogEntry
+	if err := database.DB.Where("character_id = ?", char.ID).Order("timestamp ASC, id ASC").Find(&entries).Error; err != nil {
+		return nil, fmt.Errorf("failed to load audit log: %w", err)
+	}
+
+	byField := map[string][]models.AuditLogEntry{}
+	for _, entry := range entries {
+		byField[entry.FieldName] = append(byField[entry.FieldName], entry)
+		if entry.Difference != entry.NewValue-entry.OldValue {
+			issues = append(issues, ConsistencyIssue{
+				Category: ConsistencyAuditLog,
+				Severity: SeverityWarning,
+				Item:     entry.FieldName,
+				Message:  fmt.Sprintf("Audit-Eintrag %d: Differenz %d passt nicht zu %d -> %d", entry.ID, entry.Difference, entry.OldValue, entry.NewValue),
+			})
+		}
+	}
+
+	fields := make([]string, 0, len(byField))
+	for field := range byField {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	characterID := char.ID
+	for _, field := range fields {
+		list := byField[field]
+		for i := 1; i < len(list); i++ {
+			if list[i].OldValue != list[i-1].NewValue {
+				issues = append(issues, ConsistencyIssue{
+					Category: ConsistencyAuditLog,
+					Severity: SeverityWarning,
+					Item:     field,
+					Message:  fmt.Sprintf("Audit-Eintrag %d beginnt bei %d, der vorherige endete bei %d", list[i].ID, list[i].OldValue, list[i-1].NewValue),
+				})
+			}
+		}
+
+		currentValue, ok := auditedFields[field]
+		if !ok {
+			continue
+		}
+		last := list[len(list)-1].NewValue
+		current := currentValue(char)
+		if last == current {
+			continue
+		}
+		fieldName := field
+		issues = append(issues, ConsistencyIssue{
+			Category: ConsistencyAuditLog,
+			Severity: SeverityWarning,
+			Item:     field,
+			Message:  fmt.Sprintf("Aktueller Wert von %s weicht vom Audit-Log ab", field),
+			Current:  current,
+			Expected: last,
+			Fix: &SuggestedFix{
+				ID:          "audit:" + field,
+				Description: fmt.Sprintf("Korrektureintrag %d -> %d im Audit-Log anlegen", last, current),
+				Value:       current,
+				Automatic:   true,
+				apply: func(tx *gorm.DB, userID uint) error {
+					return CreateAuditLogEntryTx(tx, characterID, fieldName, last, current, ReasonCorrection, userID, "Konsistenzprüfung")
+				},
+			},
+		})
+	}
+	return issues, nil
+}
+
+// ApplyConsistencyFixes wendet vorgeschlagene Korrekturen in einer Transaktion an
+// Ist fixIDs leer, werden alle automatischen Korrekturen angewendet.
+func ApplyConsistencyFixes(report *ConsistencyReport, fixIDs []string, userID uint) ([]string, error) {
+	wanted := map[string]bool{}
+	for _, id := range fixIDs {
+		wanted[id] = true
+	}
+
+	applied := make([]string, 0)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, issue := range report.Issues {
+			fix := issue.Fix
+			if fix == nil || fix.apply == nil {
+				continue
+			}
+			if len(wanted) == 0 && !fix.Automatic || len(wanted) > 0 && !wanted[fix.ID] {
+				continue
+			}
+			if err := fix.apply(tx, userID); err != nil {
+				return fmt.Errorf("failed to apply fix %s: %w", fix.ID, err)
+			}
+			applied = append(applied, fix.ID)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
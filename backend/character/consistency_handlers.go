@@ -0,0 +1,84 @@
+package character
+
+import (
+	"bamort/logger"
+	"bamort/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConsistencyFixRequest wählt die anzuwendenden Korrekturen aus
+type ConsistencyFixRequest struct {
+	FixIDs []string `json:"fix_ids,omitempty"` // leer: alle automatischen Korrekturen
+}
+
+// ConsistencyFixResponse enthält die angewendeten Korrekturen und den neuen Prüfbericht
+type ConsistencyFixResponse struct {
+	Applied []string           `json:"applied"`
+	Report  *ConsistencyReport `json:"report"`
+}
+
+// GetCharacterConsistency prüft einen Charakter auf Inkonsistenzen
+func GetCharacterConsistency(c *gin.Context) {
+	var char models.Char
+	if err := char.FirstID(c.Param("id")); err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return
+	}
+	if !CanReadCharacter(&char, c.GetUint("userID")) {
+		respondWithError(c, http.StatusForbidden, "You are not authorized to view this character")
+		return
+	}
+
+	report, err := ValidateCharacter(&char, nil)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, report)
+}
+
+// FixCharacterConsistency wendet vorgeschlagene Korrekturen an und prüft erneut
+func FixCharacterConsistency(c *gin.Context) {
+	var char models.Char
+	if err := char.FirstID(c.Param("id")); err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return
+	}
+	if !checkCharacterOwnership(c, &char) {
+		return
+	}
+
+	var req ConsistencyFixRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	report, err := ValidateCharacter(&char, nil)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	applied, err := ApplyConsistencyFixes(report, req.FixIDs, c.GetUint("userID"))
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	logger.Info("Konsistenzprüfung: %d Korrekturen an Charakter %d angewendet", len(applied), char.ID)
+
+	var reloaded models.Char
+	if err := reloaded.FirstID(c.Param("id")); err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to reload character")
+		return
+	}
+	report, err = ValidateCharacter(&reloaded, nil)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, ConsistencyFixResponse{Applied: applied, Report: report})
+}
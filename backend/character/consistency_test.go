@@ -0,0 +1,132 @@
+package character
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func findConsistencyIssue(report *ConsistencyReport, category, item string) *ConsistencyIssue {
+	for i := range report.Issues {
+		if report.Issues[i].Category == category && report.Issues[i].Item == item {
+			return &report.Issues[i]
+		}
+	}
+	return nil
+}
+
+func TestCharacterConsistency(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+
+	require.NoError(t, models.MigrateStructure())
+
+	require.NoError(t, database.DB.Create(&models.Skill{Name: "Prüfkunde", Initialwert: 5}).Error)
+
+	owner := ensureUserExists(t, 206)
+	char := createCharacterOwnedBy(t, owner.UserID)
+	for _, name := range []string{"St", "Gs", "Gw", "Ko", "In", "Zt", "Au"} {
+		value := 50
+		if name == "Au" {
+			value = 130
+		}
+		require.NoError(t, database.DB.Create(&models.Eigenschaft{CharacterID: char.ID, UserID: owner.UserID, Name: name, Value: value}).Error)
+	}
+	seedSkill(t, char, "prüfkunde", 25, 0)
+	seedSkill(t, char, "Unbekanntes Handwerk", 8, 0)
+	seedExperience(t, char, 120)
+	require.NoError(t, CreateAuditLogEntry(char.ID, "experience_points", 0, 100, ReasonReward, owner.UserID, ""))
+	require.NoError(t, database.DB.Create(&models.EqAusruestung{
+		BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Seil"}, CharacterID: char.ID, UserID: owner.UserID},
+		ContainedIn:     99999,
+	}).Error)
+
+	var loaded models.Char
+	require.NoError(t, loaded.FirstID(fmt.Sprint(char.ID)))
+	report, err := ValidateCharacter(&loaded, nil)
+	require.NoError(t, err)
+	assert.False(t, report.Valid)
+
+	unknown := findConsistencyIssue(report, ConsistencyMasterData, "Unbekanntes Handwerk")
+	require.NotNil(t, unknown)
+	assert.Equal(t, SeverityError, unknown.Severity)
+	assert.Nil(t, unknown.Fix, "unknown skills need a manual decision")
+
+	renamed := findConsistencyIssue(report, ConsistencyMasterData, "prüfkunde")
+	require.NotNil(t, renamed)
+	require.NotNil(t, renamed.Fix)
+	assert.Equal(t, "Prüfkunde", renamed.Fix.Value)
+
+	tooHigh := findConsistencyIssue(report, ConsistencySkillValue, "prüfkunde")
+	require.NotNil(t, tooHigh)
+	assert.Equal(t, 18, tooHigh.Expected)
+
+	attribute := findConsistencyIssue(report, ConsistencyAttribute, "Au")
+	require.NotNil(t, attribute)
+	assert.Equal(t, 100, attribute.Expected)
+
+	abwehr := findConsistencyIssue(report, ConsistencyDerived, "Abwehr")
+	require.NotNil(t, abwehr, "stored Abwehr 0 differs from the calculated value")
+	assert.Nil(t, findConsistencyIssue(report, ConsistencyDerived, "Zaubern"), "warriors cannot cast, Zaubern is not checked")
+
+	assert.NotNil(t, findConsistencyIssue(report, ConsistencyContainer, "Seil"))
+
+	audit := findConsistencyIssue(report, ConsistencyAuditLog, "experience_points")
+	require.NotNil(t, audit)
+	assert.Equal(t, 120, audit.Current)
+	assert.Equal(t, 100, audit.Expected)
+
+	t.Run("fix endpoint applies automatic fixes", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodPost, nil, owner.UserID, map[string]string{"id": fmt.Sprint(char.ID)})
+
+		FixCharacterConsistency(ctx)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response ConsistencyFixResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.NotEmpty(t, response.Applied)
+		require.Len(t, response.Report.Issues, 1, "only the unknown skill remains: %+v", response.Report.Issues)
+		assert.Equal(t, "Unbekanntes Handwerk", response.Report.Issues[0].Item)
+	})
+
+	t.Run("only the owner may apply fixes", func(t *testing.T) {
+		stranger := ensureUserExists(t, 207)
+		ctx, w := buildJSONContext(t, http.MethodPost, nil, stranger.UserID, map[string]string{"id": fmt.Sprint(char.ID)})
+
+		FixCharacterConsistency(ctx)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("report requires read access", func(t *testing.T) {
+		stranger := ensureUserExists(t, 207)
+		ctx, w := buildJSONContext(t, http.MethodGet, nil, stranger.UserID, map[string]string{"id": fmt.Sprint(char.ID)})
+
+		GetCharacterConsistency(ctx)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Zaubern is checked for casting classes", func(t *testing.T) {
+		require.NoError(t, database.DB.Model(&models.Char{}).Where("id = ?", char.ID).Updates(map[string]any{"typ": "Magier", "zaubern": 0}).Error)
+		var caster models.Char
+		require.NoError(t, caster.FirstID(fmt.Sprint(char.ID)))
+
+		report, err := ValidateCharacter(&caster, nil)
+
+		require.NoError(t, err)
+		assert.NotNil(t, findConsistencyIssue(report, ConsistencyDerived, "Zaubern"))
+	})
+}
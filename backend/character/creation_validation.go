@@ -0,0 +1,324 @@
+package character
+
+import (
+	"bamort/models"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Bereiche der Prüfungen bei Abschluss der Charaktererstellung
+const (
+	CreationCheckBasics         = "basics"
+	CreationCheckRaceClass      = "race_class"
+	CreationCheckAttribute      = "attribute"
+	CreationCheckDerivedValue   = "derived_value"
+	CreationCheckLearningPoint  = "learning_points"
+	CreationCheckSpell          = "spell"
+	CreationCheckSpecialAbility = "special_ability"
+)
+
+// CreationViolation beschreibt einen Regelverstoß in einer Erstellungssession
+type CreationViolation struct {
+	Check    string `json:"check"`
+	Field    string `json:"field"`
+	Message  string `json:"message"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// RaceRules enthält die Einschränkungen der Rassen bei der Erschaffung
+// Ein Spielsystem kann einzelne Rassen überschreiben oder neue ergänzen.
+type RaceRules struct {
+	ClassRestrictions map[string][]string          `json:"class_restrictions"` // erlaubte Klassen (Abkürzungen) je Rasse, ohne Eintrag alle
+	AttributeLimits   map[string]map[string][2]int `json:"attribute_limits"`   // Grenzen einzelner Eigenschaften je Rasse (min, max)
+}
+
+// defaultRaceRules entsprechen den Regeln des M5-Systems
+var defaultRaceRules = RaceRules{
+	ClassRestrictions: map[string][]string{
+		"Elf":      {"Ba", "Dr", "Gl", "Kr", "Ma", "Wa"},
+		"Gnom":     {"Gl", "Hä", "Hx", "Ma", "Sp"},
+		"Halbling": {"Gl", "Hä", "Kr", "Sp", "Wa"},
+		"Zwerg":    {"Gl", "Hä", "Kr", "PB", "PS", "Sp"},
+	},
+	AttributeLimits: map[string]map[string][2]int{
+		"Elf":   {"Au": {81, 100}},
+		"Gnom":  {"Au": {1, 80}},
+		"Zwerg": {"Au": {1, 80}},
+	},
+}
+
+func getRaceRules(gs *models.GameSystem) RaceRules {
+	return models.GameSystemRules(gs, "races", defaultRaceRules)
+}
+
+// creationValidator sammelt die Verstöße einer Session
+type creationValidator struct {
+	session    *models.CharacterCreationSession
+	filter     learnableFilter // Hausregeln und Quellenauswahl des Benutzers bzw. der Kampagne
+	rules      ConsistencyRules
+	races      RaceRules
+	violations []CreationViolation
+}
+
+func (v *creationValidator) add(check, field, message string, expected, actual any) {
+	violation := CreationViolation{Check: check, Field: field, Message: message}
+	if expected != nil {
+		violation.Expected = fmt.Sprint(expected)
+	}
+	if actual != nil {
+		violation.Actual = fmt.Sprint(actual)
+	}
+	v.violations = append(v.violations, violation)
+}
+
+// ValidateCreationSession prüft alle Angaben einer Erstellungssession gegen die Regeln
+// Geprüft werden Eigenschaften, abgeleitete Werte, besondere Fähigkeiten, Rasse/Klasse sowie
+// die verbrauchten Lernpunkte je Kategorie (inkl. Stand-Bonus) und Zauberlerneinheiten.
+// Klasse, Fertigkeiten und Zauber müssen aus der Quellenauswahl der Kampagne bzw. des Benutzers stammen.
+func ValidateCreationSession(session *models.CharacterCreationSession) []CreationViolation {
+	v := &creationValidator{session: session}
+	filter, err := creationLearnableFilter(session.UserID, session.CampaignID)
+	if err != nil {
+		v.add(CreationCheckBasics, "sources", "Quellenauswahl konnte nicht geladen werden", nil, nil)
+		return v.violations
+	}
+	v.filter = filter
+	gs := characterGameSystem(&models.Char{})
+	v.rules = getConsistencyRules(gs)
+	v.races = getRaceRules(gs)
+
+	if strings.TrimSpace(session.Name) == "" {
+		v.add(CreationCheckBasics, "name", "Name fehlt", nil, nil)
+	}
+	if session.Rasse == "" {
+		v.add(CreationCheckBasics, "rasse", "Rasse fehlt", nil, nil)
+	}
+
+	data, err := getLearningPointsForClass(session.Typ, session.Stand)
+	if err != nil {
+		v.add(CreationCheckBasics, "typ", "Unbekannte Charakterklasse", nil, session.Typ)
+	}
+
+	v.checkAttributes()
+	v.checkDerivedValues()
+	v.checkSpecialAbilities()
+	if data != nil {
+		v.checkRaceClass(data.ClassCode)
+		v.checkLearningPoints(data)
+		v.checkSpells(data)
+	}
+	return v.violations
+}
+
+func (v *creationValidator) attributeValues() map[string]int {
+	a := v.session.Attributes
+	return map[string]int{"St": a.ST, "Gs": a.GS, "Gw": a.GW, "Ko": a.KO, "In": a.IN, "Zt": a.ZT, "Au": a.AU}
+}
+
+func (v *creationValidator) checkAttributes() {
+	rules := v.rules
+	values := v.attributeValues()
+	for _, name := range rules.Attributes {
+		value := values[name]
+		if value < rules.MinAttribute || value > rules.MaxAttribute {
+			v.add(CreationCheckAttribute, name, "Eigenschaft außerhalb des erlaubten Bereichs",
+				fmt.Sprintf("%d-%d", rules.MinAttribute, rules.MaxAttribute), value)
+			continue
+		}
+		if limits, ok := v.races.AttributeLimits[v.session.Rasse][name]; ok && (value < limits[0] || value > limits[1]) {
+			v.add(CreationCheckAttribute, name, fmt.Sprintf("Eigenschaft für %s nicht erlaubt", v.session.Rasse),
+				fmt.Sprintf("%d-%d", limits[0], limits[1]), value)
+		}
+	}
+}
+
+func (v *creationValidator) checkRaceClass(classCode string) {
+	var class models.CharacterClass
+	if err := class.FirstByNameOrCode(classCode); err == nil && !v.filter.sources.Contains(class.SourceID) {
+		v.add(CreationCheckRaceClass, "typ", fmt.Sprintf("%s stammt aus keinem aktiven Quellenbuch", v.session.Typ), nil, classCode)
+	}
+	allowed, restricted := v.races.ClassRestrictions[v.session.Rasse]
+	if restricted && !slices.Contains(allowed, classCode) {
+		v.add(CreationCheckRaceClass, "typ", fmt.Sprintf("%s kann nicht als %s erschaffen werden", v.session.Rasse, v.session.Typ),
+			strings.Join(allowed, ", "), classCode)
+	}
+}
+
+// rolledFieldRange berechnet den kleinsten und größten möglichen Wert eines gewürfelten Feldes
+func (v *creationValidator) rolledFieldRange(field string) (int, int, error) {
+	expression, err := getCreationRollExpression(field, v.session.Rasse)
+	if err != nil {
+		return 0, 0, err
+	}
+	expr, err := ParseDiceExpression(expression)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	a := v.session.Attributes
+	bounds := [2]int{}
+	for i, face := range []int{1, expr.Sides} {
+		req := CalculateRolledFieldRequest{
+			St: a.ST, Gs: a.GS, Gw: a.GW, Ko: a.KO, In: a.IN, Zt: a.ZT, Au: a.AU,
+			Rasse: v.session.Rasse, Typ: v.session.Typ, Field: field,
+		}
+		if field == "b_max" {
+			rolls := make([]interface{}, expr.Count)
+			for j := range rolls {
+				rolls[j] = float64(face)
+			}
+			req.Roll = rolls
+		} else {
+			req.Roll = float64(face * expr.Count)
+		}
+		result, err := calculateRolledField(req)
+		if err != nil {
+			return 0, 0, err
+		}
+		bounds[i] = result.Value
+	}
+	return bounds[0], bounds[1], nil
+}
+
+func (v *creationValidator) checkDerivedValues() {
+	a := v.session.Attributes
+	d := v.session.DerivedValues
+
+	static := CalculateStaticFieldsLogic(CalculateStaticFieldsRequest{
+		St: a.ST, Gs: a.GS, Gw: a.GW, Ko: a.KO, In: a.IN, Zt: a.ZT, Au: a.AU,
+		Rasse: v.session.Rasse, Typ: v.session.Typ, Grad: 1,
+	})
+	expected := []struct {
+		field    string
+		expected int
+		actual   int
+	}{
+		{"ausdauer_bonus", static.AusdauerBonus, d.AusdauerBonus},
+		{"schadens_bonus", static.SchadensBonus, d.SchadensBonus},
+		{"angriffs_bonus", static.AngriffsBonus, d.AngriffsBonus},
+		{"abwehr_bonus", static.AbwehrBonus, d.AbwehrBonus},
+		{"zauber_bonus", static.ZauberBonus, d.ZauberBonus},
+		{"resistenz_bonus_koerper", static.ResistenzBonusKoerper, d.ResistenzBonusKoerper},
+		{"resistenz_bonus_geist", static.ResistenzBonusGeist, d.ResistenzBonusGeist},
+		{"resistenz_koerper", static.ResistenzKoerper, d.ResistenzKoerper},
+		{"resistenz_geist", static.ResistenzGeist, d.ResistenzGeist},
+		{"abwehr", static.Abwehr, d.Abwehr},
+		{"zaubern", static.Zaubern, d.Zaubern},
+		{"raufen", static.Raufen, d.Raufen},
+	}
+	for _, e := range expected {
+		if e.expected != e.actual {
+			v.add(CreationCheckDerivedValue, e.field, "Abgeleiteter Wert stimmt nicht mit der Berechnung überein", e.expected, e.actual)
+		}
+	}
+
+	rolled := map[string]int{"pa": d.PA, "wk": d.WK, "lp_max": d.LPMax, "ap_max": d.APMax, "b_max": d.BMax}
+	for _, field := range []string{"pa", "wk", "lp_max", "ap_max", "b_max"} {
+		low, high, err := v.rolledFieldRange(field)
+		if err != nil {
+			v.add(CreationCheckDerivedValue, field, err.Error(), nil, nil)
+			continue
+		}
+		// pA und Wk werden auf den Eigenschaftsbereich begrenzt
+		if field == "pa" || field == "wk" {
+			low = max(low, v.rules.MinAttribute)
+			high = min(high, v.rules.MaxAttribute)
+		}
+		if value := rolled[field]; value < low || value > high {
+			v.add(CreationCheckDerivedValue, field, "Gewürfelter Wert mit diesen Eigenschaften nicht erreichbar",
+				fmt.Sprintf("%d-%d", low, high), value)
+		}
+	}
+}
+
+// findCreationCost sucht die Erstellungskosten einer Fertigkeit in ihrer Kategorie
+func findCreationCost(skills []gin.H, name string) (int, bool) {
+	for _, skill := range skills {
+		if skill["name"] == name {
+			return skill["leCost"].(int), true
+		}
+	}
+	return 0, false
+}
+
+func (v *creationValidator) checkLearningPoints(data *LearningPointsData) {
+	skillsByCategory, err := GetAllSkillsWithLE(v.filter)
+	if err != nil {
+		v.add(CreationCheckLearningPoint, "skills", "Fertigkeiten konnten nicht geladen werden", nil, nil)
+		return
+	}
+
+	spent := map[string]int{}
+	seen := map[string]bool{}
+	for _, skill := range v.session.Skills {
+		if seen[skill.Name] {
+			v.add(CreationCheckLearningPoint, skill.Name, "Fertigkeit mehrfach gewählt", nil, nil)
+			continue
+		}
+		seen[skill.Name] = true
+
+		// Die Kosten werden serverseitig bestimmt, die Angabe des Clients wird nur verglichen
+		cost, ok := findCreationCost(skillsByCategory[skill.Category], skill.Name)
+		if !ok {
+			v.add(CreationCheckLearningPoint, skill.Name, fmt.Sprintf("Fertigkeit in Kategorie %s nicht lernbar", skill.Category), nil, skill.Category)
+			continue
+		}
+		if skill.Cost != cost {
+			v.add(CreationCheckLearningPoint, skill.Name, "Lernkosten weichen ab", cost, skill.Cost)
+		}
+		spent[skill.Category] += cost
+	}
+
+	for category, points := range spent {
+		if available := data.LearningPoints[category]; points > available {
+			v.add(CreationCheckLearningPoint, category, "Zu viele Lernpunkte in Kategorie verbraucht", available, points)
+		}
+	}
+}
+
+func (v *creationValidator) checkSpells(data *LearningPointsData) {
+	if len(v.session.Spells) == 0 {
+		return
+	}
+	if data.SpellPoints <= 0 {
+		v.add(CreationCheckSpell, "spells", fmt.Sprintf("%s kann bei der Erschaffung keine Zauber lernen", data.ClassName), 0, len(v.session.Spells))
+		return
+	}
+
+	spellsByCategory, err := GetAllSpellsWithLE(data.ClassCode, 2, v.filter)
+	if err != nil {
+		v.add(CreationCheckSpell, "spells", "Zauber konnten nicht geladen werden", nil, nil)
+		return
+	}
+	costs := map[string]int{}
+	for _, spells := range spellsByCategory {
+		for _, spell := range spells {
+			costs[spell["name"].(string)] = spell["le_cost"].(int)
+		}
+	}
+
+	spent := 0
+	seen := map[string]bool{}
+	for _, spell := range v.session.Spells {
+		cost, ok := costs[spell.Name]
+		switch {
+		case seen[spell.Name]:
+			v.add(CreationCheckSpell, spell.Name, "Zauber mehrfach gewählt", nil, nil)
+		case !ok:
+			v.add(CreationCheckSpell, spell.Name, fmt.Sprintf("Zauber für %s bei der Erschaffung nicht lernbar", data.ClassName), nil, nil)
+		default:
+			if spell.Cost != cost {
+				v.add(CreationCheckSpell, spell.Name, "Lernkosten weichen ab", cost, spell.Cost)
+			}
+			spent += cost
+		}
+		seen[spell.Name] = true
+	}
+	if spent > data.SpellPoints {
+		v.add(CreationCheckSpell, "spells", "Zu viele Zauberlerneinheiten verbraucht", data.SpellPoints, spent)
+	}
+}
@@ -0,0 +1,204 @@
+package character
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// validCreationSession liefert eine regelkonforme Session für einen Priester Streiter
+func validCreationSession(userID uint) models.CharacterCreationSession {
+	return models.CharacterCreationSession{
+		ID:         "char_create_validation",
+		UserID:     userID,
+		Name:       "Validus",
+		Rasse:      "Mensch",
+		Typ:        "Priester Streiter",
+		Stand:      "Mittelschicht",
+		Attributes: models.AttributesData{ST: 89, GS: 64, GW: 77, KO: 71, IN: 87, ZT: 44, AU: 87},
+		DerivedValues: models.DerivedValuesData{
+			PA: 33, WK: 27, LPMax: 16, APMax: 14, BMax: 26,
+			ResistenzKoerper: 11, ResistenzGeist: 12, ResistenzBonusGeist: 1,
+			Abwehr: 11, AusdauerBonus: 11, Zaubern: 11, Raufen: 8, SchadensBonus: 3,
+		},
+		Skills: models.CharacterCreationSkills{
+			{Name: "Klettern", Category: "Alltag", Cost: 1},
+			{Name: "Athletik", Category: "Kampf", Cost: 2},
+			{Name: "Spießwaffen", Category: "Waffen", Cost: 2},
+			{Name: "Stielwurfwaffen", Category: "Waffen", Cost: 4},
+		},
+		Spells: models.CharacterCreationSpells{
+			{Name: "Göttlicher Schutz v. d. Bösen", Cost: 1},
+			{Name: "Heiliger Zorn", Cost: 2},
+		},
+		CurrentStep: 5,
+	}
+}
+
+func violationFields(violations []CreationViolation) []string {
+	fields := make([]string, len(violations))
+	for i, v := range violations {
+		fields[i] = v.Check + ":" + v.Field
+	}
+	return fields
+}
+
+func TestValidateCreationSession(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+
+	t.Run("valid session", func(t *testing.T) {
+		session := validCreationSession(1)
+		assert.Empty(t, ValidateCreationSession(&session))
+	})
+
+	tests := []struct {
+		name     string
+		modify   func(s *models.CharacterCreationSession)
+		expected []string
+	}{
+		{
+			name: "too many weapon points",
+			modify: func(s *models.CharacterCreationSession) {
+				s.Skills = append(s.Skills, models.CharacterCreationSkill{Name: "Bögen", Category: "Waffen", Cost: 6})
+			},
+			expected: []string{"learning_points:Waffen"},
+		},
+		{
+			name: "manipulated skill cost",
+			modify: func(s *models.CharacterCreationSession) {
+				s.Skills[3].Cost = 1
+			},
+			expected: []string{"learning_points:Stielwurfwaffen"},
+		},
+		{
+			name: "skill not in category",
+			modify: func(s *models.CharacterCreationSession) {
+				s.Skills = append(s.Skills, models.CharacterCreationSkill{Name: "Gibtsnicht", Category: "Alltag", Cost: 1})
+			},
+			expected: []string{"learning_points:Gibtsnicht"},
+		},
+		{
+			name: "social class bonus counts",
+			modify: func(s *models.CharacterCreationSession) {
+				s.Stand = ""
+				s.Skills = append(s.Skills, models.CharacterCreationSkill{Name: "Naturkunde", Category: "Wissen", Cost: 2}, models.CharacterCreationSkill{Name: "Heilkunde", Category: "Wissen", Cost: 2})
+			},
+			expected: []string{"learning_points:Wissen"},
+		},
+		{
+			name: "too many spell units",
+			modify: func(s *models.CharacterCreationSession) {
+				s.Spells = append(s.Spells, models.CharacterCreationSpell{Name: "Erkennen der Aura", Cost: 2}, models.CharacterCreationSpell{Name: "Blutmeisterschaft", Cost: 2})
+			},
+			expected: []string{"spell:spells"},
+		},
+		{
+			name:     "attribute out of range",
+			modify:   func(s *models.CharacterCreationSession) { s.Attributes.ZT = 0 },
+			expected: []string{"attribute:Zt"},
+		},
+		{
+			name: "derived value mismatch",
+			modify: func(s *models.CharacterCreationSession) {
+				s.DerivedValues.Abwehr = 15
+				s.DerivedValues.LPMax = 30
+			},
+			expected: []string{"derived_value:abwehr", "derived_value:lp_max"},
+		},
+		{
+			name: "open free choice of special ability",
+			modify: func(s *models.CharacterCreationSession) {
+				s.SpecialAbilities = models.CharacterCreationAbilities{{Name: "Freie Wahl", Roll: 100}, {Name: "Sehen", Modifier: 2, Roll: 25}}
+			},
+			expected: []string{"special_ability:special_abilities"},
+		},
+		{
+			name: "special ability does not match roll",
+			modify: func(s *models.CharacterCreationSession) {
+				s.SpecialAbilities = models.CharacterCreationAbilities{{Name: "Berserkergang", Roll: 25}}
+			},
+			expected: []string{"special_ability:Berserkergang"},
+		},
+		{
+			name:     "race class restriction",
+			modify:   func(s *models.CharacterCreationSession) { s.Rasse = "Elf" },
+			expected: []string{"race_class:typ"},
+		},
+		{
+			name:     "race attribute limit",
+			modify:   func(s *models.CharacterCreationSession) { s.Rasse = "Zwerg"; s.Typ = "Krieger"; s.Spells = nil },
+			expected: []string{"attribute:Au"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session := validCreationSession(1)
+			tt.modify(&session)
+			fields := violationFields(ValidateCreationSession(&session))
+			for _, field := range tt.expected {
+				assert.Contains(t, fields, field)
+			}
+		})
+	}
+
+	t.Run("game system rules", func(t *testing.T) {
+		// Grenzen und Rassentabellen kommen aus den Regeln des Spielsystems wie bei der Konsistenzprüfung
+		gs := models.GameSystem{Code: "M5", Name: "midgard", IsActive: true}
+		require.NoError(t, database.DB.Where("code = ?", "M5").FirstOrCreate(&gs).Error)
+		gs.Rules = map[string]json.RawMessage{
+			"consistency": json.RawMessage(`{"max_attribute":88}`),
+			"races":       json.RawMessage(`{"class_restrictions":{"Elf":["PS"]}}`),
+		}
+		require.NoError(t, database.DB.Save(&gs).Error)
+		t.Cleanup(func() {
+			gs.Rules = nil
+			database.DB.Save(&gs)
+		})
+
+		session := validCreationSession(1)
+		session.Rasse = "Elf"
+		session.Attributes.AU = 70
+		fields := violationFields(ValidateCreationSession(&session))
+		assert.Contains(t, fields, "attribute:St", "St 89 is above the game system's maximum")
+		assert.NotContains(t, fields, "race_class:typ", "the game system allows elven priests")
+		assert.Contains(t, fields, "attribute:Au", "limits of other races still apply")
+	})
+}
+
+func TestFinalizeCharacterCreationRejectsInvalidSession(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+
+	owner := ensureUserExists(t, 234)
+	session := validCreationSession(owner.UserID)
+	session.DerivedValues.Abwehr = 18
+	require.NoError(t, database.DB.Create(&session).Error)
+
+	ctx, w := buildJSONContext(t, http.MethodPost, nil, owner.UserID, map[string]string{"sessionId": session.ID})
+	FinalizeCharacterCreation(ctx)
+	require.Equal(t, http.StatusUnprocessableEntity, w.Code, w.Body.String())
+
+	var resp struct {
+		Violations []CreationViolation `json:"violations"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Len(t, resp.Violations, 1)
+	assert.Equal(t, "abwehr", resp.Violations[0].Field)
+	assert.Equal(t, "11", resp.Violations[0].Expected)
+
+	var count int64
+	database.DB.Model(&models.CharacterCreationSession{}).Where("id = ?", session.ID).Count(&count)
+	assert.Equal(t, int64(1), count, "session must be kept for correction")
+}
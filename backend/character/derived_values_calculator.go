@@ -2,6 +2,7 @@ package character
 
 import (
 	"bamort/logger"
+	"bamort/models"
 	"fmt"
 	"net/http"
 	"strings"
@@ -58,8 +59,10 @@ type CalculateRolledFieldRequest struct {
 	Typ   string `json:"typ" binding:"required"`
 	Field string `json:"field" binding:"required"` // pa, wk, lp_max, ap_max, b_max
 
-	// Würfelwerte vom Frontend
-	Roll interface{} `json:"roll" binding:"required"` // Je nach Feld: int für 1d100, []int für mehrere Würfel
+	// Würfelwerte vom Frontend oder Verweis auf einen serverseitigen Wurf
+	Roll      interface{} `json:"roll"`                 // Je nach Feld: int für 1d100, []int für mehrere Würfel
+	RollID    uint        `json:"roll_id,omitempty"`    // ID eines Wurfs aus /create-session/:sessionId/roll
+	SessionID string      `json:"session_id,omitempty"` // Session des Wurfs, Pflicht bei roll_id
 }
 
 // RolledFieldResponse für Felder mit Würfelwürfen
@@ -119,6 +122,22 @@ func CalculateStaticFieldsLogic(req CalculateStaticFieldsRequest) StaticFieldsRe
 	return response
 }
 
+// CalculateStaticFieldsForCharacter berechnet die statischen Werte aus den Eigenschaften eines Charakters
+func CalculateStaticFieldsForCharacter(char *models.Char) StaticFieldsResponse {
+	return CalculateStaticFieldsLogic(CalculateStaticFieldsRequest{
+		St:    char.GetAttributeValue("St"),
+		Gs:    char.GetAttributeValue("Gs"),
+		Gw:    char.GetAttributeValue("Gw"),
+		Ko:    char.GetAttributeValue("Ko"),
+		In:    char.GetAttributeValue("In"),
+		Zt:    char.GetAttributeValue("Zt"),
+		Au:    char.GetAttributeValue("Au"),
+		Rasse: char.Rasse,
+		Typ:   char.Typ,
+		Grad:  char.Grad,
+	})
+}
+
 // CalculateStaticFields berechnet alle Felder ohne Würfelwürfe (HTTP Handler)
 func CalculateStaticFields(c *gin.Context) {
 	var req CalculateStaticFieldsRequest
@@ -146,6 +165,17 @@ func CalculateRolledField(c *gin.Context) {
 
 	logger.Info("Berechne Würfelfeld %s für %s %s", req.Field, req.Rasse, req.Typ)
 
+	if req.RollID != 0 {
+		if err := applyServerRoll(&req, c.GetUint("userID")); err != nil {
+			logger.Error("Fehler beim Laden des Serverwurfs %d: %v", req.RollID, err)
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+	} else if req.Roll == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "roll oder roll_id erforderlich"})
+		return
+	}
+
 	response, err := calculateRolledField(req)
 	if err != nil {
 		logger.Error("Fehler beim Berechnen des Würfelfeldes %s: %v", req.Field, err)
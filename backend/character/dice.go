@@ -0,0 +1,186 @@
+package character
+
+import (
+	"bamort/database"
+	"bamort/models"
+	crand "crypto/rand"
+	"encoding/binary"
+	"fmt"
+	"math/rand"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	maxDiceCount = 100
+	maxDiceSides = 1000
+)
+
+// diceExpressionPattern erkennt Ausdrücke wie "1d100", "2d6+3", "4d3" oder "1d3 + 7"
+var diceExpressionPattern = regexp.MustCompile(`^(\d*)[dDwW](\d+)(?:([+-])(\d+))?$`)
+
+// DiceExpression beschreibt einen geparsten Würfelausdruck
+type DiceExpression struct {
+	Count    int `json:"count"`
+	Sides    int `json:"sides"`
+	Modifier int `json:"modifier"`
+}
+
+// DiceResult ist das Ergebnis eines Würfelwurfs
+type DiceResult struct {
+	Expression string `json:"expression"`
+	Rolls      []int  `json:"rolls"`
+	Modifier   int    `json:"modifier"`
+	Total      int    `json:"total"`
+	Seed       int64  `json:"seed"`
+}
+
+// String gibt den Ausdruck in Normalform zurück (z.B. "2d6+3")
+func (e DiceExpression) String() string {
+	expr := fmt.Sprintf("%dd%d", e.Count, e.Sides)
+	if e.Modifier > 0 {
+		expr += fmt.Sprintf("+%d", e.Modifier)
+	} else if e.Modifier < 0 {
+		expr += fmt.Sprintf("%d", e.Modifier)
+	}
+	return expr
+}
+
+// ParseDiceExpression parst einen Würfelausdruck der Form NdS[+/-M]
+func ParseDiceExpression(expression string) (DiceExpression, error) {
+	normalized := strings.ReplaceAll(strings.TrimSpace(expression), " ", "")
+	matches := diceExpressionPattern.FindStringSubmatch(normalized)
+	if matches == nil {
+		return DiceExpression{}, fmt.Errorf("ungültiger Würfelausdruck: %s", expression)
+	}
+
+	count := 1
+	if matches[1] != "" {
+		count, _ = strconv.Atoi(matches[1])
+	}
+	sides, _ := strconv.Atoi(matches[2])
+
+	modifier := 0
+	if matches[4] != "" {
+		modifier, _ = strconv.Atoi(matches[4])
+		if matches[3] == "-" {
+			modifier = -modifier
+		}
+	}
+
+	if count < 1 || count > maxDiceCount {
+		return DiceExpression{}, fmt.Errorf("anzahl der Würfel muss zwischen 1 und %d liegen", maxDiceCount)
+	}
+	if sides < 2 || sides > maxDiceSides {
+		return DiceExpression{}, fmt.Errorf("anzahl der Seiten muss zwischen 2 und %d liegen", maxDiceSides)
+	}
+
+	return DiceExpression{Count: count, Sides: sides, Modifier: modifier}, nil
+}
+
+// diceSeedSource liefert den Seed für neue Würfe (in Tests austauschbar)
+var diceSeedSource = newDiceSeed
+
+// newDiceSeed erzeugt einen zufälligen Seed aus einer kryptographischen Quelle
+func newDiceSeed() int64 {
+	var buf [8]byte
+	if _, err := crand.Read(buf[:]); err != nil {
+		return rand.Int63()
+	}
+	return int64(binary.LittleEndian.Uint64(buf[:]) &^ (1 << 63))
+}
+
+// rollDiceWithSeed würfelt einen Ausdruck deterministisch mit dem angegebenen Seed
+func rollDiceWithSeed(expr DiceExpression, seed int64) DiceResult {
+	rng := rand.New(rand.NewSource(seed))
+	rolls := make([]int, expr.Count)
+	total := expr.Modifier
+	for i := range rolls {
+		rolls[i] = rng.Intn(expr.Sides) + 1
+		total += rolls[i]
+	}
+
+	return DiceResult{
+		Expression: expr.String(),
+		Rolls:      rolls,
+		Modifier:   expr.Modifier,
+		Total:      total,
+		Seed:       seed,
+	}
+}
+
+// RollDice parst und würfelt einen Ausdruck mit einem neuen Seed
+func RollDice(expression string) (DiceResult, error) {
+	expr, err := ParseDiceExpression(expression)
+	if err != nil {
+		return DiceResult{}, err
+	}
+	return rollDiceWithSeed(expr, diceSeedSource()), nil
+}
+
+// VerifyDiceRoll prüft, ob ein gespeicherter Wurf mit seinem Seed reproduzierbar ist
+func VerifyDiceRoll(roll *models.DiceRoll) bool {
+	expr, err := ParseDiceExpression(roll.Expression)
+	if err != nil {
+		return false
+	}
+	replayed := rollDiceWithSeed(expr, roll.Seed)
+	if replayed.Total != roll.Total || len(replayed.Rolls) != len(roll.Rolls) {
+		return false
+	}
+	for i := range replayed.Rolls {
+		if replayed.Rolls[i] != roll.Rolls[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// rollAndRecord würfelt einen Ausdruck und speichert den Wurf in der Würfelhistorie
+func rollAndRecord(expression, purpose string, characterID uint, sessionID string, userID uint) (*models.DiceRoll, error) {
+	roll, err := newDiceRoll(expression, purpose, characterID, sessionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := database.DB.Create(roll).Error; err != nil {
+		return nil, fmt.Errorf("failed to save dice roll: %w", err)
+	}
+	return roll, nil
+}
+
+// newDiceRoll würfelt einen Ausdruck, ohne den Wurf zu speichern
+func newDiceRoll(expression, purpose string, characterID uint, sessionID string, userID uint) (*models.DiceRoll, error) {
+	result, err := RollDice(expression)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.DiceRoll{
+		CharacterID: characterID,
+		SessionID:   sessionID,
+		UserID:      userID,
+		Expression:  result.Expression,
+		Purpose:     purpose,
+		Rolls:       result.Rolls,
+		Modifier:    result.Modifier,
+		Total:       result.Total,
+		Seed:        result.Seed,
+	}, nil
+}
+
+// getCreationRollExpression liefert den Würfelausdruck für ein Feld der Charaktererstellung
+func getCreationRollExpression(field, rasse string) (string, error) {
+	switch strings.ToLower(field) {
+	case "st", "gs", "gw", "ko", "in", "zt", "au", "pa", "wk":
+		return "1d100", nil
+	case "lp_max", "ap_max":
+		return "1d3", nil
+	case "b_max":
+		_, formula := getMovementBaseAndFormula(rasse)
+		// Formel hat die Form "4d3 + 16", gewürfelt wird nur der Würfelanteil
+		return strings.TrimSpace(strings.Split(formula, "+")[0]), nil
+	default:
+		return "", fmt.Errorf("unbekanntes Würfelfeld: %s", field)
+	}
+}
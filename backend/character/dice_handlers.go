@@ -0,0 +1,202 @@
+package character
+
+import (
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// DiceRollRequest repräsentiert einen Würfelwurf für einen Charakter
+type DiceRollRequest struct {
+	Expression string `json:"expression" binding:"required"` // z.B. "1d100", "2d6+3"
+	Purpose    string `json:"purpose,omitempty"`             // wofür gewürfelt wurde
+}
+
+// CreationRollRequest repräsentiert einen Würfelwurf während der Charaktererstellung
+type CreationRollRequest struct {
+	Field string `json:"field" binding:"required"` // st..au, pa, wk, lp_max, ap_max, b_max
+}
+
+// RollForCharacter würfelt serverseitig für einen Charakter und speichert den Wurf
+func RollForCharacter(c *gin.Context) {
+	var character models.Char
+	if err := database.DB.First(&character, c.Param("id")).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return
+	}
+	if !checkCharacterOwnership(c, &character) {
+		return
+	}
+
+	var req DiceRollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	roll, err := rollAndRecord(req.Expression, req.Purpose, character.ID, "", c.GetUint("userID"))
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	logger.Info("Charakter %d würfelt %s (%s): %d", character.ID, roll.Expression, roll.Purpose, roll.Total)
+	c.JSON(http.StatusCreated, roll)
+}
+
+// loadReadableCharacter lädt einen Charakter, den der Benutzer einsehen darf
+func loadReadableCharacter(c *gin.Context) (*models.Char, bool) {
+	var character models.Char
+	if err := database.DB.First(&character, c.Param("id")).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return nil, false
+	}
+	if !CanReadCharacter(&character, c.GetUint("userID")) {
+		respondWithError(c, http.StatusForbidden, "You are not authorized to view this character")
+		return nil, false
+	}
+	return &character, true
+}
+
+// GetCharacterRolls gibt die Würfelhistorie eines Charakters zurück (neueste zuerst)
+func GetCharacterRolls(c *gin.Context) {
+	character, ok := loadReadableCharacter(c)
+	if !ok {
+		return
+	}
+
+	limit := 100
+	if limitParam := c.Query("limit"); limitParam != "" {
+		if parsed, err := strconv.Atoi(limitParam); err == nil && parsed > 0 {
+			limit = parsed
+		}
+	}
+
+	query := database.DB.Where("character_id = ?", character.ID)
+	if purpose := c.Query("purpose"); purpose != "" {
+		query = query.Where("purpose = ?", purpose)
+	}
+
+	var rolls []models.DiceRoll
+	if err := query.Order("timestamp DESC, id DESC").Limit(limit).Find(&rolls).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve dice rolls")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"character_id": character.ID,
+		"rolls":        rolls,
+	})
+}
+
+// VerifyCharacterRoll würfelt einen gespeicherten Wurf mit seinem Seed erneut und vergleicht das Ergebnis
+func VerifyCharacterRoll(c *gin.Context) {
+	character, ok := loadReadableCharacter(c)
+	if !ok {
+		return
+	}
+	var roll models.DiceRoll
+	if err := database.DB.Where("id = ? AND character_id = ?", c.Param("rollId"), character.ID).First(&roll).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Dice roll not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"roll":     roll,
+		"verified": VerifyDiceRoll(&roll),
+	})
+}
+
+// RollForCreationSession würfelt serverseitig ein Feld der Charaktererstellung
+// Die zurückgegebene Wurf-ID kann bei calculate-rolled-field als roll_id übergeben werden.
+// Je Session und Feld wird nur einmal gewürfelt, damit nicht bis zum Wunschergebnis neu gewürfelt wird.
+func RollForCreationSession(c *gin.Context) {
+	userID := c.GetUint("userID")
+	var session models.CharacterCreationSession
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("sessionId"), userID).First(&session).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	var req CreationRollRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	field := strings.ToLower(req.Field)
+	expression, err := getCreationRollExpression(field, session.Rasse)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var existing int64
+	database.DB.Model(&models.DiceRoll{}).Where("session_id = ? AND purpose = ?", session.ID, field).Count(&existing)
+	if existing > 0 {
+		respondWithError(c, http.StatusConflict, fmt.Sprintf("Für %s wurde in dieser Session bereits gewürfelt", field))
+		return
+	}
+
+	roll, err := newDiceRoll(expression, field, 0, session.ID, userID)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	// Der eindeutige Index auf dem Feld der Session lässt nur einen von gleichzeitigen Würfen zu
+	slot := session.ID + ":" + field
+	roll.CreationSlot = &slot
+	if err := database.DB.Create(roll).Error; err != nil {
+		var taken int64
+		database.DB.Model(&models.DiceRoll{}).Where("creation_slot = ?", slot).Count(&taken)
+		if taken > 0 {
+			respondWithError(c, http.StatusConflict, fmt.Sprintf("Für %s wurde in dieser Session bereits gewürfelt", field))
+			return
+		}
+		respondWithError(c, http.StatusInternalServerError, "Failed to save dice roll")
+		return
+	}
+
+	logger.Info("Session %s würfelt %s für %s: %d", session.ID, roll.Expression, field, roll.Total)
+	c.JSON(http.StatusCreated, roll)
+}
+
+// applyServerRoll ersetzt den Würfelwert der Anfrage durch einen gespeicherten Serverwurf
+// Der Wurf muss zur Session und zum Feld gehören und wird dabei verbraucht.
+func applyServerRoll(req *CalculateRolledFieldRequest, userID uint) error {
+	if req.SessionID == "" {
+		return fmt.Errorf("session_id ist bei roll_id erforderlich")
+	}
+	var roll models.DiceRoll
+	if err := database.DB.Where("id = ? AND user_id = ? AND session_id = ?", req.RollID, userID, req.SessionID).First(&roll).Error; err != nil {
+		return fmt.Errorf("würfelwurf %d nicht gefunden", req.RollID)
+	}
+	if !strings.EqualFold(roll.Purpose, req.Field) {
+		return fmt.Errorf("würfelwurf %d gehört zu Feld %s, nicht zu %s", roll.ID, roll.Purpose, req.Field)
+	}
+	result := database.DB.Model(&models.DiceRoll{}).Where("id = ? AND consumed_at IS NULL", roll.ID).Update("consumed_at", time.Now())
+	if result.Error != nil {
+		return fmt.Errorf("würfelwurf %d konnte nicht übernommen werden: %w", roll.ID, result.Error)
+	}
+	if result.RowsAffected == 0 {
+		return fmt.Errorf("würfelwurf %d wurde bereits verwendet", roll.ID)
+	}
+
+	if strings.EqualFold(req.Field, "b_max") {
+		rolls := make([]interface{}, len(roll.Rolls))
+		for i, r := range roll.Rolls {
+			rolls[i] = float64(r)
+		}
+		req.Roll = rolls
+	} else {
+		req.Roll = float64(roll.Total)
+	}
+	return nil
+}
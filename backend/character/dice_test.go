@@ -0,0 +1,198 @@
+package character
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseDiceExpression(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected DiceExpression
+	}{
+		{"1d100", DiceExpression{Count: 1, Sides: 100}},
+		{"2d6+3", DiceExpression{Count: 2, Sides: 6, Modifier: 3}},
+		{"4d3", DiceExpression{Count: 4, Sides: 3}},
+		{"1d3 + 7", DiceExpression{Count: 1, Sides: 3, Modifier: 7}},
+		{"d20-2", DiceExpression{Count: 1, Sides: 20, Modifier: -2}},
+		{"3W6", DiceExpression{Count: 3, Sides: 6}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.input, func(t *testing.T) {
+			expr, err := ParseDiceExpression(tc.input)
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, expr)
+		})
+	}
+
+	for _, invalid := range []string{"", "abc", "2d", "0d6", "1d1", "1000d6", "2d6*3"} {
+		_, err := ParseDiceExpression(invalid)
+		assert.Error(t, err, "expected error for %q", invalid)
+	}
+}
+
+func TestRollDiceWithSeedIsReproducible(t *testing.T) {
+	expr, err := ParseDiceExpression("4d3+16")
+	require.NoError(t, err)
+
+	first := rollDiceWithSeed(expr, 4711)
+	second := rollDiceWithSeed(expr, 4711)
+	assert.Equal(t, first, second)
+	assert.Len(t, first.Rolls, 4)
+
+	sum := 16
+	for _, r := range first.Rolls {
+		assert.GreaterOrEqual(t, r, 1)
+		assert.LessOrEqual(t, r, 3)
+		sum += r
+	}
+	assert.Equal(t, sum, first.Total)
+
+	roll := &models.DiceRoll{Expression: first.Expression, Rolls: first.Rolls, Total: first.Total, Seed: first.Seed}
+	assert.True(t, VerifyDiceRoll(roll))
+	roll.Total++
+	assert.False(t, VerifyDiceRoll(roll), "manipulated roll must not verify")
+}
+
+func TestGetCreationRollExpression(t *testing.T) {
+	expr, err := getCreationRollExpression("b_max", "Zwerg")
+	require.NoError(t, err)
+	assert.Equal(t, "3d3", expr)
+
+	expr, err = getCreationRollExpression("PA", "Mensch")
+	require.NoError(t, err)
+	assert.Equal(t, "1d100", expr)
+
+	_, err = getCreationRollExpression("foo", "Mensch")
+	assert.Error(t, err)
+}
+
+func TestDiceHandlers(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+
+	require.NoError(t, models.MigrateStructure())
+
+	owner := ensureUserExists(t, 202)
+	char := createCharacterOwnedBy(t, owner.UserID)
+	params := map[string]string{"id": fmt.Sprint(char.ID)}
+
+	t.Run("RollForCharacter stores roll in history", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"expression": "2d6+3", "purpose": "Schaden"}, owner.UserID, params)
+
+		RollForCharacter(ctx)
+
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var roll models.DiceRoll
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &roll))
+		assert.Equal(t, "2d6+3", roll.Expression)
+		assert.Len(t, roll.Rolls, 2)
+		assert.Equal(t, owner.UserID, roll.UserID)
+
+		historyCtx, historyW := buildJSONContext(t, http.MethodGet, nil, owner.UserID, params)
+		GetCharacterRolls(historyCtx)
+		require.Equal(t, http.StatusOK, historyW.Code)
+		var history struct {
+			Rolls []models.DiceRoll `json:"rolls"`
+		}
+		require.NoError(t, json.Unmarshal(historyW.Body.Bytes(), &history))
+		require.Len(t, history.Rolls, 1)
+		assert.Equal(t, roll.Total, history.Rolls[0].Total)
+		assert.True(t, VerifyDiceRoll(&history.Rolls[0]))
+	})
+
+	t.Run("roll history requires read access", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodGet, nil, owner.UserID+1, params)
+		GetCharacterRolls(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		ctx, w = buildJSONContext(t, http.MethodGet, nil, owner.UserID+1, map[string]string{"id": params["id"], "rollId": "1"})
+		VerifyCharacterRoll(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("RollForCharacter rejects invalid expression", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"expression": "viele"}, owner.UserID, params)
+
+		RollForCharacter(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("RollForCharacter blocks non-owner", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"expression": "1d100"}, owner.UserID+1, params)
+
+		RollForCharacter(ctx)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("creation uses server roll instead of client value", func(t *testing.T) {
+		session := models.CharacterCreationSession{
+			ID:        "dice-test-session",
+			UserID:    owner.UserID,
+			Rasse:     "Zwerg",
+			ExpiresAt: time.Now().Add(time.Hour),
+		}
+		require.NoError(t, database.DB.Create(&session).Error)
+
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"field": "b_max"}, owner.UserID, map[string]string{"sessionId": session.ID})
+		RollForCreationSession(ctx)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var roll models.DiceRoll
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &roll))
+		assert.Equal(t, "3d3", roll.Expression)
+		assert.Equal(t, session.ID, roll.SessionID)
+
+		ctx, w = buildJSONContext(t, http.MethodPost, map[string]any{"field": "b_max"}, owner.UserID, map[string]string{"sessionId": session.ID})
+		RollForCreationSession(ctx)
+		assert.Equal(t, http.StatusConflict, w.Code, "each field is rolled only once per session")
+
+		// Gleichzeitige Anfragen kommen an der Vorprüfung vorbei, der eindeutige Index lässt nur einen Wurf zu
+		slot := session.ID + ":pa"
+		require.NoError(t, database.DB.Create(&models.DiceRoll{UserID: owner.UserID, Expression: "1d100", Total: 99, CreationSlot: &slot}).Error)
+		ctx, w = buildJSONContext(t, http.MethodPost, map[string]any{"field": "pa"}, owner.UserID, map[string]string{"sessionId": session.ID})
+		RollForCreationSession(ctx)
+		assert.Equal(t, http.StatusConflict, w.Code, w.Body.String())
+		var slotted int64
+		database.DB.Model(&models.DiceRoll{}).Where("creation_slot = ?", slot).Count(&slotted)
+		assert.EqualValues(t, 1, slotted)
+
+		req := CalculateRolledFieldRequest{St: 50, Gs: 50, Gw: 50, Ko: 50, In: 50, Zt: 50, Au: 50, Rasse: "Zwerg", Typ: "Krieger", Field: "b_max", Roll: []interface{}{3.0, 3.0, 3.0}, RollID: roll.ID}
+		otherCtx, otherW := buildJSONContext(t, http.MethodPost, req, owner.UserID, nil)
+		CalculateRolledField(otherCtx)
+		assert.Equal(t, http.StatusBadRequest, otherW.Code, "roll must be bound to its session")
+
+		req.SessionID = session.ID
+		calcCtx, calcW := buildJSONContext(t, http.MethodPost, req, owner.UserID, nil)
+		CalculateRolledField(calcCtx)
+		require.Equal(t, http.StatusOK, calcW.Code, calcW.Body.String())
+		var response RolledFieldResponse
+		require.NoError(t, json.Unmarshal(calcW.Body.Bytes(), &response))
+		assert.Equal(t, roll.Total+12, response.Value, "server roll must override client values")
+
+		againCtx, againW := buildJSONContext(t, http.MethodPost, req, owner.UserID, nil)
+		CalculateRolledField(againCtx)
+		assert.Equal(t, http.StatusBadRequest, againW.Code, "a roll is consumed after use")
+
+		req.Field = "pa"
+		wrongCtx, wrongW := buildJSONContext(t, http.MethodPost, req, owner.UserID, nil)
+		CalculateRolledField(wrongCtx)
+		assert.Equal(t, http.StatusBadRequest, wrongW.Code, "roll for b_max must not be usable for pa")
+	})
+}
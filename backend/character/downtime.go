@@ -0,0 +1,129 @@
+package character
+
+import (
+	"bamort/database"
+	"bamort/gsmaster"
+	"bamort/models"
+	"fmt"
+
+	"gorm.io/gorm"
+)
+
+// TrainingTimeRules legt fest, wie viele Tage Lernen und Verbessern dauern
+// Praxispunkte ersetzen Training, daher zählen nur die nach Abzug der PP verbleibenden TE/LE.
+type TrainingTimeRules struct {
+	DaysPerTE             int  `json:"days_per_te"`             // Verbessern einer Fertigkeit je TE
+	DaysPerLE             int  `json:"days_per_le"`             // Lernen einer Fertigkeit je LE
+	DaysPerSpellLE        int  `json:"days_per_spell_le"`       // Lernen eines Zaubers je LE
+	DaysPerSpecialization int  `json:"days_per_specialization"` // Lernen oder Wechseln einer Waffenspezialisierung
+	RequireDowntime       bool `json:"require_downtime"`        // Lernzeit immer gegen das Konto buchen, nicht nur auf Wunsch
+}
+
+// defaultTrainingTimeRules sind die Lernzeiten des M5-Systems
+var defaultTrainingTimeRules = TrainingTimeRules{
+	DaysPerTE:             1,
+	DaysPerLE:             7,
+	DaysPerSpellLE:        7,
+	DaysPerSpecialization: 7,
+}
+
+// getTrainingTimeRules liefert die Lernzeiten für das Spielsystem eines Charakters
+func getTrainingTimeRules(char *models.Char) TrainingTimeRules {
+	return models.GameSystemRules(models.GetGameSystem(char.GameSystemId, char.GameSystem), "training_time", defaultTrainingTimeRules)
+}
+
+// downtimeTracked prüft, ob eine Lernaktion gegen das Ausfallzeit-Konto geprüft und dort gebucht wird
+// Das geschieht auf Wunsch der Anfrage oder immer, wenn das Spielsystem es verlangt.
+func downtimeTracked(rules TrainingTimeRules, requested bool) bool {
+	return requested || rules.RequireDowntime
+}
+
+// DowntimeShortageError meldet, dass das Ausfallzeit-Konto für eine Lernzeit nicht reicht
+type DowntimeShortageError struct {
+	Needed    int
+	Available int
+}
+
+func (e *DowntimeShortageError) Error() string {
+	return fmt.Sprintf("Nicht genügend Zeit vorhanden: %d Tage benötigt, %d Tage verfügbar", e.Needed, e.Available)
+}
+
+// trainingDays berechnet die Lernzeit einer Lernaktion aus den Kosten je Stufe
+// Beim Lernen einer Fertigkeit zählt die erste Stufe in LE, weitere Stufen wie Verbesserungen in TE.
+func trainingDays(rules TrainingTimeRules, skillType, action string, costs []gsmaster.SkillCostResultNew) int {
+	days := 0
+	for _, cost := range costs {
+		switch {
+		case skillType == "spell":
+			days += cost.LE * rules.DaysPerSpellLE
+		case action == "learn" && cost.TargetLevel <= 1:
+			days += cost.LE * rules.DaysPerLE
+		default:
+			days += cost.LE * rules.DaysPerTE
+		}
+	}
+	return days
+}
+
+// GetDowntimeBalance liefert den Kontostand des Ausfallzeit-Kontos in Tagen
+func GetDowntimeBalance(db *gorm.DB, characterID uint) (int, error) {
+	var balance int
+	err := db.Model(&models.DowntimeEntry{}).
+		Where("character_id = ?", characterID).
+		Select("COALESCE(SUM(days), 0)").
+		Scan(&balance).Error
+	return balance, err
+}
+
+// GetDowntimeLedger liefert die Buchungen eines Charakters, die neuesten zuerst
+func GetDowntimeLedger(characterID uint, kind string) ([]models.DowntimeEntry, error) {
+	query := database.DB.Where("character_id = ?", characterID)
+	if kind != "" {
+		query = query.Where("kind = ?", kind)
+	}
+	var entries []models.DowntimeEntry
+	err := query.Order("created_at DESC, id DESC").Find(&entries).Error
+	return entries, err
+}
+
+// checkDowntimeTx prüft, ob das Ausfallzeit-Konto für days Tage reicht
+// Reicht es nicht, wird ein *DowntimeShortageError zurückgegeben.
+func checkDowntimeTx(tx *gorm.DB, characterID uint, days int) error {
+	if days <= 0 {
+		return nil
+	}
+	balance, err := GetDowntimeBalance(tx, characterID)
+	if err != nil {
+		return err
+	}
+	if balance < days {
+		return &DowntimeShortageError{Needed: days, Available: balance}
+	}
+	return nil
+}
+
+// checkDowntime prüft, ob das Ausfallzeit-Konto für die Lernzeit reicht
+func checkDowntime(characterID uint, days int) error {
+	return checkDowntimeTx(database.DB, characterID, days)
+}
+
+// RecordTrainingTimeTx bucht die Lernzeit einer Lernaktion im Ausfallzeit-Konto
+// Der Kontostand wird in derselben Transaktion geprüft und kann nicht negativ werden.
+func RecordTrainingTimeTx(tx *gorm.DB, characterID uint, activity string, days int, reason AuditLogReason, userID uint, notes string) error {
+	if days <= 0 {
+		return nil
+	}
+	if err := checkDowntimeTx(tx, characterID, days); err != nil {
+		return err
+	}
+	entry := models.DowntimeEntry{
+		CharacterID: characterID,
+		UserID:      userID,
+		Kind:        models.DowntimeTraining,
+		Days:        -days,
+		Activity:    activity,
+		Reason:      string(reason),
+		Notes:       notes,
+	}
+	return tx.Create(&entry).Error
+}
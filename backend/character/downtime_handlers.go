@@ -0,0 +1,126 @@
+package character
+
+import (
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// AddDowntimeRequest schreibt dem Ausfallzeit-Konto Tage gut oder korrigiert es
+type AddDowntimeRequest struct {
+	Days  int    `json:"days" binding:"required"`
+	Kind  string `json:"kind,omitempty" binding:"omitempty,oneof=grant correction"` // Standard: grant
+	Notes string `json:"notes,omitempty"`
+}
+
+// DowntimeResponse enthält Kontostand, Lernzeiten und Buchungen eines Charakters
+type DowntimeResponse struct {
+	CharacterID uint                   `json:"character_id"`
+	Balance     int                    `json:"balance"`
+	Rules       TrainingTimeRules      `json:"rules"`
+	Entries     []models.DowntimeEntry `json:"entries"`
+}
+
+// buildDowntimeResponse lädt Kontostand und Buchungen (optional nach Art gefiltert)
+func buildDowntimeResponse(char *models.Char, kind string) (DowntimeResponse, error) {
+	response := DowntimeResponse{CharacterID: char.ID, Rules: getTrainingTimeRules(char)}
+	balance, err := GetDowntimeBalance(database.DB, char.ID)
+	if err != nil {
+		return response, err
+	}
+	entries, err := GetDowntimeLedger(char.ID, kind)
+	if err != nil {
+		return response, err
+	}
+	response.Balance = balance
+	response.Entries = entries
+	return response, nil
+}
+
+// respondDowntimeTxError antwortet auf einen Fehler aus einer Transaktion, die das Ausfallzeit-Konto belastet
+// Reicht das Konto nicht, ist das ein Fehler der Anfrage, sonst ein Serverfehler mit message.
+func respondDowntimeTxError(c *gin.Context, err error, message string) {
+	var shortage *DowntimeShortageError
+	if errors.As(err, &shortage) {
+		respondWithError(c, http.StatusBadRequest, shortage.Error())
+		return
+	}
+	respondWithError(c, http.StatusInternalServerError, message)
+}
+
+// GetCharacterDowntime gibt das Ausfallzeit-Konto eines Charakters zurück (?kind=grant|training|correction)
+func GetCharacterDowntime(c *gin.Context) {
+	var char models.Char
+	if err := char.FirstID(c.Param("id")); err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return
+	}
+	if !CanReadCharacter(&char, c.GetUint("userID")) {
+		respondWithError(c, http.StatusForbidden, "You are not authorized to view this character")
+		return
+	}
+
+	response, err := buildDowntimeResponse(&char, c.Query("kind"))
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve downtime")
+		return
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// AddCharacterDowntime bucht freie Zeit oder eine Korrektur im Ausfallzeit-Konto
+// Nur Korrekturen dürfen negativ sein, der Kontostand selbst nie.
+func AddCharacterDowntime(c *gin.Context) {
+	var char models.Char
+	if err := char.FirstID(c.Param("id")); err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return
+	}
+	if !checkCharacterOwnership(c, &char) {
+		return
+	}
+
+	var req AddDowntimeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, "Ungültige Anfrageparameter: "+err.Error())
+		return
+	}
+	if req.Kind == "" {
+		req.Kind = models.DowntimeGrant
+	}
+	if req.Kind == models.DowntimeGrant && req.Days < 0 {
+		respondWithError(c, http.StatusBadRequest, "Gutschriften müssen positiv sein, für Abzüge bitte eine Korrektur buchen")
+		return
+	}
+
+	entry := models.DowntimeEntry{
+		CharacterID: char.ID,
+		UserID:      c.GetUint("userID"),
+		Kind:        req.Kind,
+		Days:        req.Days,
+		Notes:       req.Notes,
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := checkDowntimeTx(tx, char.ID, -req.Days); err != nil {
+			return err
+		}
+		return tx.Create(&entry).Error
+	})
+	if err != nil {
+		respondDowntimeTxError(c, err, "Failed to save downtime")
+		return
+	}
+	logger.Info("Charakter %d: %d Tage Ausfallzeit gebucht (%s)", char.ID, req.Days, req.Kind)
+
+	response, err := buildDowntimeResponse(&char, "")
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve downtime")
+		return
+	}
+	c.JSON(http.StatusCreated, response)
+}
@@ -0,0 +1,159 @@
+package character
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestDowntimeLedger(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+	require.NoError(t, database.DB.Create(&models.GameSystem{Code: "M5", Name: "midgard", IsActive: true}).Error)
+	seedImprovementCosts(t, map[int]int{12: 2, 13: 3, 14: 4, 15: 5})
+
+	owner := ensureUserExists(t, 249)
+	stranger := ensureUserExists(t, 250)
+	char := createCharacterOwnedBy(t, owner.UserID)
+	seedExperience(t, char, 500)
+	seedWealth(t, char, 1000, 0, 0)
+	seedSkill(t, char, "Athletik", 11, 0)
+	params := map[string]string{"id": fmt.Sprint(char.ID)}
+	rules := defaultTrainingTimeRules
+
+	addDowntime := func(userID uint, body map[string]any) int {
+		ctx, w := buildJSONContext(t, http.MethodPost, body, userID, params)
+		AddCharacterDowntime(ctx)
+		return w.Code
+	}
+	improveTo := func(level int, checkDowntime bool) (int, map[string]any) {
+		body := map[string]any{"char_id": char.ID, "name": "Athletik", "type": "skill", "action": "improve", "target_level": level, "reward": "default", "check_downtime": checkDowntime}
+		ctx, w := buildJSONContext(t, http.MethodPost, body, owner.UserID, nil)
+		ImproveSkill(ctx)
+		var response map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return w.Code, response
+	}
+	improve := func() (int, map[string]any) { return improveTo(13, true) }
+
+	t.Run("grants and corrections", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, addDowntime(stranger.UserID, map[string]any{"days": 3}))
+		assert.Equal(t, http.StatusBadRequest, addDowntime(owner.UserID, map[string]any{"days": -3}))
+		assert.Equal(t, http.StatusCreated, addDowntime(owner.UserID, map[string]any{"days": 5, "notes": "Winter in Corrinis"}))
+		assert.Equal(t, http.StatusCreated, addDowntime(owner.UserID, map[string]any{"days": -2, "kind": "correction"}))
+		assert.Equal(t, http.StatusBadRequest, addDowntime(owner.UserID, map[string]any{"days": -4, "kind": "correction"}), "der Kontostand darf nicht negativ werden")
+
+		balance, err := GetDowntimeBalance(database.DB, char.ID)
+		require.NoError(t, err)
+		assert.Equal(t, 3, balance)
+	})
+
+	t.Run("learning validates and books training time", func(t *testing.T) {
+		// 12 und 13 kosten zusammen 5 TE, das Konto hat nur 3 Tage
+		code, _ := improve()
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, 500, reloadCharacterWithPreloads(t, char.ID).Erfahrungsschatz.EP)
+
+		require.Equal(t, http.StatusCreated, addDowntime(owner.UserID, map[string]any{"days": 7}))
+		code, response := improve()
+		require.Equal(t, http.StatusOK, code, response)
+		assert.EqualValues(t, 5*rules.DaysPerTE, response["training_days"])
+
+		entries, err := GetDowntimeLedger(char.ID, models.DowntimeTraining)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, -5*rules.DaysPerTE, entries[0].Days)
+		assert.Equal(t, "Athletik", entries[0].Activity)
+		assert.Equal(t, string(ReasonSkillImprovement), entries[0].Reason)
+	})
+
+	t.Run("ledger alongside the audit log", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodGet, nil, owner.UserID, params)
+		ctx.Request.URL.RawQuery = "include=downtime"
+		GetCharacterAuditLog(ctx)
+		require.Equal(t, http.StatusOK, w.Code)
+		var response struct {
+			Entries         []models.AuditLogEntry `json:"entries"`
+			Downtime        []models.DowntimeEntry `json:"downtime"`
+			DowntimeBalance int                    `json:"downtime_balance"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.NotEmpty(t, response.Entries)
+		assert.Len(t, response.Downtime, 4)
+		assert.Equal(t, 10-5*rules.DaysPerTE, response.DowntimeBalance)
+
+		ctx, w = buildJSONContext(t, http.MethodGet, nil, stranger.UserID, params)
+		GetCharacterDowntime(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("planner reports training days", func(t *testing.T) {
+		loaded, err := loadCharacterForImprovement(char.ID)
+		require.NoError(t, err)
+		simulation := SimulateAdvancementPlan(loaded, []models.AdvancementPlanStep{{Action: "improve", Type: "skill", Name: "Athletik", TargetLevel: 15}})
+		require.True(t, simulation.Valid)
+		assert.Equal(t, 9*rules.DaysPerTE, simulation.Steps[0].TrainingDays)
+		assert.Equal(t, 9*rules.DaysPerTE, simulation.TotalDays)
+	})
+
+	t.Run("game system can require training time", func(t *testing.T) {
+		// ohne Prüfung wird keine Lernzeit gebucht
+		code, response := improveTo(14, false)
+		require.Equal(t, http.StatusOK, code, response)
+		entries, err := GetDowntimeLedger(char.ID, models.DowntimeTraining)
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+
+		// verlangt das Spielsystem die Buchung, reichen 4 Tage nicht für Wert 15 (5 TE)
+		require.Equal(t, http.StatusCreated, addDowntime(owner.UserID, map[string]any{"days": -1, "kind": "correction"}))
+		var gs models.GameSystem
+		require.NoError(t, gs.FirstByCode("M5"))
+		gs.Rules = map[string]json.RawMessage{"training_time": json.RawMessage(`{"require_downtime":true}`)}
+		require.NoError(t, database.DB.Save(&gs).Error)
+		code, response = improveTo(15, false)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Contains(t, response["error"], "Nicht genügend Zeit")
+		var skill models.SkFertigkeit
+		require.NoError(t, database.DB.Where("character_id = ? AND name = ?", char.ID, "Athletik").First(&skill).Error)
+		assert.Equal(t, 14, skill.Fertigkeitswert)
+	})
+
+	t.Run("failed booking rolls back the learning", func(t *testing.T) {
+		require.Equal(t, http.StatusCreated, addDowntime(owner.UserID, map[string]any{"days": 20}))
+		failBooking := func(db *gorm.DB) {
+			if _, ok := db.Statement.Dest.(*models.DowntimeEntry); ok {
+				db.AddError(errors.New("buchung fehlgeschlagen"))
+			}
+		}
+		require.NoError(t, database.DB.Callback().Create().Before("gorm:create").Register("test:fail_downtime", failBooking))
+		t.Cleanup(func() { _ = database.DB.Callback().Create().Remove("test:fail_downtime") })
+		epBefore := reloadCharacterWithPreloads(t, char.ID).Erfahrungsschatz.EP
+		auditBefore, err := GetAuditLogForCharacter(char.ID)
+		require.NoError(t, err)
+
+		// schlägt die Buchung der Lernzeit fehl, bleiben EP, Wert und Audit-Log unverändert
+		code, _ := improveTo(15, true)
+		assert.Equal(t, http.StatusInternalServerError, code)
+		assert.Equal(t, epBefore, reloadCharacterWithPreloads(t, char.ID).Erfahrungsschatz.EP)
+		var skill models.SkFertigkeit
+		require.NoError(t, database.DB.Where("character_id = ? AND name = ?", char.ID, "Athletik").First(&skill).Error)
+		assert.Equal(t, 14, skill.Fertigkeitswert)
+		auditAfter, err := GetAuditLogForCharacter(char.ID)
+		require.NoError(t, err)
+		assert.Len(t, auditAfter, len(auditBefore))
+	})
+}
@@ -90,6 +90,12 @@ func CreateCharacter(c *gin.Context) {
 		respondWithError(c, http.StatusBadRequest, err.Error())
 		return
 	}
+	// Kampagne, Spielkalender, Bild und Spezialisierung werden nur über ihre eigenen Endpunkte gesetzt
+	character.CampaignID = 0
+	character.CalendarID = 0
+	character.BirthDay = nil
+	character.Image = ""
+	character.Spezialisierung = nil
 
 	if err := database.DB.Create(&character).Error; err != nil {
 		respondWithError(c, http.StatusInternalServerError, "Failed to create character")
@@ -129,6 +135,15 @@ func UpdateCharacter(c *gin.Context) {
 	originalID := character.ID
 	originalGameSystem := character.GameSystem
 	originalGameSystemId := character.GameSystemId
+	// Kampagne, Spielkalender, Bild und Spezialisierung haben eigene Endpunkte mit eigenen Prüfungen
+	originalCampaignID := character.CampaignID
+	originalCalendarID := character.CalendarID
+	originalBirthDay := character.BirthDay
+	originalImage := character.Image
+	originalSpezialisierung := character.Spezialisierung
+	// ShouldBindJSON schreibt in vorhandene Zeiger und Slices, daher werden sie vor dem Binden gelöst
+	character.BirthDay = nil
+	character.Spezialisierung = nil
 
 	// Bind the updated data
 	if err := c.ShouldBindJSON(&character); err != nil {
@@ -140,6 +155,11 @@ func UpdateCharacter(c *gin.Context) {
 	character.ID = originalID
 	character.GameSystem = originalGameSystem
 	character.GameSystemId = originalGameSystemId
+	character.CampaignID = originalCampaignID
+	character.CalendarID = originalCalendarID
+	character.BirthDay = originalBirthDay
+	character.Image = originalImage
+	character.Spezialisierung = originalSpezialisierung
 
 	// Update all associations
 	if err := database.DB.Session(&gorm.Session{FullSaveAssociations: true}).Save(&character).Error; err != nil {
@@ -513,12 +533,12 @@ func getValueOrDefault(value *int, defaultValue int) int {
 }
 
 // updateOrCreateSkill aktualisiert eine vorhandene Fertigkeit oder erstellt eine neue
-func updateOrCreateSkill(character *models.Char, skillName string, newLevel int) error {
+func updateOrCreateSkill(tx *gorm.DB, character *models.Char, skillName string, newLevel int) error {
 	// Suche erst in normalen Fertigkeiten
 	for i := range character.Fertigkeiten {
 		if character.Fertigkeiten[i].Name == skillName {
 			character.Fertigkeiten[i].Fertigkeitswert = newLevel
-			return database.DB.Save(&character.Fertigkeiten[i]).Error
+			return tx.Save(&character.Fertigkeiten[i]).Error
 		}
 	}
 
@@ -526,7 +546,7 @@ func updateOrCreateSkill(character *models.Char, skillName string, newLevel int)
 	for i := range character.Waffenfertigkeiten {
 		if character.Waffenfertigkeiten[i].Name == skillName {
 			character.Waffenfertigkeiten[i].Fertigkeitswert = newLevel
-			return database.DB.Save(&character.Waffenfertigkeiten[i]).Error
+			return tx.Save(&character.Waffenfertigkeiten[i]).Error
 		}
 	}
 
@@ -542,7 +562,7 @@ func updateOrCreateSkill(character *models.Char, skillName string, newLevel int)
 		Improvable:      true,
 	}
 
-	if err := database.DB.Create(&newSkill).Error; err != nil {
+	if err := tx.Create(&newSkill).Error; err != nil {
 		return err
 	}
 
@@ -552,7 +572,7 @@ func updateOrCreateSkill(character *models.Char, skillName string, newLevel int)
 }
 
 // addSpellToCharacter fügt einen neuen Zauber zum Charakter hinzu
-func addSpellToCharacter(character *models.Char, spellName string) error {
+func addSpellToCharacter(tx *gorm.DB, character *models.Char, spellName string) error {
 	// Prüfe, ob Zauber bereits existiert
 	for _, spell := range character.Zauber {
 		if spell.Name == spellName {
@@ -571,7 +591,7 @@ func addSpellToCharacter(character *models.Char, spellName string) error {
 		},
 	}
 
-	if err := database.DB.Create(&newSpell).Error; err != nil {
+	if err := tx.Create(&newSpell).Error; err != nil {
 		return err
 	}
 
@@ -661,22 +681,38 @@ func LearnSkill(c *gin.Context) {
 		return
 	}
 
-	// 5. Ressourcen abziehen
-	newEP, newGold, err := deductResourcesForLearning(char, request.Name, finalLevel, totalEP, totalGold, totalPP)
-	if err != nil {
-		respondWithError(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	// 6. Skill hinzufügen/erstellen
-	if err := updateOrCreateSkill(char, request.Name, finalLevel); err != nil {
-		respondWithError(c, http.StatusInternalServerError, "Fehler beim Hinzufügen der Fertigkeit: "+err.Error())
-		return
+	// 4a. Lernzeit berechnen und auf Wunsch gegen das Ausfallzeit-Konto prüfen
+	trainingRules := getTrainingTimeRules(char)
+	days := trainingDays(trainingRules, request.Type, request.Action, response)
+	trackDowntime := downtimeTracked(trainingRules, request.CheckDowntime)
+	if trackDowntime {
+		if err := checkDowntime(char.ID, days); err != nil {
+			respondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
 	}
 
-	// 7. Charakter speichern
-	if err := database.DB.Save(char).Error; err != nil {
-		respondWithError(c, http.StatusInternalServerError, "Fehler beim Speichern des Charakters")
+	// 5.-7. Ressourcen abziehen, Skill hinzufügen, Lernzeit buchen und Charakter speichern
+	// Alles in einer Transaktion, damit bei einem Fehler weder EP noch Gold verloren gehen.
+	var newEP, newGold int
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if newEP, newGold, err = deductResourcesForLearning(tx, char, request.Name, finalLevel, totalEP, totalGold, totalPP); err != nil {
+			return err
+		}
+		if err := updateOrCreateSkill(tx, char, request.Name, finalLevel); err != nil {
+			return fmt.Errorf("fehler beim Hinzufügen der Fertigkeit: %w", err)
+		}
+		if trackDowntime {
+			notes := fmt.Sprintf("Fertigkeit '%s' gelernt", request.Name)
+			if err := RecordTrainingTimeTx(tx, char.ID, request.Name, days, ReasonSkillLearning, c.GetUint("userID"), notes); err != nil {
+				return err
+			}
+		}
+		return tx.Save(char).Error
+	})
+	if err != nil {
+		respondDowntimeTxError(c, err, "Fehler beim Lernen der Fertigkeit: "+err.Error())
 		return
 	}
 
@@ -689,6 +725,7 @@ func LearnSkill(c *gin.Context) {
 		"gold_cost":      totalGold,
 		"remaining_ep":   newEP,
 		"remaining_gold": newGold,
+		"training_days":  days,
 		"cost_details":   response,
 	}
 
@@ -721,10 +758,13 @@ func validateSkillForLearning(char *models.Char, request *gsmaster.LernCostReque
 	// Normalize skill/spell name (trim whitespace, proper case)
 	skillName := strings.TrimSpace(request.Name)
 
-	skillInfo, err := models.GetSkillCategoryAndDifficultyNewSystem(skillName, characterClass)
+	skillInfo, err := models.GetSkillCategoryAndDifficultyNewSystem(skillName, characterClass, models.CharacterContentScope(char))
 	if err != nil {
 		return "", nil, 0, fmt.Errorf("fertigkeit '%s' nicht gefunden oder nicht für Klasse '%s' verfügbar: %v", skillName, characterClass, err)
 	}
+	if learnable, err := skillLearnable(char, skillInfo.SkillID); err != nil || !learnable {
+		return "", nil, 0, fmt.Errorf("fertigkeit '%s' ist in der Quellenauswahl dieses Charakters nicht verfügbar", skillName)
+	}
 
 	// Für Learning starten wir bei Level 0
 	currentLevel := 0
@@ -800,12 +840,12 @@ func calculateLearningCosts(char *models.Char, request *gsmaster.LernCostRequest
 }
 
 // deductResourcesForLearning zieht die Ressourcen für das Lernen ab und erstellt Audit-Log-Einträge
-func deductResourcesForLearning(char *models.Char, skillName string, finalLevel, totalEP, totalGold, totalPP int) (int, int, error) {
-	return deductResourcesWithAuditReason(char, skillName, finalLevel, totalEP, totalGold, totalPP, ReasonSkillLearning)
+func deductResourcesForLearning(tx *gorm.DB, char *models.Char, skillName string, finalLevel, totalEP, totalGold, totalPP int) (int, int, error) {
+	return deductResourcesWithAuditReason(tx, char, skillName, finalLevel, totalEP, totalGold, totalPP, ReasonSkillLearning)
 }
 
 // deductResourcesWithAuditReason zieht EP, Gold und PP ab und erstellt entsprechende Audit-Log-Einträge
-func deductResourcesWithAuditReason(char *models.Char, itemName string, finalLevel, totalEP, totalGold, totalPP int, auditReason AuditLogReason) (int, int, error) {
+func deductResourcesWithAuditReason(tx *gorm.DB, char *models.Char, itemName string, finalLevel, totalEP, totalGold, totalPP int, auditReason AuditLogReason) (int, int, error) {
 	currentEP := char.Erfahrungsschatz.EP
 	currentGold := char.Vermoegen.Goldstuecke
 
@@ -821,12 +861,12 @@ func deductResourcesWithAuditReason(char *models.Char, itemName string, finalLev
 			notes = fmt.Sprintf("Fertigkeit '%s' gelernt", itemName)
 		}
 
-		err := CreateAuditLogEntry(char.ID, "experience_points", currentEP, newEP, auditReason, 0, notes)
+		err := CreateAuditLogEntryTx(tx, char.ID, "experience_points", currentEP, newEP, auditReason, 0, notes)
 		if err != nil {
 			return 0, 0, fmt.Errorf("fehler beim Erstellen des Audit-Log-Eintrags: %v", err)
 		}
 		char.Erfahrungsschatz.EP = newEP
-		if err := database.DB.Save(&char.Erfahrungsschatz).Error; err != nil {
+		if err := tx.Save(&char.Erfahrungsschatz).Error; err != nil {
 			return 0, 0, fmt.Errorf("fehler beim Speichern der Erfahrungspunkte: %v", err)
 		}
 	}
@@ -841,12 +881,12 @@ func deductResourcesWithAuditReason(char *models.Char, itemName string, finalLev
 			notes = fmt.Sprintf("Gold für Fertigkeit '%s' ausgegeben", itemName)
 		}
 
-		err := CreateAuditLogEntry(char.ID, "gold", currentGold, newGold, auditReason, 0, notes)
+		err := CreateAuditLogEntryTx(tx, char.ID, "gold", currentGold, newGold, auditReason, 0, notes)
 		if err != nil {
 			return 0, 0, fmt.Errorf("fehler beim Erstellen des Audit-Log-Eintrags: %v", err)
 		}
 		char.Vermoegen.Goldstuecke = newGold
-		if err := database.DB.Save(&char.Vermoegen).Error; err != nil {
+		if err := tx.Save(&char.Vermoegen).Error; err != nil {
 			return 0, 0, fmt.Errorf("fehler beim Speichern des Vermögens: %v", err)
 		}
 	}
@@ -857,7 +897,7 @@ func deductResourcesWithAuditReason(char *models.Char, itemName string, finalLev
 		for i := range char.Fertigkeiten {
 			if char.Fertigkeiten[i].Name == itemName {
 				char.Fertigkeiten[i].Pp -= totalPP
-				if err := database.DB.Save(&char.Fertigkeiten[i]).Error; err != nil {
+				if err := tx.Save(&char.Fertigkeiten[i]).Error; err != nil {
 					return 0, 0, fmt.Errorf("fehler beim Aktualisieren der Praxispunkte: %v", err)
 				}
 				break
@@ -867,7 +907,7 @@ func deductResourcesWithAuditReason(char *models.Char, itemName string, finalLev
 		for i := range char.Waffenfertigkeiten {
 			if char.Waffenfertigkeiten[i].Name == itemName {
 				char.Waffenfertigkeiten[i].Pp -= totalPP
-				if err := database.DB.Save(&char.Waffenfertigkeiten[i]).Error; err != nil {
+				if err := tx.Save(&char.Waffenfertigkeiten[i]).Error; err != nil {
 					return 0, 0, fmt.Errorf("fehler beim Aktualisieren der Praxispunkte: %v", err)
 				}
 				break
@@ -881,8 +921,13 @@ func deductResourcesWithAuditReason(char *models.Char, itemName string, finalLev
 // ImproveSkill verbessert eine bestehende Fertigkeit und erstellt Audit-Log-Einträge
 // loadCharacterForImprovement lädt einen Charakter mit allen benötigten Beziehungen
 func loadCharacterForImprovement(characterID uint) (*models.Char, error) {
+	return loadCharacterForImprovementTx(database.DB, characterID)
+}
+
+// loadCharacterForImprovementTx lädt den Charakter innerhalb einer Transaktion
+func loadCharacterForImprovementTx(tx *gorm.DB, characterID uint) (*models.Char, error) {
 	var char models.Char
-	err := database.DB.
+	err := tx.
 		Preload("Fertigkeiten").
 		Preload("Waffenfertigkeiten").
 		Preload("Erfahrungsschatz").
@@ -905,7 +950,7 @@ func validateSkillForImprovement(char *models.Char, request *gsmaster.LernCostRe
 	// Normalize skill/spell name (trim whitespace, proper case)
 	skillName := strings.TrimSpace(request.Name)
 
-	skillInfo, err := models.GetSkillCategoryAndDifficultyNewSystem(skillName, characterClass)
+	skillInfo, err := models.GetSkillCategoryAndDifficultyNewSystem(skillName, characterClass, models.CharacterContentScope(char))
 	if err != nil {
 		return "", nil, 0, fmt.Errorf("Fertigkeit '%s' nicht gefunden oder nicht für Klasse '%s' verfügbar: %v", skillName, characterClass, err)
 	}
@@ -1016,7 +1061,7 @@ func validateResources(char *models.Char, skillName string, totalEP, totalGold,
 
 // deductResources zieht die Kosten von den Charakterressourcen ab
 // TODO Fehlerbehandlung (Falls Tabelle nicht vorhanden ist)
-func deductResources(char *models.Char, skillName string, currentLevel, finalLevel, totalEP, totalGold, totalPP int) (int, int, error) {
+func deductResources(tx *gorm.DB, char *models.Char, skillName string, currentLevel, finalLevel, totalEP, totalGold, totalPP int) (int, int, error) {
 	currentEP := char.Erfahrungsschatz.EP
 	currentGold := char.Vermoegen.Goldstuecke
 
@@ -1032,12 +1077,12 @@ func deductResources(char *models.Char, skillName string, currentLevel, finalLev
 			notes = fmt.Sprintf("Fertigkeit '%s' von %d auf %d verbessert", skillName, currentLevel, finalLevel)
 		}
 
-		err := CreateAuditLogEntry(char.ID, "experience_points", currentEP, newEP, ReasonSkillImprovement, 0, notes)
+		err := CreateAuditLogEntryTx(tx, char.ID, "experience_points", currentEP, newEP, ReasonSkillImprovement, 0, notes)
 		if err != nil {
 			return newEP, 0, fmt.Errorf("Fehler beim Erstellen des Audit-Log-Eintrags: %v", err)
 		}
 		char.Erfahrungsschatz.EP = newEP
-		if err := database.DB.Save(&char.Erfahrungsschatz).Error; err != nil {
+		if err := tx.Save(&char.Erfahrungsschatz).Error; err != nil {
 			return newEP, 0, fmt.Errorf("Fehler beim Speichern der Erfahrungspunkte: %v", err)
 		}
 	}
@@ -1047,12 +1092,12 @@ func deductResources(char *models.Char, skillName string, currentLevel, finalLev
 	if totalGold > 0 {
 		notes := fmt.Sprintf("Gold für Verbesserung von '%s' ausgegeben", skillName)
 
-		err := CreateAuditLogEntry(char.ID, "gold", currentGold, newGold, ReasonSkillImprovement, 0, notes)
+		err := CreateAuditLogEntryTx(tx, char.ID, "gold", currentGold, newGold, ReasonSkillImprovement, 0, notes)
 		if err != nil {
 			return newEP, newGold, fmt.Errorf("Fehler beim Erstellen des Audit-Log-Eintrags: %v", err)
 		}
 		char.Vermoegen.Goldstuecke = newGold
-		if err := database.DB.Save(&char.Vermoegen).Error; err != nil {
+		if err := tx.Save(&char.Vermoegen).Error; err != nil {
 			return newEP, newGold, fmt.Errorf("Fehler beim Speichern des Vermögens: %v", err)
 		}
 	}
@@ -1063,7 +1108,7 @@ func deductResources(char *models.Char, skillName string, currentLevel, finalLev
 		for i := range char.Fertigkeiten {
 			if char.Fertigkeiten[i].Name == skillName {
 				char.Fertigkeiten[i].Pp -= totalPP
-				if err := database.DB.Save(&char.Fertigkeiten[i]).Error; err != nil {
+				if err := tx.Save(&char.Fertigkeiten[i]).Error; err != nil {
 					return newEP, newGold, fmt.Errorf("Fehler beim Aktualisieren der Praxispunkte: %v", err)
 				}
 				break
@@ -1073,7 +1118,7 @@ func deductResources(char *models.Char, skillName string, currentLevel, finalLev
 		for i := range char.Waffenfertigkeiten {
 			if char.Waffenfertigkeiten[i].Name == skillName {
 				char.Waffenfertigkeiten[i].Pp -= totalPP
-				if err := database.DB.Save(&char.Waffenfertigkeiten[i]).Error; err != nil {
+				if err := tx.Save(&char.Waffenfertigkeiten[i]).Error; err != nil {
 					return newEP, newGold, fmt.Errorf("Fehler beim Aktualisieren der Praxispunkte: %v", err)
 				}
 				break
@@ -1130,22 +1175,37 @@ func ImproveSkill(c *gin.Context) {
 		return
 	}
 
-	// 5. Ressourcen abziehen
-	newEP, newGold, err := deductResources(char, request.Name, currentLevel, finalLevel, totalEP, totalGold, totalPP)
-	if err != nil {
-		respondWithError(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	// 6. Skill-Level aktualisieren
-	if err := updateOrCreateSkill(char, request.Name, finalLevel); err != nil {
-		respondWithError(c, http.StatusInternalServerError, "Fehler beim Aktualisieren der Fertigkeit: "+err.Error())
-		return
+	// 4a. Lernzeit berechnen und auf Wunsch gegen das Ausfallzeit-Konto prüfen
+	trainingRules := getTrainingTimeRules(char)
+	days := trainingDays(trainingRules, request.Type, "improve", response)
+	trackDowntime := downtimeTracked(trainingRules, request.CheckDowntime)
+	if trackDowntime {
+		if err := checkDowntime(char.ID, days); err != nil {
+			respondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
 	}
 
-	// 7. Charakter speichern
-	if err := database.DB.Save(char).Error; err != nil {
-		respondWithError(c, http.StatusInternalServerError, "Fehler beim Speichern des Charakters")
+	// 5.-7. Ressourcen abziehen, Skill-Level aktualisieren, Lernzeit buchen und Charakter speichern
+	var newEP, newGold int
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if newEP, newGold, err = deductResources(tx, char, request.Name, currentLevel, finalLevel, totalEP, totalGold, totalPP); err != nil {
+			return err
+		}
+		if err := updateOrCreateSkill(tx, char, request.Name, finalLevel); err != nil {
+			return fmt.Errorf("fehler beim Aktualisieren der Fertigkeit: %w", err)
+		}
+		if trackDowntime {
+			notes := fmt.Sprintf("Fertigkeit '%s' von %d auf %d verbessert", request.Name, currentLevel, finalLevel)
+			if err := RecordTrainingTimeTx(tx, char.ID, request.Name, days, ReasonSkillImprovement, c.GetUint("userID"), notes); err != nil {
+				return err
+			}
+		}
+		return tx.Save(char).Error
+	})
+	if err != nil {
+		respondDowntimeTxError(c, err, "Fehler beim Verbessern der Fertigkeit: "+err.Error())
 		return
 	}
 
@@ -1159,6 +1219,7 @@ func ImproveSkill(c *gin.Context) {
 		"gold_cost":      totalGold,
 		"remaining_ep":   newEP,
 		"remaining_gold": newGold,
+		"training_days":  days,
 		"cost_details":   response,
 	}
 
@@ -1211,10 +1272,13 @@ func validateSpellForLearning(char *models.Char, request *gsmaster.LernCostReque
 		}
 	}
 
-	spellInfo, err := models.GetSpellLearningInfoNewSystem(spellName, characterClass)
+	spellInfo, err := models.GetSpellLearningInfoNewSystem(spellName, characterClass, models.CharacterContentScope(char))
 	if err != nil {
 		return "", nil, 0, fmt.Errorf("zauber '%s' nicht gefunden oder nicht für Klasse '%s' verfügbar: %v", spellName, characterClass, err)
 	}
+	if learnable, err := spellLearnable(char, spellInfo.SpellID); err != nil || !learnable {
+		return "", nil, 0, fmt.Errorf("zauber '%s' ist in der Quellenauswahl dieses Charakters nicht verfügbar", spellName)
+	}
 
 	// Für Learning starten wir bei Level 0
 	currentLevel := 0
@@ -1341,32 +1405,48 @@ func LearnSpell(c *gin.Context) {
 		return
 	}
 
-	// 5. Ressourcen abziehen
-	newEP, _, err := deductResourcesWithAuditReason(char, lernRequest.Name, 1, totalEP, 0, 0, ReasonSpellLearning)
-	if err != nil {
-		respondWithError(c, http.StatusInternalServerError, err.Error())
-		return
-	}
-
-	// 6. Zauber hinzufügen
-	if err := addSpellToCharacter(char, lernRequest.Name); err != nil {
-		respondWithError(c, http.StatusInternalServerError, "Fehler beim Hinzufügen des Zaubers: "+err.Error())
-		return
+	// 4a. Lernzeit berechnen und auf Wunsch gegen das Ausfallzeit-Konto prüfen
+	trainingRules := getTrainingTimeRules(char)
+	days := trainingDays(trainingRules, "spell", "learn", response)
+	trackDowntime := downtimeTracked(trainingRules, lernRequest.CheckDowntime)
+	if trackDowntime {
+		if err := checkDowntime(char.ID, days); err != nil {
+			respondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
 	}
 
-	// 7. Charakter speichern
-	if err := database.DB.Save(char).Error; err != nil {
-		respondWithError(c, http.StatusInternalServerError, "Fehler beim Speichern des Charakters")
+	// 5.-7. Ressourcen abziehen, Zauber hinzufügen, Lernzeit buchen und Charakter speichern
+	var newEP int
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if newEP, _, err = deductResourcesWithAuditReason(tx, char, lernRequest.Name, 1, totalEP, 0, 0, ReasonSpellLearning); err != nil {
+			return err
+		}
+		if err := addSpellToCharacter(tx, char, lernRequest.Name); err != nil {
+			return fmt.Errorf("fehler beim Hinzufügen des Zaubers: %w", err)
+		}
+		if trackDowntime {
+			notes := fmt.Sprintf("Zauber '%s' gelernt", lernRequest.Name)
+			if err := RecordTrainingTimeTx(tx, char.ID, lernRequest.Name, days, ReasonSpellLearning, c.GetUint("userID"), notes); err != nil {
+				return err
+			}
+		}
+		return tx.Save(char).Error
+	})
+	if err != nil {
+		respondDowntimeTxError(c, err, "Fehler beim Lernen des Zaubers: "+err.Error())
 		return
 	}
 
 	// 8. Response erstellen (kompatibel mit alter Version)
 	responseData := gin.H{
-		"message":      "Zauber erfolgreich gelernt",
-		"spell_name":   lernRequest.Name,
-		"ep_cost":      totalEP,
-		"remaining_ep": newEP,
-		"cost_details": response,
+		"message":       "Zauber erfolgreich gelernt",
+		"spell_name":    lernRequest.Name,
+		"ep_cost":       totalEP,
+		"remaining_ep":  newEP,
+		"training_days": days,
+		"cost_details":  response,
 	}
 
 	c.JSON(http.StatusOK, responseData)
@@ -1462,12 +1542,28 @@ func GetAvailableSkillsNewSystem(c *gin.Context) {
 		return
 	}
 
+	// Hausregeln und Quellenauswahl des Charakters; bei der Erschaffung die des Benutzers (?campaign_id=)
+	filter, ok := learnableFilter{}, false
+	if baseRequest.CharId != 0 {
+		filter, err = characterLearnableFilter(&character)
+		ok = err == nil
+		if !ok {
+			respondWithError(c, http.StatusInternalServerError, "Failed to determine active sources")
+		}
+	} else {
+		filter, ok = requestLearnableFilter(c)
+	}
+	if !ok {
+		return
+	}
+	contentScope := filter.scope
+
 	// Organisiere Fertigkeiten nach Kategorien
 	skillsByCategory := make(map[string][]gin.H)
 
 	for _, skill := range allSkills {
-		// Überspringe bereits gelernte Fertigkeiten
-		if learnedSkills[skill.Name] {
+		// Überspringe bereits gelernte und nicht angebotene Fertigkeiten
+		if learnedSkills[skill.Name] || !filter.allows(skill.SourceID, skill.HomebrewScope) {
 			continue
 		}
 
@@ -1502,7 +1598,7 @@ func GetAvailableSkillsNewSystem(c *gin.Context) {
 		remainingGold := request.UseGold
 
 		// Hole die vollständigen Skill-Informationen für die Kostenberechnung
-		skillLearningInfo, err := models.GetSkillCategoryAndDifficultyNewSystem(skill.Name, characterClass)
+		skillLearningInfo, err := models.GetSkillCategoryAndDifficultyNewSystem(skill.Name, characterClass, contentScope)
 		if err != nil {
 			// Fallback für unbekannte Skills
 			skillLearningInfo = &models.SkillLearningInfo{
@@ -1577,6 +1673,10 @@ func GetAvailableSpellsForCreation(c *gin.Context) {
 		return
 	}
 	logger.Info("GetAvailableSpellsForCreation - CharacterClass: %s", request.CharacterClass)
+	filter, ok := requestLearnableFilter(c)
+	if !ok {
+		return
+	}
 
 	// Convert character class name to code
 	characterClassCode, err := getCharacterClassCode(request.CharacterClass)
@@ -1589,7 +1689,7 @@ func GetAvailableSpellsForCreation(c *gin.Context) {
 	}
 
 	// Get all available spells with their learning costs
-	spellsByCategory, err := GetAllSpellsWithLE(characterClassCode, 2)
+	spellsByCategory, err := GetAllSpellsWithLE(characterClassCode, 2, filter)
 	if err != nil {
 		logger.Error("Fehler beim Abrufen der Zauber: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -1629,9 +1729,13 @@ func GetAvailableSkillsForCreation(c *gin.Context) {
 	}
 
 	logger.Info("GetAvailableSkillsForCreation - CharacterClass: %s", request.CharacterClass)
+	filter, ok := requestLearnableFilter(c)
+	if !ok {
+		return
+	}
 
 	// Get all available skills with their learning costs
-	skillsByCategory, err := GetAllSkillsWithLE()
+	skillsByCategory, err := GetAllSkillsWithLE(filter)
 	if err != nil {
 		logger.Error("Fehler beim Abrufen der Fertigkeiten: %v", err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -1647,7 +1751,8 @@ func GetAvailableSkillsForCreation(c *gin.Context) {
 	})
 }
 
-func GetAllSpellsWithLE(characterClass string, maxLevel int) (map[string][]gin.H, error) {
+// GetAllSpellsWithLE liefert die bei der Erschaffung lernbaren Zauber der Klasse, eingegrenzt auf den Filter
+func GetAllSpellsWithLE(characterClass string, maxLevel int, filter learnableFilter) (map[string][]gin.H, error) {
 	// Create mapping of character classes to allowed learning categories
 	allowedCategories := getCharacterClassSpellSchoolMapping()
 	allowedLearningCategories := getCharacterClassSpellLearningCategoriesMapping()
@@ -1688,6 +1793,9 @@ func GetAllSpellsWithLE(characterClass string, maxLevel int) (map[string][]gin.H
 	spellsByCategory := make(map[string][]gin.H)
 
 	for _, spell := range spells {
+		if !filter.allows(spell.SourceID, spell.HomebrewScope) {
+			continue
+		}
 		// Check if this character class can learn this spell school
 		//if !allowedSchools[spell.Category] || !allowedSpellType[spell.LearningCategory] {
 		//	continue // Skip spells from schools this class can't learn
@@ -1865,7 +1973,7 @@ func getSpellLECost(level int) int {
 	return spellLECost.LERequired
 }
 
-func GetAllSkillsWithLE() (map[string][]gin.H, error) {
+func GetAllSkillsWithLE(filter learnableFilter) (map[string][]gin.H, error) {
 	// Get all skill categories from database
 	var skillCategories []models.SkillCategory
 	if err := database.DB.Find(&skillCategories).Error; err != nil {
@@ -1890,7 +1998,7 @@ func GetAllSkillsWithLE() (map[string][]gin.H, error) {
 
 		// For each skill in this category, add it with its LE cost and difficulty
 		for _, scd := range skillCategoryDifficulties {
-			if category.Name == "Unbekannt" || scd.Skill.InnateSkill {
+			if category.Name == "Unbekannt" || scd.Skill.InnateSkill || !filter.allows(scd.Skill.SourceID, scd.Skill.HomebrewScope) {
 				continue
 			}
 
@@ -1909,7 +2017,7 @@ func GetAllSkillsWithLE() (map[string][]gin.H, error) {
 	}
 
 	// Add weapon skills to "Kampf" category
-	weaponSkills, err := GetWeaponSkillsWithLE()
+	weaponSkills, err := GetWeaponSkillsWithLE(filter)
 	if err == nil {
 		if _, exists := skillsByCategory["Waffen"]; !exists {
 			skillsByCategory["Waffen"] = []gin.H{}
@@ -2048,7 +2156,7 @@ func getSkillCreationCost(category string, difficulty string) int {
 }
 
 // GetWeaponSkillsWithLE returns all weapon skills with their learning costs
-func GetWeaponSkillsWithLE() ([]gin.H, error) {
+func GetWeaponSkillsWithLE(filter learnableFilter) ([]gin.H, error) {
 	// Query weapon skills with their difficulty from the WeaponSkillCategoryDifficulty table
 	var weaponSkillDifficulties []models.WeaponSkillCategoryDifficulty
 
@@ -2067,8 +2175,8 @@ func GetWeaponSkillsWithLE() ([]gin.H, error) {
 	for _, wscd := range weaponSkillDifficulties {
 		weaponName := wscd.WeaponSkill.Name
 
-		// Skip if we've already added this weapon (avoid duplicates)
-		if seenWeapons[weaponName] {
+		// Skip if we've already added this weapon (avoid duplicates) or it is not offered
+		if seenWeapons[weaponName] || !filter.allows(wscd.WeaponSkill.SourceID, wscd.WeaponSkill.HomebrewScope) {
 			continue
 		}
 		seenWeapons[weaponName] = true
@@ -2097,6 +2205,8 @@ func GetAllSkillsWithLearningCosts(characterClass string) (map[string][]gin.H, e
 	if err != nil {
 		return nil, err
 	}
+	// Ohne Charakter nur globale Fertigkeiten
+	skills = models.VisibleInScope(skills, models.ContentScope{})
 
 	skillsByCategory := make(map[string][]gin.H)
 
@@ -2264,6 +2374,11 @@ func GetAvailableSpellsNewSystem(c *gin.Context) {
 		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve spells from gsmaster")
 		return
 	}
+	filter, err := characterLearnableFilter(&character)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to determine active sources")
+		return
+	}
 
 	// Erstelle eine Map der bereits gelernten Zauber
 	learnedSpells := make(map[string]bool)
@@ -2275,8 +2390,8 @@ func GetAvailableSpellsNewSystem(c *gin.Context) {
 	spellsBySchool := make(map[string][]gin.H)
 
 	for _, spell := range allSpells {
-		// Überspringe bereits gelernte Zauber
-		if learnedSpells[spell.Name] {
+		// Überspringe bereits gelernte und nicht angebotene Zauber
+		if learnedSpells[spell.Name] || !filter.allows(spell.SourceID, spell.HomebrewScope) {
 			continue
 		}
 
@@ -2301,7 +2416,7 @@ func GetAvailableSpellsNewSystem(c *gin.Context) {
 		remainingGold := request.UseGold
 
 		// Hole die vollständigen Spell-Informationen für die Kostenberechnung
-		spellLearningInfo, err := models.GetSpellLearningInfoNewSystem(spell.Name, charakteClass)
+		spellLearningInfo, err := models.GetSpellLearningInfoNewSystem(spell.Name, charakteClass, models.CharacterContentScope(&character))
 		if err != nil {
 			// Fallback für unbekannte Zauber
 			spellLearningInfo = &models.SpellLearningInfo{
@@ -2579,6 +2694,7 @@ type UpdateBasicInfoRequest struct {
 	Herkunft   string `json:"herkunft" binding:"required"`
 	Stand      string `json:"stand" binding:"required"`
 	Glaube     string `json:"glaube"`
+	CampaignID uint   `json:"campaign_id,omitempty"` // Kampagne, deren Quellenauswahl und Hausregeln gelten
 }
 
 // UpdateCharacterBasicInfo speichert Grundinformationen
@@ -2604,6 +2720,9 @@ func UpdateCharacterBasicInfo(c *gin.Context) {
 
 	logger.Debug("UpdateCharacterBasicInfo: Request-Daten - Name: %s, Geschlecht: %s, Rasse: %s, Typ: %s, Herkunft: %s, Stand: %s, Glaube: %s",
 		request.Name, request.Geschlecht, request.Rasse, request.Typ, request.Herkunft, request.Stand, request.Glaube)
+	if !checkCampaignMembership(c, request.CampaignID) {
+		return
+	}
 
 	// Session aus Datenbank laden
 	logger.Debug("UpdateCharacterBasicInfo: Lade Session aus Datenbank...")
@@ -2627,6 +2746,7 @@ func UpdateCharacterBasicInfo(c *gin.Context) {
 	session.Herkunft = request.Herkunft
 	session.Stand = request.Stand
 	session.Glaube = request.Glaube
+	session.CampaignID = request.CampaignID
 	session.CurrentStep = 2
 	session.UpdatedAt = time.Now()
 
@@ -2943,6 +3063,13 @@ func FinalizeCharacterCreation(c *gin.Context) {
 		return
 	}
 
+	// Alle Angaben des Clients serverseitig gegen die Regeln prüfen
+	if violations := ValidateCreationSession(&session); len(violations) > 0 {
+		logger.Warn("FinalizeCharacterCreation: Session %s verletzt %d Regeln", sessionID, len(violations))
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Character creation invalid", "violations": violations})
+		return
+	}
+
 	logger.Debug("FinalizeCharacterCreation: Erstelle Charakter-Struktur...")
 	// Character erstellen
 	char := models.Char{
@@ -2956,6 +3083,7 @@ func FinalizeCharacterCreation(c *gin.Context) {
 		SocialClass: session.Stand,
 		Herkunft:    session.Herkunft,
 		Glaube:      session.Glaube,
+		CampaignID:  session.CampaignID,
 		Public:      false, // Default to private
 		Grad:        1,     // Default starting grade
 
@@ -3017,12 +3145,14 @@ func FinalizeCharacterCreation(c *gin.Context) {
 	logger.Debug("FinalizeCharacterCreation: Charakter-Struktur erstellt mit %d Eigenschaften",
 		len(char.Eigenschaften))
 
-	// Fertigkeiten aus der Session übertragen
+	// Fertigkeiten aus der Session übertragen; Hausregeln im Geltungsbereich des neuen Charakters
 	logger.Debug("FinalizeCharacterCreation: Übertrage %d Fertigkeiten", len(session.Skills))
+	gs := models.GetGameSystem(0, "")
+	contentScope := models.CharacterContentScope(&char)
 	for _, skill := range session.Skills {
 		// Suche den Initialwert der Fertigkeit aus der Datenbank
 		dbSkill := models.Skill{}
-		err := dbSkill.First(skill.Name)
+		err := models.FirstVisible(&dbSkill, skill.Name, gs, contentScope)
 		if err != nil {
 			logger.Warn("FinalizeCharacterCreation: Konnte Fertigkeit '%s' nicht in der Datenbank finden, verwende Level %d", skill.Name, skill.Level)
 		}
@@ -3037,7 +3167,7 @@ func FinalizeCharacterCreation(c *gin.Context) {
 		// Unterscheide zwischen normalen Fertigkeiten und Waffenfertigkeiten
 		if skill.Category == "Waffen" || skill.Category == "waffen" {
 			dbWPSkill := models.WeaponSkill{}
-			err := dbWPSkill.First(skill.Name)
+			err := models.FirstVisible(&dbWPSkill, skill.Name, gs, contentScope)
 			if err != nil {
 				logger.Warn("FinalizeCharacterCreation: Konnte WaffenFertigkeit '%s' nicht in der Datenbank finden, verwende Level %d", skill.Name, skill.Level)
 			}
@@ -3080,6 +3210,14 @@ func FinalizeCharacterCreation(c *gin.Context) {
 		}
 	}
 
+	// Besondere Fähigkeiten als angeborene, nicht steigerbare Fertigkeiten übernehmen
+	innateSkills := specialAbilitySkills(session.SpecialAbilities, session.DerivedValues.WK)
+	for i := range innateSkills {
+		innateSkills[i].UserID = userID
+	}
+	logger.Debug("FinalizeCharacterCreation: Übertrage %d besondere Fähigkeiten", len(innateSkills))
+	addInnateSkills(&char, innateSkills)
+
 	// Zauber aus der Session übertragen
 	logger.Debug("FinalizeCharacterCreation: Übertrage %d Zauber", len(session.Spells))
 	for _, spell := range session.Spells {
@@ -3123,6 +3261,19 @@ func FinalizeCharacterCreation(c *gin.Context) {
 	})
 }
 
+// ValidateCharacterSession prüft eine Session, ohne den Charakter zu erstellen
+func ValidateCharacterSession(c *gin.Context) {
+	var session models.CharacterCreationSession
+	err := database.DB.Where("id = ? AND user_id = ?", c.Param("sessionId"), c.GetUint("userID")).First(&session).Error
+	if err != nil {
+		respondWithError(c, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	violations := ValidateCreationSession(&session)
+	c.JSON(http.StatusOK, gin.H{"valid": len(violations) == 0, "violations": violations})
+}
+
 // DeleteCharacterSession löscht eine Session
 func DeleteCharacterSession(c *gin.Context) {
 	logger.Debug("DeleteCharacterSession aufgerufen")
@@ -3174,13 +3325,17 @@ func GetRaces(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"races": races})
 }
 
-// GetCharacterClasses gibt verfügbare Klassen zurück
+// GetCharacterClasses gibt verfügbare Klassen aus der Quellenauswahl zurück (?campaign_id=, sonst die des Benutzers)
 func GetCharacterClasses(c *gin.Context) {
 	// Get game system from query parameter, default to GameSystemId: 1
 	gameSystem := c.DefaultQuery("game_system", "midgard")
+	selector, ok := requestSourceSelector(c)
+	if !ok {
+		return
+	}
 
 	// Load character classes from database
-	classes, err := models.GetCharacterClassesByActiveSources(gameSystem)
+	classes, err := models.GetCharacterClassesByActiveSources(gameSystem, selector)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load character classes"})
 		return
@@ -3208,7 +3363,7 @@ func GetOrigins(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"origins": origins})
 }
 
-// SearchBeliefs sucht Glaubensrichtungen
+// SearchBeliefs sucht Glaubensrichtungen in der Quellenauswahl (?campaign_id=, sonst die des Benutzers)
 func SearchBeliefs(c *gin.Context) {
 	query := c.Query("q")
 
@@ -3219,9 +3374,13 @@ func SearchBeliefs(c *gin.Context) {
 
 	// Get game system from query parameter, default to GameSystemId: 1
 	gameSystem := c.DefaultQuery("game_system", "midgard")
+	selector, ok := requestSourceSelector(c)
+	if !ok {
+		return
+	}
 
 	// Load beliefs from database
-	believes, err := models.GetBelievesByActiveSources(gameSystem)
+	believes, err := models.GetBelievesByActiveSources(gameSystem, selector)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to load beliefs from database: " + err.Error()})
 		return
@@ -627,13 +627,13 @@ func TestFinalizeCharacterCreation(t *testing.T) {
 		DerivedValues: models.DerivedValuesData{
 			PA:                    33,
 			WK:                    27,
-			LPMax:                 11,
+			LPMax:                 16,
 			APMax:                 14,
 			BMax:                  26,
 			ResistenzKoerper:      11,
-			ResistenzGeist:        11,
+			ResistenzGeist:        12,
 			ResistenzBonusKoerper: 0,
-			ResistenzBonusGeist:   0,
+			ResistenzBonusGeist:   1,
 			Abwehr:                11,
 			AbwehrBonus:           0,
 			AusdauerBonus:         11,
@@ -652,17 +652,16 @@ func TestFinalizeCharacterCreation(t *testing.T) {
 			{Name: "Sprache", Level: 0, Category: "Alltag", Cost: 1},
 			{Name: "Athletik", Level: 0, Category: "Kampf", Cost: 2},
 			{Name: "Spießwaffen", Level: 0, Category: "Waffen", Cost: 2},
-			{Name: "Stielwurfwaffen", Level: 0, Category: "Waffen", Cost: 2},
-			{Name: "Waffenloser Kampf", Level: 0, Category: "Waffen", Cost: 2},
+			{Name: "Schilde", Level: 0, Category: "Waffen", Cost: 2},
+			{Name: "Zauberstäbe", Level: 0, Category: "Waffen", Cost: 2},
 			{Name: "Stichwaffen", Level: 0, Category: "Waffen", Cost: 2},
 			{Name: "Heilkunde", Level: 0, Category: "Wissen", Cost: 2},
 			{Name: "Naturkunde", Level: 0, Category: "Wissen", Cost: 2},
 		},
 		Spells: models.CharacterCreationSpells{
 			{Name: "Göttlicher Schutz v. d. Bösen", Cost: 1},
-			{Name: "Erkennen der Aura", Cost: 1},
-			{Name: "Heiliger Zorn", Cost: 1},
-			{Name: "Blutmeisterschaft", Cost: 1},
+			{Name: "Erkennen der Aura", Cost: 2},
+			{Name: "Heiliger Zorn", Cost: 2},
 		},
 		SkillPoints: models.SkillPointsData{},
 		CurrentStep: 5,
@@ -754,8 +753,8 @@ func TestFinalizeCharacterCreation(t *testing.T) {
 		assert.Equal(t, 27, attrMap["Wk"], "Willpower should match session")
 
 		// Validate derived values
-		assert.Equal(t, 11, createdChar.Lp.Max, "LP Max should match session")
-		assert.Equal(t, 11, createdChar.Lp.Value, "LP Value should equal Max initially")
+		assert.Equal(t, 16, createdChar.Lp.Max, "LP Max should match session")
+		assert.Equal(t, 16, createdChar.Lp.Value, "LP Value should equal Max initially")
 		assert.Equal(t, 14, createdChar.Ap.Max, "AP Max should match session")
 		assert.Equal(t, 14, createdChar.Ap.Value, "AP Value should equal Max initially")
 		assert.Equal(t, 26, createdChar.B.Max, "B Max should match session")
@@ -763,14 +762,14 @@ func TestFinalizeCharacterCreation(t *testing.T) {
 
 		// Validate static derived values (Resistenz, Abwehr, Zaubern, Raufen)
 		assert.Equal(t, 11, createdChar.ResistenzKoerper, "Resistenz Körper should match session")
-		assert.Equal(t, 11, createdChar.ResistenzGeist, "Resistenz Geist should match session")
+		assert.Equal(t, 12, createdChar.ResistenzGeist, "Resistenz Geist should match session")
 		assert.Equal(t, 11, createdChar.Abwehr, "Abwehr should match session")
 		assert.Equal(t, 11, createdChar.Zaubern, "Zaubern should match session")
 		assert.Equal(t, 8, createdChar.Raufen, "Raufen should match session")
 
 		// Validate skills were transferred (session has 10 skills: 6 regular skills + 4 weapon skills)
 		// Regular skills: Klettern, Reiten, Sprache, Athletik, Heilkunde, Naturkunde (6)
-		// Weapon skills: Spießwaffen, Stielwurfwaffen, Waffenloser Kampf, Stichwaffen (4)
+		// Weapon skills: Spießwaffen, Schilde, Zauberstäbe, Stichwaffen (4)
 		assert.Equal(t, 6, len(createdChar.Fertigkeiten), "Should have 6 regular skills")
 		assert.Equal(t, 4, len(createdChar.Waffenfertigkeiten), "Should have 4 weapon skills")
 
@@ -793,8 +792,8 @@ func TestFinalizeCharacterCreation(t *testing.T) {
 		assert.Contains(t, weaponSkillNames, "Spießwaffen", "Should contain Spießwaffen weapon skill")
 		assert.Contains(t, weaponSkillNames, "Stichwaffen", "Should contain Stichwaffen weapon skill")
 
-		// Validate spells were transferred (session has 4 spells)
-		assert.Equal(t, 4, len(createdChar.Zauber), "Should have 4 spells")
+		// Validate spells were transferred (session has 3 spells, 5 LE for Priester Streiter)
+		assert.Equal(t, 3, len(createdChar.Zauber), "Should have 3 spells")
 
 		// Validate spell names
 		spellNames := make([]string, len(createdChar.Zauber))
@@ -804,7 +803,6 @@ func TestFinalizeCharacterCreation(t *testing.T) {
 		assert.Contains(t, spellNames, "Göttlicher Schutz v. d. Bösen", "Should contain Göttlicher Schutz v. d. Bösen spell")
 		assert.Contains(t, spellNames, "Erkennen der Aura", "Should contain Erkennen der Aura spell")
 		assert.Contains(t, spellNames, "Heiliger Zorn", "Should contain Heiliger Zorn spell")
-		assert.Contains(t, spellNames, "Blutmeisterschaft", "Should contain Blutmeisterschaft spell")
 
 		// Verify session was deleted after successful creation
 		var deletedSession models.CharacterCreationSession
@@ -3,16 +3,61 @@ package character
 import (
 	"bamort/database"
 	"bamort/logger"
+	"bamort/media"
 	"bamort/models"
+	"errors"
+	"io"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
 )
 
+// ImageUpdateRequest enthält das Bild als Data-URI oder reines Base64
 type ImageUpdateRequest struct {
 	Image string `json:"image" binding:"required"`
 }
 
+// readImageUpload liest das Bild aus einem Multipart-Upload (Feld "file") oder aus JSON
+func readImageUpload(c *gin.Context) ([]byte, error) {
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			return nil, err
+		}
+		file, err := fileHeader.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		// Ein Byte mehr als erlaubt lesen, damit zu große Dateien erkannt werden
+		return io.ReadAll(io.LimitReader(file, int64(media.MaxBytes())+1))
+	}
+
+	var request ImageUpdateRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		return nil, err
+	}
+	return media.DecodeDataURI(request.Image)
+}
+
+// imageErrorStatus ordnet Fehler der Bildprüfung einem HTTP-Status zu
+func imageErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, media.ErrTooLarge):
+		return http.StatusRequestEntityTooLarge
+	case errors.Is(err, media.ErrUnsupportedType):
+		return http.StatusUnsupportedMediaType
+	case errors.Is(err, media.ErrInvalidImage), errors.Is(err, media.ErrEmpty):
+		return http.StatusBadRequest
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// UpdateCharacterImage speichert ein neues Charakterbild
+// Char.Image enthält danach nur noch die Adresse des Bildes, nicht mehr die Bilddaten.
 func UpdateCharacterImage(c *gin.Context) {
 	id := c.Param("id")
 	logger.Debug("UpdateCharacterImage called for character ID: %s", id)
@@ -30,21 +75,89 @@ func UpdateCharacterImage(c *gin.Context) {
 		return
 	}
 
-	var request ImageUpdateRequest
-	if err := c.ShouldBindJSON(&request); err != nil {
+	data, err := readImageUpload(c)
+	if err != nil {
 		logger.Error("Invalid request data: %s", err.Error())
-		respondWithError(c, http.StatusBadRequest, "Invalid request data")
+		status := imageErrorStatus(err)
+		if status == http.StatusInternalServerError {
+			status = http.StatusBadRequest
+		}
+		respondWithError(c, status, "Invalid request data: "+err.Error())
 		return
 	}
 
-	character.Image = request.Image
-
-	if err := database.DB.Save(&character).Error; err != nil {
+	var file *models.MediaFile
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		file, err = media.SavePortraitTx(tx, character.ID, c.GetUint("userID"), data)
+		return err
+	})
+	if err != nil {
 		logger.Error("Failed to update character image: %s", err.Error())
+		if status := imageErrorStatus(err); status != http.StatusInternalServerError {
+			respondWithError(c, status, err.Error())
+			return
+		}
 		respondWithError(c, http.StatusInternalServerError, "Failed to update character image")
 		return
 	}
 
 	logger.Info("Character image updated successfully for ID: %s", id)
-	c.JSON(http.StatusOK, gin.H{"message": "Image updated successfully", "image": character.Image})
+	c.JSON(http.StatusOK, gin.H{"message": "Image updated successfully", "image": media.PortraitURL(character.ID, file.ID), "media": file})
+}
+
+// GetCharacterImage liefert das Charakterbild (?size=thumb für das Vorschaubild)
+func GetCharacterImage(c *gin.Context) {
+	var character models.Char
+	if err := database.DB.Select("id", "user_id", "public").First(&character, c.Param("id")).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return
+	}
+	if !CanReadCharacter(&character, c.GetUint("userID")) {
+		respondWithError(c, http.StatusForbidden, "You are not authorized to view this character")
+		return
+	}
+
+	thumbnail := c.Query("size") == "thumb"
+	file, err := media.LoadPortrait(character.ID, thumbnail)
+	if err != nil {
+		respondWithError(c, http.StatusNotFound, "Image not found")
+		return
+	}
+
+	etag := `"` + file.Checksum + `"`
+	if thumbnail {
+		etag = `"` + file.Checksum + `-thumb"`
+	}
+	c.Header("Cache-Control", "private, max-age=86400")
+	c.Header("ETag", etag)
+	if c.GetHeader("If-None-Match") == etag {
+		c.AbortWithStatus(http.StatusNotModified)
+		return
+	}
+	if thumbnail {
+		c.Data(http.StatusOK, file.ThumbMimeType, file.Thumbnail)
+		return
+	}
+	c.Data(http.StatusOK, file.MimeType, file.Data)
+}
+
+// DeleteCharacterImage entfernt das Charakterbild
+func DeleteCharacterImage(c *gin.Context) {
+	var character models.Char
+	if err := character.FirstID(c.Param("id")); err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return
+	}
+	if !checkCharacterOwnership(c, &character) {
+		return
+	}
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return media.DeletePortraitTx(tx, character.ID)
+	})
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to delete character image")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Image deleted"})
 }
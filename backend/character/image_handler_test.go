@@ -1,16 +1,28 @@
 package character
 
 import (
+	"bamort/config"
 	"bamort/database"
+	"bamort/media"
 	"bamort/models"
+	"bamort/testutils"
 	"bytes"
 	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestUpdateCharacterImage(t *testing.T) {
@@ -46,11 +58,14 @@ func TestUpdateCharacterImage(t *testing.T) {
 
 	assert.Equal(t, http.StatusOK, w.Code, "Should successfully update image")
 
-	// Verify image was saved
+	// Verify image was saved in the media table and the character only keeps its URL
 	var updatedChar models.Char
 	err = updatedChar.FirstID("18")
 	assert.NoError(t, err)
-	assert.Equal(t, imageData, updatedChar.Image, "Image should be updated in database")
+	file, err := media.LoadPortrait(updatedChar.ID, false)
+	assert.NoError(t, err)
+	assert.Equal(t, media.PortraitURL(updatedChar.ID, file.ID), updatedChar.Image, "Image URL should be stored in database")
+	assert.Equal(t, imageData, media.DataURI(file.Data, file.MimeType), "Image data should be stored in media table")
 }
 
 func TestUpdateCharacterImageInvalidID(t *testing.T) {
@@ -97,3 +112,113 @@ func TestUpdateCharacterImageInvalidData(t *testing.T) {
 
 	assert.Equal(t, http.StatusBadRequest, w.Code, "Should return 400 for invalid JSON")
 }
+
+// testPNG erzeugt ein einfarbiges PNG der angegebenen Größe
+func testPNG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: color.RGBA{R: 200, G: 80, B: 40, A: 255}}, image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+func TestCharacterImageStorage(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+
+	owner := ensureUserExists(t, 254)
+	stranger := ensureUserExists(t, 255)
+	char := createCharacterOwnedBy(t, owner.UserID)
+	params := map[string]string{"id": fmt.Sprint(char.ID)}
+	original := testPNG(t, 300, 200)
+
+	upload := func(userID uint, image string) *httptest.ResponseRecorder {
+		ctx, w := buildJSONContext(t, http.MethodPut, map[string]string{"image": image}, userID, params)
+		UpdateCharacterImage(ctx)
+		return w
+	}
+	download := func(userID uint, query, etag string) *httptest.ResponseRecorder {
+		ctx, w := buildJSONContext(t, http.MethodGet, nil, userID, params)
+		ctx.Request.URL.RawQuery = query
+		if etag != "" {
+			ctx.Request.Header.Set("If-None-Match", etag)
+		}
+		GetCharacterImage(ctx)
+		return w
+	}
+
+	t.Run("upload validates and stores", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, upload(stranger.UserID, media.DataURI(original, "image/png")).Code)
+		assert.Equal(t, http.StatusUnsupportedMediaType, upload(owner.UserID, media.DataURI([]byte("kein Bild"), "image/png")).Code)
+
+		maxBytes := config.Cfg.MediaMaxBytes
+		config.Cfg.MediaMaxBytes = 100
+		assert.Equal(t, http.StatusRequestEntityTooLarge, upload(owner.UserID, media.DataURI(original, "image/png")).Code)
+		config.Cfg.MediaMaxBytes = maxBytes
+
+		w := upload(owner.UserID, media.DataURI(original, "image/png"))
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		reloaded := reloadCharacter(t, char.ID)
+		assert.True(t, strings.HasPrefix(reloaded.Image, fmt.Sprintf("/api/characters/%d/image?v=", char.ID)), reloaded.Image)
+	})
+
+	t.Run("authenticated download with thumbnail", func(t *testing.T) {
+		w := download(owner.UserID, "", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/png", w.Header().Get("Content-Type"))
+		assert.Equal(t, original, w.Body.Bytes())
+		assert.Equal(t, http.StatusNotModified, download(owner.UserID, "", w.Header().Get("ETag")).Code)
+
+		w = download(owner.UserID, "size=thumb", "")
+		require.Equal(t, http.StatusOK, w.Code)
+		thumb, _, err := image.DecodeConfig(bytes.NewReader(w.Body.Bytes()))
+		require.NoError(t, err)
+		assert.Equal(t, 128, thumb.Width)
+		assert.Equal(t, 85, thumb.Height)
+
+		assert.Equal(t, http.StatusForbidden, download(stranger.UserID, "", "").Code)
+	})
+
+	t.Run("multipart upload replaces the image", func(t *testing.T) {
+		var body bytes.Buffer
+		writer := multipart.NewWriter(&body)
+		part, err := writer.CreateFormFile("file", "portrait.png")
+		require.NoError(t, err)
+		replacement := testPNG(t, 64, 64)
+		_, err = part.Write(replacement)
+		require.NoError(t, err)
+		require.NoError(t, writer.Close())
+
+		ctx, w := buildJSONContext(t, http.MethodPut, nil, owner.UserID, params)
+		ctx.Request.Body = io.NopCloser(&body)
+		ctx.Request.Header.Set("Content-Type", writer.FormDataContentType())
+		UpdateCharacterImage(ctx)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var count int64
+		database.DB.Model(&models.MediaFile{}).Where("character_id = ?", char.ID).Count(&count)
+		assert.EqualValues(t, 1, count)
+		assert.Equal(t, replacement, download(owner.UserID, "", "").Body.Bytes())
+	})
+
+	t.Run("clones get their own copy", func(t *testing.T) {
+		source := reloadCharacter(t, char.ID)
+		clone, err := CloneCharacter(database.DB, &source, "Kopie", owner.UserID)
+		require.NoError(t, err)
+		assert.Equal(t, fmt.Sprintf("/api/characters/%d/image", clone.ID), strings.Split(clone.Image, "?")[0])
+		file, err := media.LoadPortrait(clone.ID, false)
+		require.NoError(t, err)
+		assert.Equal(t, clone.UserID, file.UserID)
+	})
+
+	t.Run("delete removes the image", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodDelete, nil, owner.UserID, params)
+		DeleteCharacterImage(ctx)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Empty(t, reloadCharacter(t, char.ID).Image)
+		assert.Equal(t, http.StatusNotFound, download(owner.UserID, "", "").Code)
+	})
+}
@@ -0,0 +1,111 @@
+package character
+
+import (
+	"bamort/database"
+	"bamort/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// learnableFilter grenzt die angebotenen Stammdaten ein: Hausregeln im Geltungsbereich und
+// Werke der Quellenauswahl (Kampagne, sonst Benutzer, sonst globales Source.IsActive)
+type learnableFilter struct {
+	scope   models.ContentScope
+	sources models.ActiveSources
+}
+
+// allows prüft, ob ein Stammdatensatz mit dieser Quelle und diesem Geltungsbereich angeboten wird
+func (f learnableFilter) allows(sourceID uint, homebrew models.HomebrewScope) bool {
+	return homebrew.VisibleIn(f.scope) && f.sources.Contains(sourceID)
+}
+
+// skillLearnable prüft, ob die Fertigkeit dem Charakter laut Quellenauswahl und Geltungsbereich angeboten wird
+func skillLearnable(char *models.Char, skillID uint) (bool, error) {
+	var skill models.Skill
+	if err := database.DB.First(&skill, skillID).Error; err != nil {
+		return false, err
+	}
+	filter, err := characterLearnableFilter(char)
+	if err != nil {
+		return false, err
+	}
+	return filter.allows(skill.SourceID, skill.HomebrewScope), nil
+}
+
+// spellLearnable prüft, ob der Zauber dem Charakter laut Quellenauswahl und Geltungsbereich angeboten wird
+func spellLearnable(char *models.Char, spellID uint) (bool, error) {
+	var spell models.Spell
+	if err := database.DB.First(&spell, spellID).Error; err != nil {
+		return false, err
+	}
+	filter, err := characterLearnableFilter(char)
+	if err != nil {
+		return false, err
+	}
+	return filter.allows(spell.SourceID, spell.HomebrewScope), nil
+}
+
+// gameSystemName liefert den Namen des Spielsystems eines Charakters (Standard: midgard)
+func gameSystemName(char *models.Char) string {
+	if gs := models.GetGameSystem(char.GameSystemId, char.GameSystem); gs != nil && gs.Name != "" {
+		return gs.Name
+	}
+	return "midgard"
+}
+
+// characterLearnableFilter liefert den Filter für einen bestehenden Charakter
+func characterLearnableFilter(char *models.Char) (learnableFilter, error) {
+	sources, err := models.GetActiveSources(gameSystemName(char), models.CharacterSourceSelector(char))
+	if err != nil {
+		return learnableFilter{}, err
+	}
+	return learnableFilter{scope: models.CharacterContentScope(char), sources: sources}, nil
+}
+
+// creationLearnableFilter liefert den Filter für einen neuen Charakter des Benutzers, optional in einer Kampagne
+func creationLearnableFilter(userID, campaignID uint) (learnableFilter, error) {
+	char := models.Char{UserID: userID, CampaignID: campaignID}
+	return characterLearnableFilter(&char)
+}
+
+// checkCampaignMembership prüft, ob der Benutzer in der Kampagne spielt oder sie leitet
+// 0 steht für "keine Kampagne" und ist immer erlaubt.
+func checkCampaignMembership(c *gin.Context, campaignID uint) bool {
+	if campaignID == 0 {
+		return true
+	}
+	var campaign models.Campaign
+	if err := database.DB.First(&campaign, campaignID).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Campaign not found")
+		return false
+	}
+	if !campaign.IsMember(c.GetUint("userID")) {
+		respondWithError(c, http.StatusForbidden, "You are not a member of this campaign")
+		return false
+	}
+	return true
+}
+
+// requestSourceSelector liefert die Quellenauswahl für Erstellungsendpunkte (?campaign_id=, sonst die des Benutzers)
+func requestSourceSelector(c *gin.Context) (models.SourceSelector, bool) {
+	campaignID, ok := queryInt(c, "campaign_id")
+	if !ok || !checkCampaignMembership(c, uint(campaignID)) {
+		return models.SourceSelector{}, false
+	}
+	return models.CharacterSourceSelector(&models.Char{UserID: c.GetUint("userID"), CampaignID: uint(campaignID)}), true
+}
+
+// requestLearnableFilter liefert den Filter für Erstellungsendpunkte (?campaign_id=, sonst Auswahl des Benutzers)
+func requestLearnableFilter(c *gin.Context) (learnableFilter, bool) {
+	campaignID, ok := queryInt(c, "campaign_id")
+	if !ok || !checkCampaignMembership(c, uint(campaignID)) {
+		return learnableFilter{}, false
+	}
+	filter, err := creationLearnableFilter(c.GetUint("userID"), uint(campaignID))
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to determine active sources")
+		return learnableFilter{}, false
+	}
+	return filter, true
+}
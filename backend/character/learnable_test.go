@@ -0,0 +1,123 @@
+package character
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bamort/database"
+	"bamort/gsmaster"
+	"bamort/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreationRespectsSourceSelection(t *testing.T) {
+	setupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+	require.NoError(t, database.DB.Create(&models.GameSystem{Code: "M5", Name: "midgard", IsActive: true}).Error)
+
+	const player, gm uint = 271, 272
+	countSpells := func(filter learnableFilter) int {
+		spells, err := GetAllSpellsWithLE("Ma", 6, filter)
+		require.NoError(t, err)
+		count := 0
+		for _, list := range spells {
+			count += len(list)
+		}
+		return count
+	}
+
+	global, err := creationLearnableFilter(player, 0)
+	require.NoError(t, err)
+	assert.Equal(t, models.SourceOriginGlobal, global.sources.Origin)
+	allSpells := countSpells(global)
+	require.Positive(t, allSpells)
+
+	// Nur das Kodex: Arkanum-Zauber entfallen
+	require.NoError(t, models.SetSourceSelection("midgard", models.SourceSelector{UserID: player}, []uint{1}))
+	own, err := creationLearnableFilter(player, 0)
+	require.NoError(t, err)
+	assert.Equal(t, models.SourceOriginUser, own.sources.Origin)
+	assert.Less(t, countSpells(own), allSpells)
+	assert.False(t, own.sources.Contains(2))
+
+	campaign := models.Campaign{Name: "Mysterium-Runde", GameSystem: "M5", UserID: gm}
+	require.NoError(t, database.DB.Create(&campaign).Error)
+	require.NoError(t, models.SetSourceSelection("midgard", models.SourceSelector{CampaignID: campaign.ID}, []uint{1, 2}))
+	inCampaign, err := creationLearnableFilter(player, campaign.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.SourceOriginCampaign, inCampaign.sources.Origin, "campaign selection wins over the user's")
+	assert.True(t, inCampaign.sources.Contains(2))
+
+	t.Run("creation endpoint checks campaign membership", func(t *testing.T) {
+		router := gin.New()
+		router.Use(func(c *gin.Context) { c.Set("userID", player) })
+		router.POST("/spells", GetAvailableSpellsForCreation)
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, fmt.Sprintf("/spells?campaign_id=%d", campaign.ID), bytes.NewBufferString(`{"characterClass":"Magier"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
+
+func TestLearningRespectsSourceSelection(t *testing.T) {
+	setupTestEnvironment(t)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+	require.NoError(t, database.DB.Create(&models.GameSystem{Code: "M5", Name: "midgard", IsActive: true}).Error)
+
+	const player uint = 273
+	char := createCharacterOwnedBy(t, player)
+	char.Typ = "Magier"
+	require.NoError(t, database.DB.Save(&char).Error)
+
+	skill := &gsmaster.LernCostRequest{Name: "Thaumagraphie", Type: "skill", Action: "learn"} // Quelle 3
+	spell := &gsmaster.LernCostRequest{Name: "Angst", Type: "spell", Action: "learn"}         // Quelle 2
+	_, _, _, err := validateSkillForLearning(&char, skill)
+	require.NoError(t, err)
+	_, _, _, err = validateSpellForLearning(&char, spell)
+	require.NoError(t, err)
+	var angst models.Spell
+	require.NoError(t, database.DB.Where("name = ?", "Angst").First(&angst).Error)
+	schoolSpells := func() []string {
+		names := []string{}
+		for _, step := range spellSchoolSteps(&char, angst.Category, 100) {
+			names = append(names, step.Spell)
+		}
+		return names
+	}
+	require.Contains(t, schoolSpells(), "Angst")
+
+	// Nur das Kodex ausgewählt: weder Fertigkeit noch Zauber dürfen gelernt werden
+	require.NoError(t, models.SetSourceSelection("midgard", models.SourceSelector{UserID: player}, []uint{1}))
+	_, _, _, err = validateSkillForLearning(&char, skill)
+	assert.ErrorContains(t, err, "Quellenauswahl")
+	_, _, _, err = validateSpellForLearning(&char, spell)
+	assert.ErrorContains(t, err, "Quellenauswahl")
+	assert.NotContains(t, schoolSpells(), "Angst", "PP conversion must not offer deselected sources")
+
+	simulation := SimulateAdvancementPlan(&char, []models.AdvancementPlanStep{
+		{Name: "Thaumagraphie", Type: "skill", Action: "learn"},
+		{Name: "Angst", Type: "spell", Action: "learn"},
+	})
+	assert.False(t, simulation.Valid)
+	for _, step := range simulation.Steps {
+		assert.Contains(t, step.Error, "Quellenauswahl", step.Name)
+	}
+
+	candidates, err := collectBudgetCandidates(&char, &BudgetOptimizerRequest{}, 10000, 10000)
+	require.NoError(t, err)
+	for _, candidate := range candidates {
+		assert.NotEqual(t, "Thaumagraphie", candidate.increments[0].Name, "budget optimizer must not suggest deselected sources")
+	}
+}
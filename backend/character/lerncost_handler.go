@@ -126,7 +126,7 @@ func GetLernCostNewSystem(c *gin.Context) {
 		if request.Type == "spell" {
 			// 4.1.1 "spell": Hole Zauber-Lerninformationen und berechne Kosten
 			// Spell learning logic
-			spellInfo, err := models.GetSpellLearningInfoNewSystem(skillName, characterClass)
+			spellInfo, err := models.GetSpellLearningInfoNewSystem(skillName, characterClass, models.CharacterContentScope(&character))
 			if err != nil {
 				respondWithError(c, http.StatusBadRequest, fmt.Sprintf("Zauber '%s' nicht gefunden oder nicht für Klasse '%s' verfügbar: %v", skillName, characterClass, err))
 				return
@@ -150,7 +150,7 @@ func GetLernCostNewSystem(c *gin.Context) {
 			response = append(response, levelResult)
 		} else {
 			// 4.1.2 "skill": Hole Fertigkeits-Lerninformationen und berechne Kosten
-			skillInfo, err := models.GetSkillCategoryAndDifficultyNewSystem(skillName, characterClass)
+			skillInfo, err := models.GetSkillCategoryAndDifficultyNewSystem(skillName, characterClass, models.CharacterContentScope(&character))
 			if err != nil {
 				respondWithError(c, http.StatusBadRequest, fmt.Sprintf("Fertigkeit '%s' nicht gefunden oder nicht für Klasse '%s' verfügbar: %v", skillName, characterClass, err))
 				return
@@ -176,7 +176,7 @@ func GetLernCostNewSystem(c *gin.Context) {
 	} else {
 		// Für "improve" Aktion: berechne für jedes Level von current+1 bis 18
 		// Improvement only works on skills, not spells
-		skillInfo, err := models.GetSkillCategoryAndDifficultyNewSystem(skillName, characterClass)
+		skillInfo, err := models.GetSkillCategoryAndDifficultyNewSystem(skillName, characterClass, models.CharacterContentScope(&character))
 		if err != nil {
 			respondWithError(c, http.StatusBadRequest, fmt.Sprintf("Fertigkeit '%s' nicht gefunden oder nicht für Klasse '%s' verfügbar: %v", skillName, characterClass, err))
 			return
@@ -299,10 +299,14 @@ func CalculateSkillImproveCostNewSystem(request *gsmaster.LernCostRequest, resul
 
 // calculateSkillLearnCostNewSystem berechnet die Kosten für das Erlernen einer Fertigkeit (Action: "learn", Type: "skill")
 func calculateSkillLearnCostNewSystem(request *gsmaster.LernCostRequest, result *gsmaster.SkillCostResultNew, remainingPP *int, remainingGold *int, skillInfo *models.SkillLearningInfo) error {
-	// 1. Hole die EP-Kosten pro TE für diese Klasse und Kategorie
-	epPerTE, err := models.GetEPPerTEForClassAndCategory(result.CharacterClass, skillInfo.CategoryName)
-	if err != nil {
-		return fmt.Errorf("EP-Kosten pro TE nicht gefunden für Klasse %s, Kategorie %s: %v", result.CharacterClass, skillInfo.CategoryName, err)
+	// 1. Hole die EP-Kosten pro TE für diese Klasse und Kategorie, falls die skillInfo sie nicht schon enthält
+	epPerTE := skillInfo.EPPerTE
+	if epPerTE == 0 {
+		var err error
+		epPerTE, err = models.GetEPPerTEForClassAndCategory(result.CharacterClass, skillInfo.CategoryName)
+		if err != nil {
+			return fmt.Errorf("EP-Kosten pro TE nicht gefunden für Klasse %s, Kategorie %s: %v", result.CharacterClass, skillInfo.CategoryName, err)
+		}
 	}
 
 	// 2. Verwende die Lernkosten (LE) direkt aus der skillInfo - diese enthält bereits alle benötigten Informationen
@@ -460,6 +464,14 @@ func applySpellRewardNewSystem(result *gsmaster.SkillCostResultNew, reward *stri
 	}
 }
 
+// skillLearningInfo sucht Kategorie und Schwierigkeit einer Fertigkeit ("skill") oder Waffenfertigkeit ("weapon")
+func skillLearningInfo(char *models.Char, name, skillType, classCode string) (*models.SkillLearningInfo, error) {
+	if skillType == "weapon" {
+		return models.GetWeaponSkillLearningInfoNewSystem(name, classCode, models.CharacterContentScope(char))
+	}
+	return models.GetSkillCategoryAndDifficultyNewSystem(name, classCode, models.CharacterContentScope(char))
+}
+
 // Helper function to get current skill level from character
 func getCurrentSkillLevel(character *models.Char, skillName, skillType string) int {
 	switch skillType {
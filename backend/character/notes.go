@@ -0,0 +1,190 @@
+package character
+
+import (
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NoteRequest legt eine Notiz an oder ändert sie; leere Felder bleiben beim Ändern erhalten
+type NoteRequest struct {
+	Type       string  `json:"type,omitempty"`
+	Title      *string `json:"title,omitempty"`
+	Text       *string `json:"text,omitempty"` // Markdown
+	Visibility string  `json:"visibility,omitempty"`
+	GameDay    *int    `json:"game_day,omitempty"` // Spieltag als Tagesnummer im Spielkalender
+}
+
+// isGameMasterFor prüft, ob der Benutzer die Kampagne leitet, in der der Charakter spielt
+// Der Besitzer hat der Kampagne mit dem Beitrittscode selbst zugestimmt; Begegnungen zählen nicht,
+// weil jeder lesbare Charakter in eine eigene Begegnung aufgenommen werden kann.
+func isGameMasterFor(char *models.Char, userID uint) bool {
+	if char.CampaignID == 0 || userID == 0 {
+		return false
+	}
+	var count int64
+	database.DB.Model(&models.Campaign{}).Where("id = ? AND user_id = ?", char.CampaignID, userID).Count(&count)
+	return count > 0
+}
+
+// VisibleNoteLevels liefert die Sichtbarkeiten, die der Benutzer bei einem Charakter lesen darf
+// Der Besitzer sieht alles, Spielleiter zusätzlich zu öffentlichen auch GM-Notizen.
+func VisibleNoteLevels(char *models.Char, userID uint) []string {
+	switch {
+	case char.UserID == userID:
+		return []string{models.NoteVisibilityPrivate, models.NoteVisibilityGM, models.NoteVisibilityPublic}
+	case isGameMasterFor(char, userID):
+		return []string{models.NoteVisibilityGM, models.NoteVisibilityPublic}
+	case CanReadCharacter(char, userID):
+		return []string{models.NoteVisibilityPublic}
+	default:
+		return nil
+	}
+}
+
+// applyNoteRequest übernimmt die Angaben der Anfrage und prüft Art und Sichtbarkeit
+func applyNoteRequest(note *models.CharNote, req NoteRequest) string {
+	if req.Type != "" {
+		note.Type = req.Type
+	}
+	if req.Visibility != "" {
+		note.Visibility = req.Visibility
+	}
+	if req.Title != nil {
+		note.Title = *req.Title
+	}
+	if req.Text != nil {
+		note.Text = *req.Text
+	}
+	if req.GameDay != nil {
+		note.GameDay = req.GameDay
+	}
+	if !models.ValidNoteType(note.Type) {
+		return "Ungültige Notizart: " + note.Type
+	}
+	if !models.ValidNoteVisibility(note.Visibility) {
+		return "Ungültige Sichtbarkeit: " + note.Visibility
+	}
+	return ""
+}
+
+// ListCharacterNotes gibt die für den Benutzer sichtbaren Notizen zurück (?type=... filtert)
+func ListCharacterNotes(c *gin.Context) {
+	var character models.Char
+	if err := database.DB.First(&character, c.Param("id")).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return
+	}
+	levels := VisibleNoteLevels(&character, c.GetUint("userID"))
+	if len(levels) == 0 {
+		respondWithError(c, http.StatusForbidden, "You are not authorized to view this character")
+		return
+	}
+
+	query := database.DB.Where("character_id = ? AND visibility IN ?", character.ID, levels)
+	if noteType := c.Query("type"); noteType != "" {
+		query = query.Where("type = ?", noteType)
+	}
+	notes := []models.CharNote{}
+	if err := query.Order("type ASC, created_at ASC, id ASC").Find(&notes).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve notes")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"character_id": character.ID, "notes": notes})
+}
+
+// CreateCharacterNote legt eine Notiz für einen eigenen Charakter an
+func CreateCharacterNote(c *gin.Context) {
+	var character models.Char
+	if err := database.DB.First(&character, c.Param("id")).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return
+	}
+	if !checkCharacterOwnership(c, &character) {
+		return
+	}
+
+	var req NoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	note := models.CharNote{
+		CharacterID: character.ID,
+		UserID:      c.GetUint("userID"),
+		Type:        models.NoteTypeMisc,
+		Visibility:  models.NoteVisibilityPrivate,
+	}
+	// Notizen von Charakteren mit Spielkalender erhalten standardmäßig den aktuellen Spieltag
+	var cal models.GameCalendar
+	if character.CalendarID != 0 && database.DB.First(&cal, character.CalendarID).Error == nil {
+		note.GameDay = &cal.CurrentDay
+	}
+	if msg := applyNoteRequest(&note, req); msg != "" {
+		respondWithError(c, http.StatusBadRequest, msg)
+		return
+	}
+	if err := database.DB.Create(&note).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to create note")
+		return
+	}
+
+	logger.Info("Notiz %d (%s) für Charakter %d angelegt", note.ID, note.Type, character.ID)
+	c.JSON(http.StatusCreated, note)
+}
+
+// loadOwnedNote lädt Charakter und Notiz und prüft den Besitz
+func loadOwnedNote(c *gin.Context) (*models.CharNote, bool) {
+	var character models.Char
+	if err := database.DB.First(&character, c.Param("id")).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return nil, false
+	}
+	if !checkCharacterOwnership(c, &character) {
+		return nil, false
+	}
+	var note models.CharNote
+	if err := database.DB.Where("id = ? AND character_id = ?", c.Param("noteId"), character.ID).First(&note).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Note not found")
+		return nil, false
+	}
+	return &note, true
+}
+
+// UpdateCharacterNote ändert Art, Titel, Text oder Sichtbarkeit einer Notiz
+func UpdateCharacterNote(c *gin.Context) {
+	note, ok := loadOwnedNote(c)
+	if !ok {
+		return
+	}
+	var req NoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if msg := applyNoteRequest(note, req); msg != "" {
+		respondWithError(c, http.StatusBadRequest, msg)
+		return
+	}
+	if err := database.DB.Save(note).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to update note")
+		return
+	}
+	c.JSON(http.StatusOK, note)
+}
+
+// DeleteCharacterNote löscht eine Notiz
+func DeleteCharacterNote(c *gin.Context) {
+	note, ok := loadOwnedNote(c)
+	if !ok {
+		return
+	}
+	if err := database.DB.Delete(note).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to delete note")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Note deleted"})
+}
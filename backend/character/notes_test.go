@@ -0,0 +1,150 @@
+package character
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCharacterNotes(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+
+	owner := ensureUserExists(t, 238)
+	gm := ensureUserExists(t, 239)
+	stranger := ensureUserExists(t, 240)
+	char := createCharacterOwnedBy(t, owner.UserID)
+	params := map[string]string{"id": fmt.Sprint(char.ID)}
+
+	create := func(userID uint, body map[string]any) (int, models.CharNote) {
+		ctx, w := buildJSONContext(t, http.MethodPost, body, userID, params)
+		CreateCharacterNote(ctx)
+		var note models.CharNote
+		if w.Code == http.StatusCreated {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &note))
+		}
+		return w.Code, note
+	}
+	list := func(userID uint, noteType string) (int, []models.CharNote) {
+		ctx, w := buildJSONContext(t, http.MethodGet, nil, userID, params)
+		if noteType != "" {
+			ctx.Request.URL.RawQuery = "type=" + noteType
+		}
+		ListCharacterNotes(ctx)
+		var response struct {
+			Notes []models.CharNote `json:"notes"`
+		}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response.Notes
+	}
+	titles := func(notes []models.CharNote) []string {
+		out := make([]string, len(notes))
+		for i, note := range notes {
+			out[i] = note.Title
+		}
+		return out
+	}
+
+	code, backstory := create(owner.UserID, map[string]any{"type": "backstory", "title": "Herkunft", "text": "Geboren in **Alba**", "visibility": "public"})
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, owner.UserID, backstory.UserID)
+	code, contact := create(owner.UserID, map[string]any{"type": "contact", "title": "Schmuggler", "visibility": "gm"})
+	require.Equal(t, http.StatusCreated, code)
+	code, secret := create(owner.UserID, map[string]any{"title": "Geheimnis"})
+	require.Equal(t, http.StatusCreated, code)
+	assert.Equal(t, models.NoteTypeMisc, secret.Type)
+	assert.Equal(t, models.NoteVisibilityPrivate, secret.Visibility)
+
+	t.Run("invalid type or visibility", func(t *testing.T) {
+		code, _ := create(owner.UserID, map[string]any{"type": "tagebuch", "title": "X"})
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = create(owner.UserID, map[string]any{"title": "X", "visibility": "alle"})
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("only owner creates notes", func(t *testing.T) {
+		code, _ := create(stranger.UserID, map[string]any{"title": "Fremd"})
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("owner sees all notes and filters by type", func(t *testing.T) {
+		code, notes := list(owner.UserID, "")
+		require.Equal(t, http.StatusOK, code)
+		assert.ElementsMatch(t, []string{"Herkunft", "Schmuggler", "Geheimnis"}, titles(notes))
+
+		code, notes = list(owner.UserID, models.NoteTypeContact)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []string{"Schmuggler"}, titles(notes))
+	})
+
+	t.Run("stranger is denied on private character", func(t *testing.T) {
+		code, _ := list(stranger.UserID, "")
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("game master sees gm and public notes", func(t *testing.T) {
+		// Eine eigene Begegnung mit dem Charakter macht noch niemanden zum Spielleiter
+		encounter := models.Encounter{Name: "Notiztest", UserID: gm.UserID}
+		require.NoError(t, database.DB.Create(&encounter).Error)
+		require.NoError(t, database.DB.Create(&models.EncounterParticipant{EncounterID: encounter.ID, CharacterID: char.ID, Name: char.Name}).Error)
+		code, _ := list(gm.UserID, "")
+		assert.Equal(t, http.StatusForbidden, code)
+
+		campaign := models.Campaign{Name: "Notizrunde", UserID: gm.UserID}
+		require.NoError(t, database.DB.Create(&campaign).Error)
+		require.NoError(t, database.DB.Model(&models.Char{}).Where("id = ?", char.ID).Update("campaign_id", campaign.ID).Error)
+
+		code, notes := list(gm.UserID, "")
+		require.Equal(t, http.StatusOK, code)
+		assert.ElementsMatch(t, []string{"Herkunft", "Schmuggler"}, titles(notes))
+	})
+
+	t.Run("reader of public character sees public notes", func(t *testing.T) {
+		require.NoError(t, database.DB.Model(&models.Char{}).Where("id = ?", char.ID).Update("public", true).Error)
+		code, notes := list(stranger.UserID, "")
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []string{"Herkunft"}, titles(notes))
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		noteParams := map[string]string{"id": fmt.Sprint(char.ID), "noteId": fmt.Sprint(contact.ID)}
+
+		ctx, w := buildJSONContext(t, http.MethodPut, map[string]any{"text": "Schuldet uns 10 GS", "visibility": "public"}, stranger.UserID, noteParams)
+		UpdateCharacterNote(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		ctx, w = buildJSONContext(t, http.MethodPut, map[string]any{"text": "Schuldet uns 10 GS", "visibility": "public"}, owner.UserID, noteParams)
+		UpdateCharacterNote(ctx)
+		require.Equal(t, http.StatusOK, w.Code)
+		var updated models.CharNote
+		require.NoError(t, database.DB.First(&updated, contact.ID).Error)
+		assert.Equal(t, "Schmuggler", updated.Title)
+		assert.Equal(t, "Schuldet uns 10 GS", updated.Text)
+		assert.Equal(t, models.NoteVisibilityPublic, updated.Visibility)
+
+		ctx, w = buildJSONContext(t, http.MethodDelete, nil, owner.UserID, noteParams)
+		DeleteCharacterNote(ctx)
+		require.Equal(t, http.StatusOK, w.Code)
+		var count int64
+		database.DB.Model(&models.CharNote{}).Where("id = ?", contact.ID).Count(&count)
+		assert.Zero(t, count)
+
+		ctx, w = buildJSONContext(t, http.MethodDelete, nil, owner.UserID, noteParams)
+		DeleteCharacterNote(ctx)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
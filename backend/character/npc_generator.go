@@ -0,0 +1,557 @@
+package character
+
+import (
+	"bamort/gsmaster"
+	"bamort/models"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// NPCRequest beschreibt die Vorgaben für einen zufällig erzeugten Nichtspielercharakter
+// Leere Felder (Stand, Herkunft, Glaube, Geschlecht) werden ausgewürfelt.
+type NPCRequest struct {
+	Name       string `json:"name,omitempty"`
+	Rasse      string `json:"rasse" binding:"required"`
+	Typ        string `json:"typ" binding:"required"`                          // Klassenname oder -abkürzung
+	Grad       int    `json:"grad,omitempty" binding:"omitempty,min=1,max=30"` // Zielgrad, Standard: 1
+	Stand      string `json:"stand,omitempty"`
+	Herkunft   string `json:"herkunft,omitempty"`
+	Glaube     string `json:"glaube,omitempty"`
+	Gender     string `json:"gender,omitempty"`
+	CampaignID uint   `json:"campaign_id,omitempty"` // Quellenauswahl und Hausregeln der Kampagne verwenden
+	Seed       *int64 `json:"seed,omitempty"`        // für reproduzierbare NSCs
+	Save       bool   `json:"save,omitempty"`        // NSC speichern statt nur Vorschau
+}
+
+// npcFallbackValues werden verwendet, wenn keine Stammdaten (MiscLookup) vorhanden sind
+var npcFallbackValues = map[string][]string{
+	"gender":         {"männlich", "weiblich"},
+	"social_classes": {"Unfreie", "Volk", "Mittelschicht", "Adel"},
+	"origins": {
+		"Alba", "Aran", "Buluga", "Chryseia",
+		"Eschar", "Fuardain", "Ikenga", "KanThaiPan", "Küstenstaaten",
+		"Medjis", "Moravod", "Nahuatlan", "Rawindra", "Scharidis",
+		"Tegarisch Steppe", "Valian", "Waeland", "Ywerddon",
+	},
+}
+
+// GradeRules enthält die Erfahrungsschwellen der Grade eines Spielsystems
+type GradeRules struct {
+	ESThresholds   []int `json:"es_thresholds"`     // Erfahrungsschatz, ab dem der Grad (Index) erreicht wird
+	ESPerGradeOver int   `json:"es_per_grade_over"` // zusätzlicher Erfahrungsschatz je Grad über der Tabelle
+}
+
+// defaultGradeRules entsprechen den Regeln des M5-Systems
+var defaultGradeRules = GradeRules{
+	ESThresholds:   []int{0, 0, 100, 250, 500, 750, 1000, 1250, 1500, 1750, 2000, 2500, 3000, 3500, 4000, 4500, 5000},
+	ESPerGradeOver: 1000,
+}
+
+func getGradeRules(gs *models.GameSystem) GradeRules {
+	return models.GameSystemRules(gs, "grades", defaultGradeRules)
+}
+
+// esForGrade gibt den Erfahrungsschatz zurück, ab dem ein Charakter den Grad erreicht
+func esForGrade(rules GradeRules, grad int) int {
+	thresholds := rules.ESThresholds
+	if len(thresholds) == 0 {
+		return max(grad-1, 0) * rules.ESPerGradeOver
+	}
+	if grad < len(thresholds) {
+		return thresholds[max(grad, 0)]
+	}
+	return thresholds[len(thresholds)-1] + (grad-len(thresholds)+1)*rules.ESPerGradeOver
+}
+
+// npcGenerator kapselt den Zufallsgenerator, damit alle Würfe eines NSCs aus einem Seed stammen
+type npcGenerator struct {
+	rng    *rand.Rand
+	filter learnableFilter
+	gs     *models.GameSystem
+	rules  ConsistencyRules
+}
+
+// roll würfelt einen Ausdruck aus der Charaktererstellung und gibt die Einzelwürfe zurück
+func (g *npcGenerator) roll(field, rasse string) ([]int, error) {
+	expression, err := getCreationRollExpression(field, rasse)
+	if err != nil {
+		return nil, err
+	}
+	expr, err := ParseDiceExpression(expression)
+	if err != nil {
+		return nil, err
+	}
+	rolls := make([]int, expr.Count)
+	for i := range rolls {
+		rolls[i] = g.rng.Intn(expr.Sides) + 1
+	}
+	return rolls, nil
+}
+
+// pick wählt einen zufälligen Eintrag aus einer Liste
+func (g *npcGenerator) pick(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	return values[g.rng.Intn(len(values))]
+}
+
+// lookupValues lädt die Werte eines MiscLookup-Schlüssels mit Rückfall auf Standardwerte
+func lookupValues(key string, gameSystemID uint) []string {
+	items, err := gsmaster.GetMiscLookupByKeyForSystem(key, gameSystemID)
+	if err != nil || len(items) == 0 {
+		return npcFallbackValues[key]
+	}
+	values := make([]string, len(items))
+	for i, item := range items {
+		values[i] = item.Value
+	}
+	return values
+}
+
+// rollRolledField würfelt ein Feld mit Würfelwurf und berechnet es wie in der Charaktererstellung
+func (g *npcGenerator) rollRolledField(field string, char *models.Char) (int, error) {
+	rolls, err := g.roll(field, char.Rasse)
+	if err != nil {
+		return 0, err
+	}
+	req := CalculateRolledFieldRequest{
+		St: char.GetAttributeValue("St"), Gs: char.GetAttributeValue("Gs"), Gw: char.GetAttributeValue("Gw"),
+		Ko: char.GetAttributeValue("Ko"), In: char.GetAttributeValue("In"), Zt: char.GetAttributeValue("Zt"),
+		Au:    char.GetAttributeValue("Au"),
+		Rasse: char.Rasse,
+		Typ:   char.Typ,
+		Field: field,
+	}
+	if field == "b_max" {
+		values := make([]interface{}, len(rolls))
+		for i, r := range rolls {
+			values[i] = float64(r)
+		}
+		req.Roll = values
+	} else {
+		total := 0
+		for _, r := range rolls {
+			total += r
+		}
+		req.Roll = float64(total)
+	}
+	result, err := calculateRolledField(req)
+	if err != nil {
+		return 0, err
+	}
+	return result.Value, nil
+}
+
+// rollSpecialAbilities würfelt die besondere Fähigkeit (W100)
+// Bei 100 wird frei gewählt und ein zweites Mal gewürfelt; für NSCs werden beide Fähigkeiten ausgewürfelt.
+func (g *npcGenerator) rollSpecialAbilities(wk int) []models.SkFertigkeit {
+	rolls := []int{g.rng.Intn(100) + 1}
+	if rolls[0] == 100 {
+		rolls = []int{g.rng.Intn(99) + 1, g.rng.Intn(99) + 1}
+	}
+
+	var abilities []models.CharacterCreationAbility
+	for _, roll := range rolls {
+		ability, err := GetSpecialAbilityByRoll(roll)
+		if err != nil {
+			continue
+		}
+		abilities = append(abilities, models.CharacterCreationAbility{Name: ability.Name, Modifier: ability.Modifier, Roll: roll})
+	}
+	return specialAbilitySkills(abilities, wk)
+}
+
+// hasSkill prüft, ob der Charakter eine Fertigkeit oder Waffenfertigkeit bereits beherrscht
+func hasSkill(char *models.Char, name string) bool {
+	for _, skill := range char.Fertigkeiten {
+		if skill.Name == name {
+			return true
+		}
+	}
+	for _, skill := range char.Waffenfertigkeiten {
+		if skill.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// addCreationSkill fügt eine Fertigkeit mit ihrem Initialwert hinzu (wie FinalizeCharacterCreation)
+func addCreationSkill(char *models.Char, name, category string, isWeapon bool) {
+	skill := models.SkFertigkeit{
+		BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: name}},
+		Improvable:      true,
+		Category:        category,
+	}
+	if isWeapon {
+		var master models.WeaponSkill
+		if err := master.First(name); err == nil {
+			skill.Fertigkeitswert = master.Initialwert
+		}
+		char.Waffenfertigkeiten = append(char.Waffenfertigkeiten, models.SkWaffenfertigkeit{SkFertigkeit: skill})
+		return
+	}
+	var master models.Skill
+	if err := master.First(name); err == nil {
+		skill.Fertigkeitswert = master.Initialwert
+	}
+	char.Fertigkeiten = append(char.Fertigkeiten, skill)
+}
+
+// spendLearningPoints verteilt die Lernpunkte je Kategorie
+// Typische Fertigkeiten der Klasse werden bevorzugt, der Rest wird zufällig gewählt.
+func (g *npcGenerator) spendLearningPoints(char *models.Char, data *LearningPointsData) error {
+	skillsByCategory, err := GetAllSkillsWithLE(g.filter)
+	if err != nil {
+		return fmt.Errorf("failed to load skills: %w", err)
+	}
+	typical := map[string]bool{}
+	for _, ts := range data.TypicalSkills {
+		typical[ts.Name] = true
+	}
+
+	categories := make([]string, 0, len(data.LearningPoints))
+	for category := range data.LearningPoints {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+
+	for _, category := range categories {
+		points := data.LearningPoints[category]
+		candidates := skillsByCategory[category]
+		for points > 0 {
+			var affordable, preferred []gin.H
+			for _, candidate := range candidates {
+				name := candidate["name"].(string)
+				if hasSkill(char, name) || candidate["leCost"].(int) > points {
+					continue
+				}
+				affordable = append(affordable, candidate)
+				if typical[name] {
+					preferred = append(preferred, candidate)
+				}
+			}
+			if len(preferred) > 0 {
+				affordable = preferred
+			}
+			if len(affordable) == 0 {
+				break
+			}
+			choice := affordable[g.rng.Intn(len(affordable))]
+			_, isWeapon := choice["type"]
+			addCreationSkill(char, choice["name"].(string), category, isWeapon || category == "Waffen")
+			points -= choice["leCost"].(int)
+		}
+	}
+	return nil
+}
+
+// spendSpellPoints verteilt die Zauberlerneinheiten, typische Zauber zuerst
+func (g *npcGenerator) spendSpellPoints(char *models.Char, data *LearningPointsData) error {
+	if data.SpellPoints <= 0 {
+		return nil
+	}
+	spellsByCategory, err := GetAllSpellsWithLE(data.ClassCode, 2, g.filter)
+	if err != nil {
+		return fmt.Errorf("failed to load spells: %w", err)
+	}
+
+	categories := make([]string, 0, len(spellsByCategory))
+	for category := range spellsByCategory {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	var candidates []gin.H
+	for _, category := range categories {
+		candidates = append(candidates, spellsByCategory[category]...)
+	}
+
+	known := map[string]bool{}
+	points := data.SpellPoints
+	for points > 0 {
+		var affordable, preferred []gin.H
+		for _, candidate := range candidates {
+			name := candidate["name"].(string)
+			if known[name] || candidate["le_cost"].(int) > points {
+				continue
+			}
+			affordable = append(affordable, candidate)
+			if slices.Contains(data.TypicalSpells, name) {
+				preferred = append(preferred, candidate)
+			}
+		}
+		if len(preferred) > 0 {
+			affordable = preferred
+		}
+		if len(affordable) == 0 {
+			break
+		}
+		choice := affordable[g.rng.Intn(len(affordable))]
+		name := choice["name"].(string)
+		known[name] = true
+		char.Zauber = append(char.Zauber, models.SkZauber{
+			BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: name}},
+		})
+		points -= choice["le_cost"].(int)
+	}
+	return nil
+}
+
+// npcStep ist eine mögliche Steigerung beim Aufstieg eines NSC
+type npcStep struct {
+	cost  int
+	apply func()
+}
+
+// npcMaxSpellLevel ist die höchste Zauberstufe, die ein NSC beim Aufstieg lernt
+// Wie bei der Erschaffung mindestens Stufe 2, danach eine Stufe je zwei Grade.
+func npcMaxSpellLevel(grad int) int {
+	return max(2, (grad+1)/2)
+}
+
+// advanceToGrade steigert Fertigkeiten und Waffenfertigkeiten und lernt Zauber zufällig mit dem Erfahrungsschatz des Zielgrades
+// Die Kosten stammen aus den Lernkostentabellen (TE je Stufe × EP je TE bzw. LE × EP je LE der Klasse).
+// Nicht verbrauchte EP bleiben als Erfahrungspunkte erhalten.
+func (g *npcGenerator) advanceToGrade(char *models.Char, grad int, classCode string) {
+	es := esForGrade(getGradeRules(g.gs), grad)
+	char.Grad = grad
+	char.Erfahrungsschatz.ES = es
+	budget := es
+
+	var spells []gin.H
+	if spellsByCategory, err := GetAllSpellsWithLE(classCode, npcMaxSpellLevel(grad), g.filter); err == nil {
+		categories := make([]string, 0, len(spellsByCategory))
+		for category := range spellsByCategory {
+			categories = append(categories, category)
+		}
+		sort.Strings(categories)
+		for _, category := range categories {
+			spells = append(spells, spellsByCategory[category]...)
+		}
+	}
+
+	skillInfos := map[string]*models.SkillLearningInfo{}
+	spellInfos := map[string]*models.SpellLearningInfo{}
+	for budget > 0 {
+		var options []npcStep
+		for i := range char.Fertigkeiten {
+			if step, ok := g.improveStep(char, &char.Fertigkeiten[i], "skill", classCode, skillInfos, budget); ok {
+				options = append(options, step)
+			}
+		}
+		for i := range char.Waffenfertigkeiten {
+			if step, ok := g.improveStep(char, &char.Waffenfertigkeiten[i].SkFertigkeit, "weapon", classCode, skillInfos, budget); ok {
+				options = append(options, step)
+			}
+		}
+		for _, candidate := range spells {
+			if step, ok := g.learnSpellStep(char, candidate["name"].(string), classCode, spellInfos, budget); ok {
+				options = append(options, step)
+			}
+		}
+		if len(options) == 0 {
+			break
+		}
+		choice := options[g.rng.Intn(len(options))]
+		choice.apply()
+		budget -= choice.cost
+	}
+	char.Erfahrungsschatz.EP = budget
+}
+
+// maxSkillValue liefert den Höchstwert für Fertigkeiten bzw. Waffenfertigkeiten im Spielsystem
+func (g *npcGenerator) maxSkillValue(skillType string) int {
+	if skillType == "weapon" {
+		return g.rules.MaxWeaponSkillValue
+	}
+	return g.rules.MaxSkillValue
+}
+
+// improveStep berechnet die nächste Steigerung einer Fertigkeit oder Waffenfertigkeit, falls sie ins Budget passt
+func (g *npcGenerator) improveStep(char *models.Char, skill *models.SkFertigkeit, skillType, classCode string, infos map[string]*models.SkillLearningInfo, budget int) (npcStep, bool) {
+	if !skill.Improvable || skill.Fertigkeitswert >= g.maxSkillValue(skillType) {
+		return npcStep{}, false
+	}
+	key := skillType + ":" + skill.Name
+	info, ok := infos[key]
+	if !ok {
+		info, _ = skillLearningInfo(char, skill.Name, skillType, classCode)
+		infos[key] = info
+	}
+	if info == nil {
+		return npcStep{}, false
+	}
+	var result gsmaster.SkillCostResultNew
+	result.CharacterClass = classCode
+	noPP, noGold := 0, 0
+	request := gsmaster.LernCostRequest{Name: skill.Name, Type: skillType, Action: "improve", CurrentLevel: skill.Fertigkeitswert}
+	if err := CalculateSkillImproveCostNewSystem(&request, &result, skill.Fertigkeitswert+1, &noPP, &noGold, info); err != nil {
+		return npcStep{}, false
+	}
+	if result.EP <= 0 || result.EP > budget {
+		return npcStep{}, false
+	}
+	return npcStep{cost: result.EP, apply: func() { skill.Fertigkeitswert++ }}, true
+}
+
+// learnSpellStep berechnet die Lernkosten eines noch unbekannten Zaubers, falls sie ins Budget passen
+func (g *npcGenerator) learnSpellStep(char *models.Char, name, classCode string, infos map[string]*models.SpellLearningInfo, budget int) (npcStep, bool) {
+	for _, known := range char.Zauber {
+		if known.Name == name {
+			return npcStep{}, false
+		}
+	}
+	info, ok := infos[name]
+	if !ok {
+		info, _ = models.GetSpellLearningInfoNewSystem(name, classCode, models.CharacterContentScope(char))
+		infos[name] = info
+	}
+	if info == nil {
+		return npcStep{}, false
+	}
+	var result gsmaster.SkillCostResultNew
+	result.CharacterClass = classCode
+	noPP, noGold := 0, 0
+	request := gsmaster.LernCostRequest{Name: name, Type: "spell", Action: "learn"}
+	if err := calculateSpellLearnCostNewSystem(&request, &result, &noPP, &noGold, info); err != nil {
+		return npcStep{}, false
+	}
+	if result.EP <= 0 || result.EP > budget {
+		return npcStep{}, false
+	}
+	return npcStep{cost: result.EP, apply: func() {
+		char.Zauber = append(char.Zauber, models.SkZauber{
+			BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: name}},
+		})
+	}}, true
+}
+
+// GenerateNPC erzeugt einen vollständigen Charakter nach den Erstellungsregeln der Klasse
+// Alle Zufallswerte stammen aus dem angegebenen Seed, gleiche Eingaben liefern denselben NSC.
+func GenerateNPC(req NPCRequest, seed int64, userID uint) (*models.Char, error) {
+	gs := models.GetGameSystem(0, "midgard")
+	if gs == nil {
+		gs = &models.GameSystem{Name: "midgard"}
+	}
+	g := &npcGenerator{rng: rand.New(rand.NewSource(seed)), gs: gs, rules: getConsistencyRules(gs)}
+
+	stand := req.Stand
+	if stand == "" {
+		stand = g.pick(lookupValues("social_classes", gs.ID))
+	}
+	data, err := getLearningPointsForClass(req.Typ, stand)
+	if err != nil {
+		return nil, err
+	}
+	if g.filter, err = creationLearnableFilter(userID, req.CampaignID); err != nil {
+		return nil, err
+	}
+
+	char := &models.Char{
+		BamortBase:  models.BamortBase{Name: req.Name},
+		UserID:      userID,
+		Rasse:       req.Rasse,
+		Typ:         data.ClassName,
+		Gender:      req.Gender,
+		SocialClass: stand,
+		Herkunft:    req.Herkunft,
+		Glaube:      req.Glaube,
+		Grad:        1,
+		CampaignID:  req.CampaignID,
+		Vermoegen:   models.Vermoegen{Goldstuecke: 80},
+	}
+	if char.Gender == "" {
+		char.Gender = g.pick(lookupValues("gender", gs.ID))
+	}
+	if char.Herkunft == "" {
+		char.Herkunft = g.pick(lookupValues("origins", gs.ID))
+	}
+	if char.Glaube == "" {
+		if believes, err := models.GetBelievesByActiveSources(gs.Name, models.CharacterSourceSelector(char)); err == nil && len(believes) > 0 {
+			char.Glaube = believes[g.rng.Intn(len(believes))].Name
+		} else {
+			char.Glaube = g.pick(lookupValues("faiths", gs.ID))
+		}
+	}
+	if char.Name == "" {
+		char.Name = fmt.Sprintf("%s %s (NSC %d)", req.Rasse, data.ClassName, seed%10000)
+	}
+
+	// Grundeigenschaften
+	rules := g.rules
+	for _, name := range rules.Attributes {
+		rolls, err := g.roll(strings.ToLower(name), char.Rasse)
+		if err != nil {
+			return nil, err
+		}
+		char.Eigenschaften = append(char.Eigenschaften, models.Eigenschaft{Name: name, Value: rolls[0]})
+	}
+
+	// Gewürfelte abgeleitete Werte
+	rolled := map[string]int{}
+	for _, field := range []string{"pa", "wk", "lp_max", "ap_max", "b_max"} {
+		value, err := g.rollRolledField(field, char)
+		if err != nil {
+			return nil, err
+		}
+		rolled[field] = value
+	}
+	char.Eigenschaften = append(char.Eigenschaften,
+		models.Eigenschaft{Name: "pA", Value: min(max(rolled["pa"], rules.MinAttribute), rules.MaxAttribute)},
+		models.Eigenschaft{Name: "Wk", Value: min(max(rolled["wk"], rules.MinAttribute), rules.MaxAttribute)},
+	)
+	char.Lp = models.Lp{Max: rolled["lp_max"], Value: rolled["lp_max"]}
+	char.Ap = models.Ap{Max: rolled["ap_max"], Value: rolled["ap_max"]}
+	char.B = models.B{Max: rolled["b_max"], Value: rolled["b_max"]}
+
+	// Fertigkeiten, Zauber und besondere Fähigkeit
+	if err := g.spendLearningPoints(char, data); err != nil {
+		return nil, err
+	}
+	if err := g.spendSpellPoints(char, data); err != nil {
+		return nil, err
+	}
+	char.Fertigkeiten = append(char.Fertigkeiten, g.rollSpecialAbilities(char.GetAttributeValue("Wk"))...)
+
+	if req.Grad > 1 {
+		g.advanceToGrade(char, req.Grad, data.ClassCode)
+	}
+
+	// Statische Werte zuletzt, da sie vom Grad abhängen
+	static := CalculateStaticFieldsForCharacter(char)
+	char.ResistenzKoerper = static.ResistenzKoerper
+	char.ResistenzGeist = static.ResistenzGeist
+	char.Abwehr = static.Abwehr
+	char.Zaubern = static.Zaubern
+	char.Raufen = static.Raufen
+
+	setCharacterOwner(char, userID)
+	return char, nil
+}
+
+// setCharacterOwner trägt den Besitzer in alle Unterelemente eines neuen Charakters ein
+func setCharacterOwner(char *models.Char, userID uint) {
+	char.UserID = userID
+	char.Vermoegen.UserID = userID
+	char.Bennies.UserID = userID
+	char.Erfahrungsschatz.UserID = userID
+	char.Merkmale.UserID = userID
+	for i := range char.Eigenschaften {
+		char.Eigenschaften[i].UserID = userID
+	}
+	for i := range char.Fertigkeiten {
+		char.Fertigkeiten[i].UserID = userID
+	}
+	for i := range char.Waffenfertigkeiten {
+		char.Waffenfertigkeiten[i].UserID = userID
+	}
+	for i := range char.Zauber {
+		char.Zauber[i].UserID = userID
+	}
+}
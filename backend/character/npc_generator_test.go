@@ -0,0 +1,142 @@
+package character
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateNPC(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+	database.DB.Exec("INSERT OR IGNORE INTO game_systems(code,name,description,is_active,created_at,modified_at) VALUES (?,?,?,?,strftime('%s','now'),strftime('%s','now'))", "M5", "midgard", "", true)
+
+	req := NPCRequest{Rasse: "Mensch", Typ: "Krieger", Stand: "Volk"}
+	first, err := GenerateNPC(req, 42, 1)
+	require.NoError(t, err)
+	second, err := GenerateNPC(req, 42, 1)
+	require.NoError(t, err)
+
+	t.Run("reproducible with same seed", func(t *testing.T) {
+		assert.Equal(t, first.Eigenschaften, second.Eigenschaften)
+		assert.Equal(t, first.Fertigkeiten, second.Fertigkeiten)
+		assert.Equal(t, first.Waffenfertigkeiten, second.Waffenfertigkeiten)
+		assert.Equal(t, first.Herkunft, second.Herkunft)
+		assert.Equal(t, first.Glaube, second.Glaube)
+	})
+
+	t.Run("complete and valid character", func(t *testing.T) {
+		assert.Equal(t, "Krieger", first.Typ)
+		assert.Equal(t, 1, first.Grad)
+		assert.NotEmpty(t, first.Name)
+		assert.NotEmpty(t, first.Herkunft)
+		assert.Len(t, first.Eigenschaften, 9)
+		assert.Positive(t, first.Lp.Max)
+		assert.Positive(t, first.Ap.Max)
+		assert.Positive(t, first.B.Max)
+		assert.NotEmpty(t, first.Fertigkeiten)
+		assert.NotEmpty(t, first.Waffenfertigkeiten, "Krieger should learn weapon skills")
+		for _, attr := range first.Eigenschaften {
+			assert.GreaterOrEqual(t, attr.Value, 1, attr.Name)
+			assert.LessOrEqual(t, attr.Value, 100, attr.Name)
+		}
+		var innate int
+		for _, skill := range first.Fertigkeiten {
+			if !skill.Improvable {
+				innate++
+			}
+		}
+		assert.GreaterOrEqual(t, innate, 1, "special ability should be stored as innate skill")
+
+		static := CalculateStaticFieldsForCharacter(first)
+		assert.Equal(t, static.Abwehr, first.Abwehr)
+		assert.Equal(t, static.ResistenzGeist, first.ResistenzGeist)
+	})
+
+	t.Run("different seed gives different attributes", func(t *testing.T) {
+		other, err := GenerateNPC(req, 43, 1)
+		require.NoError(t, err)
+		assert.NotEqual(t, first.Eigenschaften, other.Eigenschaften)
+	})
+
+	t.Run("advanced to grade spends experience", func(t *testing.T) {
+		// Die vorbereitete Testdatenbank enthält keine Steigerungskosten
+		require.NoError(t, database.DB.Exec(`INSERT INTO skill_improvement_cost2 (current_level, te_required, skill_category_id, skill_difficulty_id)
+			SELECT lvl.n, 2, c.id, d.id FROM learning_skill_categories c, learning_skill_difficulties d,
+			(SELECT 6 AS n UNION SELECT 7 UNION SELECT 8 UNION SELECT 9 UNION SELECT 10 UNION SELECT 11 UNION SELECT 12 UNION SELECT 13 UNION SELECT 14 UNION SELECT 15 UNION SELECT 16 UNION SELECT 17 UNION SELECT 18) lvl`).Error)
+
+		advanced, err := GenerateNPC(NPCRequest{Rasse: "Mensch", Typ: "Krieger", Stand: "Volk", Grad: 5}, 42, 1)
+		require.NoError(t, err)
+		assert.Equal(t, 5, advanced.Grad)
+		assert.Equal(t, esForGrade(defaultGradeRules, 5), advanced.Erfahrungsschatz.ES)
+		assert.Less(t, advanced.Erfahrungsschatz.EP, advanced.Erfahrungsschatz.ES, "experience should be spent on skills")
+		assert.Equal(t, getAbwehrBaseByGrade(5)+CalculateStaticFieldsForCharacter(advanced).AbwehrBonus, advanced.Abwehr)
+		for _, skill := range advanced.Fertigkeiten {
+			assert.LessOrEqual(t, skill.Fertigkeitswert, defaultConsistencyRules.MaxSkillValue, skill.Name)
+		}
+
+		// Gleicher Seed: bis zum Aufstieg identisch mit dem NSC auf Grad 1
+		weaponTotal := func(char *models.Char) (total int) {
+			for _, skill := range char.Waffenfertigkeiten {
+				total += skill.Fertigkeitswert
+			}
+			return total
+		}
+		assert.Greater(t, weaponTotal(advanced), weaponTotal(first), "experience should also be spent on weapon skills")
+	})
+
+	t.Run("casters learn spells when advancing", func(t *testing.T) {
+		mage := NPCRequest{Rasse: "Mensch", Typ: "Magier", Stand: "Volk"}
+		base, err := GenerateNPC(mage, 7, 1)
+		require.NoError(t, err)
+		mage.Grad = 8
+		advanced, err := GenerateNPC(mage, 7, 1)
+		require.NoError(t, err)
+		assert.Greater(t, len(advanced.Zauber), len(base.Zauber), "experience should also be spent on spells")
+	})
+
+	t.Run("unknown class", func(t *testing.T) {
+		_, err := GenerateNPC(NPCRequest{Rasse: "Mensch", Typ: "Unbekannt"}, 1, 1)
+		assert.Error(t, err)
+	})
+}
+
+func TestGenerateNPCHandler(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+
+	owner := ensureUserExists(t, 233)
+	body := map[string]any{"rasse": "Zwerg", "typ": "Kr", "name": "Grimbart", "seed": 7, "save": true}
+	ctx, w := buildJSONContext(t, http.MethodPost, body, owner.UserID, nil)
+	GenerateNPCHandler(ctx)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	var resp struct {
+		Seed      int64       `json:"seed"`
+		Character models.Char `json:"character"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	assert.Equal(t, int64(7), resp.Seed)
+	require.NotZero(t, resp.Character.ID)
+
+	var saved models.Char
+	require.NoError(t, saved.FirstID(fmt.Sprint(resp.Character.ID)))
+	assert.Equal(t, "Grimbart", saved.Name)
+	assert.Equal(t, owner.UserID, saved.UserID)
+	assert.Len(t, saved.Eigenschaften, 9)
+	assert.Equal(t, len(resp.Character.Fertigkeiten), len(saved.Fertigkeiten))
+}
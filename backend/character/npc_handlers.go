@@ -0,0 +1,44 @@
+package character
+
+import (
+	"bamort/logger"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GenerateNPCHandler erzeugt einen zufälligen NSC
+// Ohne "save" wird nur eine Vorschau zurückgegeben, der Seed erlaubt die spätere Wiederholung.
+func GenerateNPCHandler(c *gin.Context) {
+	var req NPCRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !checkCampaignMembership(c, req.CampaignID) {
+		return
+	}
+	seed := diceSeedSource()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+
+	userID := c.GetUint("userID")
+	char, err := GenerateNPC(req, seed, userID)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if !req.Save {
+		c.JSON(http.StatusOK, gin.H{"seed": seed, "character": char})
+		return
+	}
+	if err := char.Create(); err != nil {
+		logger.Error("Fehler beim Speichern des NSC '%s': %s", char.Name, err.Error())
+		respondWithError(c, http.StatusInternalServerError, "Failed to save NPC")
+		return
+	}
+	logger.Info("NSC '%s' (ID %d, Grad %d) für Benutzer %d erzeugt (Seed %d)", char.Name, char.ID, char.Grad, userID, seed)
+	c.JSON(http.StatusCreated, gin.H{"seed": seed, "character": char})
+}
@@ -217,6 +217,46 @@ func TestOwnershipGuardsBlockMutations(t *testing.T) {
 		reloaded := reloadCharacter(t, char.ID)
 		require.Equal(t, "initial.png", reloaded.Image)
 	})
+
+	t.Run("UpdateCharacter keeps fields with their own endpoints", func(t *testing.T) {
+		owner := ensureUserExists(t, 114)
+		char := createCharacterOwnedBy(t, owner.UserID)
+		birthDay := 100
+		char.CampaignID, char.CalendarID, char.BirthDay = 3, 4, &birthDay
+		char.Image = "stored.png"
+		char.Spezialisierung = database.StringArray{"Langschwert"}
+		require.NoError(t, database.DB.Save(&char).Error)
+
+		body := map[string]any{"name": "Neuer Name", "campaign_id": 99, "calendar_id": 98, "birth_day": 1, "image": "data:image/png;base64,AAAA", "spezialisierung": []string{"Bogen"}}
+		ctx, w := buildJSONContext(t, http.MethodPut, body, owner.UserID, map[string]string{"id": fmt.Sprint(char.ID)})
+		UpdateCharacter(ctx)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		reloaded := reloadCharacter(t, char.ID)
+		require.Equal(t, "Neuer Name", reloaded.Name)
+		require.EqualValues(t, 3, reloaded.CampaignID)
+		require.EqualValues(t, 4, reloaded.CalendarID)
+		require.Equal(t, &birthDay, reloaded.BirthDay)
+		require.Equal(t, "stored.png", reloaded.Image)
+		require.Equal(t, database.StringArray{"Langschwert"}, reloaded.Spezialisierung)
+	})
+
+	t.Run("CreateCharacter ignores fields with their own endpoints", func(t *testing.T) {
+		owner := ensureUserExists(t, 115)
+		body := map[string]any{"name": "Frisch", "user_id": owner.UserID, "campaign_id": 99, "calendar_id": 98, "birth_day": 1, "image": "data:image/png;base64,AAAA", "spezialisierung": []string{"Bogen"}}
+		ctx, w := buildJSONContext(t, http.MethodPost, body, owner.UserID, nil)
+		CreateCharacter(ctx)
+
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+		var created models.Char
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+		reloaded := reloadCharacter(t, created.ID)
+		require.Zero(t, reloaded.CampaignID)
+		require.Zero(t, reloaded.CalendarID)
+		require.Nil(t, reloaded.BirthDay)
+		require.Empty(t, reloaded.Image)
+		require.Empty(t, reloaded.Spezialisierung)
+	})
 }
 
 func ensureUserExists(t *testing.T, id uint) user.User {
@@ -0,0 +1,202 @@
+package character
+
+import (
+	"bamort/database"
+	"bamort/gsmaster"
+	"bamort/models"
+	"fmt"
+	"sort"
+)
+
+// maxPPConversionLevels begrenzt die Vorschau, falls die Kostentabelle keinen Endwert liefert
+const maxPPConversionLevels = 20
+
+// Arten von PP-Umwandlungen
+const (
+	PPConversionSkill       = "skill"        // Fertigkeit verbessern
+	PPConversionWeapon      = "weapon"       // Waffenfertigkeit verbessern
+	PPConversionSpellSchool = "spell_school" // Zauber der Zauberschule lernen
+)
+
+// PPConversionStep ist eine Verbesserung um einen Wert oder ein neu gelernter Zauber
+type PPConversionStep struct {
+	TargetLevel int    `json:"target_level,omitempty"`
+	Spell       string `json:"spell,omitempty"`
+	SpellLevel  int    `json:"spell_level,omitempty"`
+	PPUsed      int    `json:"pp_used"`
+	LE          int    `json:"le"`   // nach Abzug der PP verbleibende TE bzw. LE (bestimmen die Lernzeit)
+	EP          int    `json:"ep"`   // zusätzlich benötigte EP
+	Gold        int    `json:"gold"` // zusätzlich benötigtes Gold
+	PPOnly      bool   `json:"pp_only"`
+	Affordable  bool   `json:"affordable"` // mit den aktuellen EP und Gold bezahlbar (bei Fertigkeiten kumuliert)
+}
+
+// PPConversionCandidate beschreibt, was mit den PP einer Fertigkeit oder Zauberschule möglich ist
+// Bei Fertigkeiten bauen die Schritte aufeinander auf, bei Zauberschulen ist jeder Zauber eine Alternative.
+type PPConversionCandidate struct {
+	Name         string             `json:"name"`
+	Type         string             `json:"type"`
+	CurrentLevel int                `json:"current_level,omitempty"`
+	PP           int                `json:"pp"`
+	PPOnlyLevels int                `json:"pp_only_levels"` // Werte bzw. Zauber allein durch PP
+	WithEPLevels int                `json:"with_ep_levels"` // Werte bzw. Zauber mit PP und EP
+	Steps        []PPConversionStep `json:"steps"`
+	Error        string             `json:"error,omitempty"`
+}
+
+// characterClassCode liefert die Klassenabkürzung für die Lernkostentabellen
+func characterClassCode(char *models.Char) string {
+	if len(char.Typ) > 3 {
+		return gsmaster.GetClassAbbreviationNewSystem(char.Typ)
+	}
+	return char.Typ
+}
+
+// isSpellSchool prüft, ob es Zauber der Zauberschule gibt
+// PP für Zauber werden auf einer Fertigkeit mit dem Namen der Schule gesammelt.
+func isSpellSchool(name string) bool {
+	var count int64
+	database.DB.Model(&models.Spell{}).Where("category = ?", name).Count(&count)
+	return count > 0
+}
+
+// skillImprovementSteps berechnet, um wie viele Werte eine Fertigkeit mit ihren PP steigen kann
+// Es wird nur so weit gerechnet, wie PP zu jedem Schritt beitragen.
+func skillImprovementSteps(char *models.Char, name string, currentLevel, pp int) ([]PPConversionStep, error) {
+	classCode := characterClassCode(char)
+	skillInfo, err := models.GetSkillCategoryAndDifficultyNewSystem(name, classCode, models.CharacterContentScope(char))
+	if err != nil {
+		return []PPConversionStep{}, fmt.Errorf("fertigkeit '%s' nicht für Klasse '%s' verfügbar", name, classCode)
+	}
+
+	steps := []PPConversionStep{}
+	remainingPP := pp
+	noGold := 0
+	totalEP, totalGold := 0, 0
+	for level := currentLevel + 1; remainingPP > 0 && len(steps) < maxPPConversionLevels; level++ {
+		request := gsmaster.LernCostRequest{CharId: char.ID, Name: name, Type: "skill", Action: "improve", CurrentLevel: level - 1, TargetLevel: level}
+		result := gsmaster.SkillCostResultNew{CharacterID: fmt.Sprint(char.ID), CharacterClass: classCode, SkillName: name}
+		if err := CalculateSkillImproveCostNewSystem(&request, &result, level, &remainingPP, &noGold, skillInfo); err != nil {
+			break // höchster Wert erreicht
+		}
+		if result.PPUsed == 0 && result.LE == 0 {
+			break // keine Kosten für diesen Wert hinterlegt
+		}
+		totalEP += result.EP
+		totalGold += result.GoldCost
+		steps = append(steps, PPConversionStep{
+			TargetLevel: level,
+			PPUsed:      result.PPUsed,
+			LE:          result.LE,
+			EP:          result.EP,
+			Gold:        result.GoldCost,
+			PPOnly:      result.EP == 0 && result.GoldCost == 0,
+			Affordable:  totalEP <= char.Erfahrungsschatz.EP && totalGold <= char.Vermoegen.Goldstuecke,
+		})
+	}
+	return steps, nil
+}
+
+// spellSchoolSteps listet die Zauber einer Schule, deren LE die PP der Schule ganz oder teilweise decken
+// Angeboten werden nur Zauber aus der Quellenauswahl und dem Geltungsbereich des Charakters.
+// Wie beim Lernen von Zaubern entspricht 1 PP einer LE, die übrigen LE kosten nur EP.
+func spellSchoolSteps(char *models.Char, school string, pp int) []PPConversionStep {
+	classCode := characterClassCode(char)
+	steps := []PPConversionStep{}
+	filter, err := characterLearnableFilter(char)
+	if err != nil {
+		return steps
+	}
+	var spells []models.Spell
+	database.DB.Where("category = ?", school).Order("stufe ASC, name ASC").Find(&spells)
+
+	for _, spell := range spells {
+		if hasSpell(char, spell.Name) || !filter.allows(spell.SourceID, spell.HomebrewScope) {
+			continue
+		}
+		info, err := models.GetSpellLearningInfoNewSystem(spell.Name, classCode, models.CharacterContentScope(char))
+		if err != nil || info.LERequired <= 0 {
+			continue
+		}
+		ppUsed := min(pp, info.LERequired)
+		ep := (info.LERequired - ppUsed) * info.EPPerLE
+		if ep > char.Erfahrungsschatz.EP {
+			continue
+		}
+		steps = append(steps, PPConversionStep{
+			Spell:      spell.Name,
+			SpellLevel: info.SpellLevel,
+			PPUsed:     ppUsed,
+			LE:         info.LERequired - ppUsed,
+			EP:         ep,
+			PPOnly:     ep == 0,
+			Affordable: true,
+		})
+	}
+	sort.SliceStable(steps, func(i, j int) bool { return steps[i].EP < steps[j].EP })
+	return steps
+}
+
+// hasSpell prüft, ob der Charakter den Zauber bereits beherrscht
+func hasSpell(char *models.Char, name string) bool {
+	for _, spell := range char.Zauber {
+		if spell.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// countPPConversionSteps zählt die Schritte allein mit PP und die mit EP bezahlbaren Schritte
+// Bei Fertigkeiten werden EP erst nötig, wenn die PP aufgebraucht sind, daher bilden beide Zählungen
+// einen zusammenhängenden Anfang der Schritte.
+func countPPConversionSteps(candidate *PPConversionCandidate) {
+	for _, step := range candidate.Steps {
+		if step.PPOnly {
+			candidate.PPOnlyLevels++
+		}
+		if step.Affordable {
+			candidate.WithEPLevels++
+		}
+	}
+}
+
+// PPConversionCandidates ermittelt für alle Fertigkeiten und Zauberschulen mit PP die möglichen Umwandlungen
+// Jede Fertigkeit wird für sich mit den gesamten EP und dem Gold des Charakters bewertet.
+func PPConversionCandidates(char *models.Char) []PPConversionCandidate {
+	candidates := []PPConversionCandidate{}
+	for _, skill := range char.Fertigkeiten {
+		if skill.Pp <= 0 {
+			continue
+		}
+		candidate := PPConversionCandidate{Name: skill.Name, PP: skill.Pp}
+		if isSpellSchool(skill.Name) {
+			candidate.Type = PPConversionSpellSchool
+			candidate.Steps = spellSchoolSteps(char, skill.Name, skill.Pp)
+		} else {
+			candidate.Type = PPConversionSkill
+			candidate.CurrentLevel = skill.Fertigkeitswert
+			steps, err := skillImprovementSteps(char, skill.Name, skill.Fertigkeitswert, skill.Pp)
+			if err != nil {
+				candidate.Error = err.Error()
+			}
+			candidate.Steps = steps
+		}
+		countPPConversionSteps(&candidate)
+		candidates = append(candidates, candidate)
+	}
+	for _, skill := range char.Waffenfertigkeiten {
+		if skill.Pp <= 0 {
+			continue
+		}
+		candidate := PPConversionCandidate{Name: skill.Name, Type: PPConversionWeapon, PP: skill.Pp, CurrentLevel: skill.Fertigkeitswert}
+		steps, err := skillImprovementSteps(char, skill.Name, skill.Fertigkeitswert, skill.Pp)
+		if err != nil {
+			candidate.Error = err.Error()
+		}
+		candidate.Steps = steps
+		countPPConversionSteps(&candidate)
+		candidates = append(candidates, candidate)
+	}
+	return candidates
+}
@@ -0,0 +1,290 @@
+package character
+
+import (
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// PPConversion wählt eine Umwandlung aus der Vorschau
+type PPConversion struct {
+	Name   string `json:"name" binding:"required"` // Fertigkeit, Waffenfertigkeit oder Zauberschule
+	Levels int    `json:"levels,omitempty"`        // Anzahl der Verbesserungen, Standard 1
+	Spell  string `json:"spell,omitempty"`         // zu lernender Zauber (nur bei Zauberschulen)
+	PPOnly bool   `json:"pp_only,omitempty"`       // ablehnen, falls EP oder Gold nötig sind
+}
+
+// ApplyPPConversionsRequest enthält die gewählten Umwandlungen
+type ApplyPPConversionsRequest struct {
+	Conversions   []PPConversion `json:"conversions" binding:"required,min=1,dive"`
+	Notes         string         `json:"notes,omitempty"`
+	CheckDowntime bool           `json:"check_downtime,omitempty"` // Lernzeit gegen das Ausfallzeit-Konto prüfen und buchen
+}
+
+// AppliedPPConversion beschreibt eine durchgeführte Umwandlung
+type AppliedPPConversion struct {
+	Name         string `json:"name"`
+	Type         string `json:"type"`
+	FromLevel    int    `json:"from_level,omitempty"`
+	ToLevel      int    `json:"to_level,omitempty"`
+	Spell        string `json:"spell,omitempty"`
+	PPUsed       int    `json:"pp_used"`
+	EP           int    `json:"ep"`
+	Gold         int    `json:"gold"`
+	TrainingDays int    `json:"training_days"` // Lernzeit für die nicht durch PP gedeckten TE/LE
+}
+
+// loadCharacterWithResources lädt den Charakter aus der URL mit Fertigkeiten, Zaubern, EP und Vermögen
+// (für PP-Umwandlung und Steigerungspläne)
+func loadCharacterWithResources(c *gin.Context) (*models.Char, bool) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "Ungültige Charakter-ID")
+		return nil, false
+	}
+	char, err := loadCharacterForImprovement(uint(id))
+	if err != nil {
+		respondWithError(c, http.StatusNotFound, "Charakter nicht gefunden")
+		return nil, false
+	}
+	return char, true
+}
+
+// planPPConversion wählt die Schritte einer Umwandlung aus der Vorschau
+// Die PP einer Zauberschule können auf mehrere Zauber verteilt werden, usedPP sind die bereits verplanten.
+func planPPConversion(char *models.Char, candidates []PPConversionCandidate, conv PPConversion, usedPP int, rules TrainingTimeRules) (AppliedPPConversion, error) {
+	idx := slices.IndexFunc(candidates, func(c PPConversionCandidate) bool { return c.Name == conv.Name })
+	if idx < 0 {
+		return AppliedPPConversion{}, fmt.Errorf("keine Praxispunkte für '%s' vorhanden", conv.Name)
+	}
+	candidate := candidates[idx]
+	if candidate.Error != "" {
+		return AppliedPPConversion{}, fmt.Errorf("%s", candidate.Error)
+	}
+
+	applied := AppliedPPConversion{Name: candidate.Name, Type: candidate.Type}
+	var steps []PPConversionStep
+	if candidate.Type == PPConversionSpellSchool {
+		if usedPP > 0 {
+			if candidate.PP <= usedPP {
+				return applied, fmt.Errorf("keine Praxispunkte für '%s' mehr übrig", conv.Name)
+			}
+			candidate.Steps = spellSchoolSteps(char, candidate.Name, candidate.PP-usedPP)
+		}
+		stepIdx := slices.IndexFunc(candidate.Steps, func(s PPConversionStep) bool { return s.Spell == conv.Spell })
+		if conv.Spell == "" || stepIdx < 0 {
+			return applied, fmt.Errorf("zauber '%s' kann mit den PP von '%s' nicht gelernt werden", conv.Spell, conv.Name)
+		}
+		steps = candidate.Steps[stepIdx : stepIdx+1]
+		applied.Spell = conv.Spell
+	} else {
+		levels := max(conv.Levels, 1)
+		if levels > len(candidate.Steps) {
+			return applied, fmt.Errorf("mit den PP von '%s' sind höchstens %d Verbesserungen möglich", conv.Name, len(candidate.Steps))
+		}
+		steps = candidate.Steps[:levels]
+		applied.FromLevel = candidate.CurrentLevel
+		applied.ToLevel = steps[len(steps)-1].TargetLevel
+	}
+
+	daysPerLE := rules.DaysPerTE
+	if candidate.Type == PPConversionSpellSchool {
+		daysPerLE = rules.DaysPerSpellLE
+	}
+	for _, step := range steps {
+		applied.PPUsed += step.PPUsed
+		applied.EP += step.EP
+		applied.Gold += step.Gold
+		applied.TrainingDays += step.LE * daysPerLE
+	}
+	if conv.PPOnly && (applied.EP > 0 || applied.Gold > 0) {
+		return applied, fmt.Errorf("'%s' ist nicht allein mit PP möglich (%d EP, %d Gold)", conv.Name, applied.EP, applied.Gold)
+	}
+	return applied, nil
+}
+
+// findSkillForPPConversion liefert die Fertigkeit, deren PP und Wert geändert werden,
+// sowie das zu speichernde Modell (Fertigkeit oder Waffenfertigkeit)
+func findSkillForPPConversion(char *models.Char, applied AppliedPPConversion) (*models.SkFertigkeit, any) {
+	if applied.Type == PPConversionWeapon {
+		return lookupCharacterSkill(char, "weapon", applied.Name)
+	}
+	return lookupCharacterSkill(char, "skill", applied.Name)
+}
+
+// applyPPConversionTx führt eine Umwandlung innerhalb der Transaktion aus und schreibt das Audit-Log
+// Bei trackDowntime wird auch die Lernzeit im Ausfallzeit-Konto gebucht.
+func applyPPConversionTx(tx *gorm.DB, char *models.Char, applied AppliedPPConversion, trackDowntime bool, ep, gold *int, userID uint, notes string) error {
+	skill, model := findSkillForPPConversion(char, applied)
+	if skill == nil {
+		return fmt.Errorf("fertigkeit '%s' nicht gefunden", applied.Name)
+	}
+
+	var summary string
+	oldPP := skill.Pp
+	skill.Pp -= applied.PPUsed
+	if applied.Type == PPConversionSpellSchool {
+		summary = fmt.Sprintf("Zauber '%s' mit PP aus '%s' gelernt", applied.Spell, applied.Name)
+		spell := models.SkZauber{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: applied.Spell}, CharacterID: char.ID}}
+		if err := tx.Create(&spell).Error; err != nil {
+			return err
+		}
+		char.Zauber = append(char.Zauber, spell)
+	} else {
+		summary = fmt.Sprintf("Fertigkeit '%s' von %d auf %d verbessert (PP-Umwandlung)", applied.Name, applied.FromLevel, applied.ToLevel)
+		skill.Fertigkeitswert = applied.ToLevel
+	}
+	if err := tx.Model(model).Updates(map[string]any{"pp": skill.Pp, "fertigkeitswert": skill.Fertigkeitswert}).Error; err != nil {
+		return err
+	}
+	if notes != "" {
+		summary += ": " + notes
+	}
+
+	if err := CreateAuditLogEntryTx(tx, char.ID, "practice_points", oldPP, skill.Pp, ReasonPPConversion, userID, summary); err != nil {
+		return err
+	}
+	if trackDowntime {
+		activity := applied.Name
+		if applied.Spell != "" {
+			activity = applied.Spell
+		}
+		if err := RecordTrainingTimeTx(tx, char.ID, activity, applied.TrainingDays, ReasonPPConversion, userID, summary); err != nil {
+			return err
+		}
+	}
+	if applied.EP > 0 {
+		if err := CreateAuditLogEntryTx(tx, char.ID, "experience_points", *ep, *ep-applied.EP, ReasonPPConversion, userID, summary); err != nil {
+			return err
+		}
+		*ep -= applied.EP
+	}
+	if applied.Gold > 0 {
+		if err := CreateAuditLogEntryTx(tx, char.ID, "gold", *gold, *gold-applied.Gold, ReasonPPConversion, userID, summary); err != nil {
+			return err
+		}
+		*gold -= applied.Gold
+	}
+	return nil
+}
+
+// GetPPConversions zeigt für alle Fertigkeiten und Zauberschulen mit PP, was allein mit PP
+// oder mit PP und EP verbessert bzw. gelernt werden kann
+func GetPPConversions(c *gin.Context) {
+	char, ok := loadCharacterWithResources(c)
+	if !ok {
+		return
+	}
+	if !CanReadCharacter(char, c.GetUint("userID")) {
+		respondWithError(c, http.StatusForbidden, "You are not authorized to view this character")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"character_id": char.ID,
+		"ep":           char.Erfahrungsschatz.EP,
+		"gold":         char.Vermoegen.Goldstuecke,
+		"conversions":  PPConversionCandidates(char),
+	})
+}
+
+// ApplyPPConversions führt die gewählten Umwandlungen gemeinsam in einer Transaktion aus
+// Entweder werden alle Umwandlungen übernommen oder keine.
+func ApplyPPConversions(c *gin.Context) {
+	char, ok := loadCharacterWithResources(c)
+	if !ok {
+		return
+	}
+	if !checkCharacterOwnership(c, char) {
+		return
+	}
+
+	var req ApplyPPConversionsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, "Ungültige Anfrageparameter: "+err.Error())
+		return
+	}
+
+	candidates := PPConversionCandidates(char)
+	rules := getTrainingTimeRules(char)
+	var planned []AppliedPPConversion
+	usedPP := map[string]int{}
+	totalEP, totalGold, totalDays := 0, 0, 0
+	for _, conv := range req.Conversions {
+		if slices.ContainsFunc(planned, func(p AppliedPPConversion) bool { return p.Name == conv.Name && p.Spell == conv.Spell }) {
+			respondWithError(c, http.StatusBadRequest, fmt.Sprintf("'%s' ist mehrfach angegeben", conv.Name))
+			return
+		}
+		applied, err := planPPConversion(char, candidates, conv, usedPP[conv.Name], rules)
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		usedPP[conv.Name] += applied.PPUsed
+		planned = append(planned, applied)
+		totalEP += applied.EP
+		totalGold += applied.Gold
+		totalDays += applied.TrainingDays
+	}
+	if totalEP > char.Erfahrungsschatz.EP {
+		respondWithError(c, http.StatusBadRequest, "Nicht genügend Erfahrungspunkte vorhanden")
+		return
+	}
+	if totalGold > char.Vermoegen.Goldstuecke {
+		respondWithError(c, http.StatusBadRequest, "Nicht genügend Gold vorhanden")
+		return
+	}
+	trackDowntime := downtimeTracked(rules, req.CheckDowntime)
+	if trackDowntime {
+		if err := checkDowntime(char.ID, totalDays); err != nil {
+			respondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	userID := c.GetUint("userID")
+	ep := char.Erfahrungsschatz.EP
+	gold := char.Vermoegen.Goldstuecke
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for _, applied := range planned {
+			if err := applyPPConversionTx(tx, char, applied, trackDowntime, &ep, &gold, userID, req.Notes); err != nil {
+				return err
+			}
+		}
+		if totalEP > 0 {
+			if err := tx.Model(&char.Erfahrungsschatz).Update("ep", ep).Error; err != nil {
+				return err
+			}
+		}
+		if totalGold > 0 {
+			if err := tx.Model(&char.Vermoegen).Update("goldstuecke", gold).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		logger.Error("PP-Umwandlung für Charakter %d fehlgeschlagen: %s", char.ID, err.Error())
+		respondDowntimeTxError(c, err, "Fehler bei der PP-Umwandlung")
+		return
+	}
+
+	char.Erfahrungsschatz.EP = ep
+	char.Vermoegen.Goldstuecke = gold
+	logger.Info("Charakter %d: %d PP-Umwandlungen durchgeführt (%d EP, %d Gold)", char.ID, len(planned), totalEP, totalGold)
+	c.JSON(http.StatusOK, gin.H{
+		"character_id":   char.ID,
+		"applied":        planned,
+		"remaining_ep":   ep,
+		"remaining_gold": gold,
+		"training_days":  totalDays,
+		"conversions":    PPConversionCandidates(char),
+	})
+}
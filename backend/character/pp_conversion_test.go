@@ -0,0 +1,179 @@
+package character
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// seedImprovementCosts hinterlegt TE-Kosten für Athletik (Kampf/normal) ab Wert 12
+func seedImprovementCosts(t *testing.T, teByLevel map[int]int) {
+	var category models.SkillCategory
+	require.NoError(t, database.DB.Where("name = ?", "Kampf").First(&category).Error)
+	var difficulty models.SkillDifficulty
+	require.NoError(t, database.DB.Where("name = ?", "normal").First(&difficulty).Error)
+	for level, te := range teByLevel {
+		require.NoError(t, database.DB.Create(&models.SkillImprovementCost{
+			CurrentLevel: level, TERequired: te, CategoryID: category.ID, DifficultyID: difficulty.ID,
+		}).Error)
+	}
+}
+
+func TestPPConversion(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+	require.NoError(t, database.DB.Create(&models.GameSystem{Code: "M5", Name: "midgard", IsActive: true}).Error)
+	seedImprovementCosts(t, map[int]int{12: 2, 13: 3, 14: 4, 15: 5})
+
+	owner := ensureUserExists(t, 243)
+	stranger := ensureUserExists(t, 244)
+
+	list := func(charID uint) []PPConversionCandidate {
+		ctx, w := buildJSONContext(t, http.MethodGet, nil, owner.UserID, map[string]string{"id": fmt.Sprint(charID)})
+		GetPPConversions(ctx)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Conversions []PPConversionCandidate `json:"conversions"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response.Conversions
+	}
+	convert := func(charID, userID uint, conversions ...map[string]any) int {
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"conversions": conversions}, userID, map[string]string{"id": fmt.Sprint(charID)})
+		ApplyPPConversions(ctx)
+		return w.Code
+	}
+
+	t.Run("skill with PP alone and PP plus EP", func(t *testing.T) {
+		char := createCharacterOwnedBy(t, owner.UserID)
+		seedExperience(t, char, 100)
+		seedWealth(t, char, 100, 0, 0)
+		seedSkill(t, char, "Athletik", 11, 6)
+
+		candidates := list(char.ID)
+		require.Len(t, candidates, 1)
+		athletik := candidates[0]
+		assert.Equal(t, PPConversionSkill, athletik.Type)
+		assert.Equal(t, 2, athletik.PPOnlyLevels)
+		assert.Equal(t, 3, athletik.WithEPLevels)
+		require.Len(t, athletik.Steps, 3)
+		// Wert 14 kostet 4 TE, 1 PP ist übrig: 3 TE zu 10 EP und 20 Gold
+		assert.Equal(t, PPConversionStep{TargetLevel: 14, PPUsed: 1, LE: 3, EP: 30, Gold: 60, Affordable: true}, athletik.Steps[2])
+
+		assert.Equal(t, http.StatusForbidden, convert(char.ID, stranger.UserID, map[string]any{"name": "Athletik"}))
+		assert.Equal(t, http.StatusBadRequest, convert(char.ID, owner.UserID, map[string]any{"name": "Athletik", "levels": 4}))
+		assert.Equal(t, http.StatusBadRequest, convert(char.ID, owner.UserID, map[string]any{"name": "Athletik", "levels": 3, "pp_only": true}))
+		assert.Equal(t, http.StatusBadRequest, convert(char.ID, owner.UserID, map[string]any{"name": "Klettern"}))
+
+		require.Equal(t, http.StatusOK, convert(char.ID, owner.UserID, map[string]any{"name": "Athletik", "levels": 3}))
+
+		reloaded := reloadCharacterWithPreloads(t, char.ID)
+		assert.Equal(t, 70, reloaded.Erfahrungsschatz.EP)
+		assert.Equal(t, 40, reloaded.Vermoegen.Goldstuecke)
+		var skill models.SkFertigkeit
+		require.NoError(t, database.DB.Where("character_id = ? AND name = ?", char.ID, "Athletik").First(&skill).Error)
+		assert.Equal(t, 14, skill.Fertigkeitswert)
+		assert.Equal(t, 0, skill.Pp)
+
+		entries, err := GetAuditLogForCharacter(char.ID)
+		require.NoError(t, err)
+		fields := map[string]models.AuditLogEntry{}
+		for _, entry := range entries {
+			assert.Equal(t, string(ReasonPPConversion), entry.Reason)
+			fields[entry.FieldName] = entry
+		}
+		assert.Equal(t, -6, fields["practice_points"].Difference)
+		assert.Equal(t, -30, fields["experience_points"].Difference)
+		assert.Equal(t, -60, fields["gold"].Difference)
+	})
+
+	t.Run("insufficient EP rolls back everything", func(t *testing.T) {
+		char := createCharacterOwnedBy(t, owner.UserID)
+		seedExperience(t, char, 10)
+		seedWealth(t, char, 100, 0, 0)
+		seedSkill(t, char, "Athletik", 11, 6)
+
+		candidates := list(char.ID)
+		require.Len(t, candidates, 1)
+		assert.Equal(t, 2, candidates[0].WithEPLevels)
+
+		assert.Equal(t, http.StatusBadRequest, convert(char.ID, owner.UserID, map[string]any{"name": "Athletik", "levels": 3}))
+		assert.Equal(t, 6, fetchSkillPp(t, char.ID, "Athletik"))
+		assert.Equal(t, 10, reloadCharacterWithPreloads(t, char.ID).Erfahrungsschatz.EP)
+	})
+
+	t.Run("training time is checked and booked", func(t *testing.T) {
+		char := createCharacterOwnedBy(t, owner.UserID)
+		seedExperience(t, char, 100)
+		seedWealth(t, char, 100, 0, 0)
+		seedSkill(t, char, "Athletik", 11, 6)
+		apply := func() int {
+			body := map[string]any{"conversions": []map[string]any{{"name": "Athletik", "levels": 3}}, "check_downtime": true}
+			ctx, w := buildJSONContext(t, http.MethodPost, body, owner.UserID, map[string]string{"id": fmt.Sprint(char.ID)})
+			ApplyPPConversions(ctx)
+			return w.Code
+		}
+
+		// nur für Wert 14 bleiben 3 TE ohne PP, das Konto ist leer
+		days := 3 * defaultTrainingTimeRules.DaysPerTE
+		assert.Equal(t, http.StatusBadRequest, apply())
+		assert.Equal(t, 6, fetchSkillPp(t, char.ID, "Athletik"))
+
+		require.NoError(t, database.DB.Create(&models.DowntimeEntry{CharacterID: char.ID, Kind: models.DowntimeGrant, Days: days}).Error)
+		require.Equal(t, http.StatusOK, apply())
+		entries, err := GetDowntimeLedger(char.ID, models.DowntimeTraining)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, -days, entries[0].Days)
+		assert.Equal(t, string(ReasonPPConversion), entries[0].Reason)
+		balance, err := GetDowntimeBalance(database.DB, char.ID)
+		require.NoError(t, err)
+		assert.Zero(t, balance)
+	})
+
+	t.Run("spell school PP learn spells", func(t *testing.T) {
+		char := createCharacterOwnedBy(t, owner.UserID)
+		require.NoError(t, database.DB.Model(&models.Char{}).Where("id = ?", char.ID).Update("typ", "Magier").Error)
+		seedExperience(t, char, 100)
+		seedSkill(t, char, "Beherrschen", 0, 2)
+
+		candidates := list(char.ID)
+		require.Len(t, candidates, 1)
+		school := candidates[0]
+		assert.Equal(t, PPConversionSpellSchool, school.Type)
+		require.NotEmpty(t, school.Steps)
+		first, second := school.Steps[0], school.Steps[1]
+		require.True(t, first.PPOnly)
+		require.True(t, second.PPOnly)
+		assert.Equal(t, 1, first.PPUsed)
+
+		// zwei Zauber mit je einem PP, ein dritter findet keine PP mehr
+		assert.Equal(t, http.StatusBadRequest, convert(char.ID, owner.UserID,
+			map[string]any{"name": "Beherrschen", "spell": first.Spell},
+			map[string]any{"name": "Beherrschen", "spell": second.Spell},
+			map[string]any{"name": "Beherrschen", "spell": school.Steps[2].Spell},
+		))
+		assert.Equal(t, 0, countSpells(t, char.ID))
+
+		require.Equal(t, http.StatusOK, convert(char.ID, owner.UserID,
+			map[string]any{"name": "Beherrschen", "spell": first.Spell},
+			map[string]any{"name": "Beherrschen", "spell": second.Spell},
+		))
+		assert.Equal(t, 2, countSpells(t, char.ID))
+		assert.Equal(t, 0, fetchSkillPp(t, char.ID, "Beherrschen"))
+		assert.Equal(t, 100, reloadCharacterWithPreloads(t, char.ID).Erfahrungsschatz.EP)
+		assert.Empty(t, list(char.ID))
+	})
+}
@@ -7,13 +7,16 @@ import (
 func RegisterRoutes(r *gin.RouterGroup) {
 	charGrp := r.Group("/characters")
 	charGrp.GET("", ListCharacters)
+	charGrp.GET("/search", SearchCharacters) // Suche mit Filtern, Sortierung, Seiten und Gesamtzahlen
 	charGrp.POST("", CreateCharacter)
 	charGrp.GET("/:id", GetCharacter)
 	charGrp.PUT("/:id", UpdateCharacter)
 	charGrp.PATCH("/:id", UpdateCharacter)
 
 	charGrp.DELETE("/:id", DeleteCharacter)
-	charGrp.PUT("/:id/image", UpdateCharacterImage)
+	charGrp.PUT("/:id/image", UpdateCharacterImage) // Data-URI als JSON oder Multipart-Upload (Feld "file")
+	charGrp.GET("/:id/image", GetCharacterImage)    // Charakterbild (?size=thumb für das Vorschaubild)
+	charGrp.DELETE("/:id/image", DeleteCharacterImage)
 	charGrp.GET("/:id/datasheet-options", GetDatasheetOptions)
 
 	// Character Sharing
@@ -26,10 +29,53 @@ func RegisterRoutes(r *gin.RouterGroup) {
 	charGrp.PUT("/:id/experience", UpdateCharacterExperience)              // NewSystem
 	charGrp.PUT("/:id/wealth", UpdateCharacterWealth)                      // NewSystem
 
+	// LP/AP-Verwaltung (Schaden, Heilung, Erschöpfung)
+	charGrp.GET("/:id/vitals", GetCharacterVitals)
+	charGrp.POST("/:id/vitals/damage", ApplyDamage)
+	charGrp.POST("/:id/vitals/spend-ap", SpendAP)
+	charGrp.POST("/:id/vitals/regain-ap", RegainAP)
+	charGrp.POST("/:id/vitals/heal", HealCharacter)
+
+	// Würfel (serverseitig, mit Historie)
+	charGrp.POST("/:id/roll", RollForCharacter)                   // Würfeln, z.B. {"expression": "2d6+3"}
+	charGrp.GET("/:id/rolls", GetCharacterRolls)                  // Würfelhistorie (?purpose=...&limit=...)
+	charGrp.GET("/:id/rolls/:rollId/verify", VerifyCharacterRoll) // Wurf anhand des Seeds nachprüfen
+	charGrp.POST("/:id/skill-check", PerformSkillCheck)           // Erfolgswurf (EW) auf Fertigkeit, Waffenfertigkeit oder Zauber
+
+	// Kampf (Angriff, Abwehr, Schaden)
+	charGrp.POST("/:id/combat/attack", CombatAttack)   // Angriff mit einer Waffe des Charakters
+	charGrp.POST("/:id/combat/defense", CombatDefense) // Abwehr, optional mit Schild
+	charGrp.POST("/:id/combat/damage", CombatDamage)   // Schaden würfeln, Rüstung des Ziels abziehen
+
+	// Waffenspezialisierung (Zuschlag auf den Angriff, Lernen/Wechseln kostet EP)
+	charGrp.GET("/:id/specializations", GetCharacterSpecializations)
+	charGrp.POST("/:id/specializations", LearnSpecialization) // {"weapon": "...", "replace": "..."}
+
+	// Notizen (Hintergrund, Kontakte, Aufträge; Markdown, Sichtbarkeit privat/Spielleiter/öffentlich)
+	charGrp.GET("/:id/notes", ListCharacterNotes)             // Sichtbare Notizen (?type=...)
+	charGrp.POST("/:id/notes", CreateCharacterNote)           // Notiz anlegen
+	charGrp.PUT("/:id/notes/:noteId", UpdateCharacterNote)    // Notiz ändern
+	charGrp.DELETE("/:id/notes/:noteId", DeleteCharacterNote) // Notiz löschen
+
+	// Kopieren und Vorlagen
+	charGrp.POST("/:id/clone", CloneCharacterHandler)      // Charakter mit allen Fertigkeiten und Ausrüstung kopieren
+	charGrp.POST("/:id/template", CreateCharacterTemplate) // Charakter als Vorlage speichern
+
+	// Zufällige NSCs nach den Klassenregeln
+	charGrp.POST("/generate-npc", GenerateNPCHandler) // Vorschau oder mit {"save": true} speichern, optional mit Seed und Zielgrad
+
+	// Konsistenzprüfung (Stammdaten, Eigenschaften, abgeleitete Werte, Behälter, Audit-Log)
+	charGrp.GET("/:id/consistency", GetCharacterConsistency)      // Prüfbericht mit Korrekturvorschlägen
+	charGrp.POST("/:id/consistency/fix", FixCharacterConsistency) // Korrekturen anwenden (alle automatischen oder {"fix_ids": [...]})
+
 	// Audit-Log für Änderungen
-	charGrp.GET("/:id/audit-log", GetCharacterAuditLog)   // Alle Änderungen oder gefiltert nach Feld (?field=experience_points)
+	charGrp.GET("/:id/audit-log", GetCharacterAuditLog)   // Alle Änderungen oder gefiltert nach Feld (?field=experience_points), mit ?include=downtime samt Ausfallzeit-Konto
 	charGrp.GET("/:id/audit-log/stats", GetAuditLogStats) // Statistiken über Änderungen
 
+	// Ausfallzeit-Konto (Lernzeiten werden beim Lernen und Verbessern automatisch gebucht)
+	charGrp.GET("/:id/downtime", GetCharacterDowntime)  // Kontostand und Buchungen (?kind=grant|training|correction)
+	charGrp.POST("/:id/downtime", AddCharacterDowntime) // {"days": 14, "kind": "grant"}
+
 	// im Frontend wir nur noch der neue Endpunkt benutzt
 	//charGrp.POST("/lerncost", GetLernCost)              // alter Hauptendpunkt für alle Kostenberechnungen (verwendet lerningCostsData)
 	charGrp.POST("/lerncost-new", GetLernCostNewSystem) // neuer Hauptendpunkt für alle Kostenberechnungen (verwendet neue Datenbank)
@@ -58,6 +104,22 @@ func RegisterRoutes(r *gin.RouterGroup) {
 	charGrp.POST("/:id/practice-points/add", AddPracticePoint) // NewSystem
 	charGrp.POST("/:id/practice-points/use", UsePracticePoint) // NewSystem
 
+	// PP-Umwandlung: Vorschau und Durchführung in einer Transaktion
+	charGrp.GET("/:id/practice-points/conversions", GetPPConversions)
+	charGrp.POST("/:id/practice-points/convert", ApplyPPConversions) // {"conversions": [{"name": "...", "levels": 2}]}
+
+	// Steigerungspläne: Probelauf gegen eine Kopie, gespeicherte Pläne in einer Transaktion ausführen
+	charGrp.POST("/:id/advancement-plans/simulate", SimulateAdvancementPlanHandler) // {"steps": [{"action": "learn", "type": "skill", "name": "..."}]}
+	charGrp.GET("/:id/advancement-plans", ListAdvancementPlans)                     // Gespeicherte Pläne (?status=draft|executed)
+	charGrp.POST("/:id/advancement-plans", CreateAdvancementPlan)
+	charGrp.GET("/:id/advancement-plans/:planId", GetAdvancementPlan) // Plan mit Simulation gegen den aktuellen Stand
+	charGrp.PUT("/:id/advancement-plans/:planId", UpdateAdvancementPlan)
+	charGrp.DELETE("/:id/advancement-plans/:planId", DeleteAdvancementPlan)
+	charGrp.POST("/:id/advancement-plans/:planId/execute", ExecuteAdvancementPlan)
+
+	// Budget-Optimierer: bezahlbare Verbesserungen nach Prioritäten, beste Pakete für EP und Gold
+	charGrp.POST("/:id/budget-suggestions", GetBudgetSuggestions) // {"priorities": {"favorite_skills": [...], "categories": [...], "weapon_skills": true}}
+
 	// System-Information
 	//charGrp.GET("/character-classes", GetCharacterClassesHandlerOld)
 	charGrp.GET("/skill-categories", GetSkillCategoriesHandlerStatic)
@@ -70,17 +132,31 @@ func RegisterRoutes(r *gin.RouterGroup) {
 	charGrp.PUT("/create-session/:sessionId/attributes", UpdateCharacterAttributes) // Grundwerte speichern
 	charGrp.PUT("/create-session/:sessionId/derived", UpdateCharacterDerivedValues) // Abgeleitete Werte speichern
 	charGrp.PUT("/create-session/:sessionId/skills", UpdateCharacterSkills)         // Fertigkeiten speichern
-	charGrp.POST("/create-session/:sessionId/finalize", FinalizeCharacterCreation)  // Charakter-Erstellung abschließen
+	charGrp.POST("/create-session/:sessionId/roll", RollForCreationSession)         // Serverseitiger Wurf für ein Feld
+	charGrp.GET("/create-session/:sessionId/validate", ValidateCharacterSession)    // Regelprüfung ohne Abschluss
+	charGrp.POST("/create-session/:sessionId/finalize", FinalizeCharacterCreation)  // Charakter-Erstellung abschließen (mit Regelprüfung)
 	charGrp.DELETE("/create-session/:sessionId", DeleteCharacterSession)            // Session löschen
 
+	// Besondere Fähigkeiten während der Erstellung (nach den abgeleiteten Werten, da Berserkergang von Wk abhängt)
+	charGrp.POST("/create-session/:sessionId/special-abilities/roll", RollSessionSpecialAbilities)  // W100, bei 100 freie Wahl und zweiter Wurf
+	charGrp.PUT("/create-session/:sessionId/special-abilities/choice", ChooseSessionSpecialAbility) // Freie Wahl nach einem Wurf von 100
+
 	// Reference Data für Character Creation
 	charGrp.GET("/races", GetRaces)                                          // Verfügbare Rassen
 	charGrp.GET("/classes", GetCharacterClasses)                             // Verfügbare Klassen
 	charGrp.GET("/classes/learning-points", GetCharacterClassLearningPoints) // Lernpunkte für Charakterklasse
 	charGrp.GET("/origins", GetOrigins)                                      // Verfügbare Herkünfte
 	charGrp.GET("/beliefs", SearchBeliefs)                                   // Glaube-Suche
+	charGrp.GET("/special-abilities", GetSpecialAbilities)                   // W100-Tabelle der besonderen Fähigkeiten
 
 	// Derived Values Calculation
 	charGrp.POST("/calculate-static-fields", CalculateStaticFields) // Berechnung ohne Würfelwürfe
 	charGrp.POST("/calculate-rolled-field", CalculateRolledField)   // Berechnung mit Würfelwürfen
+
+	// Charaktervorlagen
+	tplGrp := r.Group("/character-templates")
+	tplGrp.GET("", ListCharacterTemplates)
+	tplGrp.GET("/:templateId", GetCharacterTemplate)
+	tplGrp.DELETE("/:templateId", DeleteCharacterTemplate)
+	tplGrp.POST("/:templateId/instantiate", InstantiateCharacterTemplate) // Neuen Charakter erzeugen, optional mit variierten Eigenschaften
 }
@@ -0,0 +1,98 @@
+package character
+
+import (
+	"bamort/models"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultCharacterSearchLimit = 25
+	maxCharacterSearchLimit     = 100
+)
+
+// queryInt liest einen optionalen, nicht negativen Ganzzahlparameter
+func queryInt(c *gin.Context, name string) (int, bool) {
+	value := c.Query(name)
+	if value == "" {
+		return 0, true
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < 0 {
+		respondWithError(c, http.StatusBadRequest, "Ungültiger Parameter: "+name)
+		return 0, false
+	}
+	return parsed, true
+}
+
+// SearchCharacters durchsucht alle sichtbaren Charaktere (eigene, öffentliche, geteilte)
+// Parameter: q, rasse, typ, min_grad, max_grad, game_system, owner_id, campaign_id, scope (all|own|others),
+// sort (name|grad|rasse|typ|owner|id), order (asc|desc), limit (max. 100), offset
+func SearchCharacters(c *gin.Context) {
+	filter := models.CharSearchFilter{
+		Query: c.Query("q"),
+		Rasse: c.Query("rasse"),
+		Typ:   c.Query("typ"),
+		Scope: c.DefaultQuery("scope", models.CharScopeAll),
+		Sort:  c.DefaultQuery("sort", "name"),
+		Desc:  strings.EqualFold(c.Query("order"), "desc"),
+		Limit: defaultCharacterSearchLimit,
+	}
+
+	switch filter.Scope {
+	case models.CharScopeAll, models.CharScopeOwn, models.CharScopeOthers:
+	default:
+		respondWithError(c, http.StatusBadRequest, "Ungültiger Parameter: scope")
+		return
+	}
+	if !models.ValidCharSearchSort(filter.Sort) {
+		respondWithError(c, http.StatusBadRequest, "Ungültiger Parameter: sort")
+		return
+	}
+
+	var ok bool
+	if filter.MinGrad, ok = queryInt(c, "min_grad"); !ok {
+		return
+	}
+	if filter.MaxGrad, ok = queryInt(c, "max_grad"); !ok {
+		return
+	}
+	if filter.Offset, ok = queryInt(c, "offset"); !ok {
+		return
+	}
+	limit, ok := queryInt(c, "limit")
+	if !ok {
+		return
+	}
+	if limit > 0 {
+		filter.Limit = min(limit, maxCharacterSearchLimit)
+	}
+	ownerID, ok := queryInt(c, "owner_id")
+	if !ok {
+		return
+	}
+	filter.OwnerID = uint(ownerID)
+	campaignID, ok := queryInt(c, "campaign_id")
+	if !ok {
+		return
+	}
+	filter.CampaignID = uint(campaignID)
+
+	if name := c.Query("game_system"); name != "" {
+		filter.GameSystem = models.GetGameSystem(0, name)
+		if filter.GameSystem == nil {
+			respondWithError(c, http.StatusBadRequest, "Unbekanntes Spielsystem: "+name)
+			return
+		}
+	}
+
+	result, err := models.SearchCharList(c.GetUint("userID"), filter)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to search characters")
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
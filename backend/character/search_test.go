@@ -0,0 +1,108 @@
+package character
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSearchCharacters(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+
+	me := ensureUserExists(t, 236)
+	other := ensureUserExists(t, 237)
+
+	seed := func(owner uint, name, rasse, typ string, grad int, public bool) models.Char {
+		char := models.Char{BamortBase: models.BamortBase{Name: name}, UserID: owner, Rasse: rasse, Typ: typ, Grad: grad, Public: public}
+		require.NoError(t, database.DB.Create(&char).Error)
+		return char
+	}
+	seed(me.UserID, "Suchtest Alrik", "Mensch", "Krieger", 3, false)
+	seed(me.UserID, "Suchtest Borgar", "Zwerg", "Krieger", 7, false)
+	seed(me.UserID, "SUCHTEST Elwen", "Elf", "Magier", 1, false)
+	seed(other.UserID, "Suchtest Alrik der Große", "Mensch", "Krieger", 5, true)
+	seed(other.UserID, "Suchtest Geheim", "Mensch", "Krieger", 2, false)
+	shared := seed(other.UserID, "Suchtest Geteilt", "Gnom", "Hexer", 4, false)
+	require.NoError(t, database.DB.Create(&models.CharShare{CharacterID: shared.ID, UserID: me.UserID, Permission: "read"}).Error)
+
+	search := func(params url.Values) (int, models.CharSearchResult) {
+		ctx, w := buildJSONContext(t, http.MethodGet, nil, me.UserID, nil)
+		ctx.Request.URL.RawQuery = params.Encode()
+		SearchCharacters(ctx)
+		var result models.CharSearchResult
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+		}
+		return w.Code, result
+	}
+	names := func(result models.CharSearchResult) []string {
+		out := make([]string, len(result.Items))
+		for i, item := range result.Items {
+			out[i] = item.Name
+		}
+		return out
+	}
+
+	t.Run("visibility and counts", func(t *testing.T) {
+		code, result := search(url.Values{"q": {"suchtest"}})
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, int64(5), result.Total, "private characters of others are hidden")
+		assert.Equal(t, int64(3), result.OwnCount)
+		assert.Equal(t, int64(2), result.OtherCount)
+		assert.NotContains(t, names(result), "Suchtest Geheim")
+		assert.Equal(t, "Suchtest Alrik", result.Items[0].Name, "sorted by name")
+		assert.NotEmpty(t, result.Items[0].Owner)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		_, result := search(url.Values{"q": {"alrik"}, "rasse": {"Mensch"}})
+		assert.ElementsMatch(t, []string{"Suchtest Alrik", "Suchtest Alrik der Große"}, names(result))
+
+		_, result = search(url.Values{"q": {"suchtest"}, "typ": {"Krieger"}, "min_grad": {"4"}, "max_grad": {"7"}})
+		assert.ElementsMatch(t, []string{"Suchtest Borgar", "Suchtest Alrik der Große"}, names(result))
+
+		_, result = search(url.Values{"q": {"suchtest"}, "scope": {"others"}})
+		assert.ElementsMatch(t, []string{"Suchtest Alrik der Große", "Suchtest Geteilt"}, names(result))
+
+		_, result = search(url.Values{"q": {"suchtest"}, "owner_id": {"237"}})
+		assert.Len(t, result.Items, 2)
+	})
+
+	t.Run("sorting and pagination", func(t *testing.T) {
+		_, result := search(url.Values{"q": {"suchtest"}, "sort": {"grad"}, "order": {"desc"}, "limit": {"2"}})
+		assert.Equal(t, []string{"Suchtest Borgar", "Suchtest Alrik der Große"}, names(result))
+		require.NotNil(t, result.NextOffset)
+		assert.Equal(t, 2, *result.NextOffset)
+
+		_, result = search(url.Values{"q": {"suchtest"}, "sort": {"grad"}, "order": {"desc"}, "limit": {"2"}, "offset": {"4"}})
+		assert.Equal(t, []string{"SUCHTEST Elwen"}, names(result))
+		assert.Nil(t, result.NextOffset)
+		assert.Equal(t, int64(5), result.Total)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, params := range []url.Values{
+			{"sort": {"image"}},
+			{"scope": {"everyone"}},
+			{"limit": {"-1"}},
+			{"min_grad": {"drei"}},
+			{"game_system": {"unbekannt"}},
+		} {
+			code, _ := search(params)
+			assert.Equal(t, http.StatusBadRequest, code, params.Encode())
+		}
+	})
+}
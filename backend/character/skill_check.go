@@ -0,0 +1,162 @@
+package character
+
+import (
+	"bamort/models"
+	"fmt"
+	"strings"
+)
+
+// SkillCheckOutcome klassifiziert das Ergebnis eines Erfolgswurfs
+type SkillCheckOutcome string
+
+const (
+	OutcomeCriticalSuccess SkillCheckOutcome = "kritischer_erfolg"
+	OutcomeSuccess         SkillCheckOutcome = "erfolg"
+	OutcomeFailure         SkillCheckOutcome = "misserfolg"
+	OutcomeCriticalFailure SkillCheckOutcome = "kritischer_fehler"
+)
+
+// Arten von Fertigkeiten, auf die ein EW gewürfelt werden kann
+const (
+	CheckTypeSkill       = "skill"
+	CheckTypeWeaponSkill = "weaponskill"
+	CheckTypeSpell       = "spell"
+)
+
+// SkillCheckRules enthält die Regeln für Erfolgswürfe eines Spielsystems
+type SkillCheckRules struct {
+	Dice                     string `json:"dice"`                         // Würfelausdruck für den EW
+	TargetNumber             int    `json:"target_number"`                // Wurf + Erfolgswert muss mindestens diesen Wert erreichen
+	CriticalSuccessRoll      int    `json:"critical_success_roll"`        // unmodifizierter Wurf für einen kritischen Erfolg
+	CriticalFailureRoll      int    `json:"critical_failure_roll"`        // unmodifizierter Wurf für einen kritischen Fehler
+	AwardPPOnCriticalSuccess bool   `json:"award_pp_on_critical_success"` // kritischer Erfolg bringt einen Praxispunkt
+}
+
+// defaultSkillCheckRules entsprechen den Regeln des M5-Systems
+var defaultSkillCheckRules = SkillCheckRules{
+	Dice:                     "1d20",
+	TargetNumber:             20,
+	CriticalSuccessRoll:      20,
+	CriticalFailureRoll:      1,
+	AwardPPOnCriticalSuccess: true,
+}
+
+// SkillCheckModifier ist ein situationsbedingter Zu- oder Abschlag
+type SkillCheckModifier struct {
+	Label string `json:"label"`
+	Value int    `json:"value"`
+}
+
+// SkillCheckBreakdown schlüsselt den Erfolgswert eines EW auf
+type SkillCheckBreakdown struct {
+	SkillValue     int                  `json:"skill_value"`
+	SkillBonus     int                  `json:"skill_bonus"`
+	Attribute      string               `json:"attribute,omitempty"`
+	AttributeBonus int                  `json:"attribute_bonus"`
+	EquipmentBonus int                  `json:"equipment_bonus"`
+	Modifiers      []SkillCheckModifier `json:"modifiers"`
+	Total          int                  `json:"total"`
+}
+
+// getSkillCheckRules liefert die EW-Regeln für das Spielsystem eines Charakters
+func getSkillCheckRules(char *models.Char) SkillCheckRules {
+	return models.GameSystemRules(models.GetGameSystem(char.GameSystemId, char.GameSystem), "skill_check", defaultSkillCheckRules)
+}
+
+// findCharacterSkill sucht eine Fertigkeit, Waffenfertigkeit oder einen Zauber am Charakter
+// Ist checkType leer, wird in dieser Reihenfolge gesucht.
+func findCharacterSkill(char *models.Char, name, checkType string) (string, error) {
+	if checkType == "" || checkType == CheckTypeSkill {
+		for _, skill := range char.Fertigkeiten {
+			if strings.EqualFold(skill.Name, name) {
+				return CheckTypeSkill, nil
+			}
+		}
+	}
+	if checkType == "" || checkType == CheckTypeWeaponSkill {
+		for _, skill := range char.Waffenfertigkeiten {
+			if strings.EqualFold(skill.Name, name) {
+				return CheckTypeWeaponSkill, nil
+			}
+		}
+	}
+	if checkType == "" || checkType == CheckTypeSpell {
+		for _, spell := range char.Zauber {
+			if strings.EqualFold(spell.Name, name) {
+				return CheckTypeSpell, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("'%s' ist dem Charakter nicht bekannt", name)
+}
+
+// calculateSkillCheckBreakdown berechnet den Erfolgswert für einen EW
+// bonusAttribute ist die Leiteigenschaft der Fertigkeit aus den Stammdaten (z.B. "Gs"),
+// equipmentBonus die Summe der Boni aus eingesetzter Ausrüstung bzw. Waffe.
+func calculateSkillCheckBreakdown(char *models.Char, name, checkType, bonusAttribute string, equipmentBonus int, modifiers []SkillCheckModifier) SkillCheckBreakdown {
+	breakdown := SkillCheckBreakdown{
+		EquipmentBonus: equipmentBonus,
+		Modifiers:      modifiers,
+	}
+	if breakdown.Modifiers == nil {
+		breakdown.Modifiers = []SkillCheckModifier{}
+	}
+
+	switch checkType {
+	case CheckTypeSkill:
+		for _, skill := range char.Fertigkeiten {
+			if strings.EqualFold(skill.Name, name) {
+				breakdown.SkillValue = skill.Fertigkeitswert
+				breakdown.SkillBonus = skill.Bonus
+				break
+			}
+		}
+		if bonusAttribute != "" {
+			breakdown.Attribute = bonusAttribute
+			breakdown.AttributeBonus = calculateAttributeBonus(char.GetAttributeValue(bonusAttribute))
+		}
+	case CheckTypeWeaponSkill:
+		for _, skill := range char.Waffenfertigkeiten {
+			if strings.EqualFold(skill.Name, name) {
+				breakdown.SkillValue = skill.Fertigkeitswert
+				breakdown.SkillBonus = skill.Bonus
+				break
+			}
+		}
+		// Angriffsbonus aus Gs, wie auf dem Charakterbogen
+		breakdown.Attribute = "Gs"
+		breakdown.AttributeBonus = CalculateStaticFieldsForCharacter(char).AngriffsBonus
+	case CheckTypeSpell:
+		for _, spell := range char.Zauber {
+			if strings.EqualFold(spell.Name, name) {
+				breakdown.SkillBonus = spell.Bonus
+				break
+			}
+		}
+		// Zaubern nach Grad, der Zauberbonus aus Zt wird separat ausgewiesen
+		breakdown.SkillValue = getZaubernBaseByGrade(max(char.Grad, 1))
+		breakdown.Attribute = "Zt"
+		breakdown.AttributeBonus = CalculateStaticFieldsForCharacter(char).ZauberBonus
+	}
+
+	breakdown.Total = breakdown.SkillValue + breakdown.SkillBonus + breakdown.AttributeBonus + breakdown.EquipmentBonus
+	for _, mod := range breakdown.Modifiers {
+		breakdown.Total += mod.Value
+	}
+	return breakdown
+}
+
+// classifySkillCheck bestimmt das Ergebnis eines EW aus Wurf und Erfolgswert
+// Kritische Würfe gelten unabhängig vom Erfolgswert.
+func classifySkillCheck(roll, total int, rules SkillCheckRules) SkillCheckOutcome {
+	switch {
+	case roll >= rules.CriticalSuccessRoll:
+		return OutcomeCriticalSuccess
+	case roll <= rules.CriticalFailureRoll:
+		return OutcomeCriticalFailure
+	case roll+total >= rules.TargetNumber:
+		return OutcomeSuccess
+	default:
+		return OutcomeFailure
+	}
+}
@@ -0,0 +1,186 @@
+package character
+
+import (
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SkillCheckRequest repräsentiert einen Erfolgswurf (EW) auf eine Fertigkeit, Waffenfertigkeit oder einen Zauber
+type SkillCheckRequest struct {
+	Name         string               `json:"name" binding:"required"`
+	Type         string               `json:"type,omitempty" binding:"omitempty,oneof=skill weaponskill spell"` // ohne Angabe automatisch ermittelt
+	Modifiers    []SkillCheckModifier `json:"modifiers,omitempty"`                                              // situationsbedingte Zu- und Abschläge
+	EquipmentIDs []uint               `json:"equipment_ids,omitempty"`                                          // eingesetzte Ausrüstung, deren Bonus zählt
+	WeaponID     uint                 `json:"weapon_id,omitempty"`                                              // geführte Waffe (Anb) bei Waffenfertigkeiten
+	AwardPP      bool                 `json:"award_pp,omitempty"`                                               // bei kritischem Erfolg Praxispunkt vergeben
+}
+
+// SkillCheckResponse enthält Wurf, Aufschlüsselung und Ergebnis eines EW
+type SkillCheckResponse struct {
+	CharacterID  uint                `json:"character_id"`
+	Name         string              `json:"name"`
+	Type         string              `json:"type"`
+	Roll         *models.DiceRoll    `json:"roll"`
+	Breakdown    SkillCheckBreakdown `json:"breakdown"`
+	Result       int                 `json:"result"` // Wurf + Erfolgswert
+	TargetNumber int                 `json:"target_number"`
+	Outcome      SkillCheckOutcome   `json:"outcome"`
+	Success      bool                `json:"success"`
+	PPAwarded    bool                `json:"pp_awarded"`
+	PPTarget     string              `json:"pp_target,omitempty"`
+}
+
+// lookupBonusAttribute liefert die Leiteigenschaft einer Fertigkeit aus den Stammdaten
+func lookupBonusAttribute(char *models.Char, skillName string) string {
+	var skill models.Skill
+	query := database.DB.Where("name = ?", skillName)
+	if gs := models.GetGameSystem(char.GameSystemId, char.GameSystem); gs != nil {
+		query = query.Where("(game_system=? OR game_system_id=?)", gs.Name, gs.ID)
+	}
+	if err := query.Order("id ASC").First(&skill).Error; err != nil {
+		return ""
+	}
+	return skill.Bonuseigenschaft
+}
+
+// collectEquipmentBonus summiert die Boni der eingesetzten Ausrüstung und Waffe
+func collectEquipmentBonus(char *models.Char, equipmentIDs []uint, weaponID uint, checkType string) (int, error) {
+	bonus := 0
+	for _, id := range equipmentIDs {
+		found := false
+		for _, item := range char.Ausruestung {
+			if item.ID == id {
+				bonus += item.Bonus
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("ausrüstung %d gehört nicht zum Charakter", id)
+		}
+	}
+
+	if weaponID != 0 {
+		if checkType != CheckTypeWeaponSkill {
+			return 0, fmt.Errorf("eine Waffe kann nur bei Waffenfertigkeiten eingesetzt werden")
+		}
+		found := false
+		for _, weapon := range char.Waffen {
+			if weapon.ID == weaponID {
+				bonus += weapon.Anb
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("waffe %d gehört nicht zum Charakter", weaponID)
+		}
+	}
+	return bonus, nil
+}
+
+// awardSkillCheckPP vergibt einen Praxispunkt für den gewürfelten EW
+// Bei Zaubern geht der Praxispunkt wie bei AddPracticePoint an die Zaubergruppe.
+func awardSkillCheckPP(char *models.Char, name, checkType string) (string, error) {
+	switch checkType {
+	case CheckTypeWeaponSkill:
+		for i := range char.Waffenfertigkeiten {
+			if strings.EqualFold(char.Waffenfertigkeiten[i].Name, name) {
+				char.Waffenfertigkeiten[i].Pp++
+				return char.Waffenfertigkeiten[i].Name, database.DB.Save(&char.Waffenfertigkeiten[i]).Error
+			}
+		}
+	default:
+		target := name
+		if checkType == CheckTypeSpell {
+			target = getSpellCategoryNewSystem(name)
+		}
+		for i := range char.Fertigkeiten {
+			if strings.EqualFold(char.Fertigkeiten[i].Name, target) {
+				if !char.Fertigkeiten[i].Improvable && checkType == CheckTypeSkill {
+					return "", nil
+				}
+				char.Fertigkeiten[i].Pp++
+				return char.Fertigkeiten[i].Name, database.DB.Save(&char.Fertigkeiten[i]).Error
+			}
+		}
+	}
+	return "", nil
+}
+
+// PerformSkillCheck würfelt einen EW gegen die aktuellen Werte des Charakters
+func PerformSkillCheck(c *gin.Context) {
+	var char models.Char
+	if err := char.FirstID(c.Param("id")); err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return
+	}
+	if !checkCharacterOwnership(c, &char) {
+		return
+	}
+
+	var req SkillCheckRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	checkType, err := findCharacterSkill(&char, req.Name, req.Type)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	equipmentBonus, err := collectEquipmentBonus(&char, req.EquipmentIDs, req.WeaponID, checkType)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	bonusAttribute := ""
+	if checkType == CheckTypeSkill {
+		bonusAttribute = lookupBonusAttribute(&char, req.Name)
+	}
+	breakdown := calculateSkillCheckBreakdown(&char, req.Name, checkType, bonusAttribute, equipmentBonus, req.Modifiers)
+
+	rules := getSkillCheckRules(&char)
+	userID := c.GetUint("userID")
+	roll, err := rollAndRecord(rules.Dice, "EW: "+req.Name, char.ID, "", userID)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	outcome := classifySkillCheck(roll.Total, breakdown.Total, rules)
+	response := SkillCheckResponse{
+		CharacterID:  char.ID,
+		Name:         req.Name,
+		Type:         checkType,
+		Roll:         roll,
+		Breakdown:    breakdown,
+		Result:       roll.Total + breakdown.Total,
+		TargetNumber: rules.TargetNumber,
+		Outcome:      outcome,
+		Success:      outcome == OutcomeSuccess || outcome == OutcomeCriticalSuccess,
+	}
+
+	if req.AwardPP && rules.AwardPPOnCriticalSuccess && outcome == OutcomeCriticalSuccess {
+		target, err := awardSkillCheckPP(&char, req.Name, checkType)
+		if err != nil {
+			logger.Error("Fehler beim Vergeben des Praxispunkts für %s: %s", req.Name, err.Error())
+			respondWithError(c, http.StatusInternalServerError, "Failed to award practice point")
+			return
+		}
+		response.PPAwarded = target != ""
+		response.PPTarget = target
+	}
+
+	logger.Info("EW %s für Charakter %d: Wurf %d + %d = %d (%s)", req.Name, char.ID, roll.Total, breakdown.Total, response.Result, outcome)
+	c.JSON(http.StatusOK, response)
+}
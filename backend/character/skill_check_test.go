@@ -0,0 +1,140 @@
+package character
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// useFixedD20 sorgt dafür, dass der nächste 1d20-Wurf den gewünschten Wert ergibt
+func useFixedD20(t *testing.T, value int) {
+	expr := DiceExpression{Count: 1, Sides: 20}
+	for seed := int64(1); seed < 10000; seed++ {
+		if rollDiceWithSeed(expr, seed).Total == value {
+			original := diceSeedSource
+			diceSeedSource = func() int64 { return seed }
+			t.Cleanup(func() { diceSeedSource = original })
+			return
+		}
+	}
+	t.Fatalf("no seed found for d20 = %d", value)
+}
+
+func TestClassifySkillCheck(t *testing.T) {
+	rules := defaultSkillCheckRules
+	assert.Equal(t, OutcomeCriticalSuccess, classifySkillCheck(20, -5, rules))
+	assert.Equal(t, OutcomeCriticalFailure, classifySkillCheck(1, 30, rules))
+	assert.Equal(t, OutcomeSuccess, classifySkillCheck(8, 12, rules))
+	assert.Equal(t, OutcomeFailure, classifySkillCheck(7, 12, rules))
+}
+
+func TestCalculateSkillCheckBreakdown(t *testing.T) {
+	char := &models.Char{
+		Grad: 1,
+		Eigenschaften: []models.Eigenschaft{
+			{Name: "Gs", Value: 85},
+			{Name: "Zt", Value: 50},
+		},
+		Fertigkeiten: []models.SkFertigkeit{
+			{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Klettern"}}, Fertigkeitswert: 12, Bonus: 1},
+		},
+		Waffenfertigkeiten: []models.SkWaffenfertigkeit{
+			{SkFertigkeit: models.SkFertigkeit{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Schwerter"}}, Fertigkeitswert: 7}},
+		},
+		Zauber: []models.SkZauber{
+			{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Feuerlanze"}}, Bonus: 2},
+		},
+	}
+
+	skill := calculateSkillCheckBreakdown(char, "Klettern", CheckTypeSkill, "Gs", 2, []SkillCheckModifier{{Label: "nasse Wand", Value: -4}})
+	assert.Equal(t, 12, skill.SkillValue)
+	assert.Equal(t, 1, skill.SkillBonus)
+	assert.Equal(t, 1, skill.AttributeBonus)
+	assert.Equal(t, 12+1+1+2-4, skill.Total)
+
+	weapon := calculateSkillCheckBreakdown(char, "Schwerter", CheckTypeWeaponSkill, "", 1, nil)
+	assert.Equal(t, 7+1+1, weapon.Total)
+	assert.NotNil(t, weapon.Modifiers)
+
+	spell := calculateSkillCheckBreakdown(char, "Feuerlanze", CheckTypeSpell, "", 0, nil)
+	assert.Equal(t, getZaubernBaseByGrade(1), spell.SkillValue)
+	assert.Equal(t, getZaubernBaseByGrade(1)+2+0, spell.Total)
+}
+
+func TestPerformSkillCheck(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+
+	require.NoError(t, models.MigrateStructure())
+
+	owner := ensureUserExists(t, 203)
+	char := createCharacterOwnedBy(t, owner.UserID)
+	seedSkill(t, char, "Testkunde", 10, 0)
+	params := map[string]string{"id": fmt.Sprint(char.ID)}
+
+	t.Run("critical success awards practice point", func(t *testing.T) {
+		useFixedD20(t, 20)
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"name": "Testkunde", "award_pp": true}, owner.UserID, params)
+
+		PerformSkillCheck(ctx)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response SkillCheckResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, CheckTypeSkill, response.Type)
+		assert.Equal(t, OutcomeCriticalSuccess, response.Outcome)
+		assert.True(t, response.PPAwarded)
+		assert.Equal(t, 20, response.Roll.Total)
+
+		var skill models.SkFertigkeit
+		require.NoError(t, database.DB.Where("character_id = ? AND name = ?", char.ID, "Testkunde").First(&skill).Error)
+		assert.Equal(t, 1, skill.Pp)
+	})
+
+	t.Run("modifiers decide between success and failure", func(t *testing.T) {
+		useFixedD20(t, 10)
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{
+			"name":      "Testkunde",
+			"modifiers": []SkillCheckModifier{{Label: "Dunkelheit", Value: -4}},
+			"award_pp":  true,
+		}, owner.UserID, params)
+
+		PerformSkillCheck(ctx)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response SkillCheckResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, OutcomeFailure, response.Outcome)
+		assert.False(t, response.Success)
+		assert.False(t, response.PPAwarded)
+		assert.Equal(t, response.Roll.Total+response.Breakdown.Total, response.Result)
+	})
+
+	t.Run("unknown skill is rejected", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"name": "Fliegen"}, owner.UserID, params)
+
+		PerformSkillCheck(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("foreign equipment is rejected", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"name": "Testkunde", "equipment_ids": []uint{999999}}, owner.UserID, params)
+
+		PerformSkillCheck(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
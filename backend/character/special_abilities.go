@@ -0,0 +1,282 @@
+package character
+
+import (
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"fmt"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// specialAbilityFreeChoice ist der Platzhalter für einen Wurf von 100, bis frei gewählt wurde
+const specialAbilityFreeChoice = "Freie Wahl"
+
+// specialAbilityRollPurpose kennzeichnet die W100-Würfe in der Würfelhistorie
+const specialAbilityRollPurpose = "special_ability"
+
+// specialAbilityBaseValues sind die Grundwerte, auf die der Modifikator der besonderen Fähigkeit
+// angerechnet wird. Sinne haben den Grundwert 8 und werden um ±2 verändert; bei allen anderen
+// Fähigkeiten ist der Tabellenwert bereits der Erfolgswert. Berserkergang hängt von Wk ab.
+var specialAbilityBaseValues = map[string]int{
+	"Sehen":                    8,
+	"Hören":                    8,
+	"Riechen":                  8,
+	"Sechster Sinn":            0,
+	"Nachtsicht":               0,
+	"Gute Reflexe":             0,
+	"Richtungssinn":            0,
+	"Robustheit":               0,
+	"Schmerzunempfindlichkeit": 0,
+	"Trinken":                  0,
+	"Wachgabe":                 0,
+	"Wahrnehmung":              0,
+	"Einprägen":                0,
+}
+
+// specialAbilityValue berechnet den Fertigkeitswert einer besonderen Fähigkeit
+// Berserkergang hängt von der Willenskraft ab (18 - Wk/5).
+func specialAbilityValue(name string, modifier, wk int) int {
+	if name == "Berserkergang" {
+		return 18 - wk/5
+	}
+	return specialAbilityBaseValues[name] + modifier
+}
+
+// specialAbilityCategory liefert die Kategorie einer besonderen Fähigkeit
+// Lernbare Fähigkeiten erhalten ihre Kategorie erst beim Hinzufügen aus den Stammdaten.
+func specialAbilityCategory(name string) string {
+	if models.IsInnateSkill(name) {
+		return "Angeboren"
+	}
+	return ""
+}
+
+// specialAbilitySkills wandelt besondere Fähigkeiten in Fertigkeiten um; angeborene sind nicht steigerbar
+// Doppelt erhaltene Fähigkeiten werden zusammengefasst, es zählt der höhere Wert.
+func specialAbilitySkills(abilities []models.CharacterCreationAbility, wk int) []models.SkFertigkeit {
+	var skills []models.SkFertigkeit
+	for _, ability := range abilities {
+		if ability.Name == specialAbilityFreeChoice {
+			continue
+		}
+		value := specialAbilityValue(ability.Name, ability.Modifier, wk)
+		if idx := slices.IndexFunc(skills, func(s models.SkFertigkeit) bool { return s.Name == ability.Name }); idx >= 0 {
+			skills[idx].Fertigkeitswert = max(skills[idx].Fertigkeitswert, value)
+			continue
+		}
+		skills = append(skills, models.SkFertigkeit{
+			BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: ability.Name}},
+			Fertigkeitswert: value,
+			Improvable:      !models.IsInnateSkill(ability.Name),
+			Category:        specialAbilityCategory(ability.Name),
+		})
+	}
+	return skills
+}
+
+// addInnateSkills fügt die Fertigkeiten der besonderen Fähigkeiten hinzu; ist die Fertigkeit bereits
+// gelernt, gilt der höhere Wert und angeborene Fertigkeiten werden nicht steigerbar
+func addInnateSkills(char *models.Char, skills []models.SkFertigkeit) {
+	for _, skill := range skills {
+		idx := slices.IndexFunc(char.Fertigkeiten, func(s models.SkFertigkeit) bool { return s.Name == skill.Name })
+		if idx < 0 {
+			if skill.Category == "" {
+				var master models.Skill
+				if err := master.First(skill.Name); err == nil {
+					skill.Category = master.Category
+				}
+			}
+			char.Fertigkeiten = append(char.Fertigkeiten, skill)
+			continue
+		}
+		existing := &char.Fertigkeiten[idx]
+		existing.Fertigkeitswert = max(existing.Fertigkeitswert, skill.Fertigkeitswert)
+		if !skill.Improvable {
+			existing.Improvable = false
+			existing.Category = skill.Category
+		}
+	}
+}
+
+// choosableSpecialAbilities liefert die bei einem Wurf von 100 frei wählbaren Fähigkeiten
+// Für Sinne mit mehreren Einträgen wird der günstigste Modifikator angeboten.
+func choosableSpecialAbilities() []SpecialAbility {
+	var choices []SpecialAbility
+	for _, entry := range GetAllSpecialAbilities() {
+		ability := *entry.Ability
+		if ability.Name == specialAbilityFreeChoice {
+			continue
+		}
+		if idx := slices.IndexFunc(choices, func(a SpecialAbility) bool { return a.Name == ability.Name }); idx >= 0 {
+			choices[idx].Modifier = max(choices[idx].Modifier, ability.Modifier)
+			continue
+		}
+		choices = append(choices, ability)
+	}
+	return choices
+}
+
+// specialAbilityFromRoll übersetzt einen W100-Wurf in den Eintrag der Session
+func specialAbilityFromRoll(roll *models.DiceRoll) (models.CharacterCreationAbility, error) {
+	ability, err := GetSpecialAbilityByRoll(roll.Total)
+	if err != nil {
+		return models.CharacterCreationAbility{}, err
+	}
+	return models.CharacterCreationAbility{Name: ability.Name, Modifier: ability.Modifier, Roll: roll.Total, RollID: roll.ID}, nil
+}
+
+// rollSessionSpecialAbilities würfelt die besonderen Fähigkeiten einer Session
+// Bei 100 darf frei gewählt werden und es wird erneut gewürfelt.
+func rollSessionSpecialAbilities(session *models.CharacterCreationSession, userID uint) ([]models.CharacterCreationAbility, error) {
+	var abilities []models.CharacterCreationAbility
+	for {
+		roll, err := rollAndRecord("1d100", specialAbilityRollPurpose, 0, session.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		ability, err := specialAbilityFromRoll(roll)
+		if err != nil {
+			return nil, err
+		}
+		abilities = append(abilities, ability)
+		if roll.Total != 100 {
+			return abilities, nil
+		}
+	}
+}
+
+// checkSpecialAbilities stellt sicher, dass alle Würfe aufgelöst sind und zur Tabelle passen
+func (v *creationValidator) checkSpecialAbilities() {
+	choices := choosableSpecialAbilities()
+	for _, ability := range v.session.SpecialAbilities {
+		switch {
+		case ability.Name == specialAbilityFreeChoice:
+			v.add(CreationCheckSpecialAbility, "special_abilities", "Freie Wahl der besonderen Fähigkeit steht noch aus", nil, nil)
+		case ability.Chosen:
+			idx := slices.IndexFunc(choices, func(a SpecialAbility) bool { return a.Name == ability.Name })
+			if idx < 0 || choices[idx].Modifier != ability.Modifier {
+				v.add(CreationCheckSpecialAbility, ability.Name, "Frei gewählte Fähigkeit nicht erlaubt", nil, ability.Modifier)
+			}
+		default:
+			expected, err := GetSpecialAbilityByRoll(ability.Roll)
+			if err != nil || expected.Name != ability.Name || expected.Modifier != ability.Modifier {
+				v.add(CreationCheckSpecialAbility, ability.Name, "Fähigkeit passt nicht zum Wurf", nil, ability.Roll)
+			}
+		}
+	}
+}
+
+// specialAbilitiesResponse liefert die Fähigkeiten der Session samt berechneter Werte
+func specialAbilitiesResponse(session *models.CharacterCreationSession) gin.H {
+	pending := slices.ContainsFunc(session.SpecialAbilities, func(a models.CharacterCreationAbility) bool {
+		return a.Name == specialAbilityFreeChoice
+	})
+	response := gin.H{
+		"session_id":        session.ID,
+		"special_abilities": session.SpecialAbilities,
+		"skills":            specialAbilitySkills(session.SpecialAbilities, session.DerivedValues.WK),
+		"choice_pending":    pending,
+	}
+	if pending {
+		response["choices"] = choosableSpecialAbilities()
+	}
+	return response
+}
+
+// GetSpecialAbilities gibt die W100-Tabelle und die frei wählbaren Fähigkeiten zurück
+func GetSpecialAbilities(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{
+		"table":   GetAllSpecialAbilities(),
+		"choices": choosableSpecialAbilities(),
+	})
+}
+
+// RollSessionSpecialAbilities würfelt serverseitig die besonderen Fähigkeiten einer Session
+// Die Willenskraft (Berserkergang) muss bereits feststehen, daher erst nach den abgeleiteten Werten.
+func RollSessionSpecialAbilities(c *gin.Context) {
+	userID := c.GetUint("userID")
+	var session models.CharacterCreationSession
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("sessionId"), userID).First(&session).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Session not found")
+		return
+	}
+	if session.CurrentStep < 4 {
+		respondWithError(c, http.StatusBadRequest, "Abgeleitete Werte müssen vor den besonderen Fähigkeiten gespeichert werden")
+		return
+	}
+	if len(session.SpecialAbilities) > 0 {
+		respondWithError(c, http.StatusConflict, "Besondere Fähigkeiten wurden bereits gewürfelt")
+		return
+	}
+
+	abilities, err := rollSessionSpecialAbilities(&session, userID)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	session.SpecialAbilities = abilities
+	session.UpdatedAt = time.Now()
+	if err := database.DB.Save(&session).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to update session")
+		return
+	}
+
+	logger.Info("Session %s würfelt %d besondere Fähigkeiten", session.ID, len(abilities))
+	c.JSON(http.StatusCreated, specialAbilitiesResponse(&session))
+}
+
+// ChooseSpecialAbilityRequest löst einen Wurf von 100 durch freie Wahl auf
+type ChooseSpecialAbilityRequest struct {
+	Name string `json:"name" binding:"required"`
+}
+
+// ChooseSessionSpecialAbility ersetzt eine offene freie Wahl durch die gewählte Fähigkeit
+func ChooseSessionSpecialAbility(c *gin.Context) {
+	userID := c.GetUint("userID")
+	var session models.CharacterCreationSession
+	if err := database.DB.Where("id = ? AND user_id = ?", c.Param("sessionId"), userID).First(&session).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Session not found")
+		return
+	}
+
+	var req ChooseSpecialAbilityRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	idx := slices.IndexFunc(session.SpecialAbilities, func(a models.CharacterCreationAbility) bool {
+		return a.Name == specialAbilityFreeChoice
+	})
+	if idx < 0 {
+		respondWithError(c, http.StatusConflict, "Keine freie Wahl offen")
+		return
+	}
+	choices := choosableSpecialAbilities()
+	choice := slices.IndexFunc(choices, func(a SpecialAbility) bool { return a.Name == req.Name })
+	if choice < 0 {
+		respondWithError(c, http.StatusBadRequest, fmt.Sprintf("Unbekannte besondere Fähigkeit: %s", req.Name))
+		return
+	}
+
+	pending := session.SpecialAbilities[idx]
+	session.SpecialAbilities[idx] = models.CharacterCreationAbility{
+		Name:     choices[choice].Name,
+		Modifier: choices[choice].Modifier,
+		Roll:     pending.Roll,
+		RollID:   pending.RollID,
+		Chosen:   true,
+	}
+	session.UpdatedAt = time.Now()
+	if err := database.DB.Save(&session).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to update session")
+		return
+	}
+
+	logger.Info("Session %s wählt besondere Fähigkeit %s", session.ID, req.Name)
+	c.JSON(http.StatusOK, specialAbilitiesResponse(&session))
+}
@@ -0,0 +1,148 @@
+package character
+
+import (
+	"encoding/json"
+	"net/http"
+	"slices"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSpecialAbilitySkills(t *testing.T) {
+	abilities := []models.CharacterCreationAbility{
+		{Name: "Freie Wahl", Roll: 100},
+		{Name: "Sehen", Modifier: -2, Roll: 3},
+		{Name: "Sehen", Modifier: 2, Roll: 100, Chosen: true},
+		{Name: "Berserkergang", Roll: 97},
+		{Name: "Gute Reflexe", Modifier: 9, Roll: 58},
+		{Name: "Wahrnehmung", Modifier: 8, Roll: 88},
+	}
+	skills := specialAbilitySkills(abilities, 42)
+	require.Len(t, skills, 4)
+
+	values := map[string]int{}
+	for _, skill := range skills {
+		values[skill.Name] = skill.Fertigkeitswert
+		if skill.Name == "Wahrnehmung" {
+			assert.True(t, skill.Improvable, "learnable skills stay improvable")
+			continue
+		}
+		assert.False(t, skill.Improvable, skill.Name)
+		assert.Equal(t, "Angeboren", skill.Category)
+	}
+	assert.Equal(t, 10, values["Sehen"], "duplicate senses keep the better value")
+	assert.Equal(t, 10, values["Berserkergang"], "18 - Wk/5")
+	assert.Equal(t, 9, values["Gute Reflexe"])
+	assert.Equal(t, 8, values["Wahrnehmung"])
+}
+
+func TestSpecialAbilitiesAreInnateSkills(t *testing.T) {
+	for _, entry := range GetAllSpecialAbilities() {
+		if entry.Ability.Name == specialAbilityFreeChoice {
+			continue
+		}
+		assert.Contains(t, models.SpecialAbilitySkills, entry.Ability.Name)
+		assert.Equal(t, !slices.Contains(models.LearnableSpecialAbilities, entry.Ability.Name), models.IsInnateSkill(entry.Ability.Name), entry.Ability.Name)
+		if entry.Ability.Name != "Berserkergang" {
+			assert.Contains(t, specialAbilityBaseValues, entry.Ability.Name, "every ability needs a base value")
+		}
+	}
+	assert.False(t, models.IsInnateSkill("Trinken"))
+	assert.False(t, models.IsInnateSkill("Wahrnehmung"))
+
+	choices := choosableSpecialAbilities()
+	assert.Len(t, choices, len(models.SpecialAbilitySkills))
+	for _, choice := range choices {
+		if choice.Name == "Sehen" {
+			assert.Equal(t, 2, choice.Modifier, "free choice offers the positive sense")
+		}
+	}
+}
+
+func TestSessionSpecialAbilities(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+
+	owner := ensureUserExists(t, 235)
+	params := func(session models.CharacterCreationSession) map[string]string {
+		return map[string]string{"sessionId": session.ID}
+	}
+
+	t.Run("roll is stored once", func(t *testing.T) {
+		session := validCreationSession(owner.UserID)
+		session.ID = "char_create_abilities_roll"
+		require.NoError(t, database.DB.Create(&session).Error)
+
+		ctx, w := buildJSONContext(t, http.MethodPost, nil, owner.UserID, params(session))
+		RollSessionSpecialAbilities(ctx)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var stored models.CharacterCreationSession
+		require.NoError(t, database.DB.First(&stored, "id = ?", session.ID).Error)
+		require.NotEmpty(t, stored.SpecialAbilities)
+		last := stored.SpecialAbilities[len(stored.SpecialAbilities)-1]
+		assert.NotEqual(t, 100, last.Roll, "a roll of 100 is followed by a second roll")
+		assert.NotZero(t, last.RollID)
+
+		var rolls int64
+		database.DB.Model(&models.DiceRoll{}).Where("session_id = ? AND purpose = ?", session.ID, specialAbilityRollPurpose).Count(&rolls)
+		assert.Equal(t, int64(len(stored.SpecialAbilities)), rolls)
+
+		ctx, w = buildJSONContext(t, http.MethodPost, nil, owner.UserID, params(session))
+		RollSessionSpecialAbilities(ctx)
+		assert.Equal(t, http.StatusConflict, w.Code, "no re-rolling")
+	})
+
+	t.Run("free choice and finalize", func(t *testing.T) {
+		session := validCreationSession(owner.UserID)
+		session.ID = "char_create_abilities_choice"
+		session.SpecialAbilities = models.CharacterCreationAbilities{
+			{Name: "Freie Wahl", Roll: 100},
+			{Name: "Berserkergang", Roll: 98},
+		}
+		require.NoError(t, database.DB.Create(&session).Error)
+
+		ctx, w := buildJSONContext(t, http.MethodPost, nil, owner.UserID, params(session))
+		FinalizeCharacterCreation(ctx)
+		require.Equal(t, http.StatusUnprocessableEntity, w.Code, "open free choice blocks finalize")
+
+		ctx, w = buildJSONContext(t, http.MethodPut, map[string]any{"name": "Gibtsnicht"}, owner.UserID, params(session))
+		ChooseSessionSpecialAbility(ctx)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		ctx, w = buildJSONContext(t, http.MethodPut, map[string]any{"name": "Nachtsicht"}, owner.UserID, params(session))
+		ChooseSessionSpecialAbility(ctx)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		ctx, w = buildJSONContext(t, http.MethodPut, map[string]any{"name": "Sehen"}, owner.UserID, params(session))
+		ChooseSessionSpecialAbility(ctx)
+		assert.Equal(t, http.StatusConflict, w.Code, "only one free choice was rolled")
+
+		ctx, w = buildJSONContext(t, http.MethodPost, nil, owner.UserID, params(session))
+		FinalizeCharacterCreation(ctx)
+		require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+		var resp struct {
+			CharacterID uint `json:"character_id"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		var innate []models.SkFertigkeit
+		require.NoError(t, database.DB.Where("character_id = ? AND category = ?", resp.CharacterID, "Angeboren").Order("name").Find(&innate).Error)
+		require.Len(t, innate, 2)
+		assert.Equal(t, "Berserkergang", innate[0].Name)
+		assert.Equal(t, 18-session.DerivedValues.WK/5, innate[0].Fertigkeitswert)
+		assert.Equal(t, "Nachtsicht", innate[1].Name)
+		assert.Equal(t, 2, innate[1].Fertigkeitswert)
+		for _, skill := range innate {
+			assert.False(t, skill.Improvable)
+		}
+	})
+}
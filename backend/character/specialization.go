@@ -0,0 +1,107 @@
+package character
+
+import (
+	"bamort/models"
+	"fmt"
+	"slices"
+)
+
+// SpecializationRules enthält die Regeln eines Spielsystems für Waffenspezialisierungen
+type SpecializationRules struct {
+	AttackBonus        int `json:"attack_bonus"`        // Zuschlag auf den Angriff mit der Spezialwaffe
+	MaxSpecializations int `json:"max_specializations"` // Anzahl gleichzeitiger Spezialisierungen
+	LearnCostEP        int `json:"learn_cost_ep"`       // EP für eine neue Spezialisierung
+	ChangeCostEP       int `json:"change_cost_ep"`      // EP für den Wechsel der Spezialwaffe
+}
+
+// defaultSpecializationRules entsprechen den Regeln des M5-Systems (eine Spezialwaffe, +2 auf den Angriff)
+var defaultSpecializationRules = SpecializationRules{
+	AttackBonus:        2,
+	MaxSpecializations: 1,
+	LearnCostEP:        40,
+	ChangeCostEP:       40,
+}
+
+// getSpecializationRules liefert die Spezialisierungsregeln für das Spielsystem eines Charakters
+func getSpecializationRules(char *models.Char) SpecializationRules {
+	return models.GameSystemRules(models.GetGameSystem(char.GameSystemId, char.GameSystem), "specialization", defaultSpecializationRules)
+}
+
+// specializationName liefert den Namen, unter dem eine Waffe als Spezialwaffe zählt
+// Magische oder besondere Waffen verweisen über NameFuerSpezialisierung auf ihre Grundwaffe.
+func specializationName(weapon *models.EqWaffe) string {
+	if weapon.NameFuerSpezialisierung != "" {
+		return weapon.NameFuerSpezialisierung
+	}
+	return weapon.Name
+}
+
+// isSpecializedIn prüft, ob der Charakter auf die Waffe spezialisiert ist
+func isSpecializedIn(char *models.Char, weapon *models.EqWaffe) bool {
+	return slices.Contains(char.Spezialisierung, specializationName(weapon))
+}
+
+// hasWeaponSkill prüft, ob der Charakter die Waffenfertigkeit gelernt hat
+func hasWeaponSkill(char *models.Char, skillName string) bool {
+	return slices.ContainsFunc(char.Waffenfertigkeiten, func(s models.SkWaffenfertigkeit) bool {
+		return s.Name == skillName
+	})
+}
+
+// validateSpecialization prüft, ob eine Spezialisierung auf die Waffe möglich ist
+// Die Waffe muss in den Stammdaten stehen und ihre Waffenfertigkeit muss gelernt sein.
+func validateSpecialization(char *models.Char, weaponName string) (*models.Weapon, error) {
+	baseWeapon := &models.Weapon{}
+	if err := baseWeapon.First(weaponName); err != nil || baseWeapon.ID == 0 {
+		return nil, fmt.Errorf("unbekannte Waffe: %s", weaponName)
+	}
+	if baseWeapon.SkillRequired == "" {
+		return nil, fmt.Errorf("für %s gibt es keine Waffenfertigkeit", weaponName)
+	}
+	if !hasWeaponSkill(char, baseWeapon.SkillRequired) {
+		return nil, fmt.Errorf("waffenfertigkeit %s für %s nicht gelernt", baseWeapon.SkillRequired, weaponName)
+	}
+	return baseWeapon, nil
+}
+
+// SpecializationStatus beschreibt eine eingetragene Spezialisierung
+// Einträge, die keine Waffe sind (z.B. Zauberprozesse von Magiern), bleiben unverändert erhalten.
+type SpecializationStatus struct {
+	Name   string `json:"name"`
+	Weapon bool   `json:"weapon"` // Waffe aus den Stammdaten
+	Skill  string `json:"skill,omitempty"`
+	Valid  bool   `json:"valid"` // Waffenfertigkeit gelernt
+	Error  string `json:"error,omitempty"`
+}
+
+// checkSpecializations prüft alle Spezialisierungen eines Charakters
+// Importierte Charaktere können Waffen ohne passende Waffenfertigkeit enthalten.
+func checkSpecializations(char *models.Char) []SpecializationStatus {
+	statuses := make([]SpecializationStatus, 0, len(char.Spezialisierung))
+	for _, name := range char.Spezialisierung {
+		status := SpecializationStatus{Name: name}
+		baseWeapon := &models.Weapon{}
+		if err := baseWeapon.First(name); err == nil && baseWeapon.ID != 0 {
+			status.Weapon = true
+			status.Skill = baseWeapon.SkillRequired
+			if _, err := validateSpecialization(char, name); err != nil {
+				status.Error = err.Error()
+			} else {
+				status.Valid = true
+			}
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+// countWeaponSpecializations zählt die Spezialisierungen auf Waffen
+func countWeaponSpecializations(statuses []SpecializationStatus) int {
+	count := 0
+	for _, status := range statuses {
+		if status.Weapon {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,155 @@
+package character
+
+import (
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"fmt"
+	"net/http"
+	"slices"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// LearnSpecializationRequest lernt eine Spezialisierung oder ersetzt eine bestehende
+type LearnSpecializationRequest struct {
+	Weapon        string `json:"weapon" binding:"required"`
+	Replace       string `json:"replace,omitempty"` // bisherige Spezialwaffe, die ersetzt wird
+	Notes         string `json:"notes,omitempty"`
+	CheckDowntime bool   `json:"check_downtime,omitempty"` // Lernzeit gegen das Ausfallzeit-Konto prüfen und buchen
+}
+
+// SpecializationsResponse enthält die Spezialisierungen eines Charakters und die Regeln
+type SpecializationsResponse struct {
+	CharacterID     uint                   `json:"character_id"`
+	Specializations []SpecializationStatus `json:"specializations"`
+	Rules           SpecializationRules    `json:"rules"`
+	EP              int                    `json:"ep"`
+	CostEP          int                    `json:"cost_ep,omitempty"` // bei Lernen/Wechsel abgezogene EP
+}
+
+// loadCharacterForSpecialization lädt einen Charakter mit Waffenfertigkeiten und Erfahrung
+func loadCharacterForSpecialization(c *gin.Context) (*models.Char, bool) {
+	var character models.Char
+	err := database.DB.
+		Preload("Waffenfertigkeiten").
+		Preload("Erfahrungsschatz").
+		First(&character, c.Param("id")).Error
+	if err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return nil, false
+	}
+	return &character, true
+}
+
+// buildSpecializationsResponse erstellt die Antwort mit geprüften Spezialisierungen
+func buildSpecializationsResponse(char *models.Char, cost int) SpecializationsResponse {
+	return SpecializationsResponse{
+		CharacterID:     char.ID,
+		Specializations: checkSpecializations(char),
+		Rules:           getSpecializationRules(char),
+		EP:              char.Erfahrungsschatz.EP,
+		CostEP:          cost,
+	}
+}
+
+// GetCharacterSpecializations gibt die Spezialisierungen samt Gültigkeit und Kosten zurück
+func GetCharacterSpecializations(c *gin.Context) {
+	char, ok := loadCharacterForSpecialization(c)
+	if !ok {
+		return
+	}
+	if !CanReadCharacter(char, c.GetUint("userID")) {
+		respondWithError(c, http.StatusForbidden, "You are not authorized to view this character")
+		return
+	}
+
+	c.JSON(http.StatusOK, buildSpecializationsResponse(char, 0))
+}
+
+// LearnSpecialization lernt eine Waffenspezialisierung oder wechselt die Spezialwaffe
+// Die EP werden abgezogen und die Änderung im Audit-Log festgehalten.
+func LearnSpecialization(c *gin.Context) {
+	char, ok := loadCharacterForSpecialization(c)
+	if !ok {
+		return
+	}
+	if !checkCharacterOwnership(c, char) {
+		return
+	}
+
+	var req LearnSpecializationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if slices.Contains(char.Spezialisierung, req.Weapon) {
+		respondWithError(c, http.StatusConflict, fmt.Sprintf("Spezialisierung auf %s besteht bereits", req.Weapon))
+		return
+	}
+	if _, err := validateSpecialization(char, req.Weapon); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rules := getSpecializationRules(char)
+	specializations := slices.Clone(char.Spezialisierung)
+	cost := rules.LearnCostEP
+	notes := fmt.Sprintf("Spezialisierung auf %s", req.Weapon)
+	if req.Replace != "" {
+		idx := slices.Index(specializations, req.Replace)
+		if idx < 0 {
+			respondWithError(c, http.StatusBadRequest, fmt.Sprintf("Keine Spezialisierung auf %s vorhanden", req.Replace))
+			return
+		}
+		specializations[idx] = req.Weapon
+		cost = rules.ChangeCostEP
+		notes = fmt.Sprintf("Spezialisierung von %s auf %s gewechselt", req.Replace, req.Weapon)
+	} else {
+		if countWeaponSpecializations(checkSpecializations(char)) >= rules.MaxSpecializations {
+			respondWithError(c, http.StatusConflict, fmt.Sprintf("Höchstens %d Spezialisierung(en) möglich, bitte die bisherige ersetzen", rules.MaxSpecializations))
+			return
+		}
+		specializations = append(specializations, req.Weapon)
+	}
+	if req.Notes != "" {
+		notes += ": " + req.Notes
+	}
+
+	oldEP := char.Erfahrungsschatz.EP
+	if oldEP < cost {
+		respondWithError(c, http.StatusBadRequest, "Nicht genügend Erfahrungspunkte vorhanden")
+		return
+	}
+	trainingRules := getTrainingTimeRules(char)
+	trackDowntime := downtimeTracked(trainingRules, req.CheckDowntime)
+
+	userID := c.GetUint("userID")
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if trackDowntime {
+			if err := RecordTrainingTimeTx(tx, char.ID, req.Weapon, trainingRules.DaysPerSpecialization, ReasonSpecialization, userID, notes); err != nil {
+				return err
+			}
+		}
+		if cost > 0 {
+			if err := tx.Model(&char.Erfahrungsschatz).Update("ep", oldEP-cost).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Model(&models.Char{}).Where("id = ?", char.ID).Update("spezialisierung", database.StringArray(specializations)).Error; err != nil {
+			return err
+		}
+		return CreateAuditLogEntryTx(tx, char.ID, "experience_points", oldEP, oldEP-cost, ReasonSpecialization, userID, notes)
+	})
+	if err != nil {
+		respondDowntimeTxError(c, err, "Failed to save specialization")
+		return
+	}
+
+	char.Erfahrungsschatz.EP = oldEP - cost
+	char.Spezialisierung = specializations
+	logger.Info("Charakter %d: %s (%d EP)", char.ID, notes, cost)
+	c.JSON(http.StatusOK, buildSpecializationsResponse(char, cost))
+}
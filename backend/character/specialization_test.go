@@ -0,0 +1,156 @@
+package character
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWeaponSpecialization(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+
+	for _, w := range []models.Weapon{
+		{Equipment: models.Equipment{Name: "Spezklinge"}, Damage: "1W6", SkillRequired: "Spezwaffen"},
+		{Equipment: models.Equipment{Name: "Spezdolch"}, Damage: "1W6-1", SkillRequired: "Spezwaffen"},
+		{Equipment: models.Equipment{Name: "Spezbogen"}, Damage: "1W6", SkillRequired: "Spezbögen"},
+	} {
+		require.NoError(t, database.DB.Create(&w).Error)
+	}
+
+	owner := ensureUserExists(t, 241)
+	stranger := ensureUserExists(t, 242)
+	char := createCharacterOwnedBy(t, owner.UserID)
+	seedExperience(t, char, 100)
+	require.NoError(t, database.DB.Create(&models.SkWaffenfertigkeit{SkFertigkeit: models.SkFertigkeit{
+		BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Spezwaffen"}, CharacterID: char.ID, UserID: owner.UserID},
+		Fertigkeitswert: 7,
+	}}).Error)
+	// Zauberprozesse von Magiern stehen ebenfalls in der Liste und bleiben unangetastet
+	require.NoError(t, database.DB.Model(&models.Char{}).Where("id = ?", char.ID).
+		Update("spezialisierung", database.StringArray{"Erschaffen"}).Error)
+
+	params := map[string]string{"id": fmt.Sprint(char.ID)}
+	learn := func(userID uint, body map[string]any) (int, SpecializationsResponse) {
+		ctx, w := buildJSONContext(t, http.MethodPost, body, userID, params)
+		LearnSpecialization(ctx)
+		var response SpecializationsResponse
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response
+	}
+	rules := defaultSpecializationRules
+
+	t.Run("rejects strangers, unknown weapons and missing skills", func(t *testing.T) {
+		code, _ := learn(stranger.UserID, map[string]any{"weapon": "Spezklinge"})
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = learn(owner.UserID, map[string]any{"weapon": "Gibtsnicht"})
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = learn(owner.UserID, map[string]any{"weapon": "Spezbogen"})
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("learn costs EP and is audited", func(t *testing.T) {
+		code, response := learn(owner.UserID, map[string]any{"weapon": "Spezklinge"})
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, rules.LearnCostEP, response.CostEP)
+		assert.Equal(t, 100-rules.LearnCostEP, response.EP)
+		require.Len(t, response.Specializations, 2)
+		assert.False(t, response.Specializations[0].Weapon)
+		assert.Equal(t, SpecializationStatus{Name: "Spezklinge", Weapon: true, Skill: "Spezwaffen", Valid: true}, response.Specializations[1])
+
+		reloaded := reloadCharacterWithPreloads(t, char.ID)
+		assert.Equal(t, database.StringArray{"Erschaffen", "Spezklinge"}, reloaded.Spezialisierung)
+		assert.Equal(t, 100-rules.LearnCostEP, reloaded.Erfahrungsschatz.EP)
+
+		entries, err := GetAuditLogForField(char.ID, "experience_points")
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, string(ReasonSpecialization), entries[0].Reason)
+		assert.Equal(t, -rules.LearnCostEP, entries[0].Difference)
+		assert.Equal(t, owner.UserID, entries[0].UserID)
+	})
+
+	t.Run("duplicates and a second weapon are rejected", func(t *testing.T) {
+		code, _ := learn(owner.UserID, map[string]any{"weapon": "Spezklinge"})
+		assert.Equal(t, http.StatusConflict, code)
+		code, _ = learn(owner.UserID, map[string]any{"weapon": "Spezdolch"})
+		assert.Equal(t, http.StatusConflict, code)
+	})
+
+	t.Run("attack includes specialization bonus", func(t *testing.T) {
+		loaded := reloadCharacter(t, char.ID)
+		require.NoError(t, database.DB.Where("character_id = ?", char.ID).Find(&loaded.Waffenfertigkeiten).Error)
+		static := StaticFieldsResponse{AngriffsBonus: 1}
+		base := &models.Weapon{SkillRequired: "Spezwaffen"}
+
+		special := CalculateWeaponAttack(&loaded, &models.EqWaffe{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Spezklinge"}}}, base, static)
+		assert.True(t, special.Specialized)
+		assert.Equal(t, 7+1+rules.AttackBonus, special.Total)
+
+		// magische Waffen zählen über NameFuerSpezialisierung als Spezialwaffe
+		magic := CalculateWeaponAttack(&loaded, &models.EqWaffe{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Flammenklinge"}}, NameFuerSpezialisierung: "Spezklinge", Anb: 1}, base, static)
+		assert.Equal(t, 7+1+1+rules.AttackBonus, magic.Total)
+
+		other := CalculateWeaponAttack(&loaded, &models.EqWaffe{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Spezdolch"}}}, base, static)
+		assert.False(t, other.Specialized)
+		assert.Equal(t, 7+1, other.Total)
+	})
+
+	t.Run("change replaces the weapon", func(t *testing.T) {
+		code, _ := learn(owner.UserID, map[string]any{"weapon": "Spezdolch", "replace": "Gibtsnicht"})
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		// mit Prüfung der Lernzeit reicht das leere Ausfallzeit-Konto nicht
+		change := map[string]any{"weapon": "Spezdolch", "replace": "Spezklinge", "check_downtime": true}
+		code, _ = learn(owner.UserID, change)
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, database.StringArray{"Erschaffen", "Spezklinge"}, reloadCharacter(t, char.ID).Spezialisierung)
+
+		days := defaultTrainingTimeRules.DaysPerSpecialization
+		require.NoError(t, database.DB.Create(&models.DowntimeEntry{CharacterID: char.ID, Kind: models.DowntimeGrant, Days: days}).Error)
+		code, response := learn(owner.UserID, change)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, 100-rules.LearnCostEP-rules.ChangeCostEP, response.EP)
+		assert.Equal(t, database.StringArray{"Erschaffen", "Spezdolch"}, reloadCharacter(t, char.ID).Spezialisierung)
+
+		entries, err := GetDowntimeLedger(char.ID, models.DowntimeTraining)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, -days, entries[0].Days)
+		assert.Equal(t, "Spezdolch", entries[0].Activity)
+	})
+
+	t.Run("insufficient EP", func(t *testing.T) {
+		code, _ := learn(owner.UserID, map[string]any{"weapon": "Spezklinge", "replace": "Spezdolch"})
+		assert.Equal(t, http.StatusBadRequest, code)
+		assert.Equal(t, database.StringArray{"Erschaffen", "Spezdolch"}, reloadCharacter(t, char.ID).Spezialisierung)
+	})
+
+	t.Run("read access", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodGet, nil, stranger.UserID, params)
+		GetCharacterSpecializations(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		ctx, w = buildJSONContext(t, http.MethodGet, nil, owner.UserID, params)
+		GetCharacterSpecializations(ctx)
+		require.Equal(t, http.StatusOK, w.Code)
+		var response SpecializationsResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, rules, response.Rules)
+		assert.Len(t, response.Specializations, 2)
+	})
+}
@@ -0,0 +1,152 @@
+package character
+
+import (
+	"bamort/models"
+	"fmt"
+	"strings"
+)
+
+// VitalState beschreibt einen Zustand, der sich aus den aktuellen LP/AP ergibt
+type VitalState string
+
+const (
+	StateExhausted        VitalState = "erschoepft"      // AP aufgebraucht
+	StateSeriouslyWounded VitalState = "schwer_verletzt" // höchstens die Hälfte der LP übrig
+	StateIncapacitated    VitalState = "kampfunfaehig"   // nur noch wenige LP
+	StateUnconscious      VitalState = "bewusstlos"      // keine LP mehr
+	StateDying            VitalState = "sterbend"        // LP im negativen Bereich
+)
+
+// VitalRules enthält die Schwellenwerte und Heilraten eines Spielsystems
+type VitalRules struct {
+	SeriouslyWoundedDivisor int  `json:"seriously_wounded_divisor"`  // LP <= Max/Divisor gilt als schwer verletzt
+	IncapacitatedLP         int  `json:"incapacitated_lp"`           // LP <= Wert gilt als kampfunfähig
+	UnconsciousLP           int  `json:"unconscious_lp"`             // LP <= Wert gilt als bewusstlos
+	DyingLP                 int  `json:"dying_lp"`                   // LP <= Wert gilt als sterbend
+	RestLPPerDay            int  `json:"rest_lp_per_day"`            // natürliche Heilung pro Tag Ruhe
+	ArmorReducesLightDamage bool `json:"armor_reduces_light_damage"` // Rüstung schützt auch vor leichtem Schaden (nur AP-Verlust)
+}
+
+// defaultVitalRules entsprechen den Regeln des M5-Systems
+var defaultVitalRules = VitalRules{
+	SeriouslyWoundedDivisor: 2,
+	IncapacitatedLP:         3,
+	UnconsciousLP:           0,
+	DyingLP:                 -1,
+	RestLPPerDay:            1,
+	ArmorReducesLightDamage: true,
+}
+
+// armorProtection enthält den Rüstungsschutz (RS) der bekannten Rüstungsarten
+var armorProtection = map[string]int{
+	"Textilrüstung":  1,
+	"Lederrüstung":   2,
+	"Kettenrüstung":  3,
+	"Plattenrüstung": 4,
+}
+
+// getVitalRules liefert die LP/AP-Regeln für das Spielsystem eines Charakters
+func getVitalRules(char *models.Char) VitalRules {
+	return models.GameSystemRules(models.GetGameSystem(char.GameSystemId, char.GameSystem), "vitals", defaultVitalRules)
+}
+
+// determineArmorProtection ermittelt den Rüstungsschutz aus der am Körper getragenen Ausrüstung
+func determineArmorProtection(char *models.Char) int {
+	best := 0
+	for _, item := range char.Ausruestung {
+		if item.BeinhaltetIn != "" && item.BeinhaltetIn != "Am Körper" {
+			continue
+		}
+		for armorName, rs := range armorProtection {
+			if strings.HasPrefix(item.Name, armorName) && rs+item.Bonus > best {
+				best = rs + item.Bonus
+			}
+		}
+	}
+	return best
+}
+
+// calculateDamageLoss berechnet LP- und AP-Verlust eines Treffers
+// Schwerer Schaden kostet LP und AP in Höhe des Schadens abzüglich Rüstungsschutz,
+// leichter Schaden (erfolgreich abgewehrt) kostet nur AP.
+func calculateDamageLoss(damage int, heavy bool, armor int, rules VitalRules) (int, int) {
+	if damage <= 0 {
+		return 0, 0
+	}
+	if armor < 0 {
+		armor = 0
+	}
+
+	reduced := damage - armor
+	if reduced < 0 {
+		reduced = 0
+	}
+
+	if heavy {
+		return reduced, reduced
+	}
+	if rules.ArmorReducesLightDamage {
+		return 0, reduced
+	}
+	return 0, damage
+}
+
+// evaluateVitalStates bestimmt alle Zustände, die sich aus den LP/AP ergeben
+func evaluateVitalStates(lp models.Lp, ap models.Ap, rules VitalRules) []VitalState {
+	states := make([]VitalState, 0)
+
+	if ap.Max > 0 && ap.Value <= 0 {
+		states = append(states, StateExhausted)
+	}
+	if lp.Max > 0 && rules.SeriouslyWoundedDivisor > 0 && lp.Value <= lp.Max/rules.SeriouslyWoundedDivisor {
+		states = append(states, StateSeriouslyWounded)
+	}
+	if lp.Max > 0 && lp.Value <= rules.IncapacitatedLP {
+		states = append(states, StateIncapacitated)
+	}
+	if lp.Max > 0 && lp.Value <= rules.UnconsciousLP {
+		states = append(states, StateUnconscious)
+	}
+	if lp.Max > 0 && lp.Value <= rules.DyingLP {
+		states = append(states, StateDying)
+	}
+
+	return states
+}
+
+// vitalWarnings erzeugt lesbare Warnungen für die kritischen Zustände
+func vitalWarnings(states []VitalState) []string {
+	warnings := make([]string, 0)
+	for _, state := range states {
+		switch state {
+		case StateExhausted:
+			warnings = append(warnings, "Charakter ist erschöpft (keine AP mehr)")
+		case StateIncapacitated:
+			warnings = append(warnings, "Charakter ist kampfunfähig")
+		case StateUnconscious:
+			warnings = append(warnings, "Charakter ist bewusstlos")
+		case StateDying:
+			warnings = append(warnings, "Charakter stirbt ohne sofortige Hilfe")
+		}
+	}
+	return warnings
+}
+
+// clampValue begrenzt einen Wert auf das Intervall [min, max]
+func clampValue(value, min, max int) int {
+	if value < min {
+		return min
+	}
+	if value > max {
+		return max
+	}
+	return value
+}
+
+// calculateRestHealing berechnet die LP-Heilung durch Ruhe
+func calculateRestHealing(days int, rules VitalRules) (int, error) {
+	if days <= 0 {
+		return 0, fmt.Errorf("anzahl der Ruhetage muss größer als 0 sein")
+	}
+	return days * rules.RestLPPerDay, nil
+}
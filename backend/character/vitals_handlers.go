@@ -0,0 +1,306 @@
+package character
+
+import (
+	"bamort/database"
+	"bamort/models"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// VitalsResponse repräsentiert den aktuellen LP/AP-Stand eines Charakters
+type VitalsResponse struct {
+	CharacterID uint         `json:"character_id"`
+	LP          models.Lp    `json:"lp"`
+	AP          models.Ap    `json:"ap"`
+	States      []VitalState `json:"states"`
+	Warnings    []string     `json:"warnings"`
+}
+
+// ApplyDamageRequest repräsentiert einen erlittenen Treffer
+type ApplyDamageRequest struct {
+	Damage int    `json:"damage" binding:"required,min=1"`
+	Heavy  bool   `json:"heavy"`           // schwerer Schaden (nicht abgewehrt)
+	Armor  *int   `json:"armor,omitempty"` // Rüstungsschutz, ohne Angabe aus der Ausrüstung ermittelt
+	Notes  string `json:"notes,omitempty"`
+}
+
+// SpendAPRequest repräsentiert den Verbrauch von AP, z.B. für einen Zauber
+type SpendAPRequest struct {
+	Amount int    `json:"amount" binding:"required,min=1"`
+	Spell  string `json:"spell,omitempty"`
+	Notes  string `json:"notes,omitempty"`
+}
+
+// RegainAPRequest repräsentiert die Erholung von AP
+type RegainAPRequest struct {
+	Amount int    `json:"amount,omitempty"`
+	Full   bool   `json:"full,omitempty"` // alle AP wiederherstellen
+	Notes  string `json:"notes,omitempty"`
+}
+
+// HealRequest repräsentiert eine Heilung von LP durch Ruhe oder Magie
+type HealRequest struct {
+	Method string `json:"method" binding:"required,oneof=rest magic"`
+	Days   int    `json:"days,omitempty"`   // Ruhetage (nur bei rest)
+	Amount int    `json:"amount,omitempty"` // geheilte LP (nur bei magic)
+	Notes  string `json:"notes,omitempty"`
+}
+
+// loadCharacterForVitals lädt einen Charakter mit den für LP/AP benötigten Beziehungen
+func loadCharacterForVitals(c *gin.Context) (*models.Char, bool) {
+	var character models.Char
+	err := database.DB.
+		Preload("Lp").
+		Preload("Ap").
+		Preload("Ausruestung").
+		First(&character, c.Param("id")).Error
+	if err != nil {
+		respondWithError(c, http.StatusNotFound, "Character not found")
+		return nil, false
+	}
+	return &character, true
+}
+
+// buildVitalsResponse erstellt die Antwort inklusive Zuständen und Warnungen
+func buildVitalsResponse(char *models.Char) VitalsResponse {
+	states := evaluateVitalStates(char.Lp, char.Ap, getVitalRules(char))
+	return VitalsResponse{
+		CharacterID: char.ID,
+		LP:          char.Lp,
+		AP:          char.Ap,
+		States:      states,
+		Warnings:    vitalWarnings(states),
+	}
+}
+
+// saveVitalChange speichert einen geänderten LP- oder AP-Wert mit tx und schreibt das Audit-Log
+func saveVitalChange(tx *gorm.DB, char *models.Char, fieldName string, oldValue, newValue int, reason AuditLogReason, userID uint, notes string) error {
+	if oldValue == newValue {
+		return nil
+	}
+
+	switch fieldName {
+	case "lp":
+		char.Lp.CharacterID = char.ID
+		char.Lp.Value = newValue
+		if err := tx.Save(&char.Lp).Error; err != nil {
+			return fmt.Errorf("failed to save LP: %w", err)
+		}
+	case "ap":
+		char.Ap.CharacterID = char.ID
+		char.Ap.Value = newValue
+		if err := tx.Save(&char.Ap).Error; err != nil {
+			return fmt.Errorf("failed to save AP: %w", err)
+		}
+	default:
+		return fmt.Errorf("unknown vital field: %s", fieldName)
+	}
+
+	if err := CreateAuditLogEntryTx(tx, char.ID, fieldName, oldValue, newValue, reason, userID, notes); err != nil {
+		return fmt.Errorf("failed to create audit log entry for %s: %w", fieldName, err)
+	}
+	return nil
+}
+
+// applyDamageLoss zieht LP- und AP-Verlust in einer Transaktion ab, AP fallen dabei nicht unter 0
+func applyDamageLoss(char *models.Char, lpLoss, apLoss int, userID uint, notes string) error {
+	oldLP := char.Lp.Value
+	oldAP := char.Ap.Value
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := saveVitalChange(tx, char, "lp", oldLP, oldLP-lpLoss, ReasonDamage, userID, notes); err != nil {
+			return err
+		}
+		return saveVitalChange(tx, char, "ap", oldAP, clampValue(oldAP-apLoss, 0, oldAP), ReasonDamage, userID, notes)
+	})
+	if err != nil {
+		char.Lp.Value = oldLP
+		char.Ap.Value = oldAP
+	}
+	return err
+}
+
+// GetCharacterVitals gibt die aktuellen LP/AP und Zustände eines Charakters zurück
+func GetCharacterVitals(c *gin.Context) {
+	char, ok := loadCharacterForVitals(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, buildVitalsResponse(char))
+}
+
+// ApplyDamage zieht Schaden unter Berücksichtigung des Rüstungsschutzes ab
+func ApplyDamage(c *gin.Context) {
+	char, ok := loadCharacterForVitals(c)
+	if !ok {
+		return
+	}
+	if !checkCharacterOwnership(c, char) {
+		return
+	}
+
+	var req ApplyDamageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	rules := getVitalRules(char)
+	armor := determineArmorProtection(char)
+	if req.Armor != nil {
+		armor = *req.Armor
+	}
+
+	lpLoss, apLoss := calculateDamageLoss(req.Damage, req.Heavy, armor, rules)
+	notes := req.Notes
+	if notes == "" {
+		notes = fmt.Sprintf("%d Schaden erlitten (RS %d)", req.Damage, armor)
+	}
+
+	oldAP := char.Ap.Value
+	if err := applyDamageLoss(char, lpLoss, apLoss, c.GetUint("userID"), notes); err != nil {
+		respondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"vitals":  buildVitalsResponse(char),
+		"armor":   armor,
+		"lp_loss": lpLoss,
+		"ap_loss": oldAP - char.Ap.Value,
+	})
+}
+
+// SpendAP verbraucht AP, z.B. für das Wirken eines Zaubers
+func SpendAP(c *gin.Context) {
+	char, ok := loadCharacterForVitals(c)
+	if !ok {
+		return
+	}
+	if !checkCharacterOwnership(c, char) {
+		return
+	}
+
+	var req SpendAPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if char.Ap.Value < req.Amount {
+		respondWithError(c, http.StatusBadRequest, fmt.Sprintf("Not enough AP: %d available, %d required", char.Ap.Value, req.Amount))
+		return
+	}
+
+	notes := req.Notes
+	if notes == "" && req.Spell != "" {
+		notes = fmt.Sprintf("%d AP für Zauber '%s' ausgegeben", req.Amount, req.Spell)
+	}
+
+	oldAP := char.Ap.Value
+	if err := saveVitalChange(database.DB, char, "ap", oldAP, oldAP-req.Amount, ReasonAPSpent, c.GetUint("userID"), notes); err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to update AP")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{"vitals": buildVitalsResponse(char)})
+}
+
+// RegainAP stellt AP wieder her, höchstens bis zum Maximum
+func RegainAP(c *gin.Context) {
+	char, ok := loadCharacterForVitals(c)
+	if !ok {
+		return
+	}
+	if !checkCharacterOwnership(c, char) {
+		return
+	}
+
+	var req RegainAPRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if !req.Full && req.Amount <= 0 {
+		respondWithError(c, http.StatusBadRequest, "Either amount or full must be given")
+		return
+	}
+
+	oldAP := char.Ap.Value
+	newAP := char.Ap.Max
+	if !req.Full {
+		newAP = clampValue(oldAP+req.Amount, oldAP, char.Ap.Max)
+	}
+
+	if err := saveVitalChange(database.DB, char, "ap", oldAP, newAP, ReasonRest, c.GetUint("userID"), req.Notes); err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to update AP")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"vitals":    buildVitalsResponse(char),
+		"ap_gained": newAP - oldAP,
+	})
+}
+
+// HealCharacter heilt LP durch Ruhe oder Magie, höchstens bis zum Maximum
+func HealCharacter(c *gin.Context) {
+	char, ok := loadCharacterForVitals(c)
+	if !ok {
+		return
+	}
+	if !checkCharacterOwnership(c, char) {
+		return
+	}
+
+	var req HealRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var healing int
+	var reason AuditLogReason
+	notes := req.Notes
+	if req.Method == "rest" {
+		var err error
+		healing, err = calculateRestHealing(req.Days, getVitalRules(char))
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, err.Error())
+			return
+		}
+		reason = ReasonRest
+		if notes == "" {
+			notes = fmt.Sprintf("%d Tage Ruhe", req.Days)
+		}
+	} else {
+		if req.Amount <= 0 {
+			respondWithError(c, http.StatusBadRequest, "Amount must be greater than 0 for magical healing")
+			return
+		}
+		healing = req.Amount
+		reason = ReasonHealing
+		if notes == "" {
+			notes = "Magische Heilung"
+		}
+	}
+
+	oldLP := char.Lp.Value
+	newLP := oldLP
+	if oldLP < char.Lp.Max {
+		newLP = clampValue(oldLP+healing, oldLP, char.Lp.Max)
+	}
+
+	if err := saveVitalChange(database.DB, char, "lp", oldLP, newLP, reason, c.GetUint("userID"), notes); err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to update LP")
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"vitals":    buildVitalsResponse(char),
+		"lp_healed": newLP - oldLP,
+	})
+}
@@ -0,0 +1,164 @@
+package character
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+)
+
+func TestCalculateDamageLoss(t *testing.T) {
+	testCases := []struct {
+		name   string
+		damage int
+		heavy  bool
+		armor  int
+		lpLoss int
+		apLoss int
+	}{
+		{"schwerer Schaden ohne Rüstung", 7, true, 0, 7, 7},
+		{"schwerer Schaden mit Lederrüstung", 7, true, 2, 5, 5},
+		{"Rüstung schluckt alles", 2, true, 4, 0, 0},
+		{"leichter Schaden kostet nur AP", 6, false, 2, 0, 4},
+		{"kein Schaden", 0, true, 2, 0, 0},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			lpLoss, apLoss := calculateDamageLoss(tc.damage, tc.heavy, tc.armor, defaultVitalRules)
+			assert.Equal(t, tc.lpLoss, lpLoss)
+			assert.Equal(t, tc.apLoss, apLoss)
+		})
+	}
+}
+
+func TestEvaluateVitalStates(t *testing.T) {
+	testCases := []struct {
+		name     string
+		lp       int
+		ap       int
+		expected []VitalState
+	}{
+		{"unverletzt", 14, 20, []VitalState{}},
+		{"erschöpft", 14, 0, []VitalState{StateExhausted}},
+		{"schwer verletzt", 7, 5, []VitalState{StateSeriouslyWounded}},
+		{"kampfunfähig", 3, 5, []VitalState{StateSeriouslyWounded, StateIncapacitated}},
+		{"bewusstlos", 0, 0, []VitalState{StateExhausted, StateSeriouslyWounded, StateIncapacitated, StateUnconscious}},
+		{"sterbend", -2, 0, []VitalState{StateExhausted, StateSeriouslyWounded, StateIncapacitated, StateUnconscious, StateDying}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			states := evaluateVitalStates(models.Lp{Max: 14, Value: tc.lp}, models.Ap{Max: 20, Value: tc.ap}, defaultVitalRules)
+			assert.Equal(t, tc.expected, states)
+		})
+	}
+}
+
+func TestDetermineArmorProtection(t *testing.T) {
+	char := &models.Char{
+		Ausruestung: []models.EqAusruestung{
+			{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Seil"}}, BeinhaltetIn: "Am Körper"},
+			{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Plattenrüstung"}}, BeinhaltetIn: "Packpferd"},
+			{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Lederrüstung"}}, BeinhaltetIn: "Am Körper"},
+		},
+	}
+
+	assert.Equal(t, 2, determineArmorProtection(char), "only worn armour should count")
+}
+
+func TestVitalsHandlers(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+
+	require.NoError(t, models.MigrateStructure())
+
+	owner := ensureUserExists(t, 201)
+	char := createCharacterOwnedBy(t, owner.UserID)
+	require.NoError(t, database.DB.Create(&models.Lp{CharacterID: char.ID, Max: 14, Value: 14}).Error)
+	require.NoError(t, database.DB.Create(&models.Ap{CharacterID: char.ID, Max: 20, Value: 20}).Error)
+	params := map[string]string{"id": fmt.Sprint(char.ID)}
+
+	t.Run("ApplyDamage reduces LP and AP by armour", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"damage": 13, "heavy": true, "armor": 2}, owner.UserID, params)
+
+		ApplyDamage(ctx)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var response struct {
+			Vitals VitalsResponse `json:"vitals"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, 3, response.Vitals.LP.Value)
+		assert.Equal(t, 9, response.Vitals.AP.Value)
+		assert.Contains(t, response.Vitals.States, StateIncapacitated)
+		assert.NotEmpty(t, response.Vitals.Warnings)
+
+		entries, err := GetAuditLogForField(char.ID, "lp")
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		assert.Equal(t, -11, entries[0].Difference)
+		assert.Equal(t, string(ReasonDamage), entries[0].Reason)
+	})
+
+	t.Run("ApplyDamage rolls back LP when the AP save fails", func(t *testing.T) {
+		failAP := func(db *gorm.DB) {
+			if _, ok := db.Statement.Dest.(*models.Ap); ok {
+				db.AddError(errors.New("AP speichern fehlgeschlagen"))
+			}
+		}
+		require.NoError(t, database.DB.Callback().Update().Before("gorm:update").Register("test:fail_ap", failAP))
+		t.Cleanup(func() { _ = database.DB.Callback().Update().Remove("test:fail_ap") })
+
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"damage": 2, "heavy": true, "armor": 0}, owner.UserID, params)
+		ApplyDamage(ctx)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		var lp models.Lp
+		require.NoError(t, database.DB.Where("character_id = ?", char.ID).First(&lp).Error)
+		assert.Equal(t, 3, lp.Value)
+		entries, err := GetAuditLogForField(char.ID, "lp")
+		require.NoError(t, err)
+		assert.Len(t, entries, 1)
+	})
+
+	t.Run("SpendAP rejects more AP than available", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"amount": 10, "spell": "Feuerlanze"}, owner.UserID, params)
+
+		SpendAP(ctx)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("HealCharacter caps LP at maximum", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"method": "magic", "amount": 50}, owner.UserID, params)
+
+		HealCharacter(ctx)
+
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var lp models.Lp
+		require.NoError(t, database.DB.Where("character_id = ?", char.ID).First(&lp).Error)
+		assert.Equal(t, 14, lp.Value)
+	})
+
+	t.Run("RegainAP blocks non-owner", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"full": true}, owner.UserID+1, params)
+
+		RegainAP(ctx)
+
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
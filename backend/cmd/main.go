@@ -1,10 +1,15 @@
 package main
 
 import (
+	"context"
+
 	"bamort/appsystem"
+	"bamort/calendar"
+	"bamort/campaign"
 	"bamort/character"
 	"bamort/config"
 	"bamort/database"
+	"bamort/encounter"
 	"bamort/equipment"
 	"bamort/gsmaster"
 	"bamort/importer"
@@ -12,6 +17,7 @@ import (
 	"bamort/maintenance"
 	"bamort/pdfrender"
 	"bamort/router"
+	"bamort/scheduler"
 	"bamort/transfer"
 	"bamort/user"
 
@@ -94,6 +100,10 @@ func main() {
 	pdfrender.RegisterRoutes(protected)
 	transfer.RegisterRoutes(protected)
 	appsystem.RegisterRoutes(protected)
+	encounter.RegisterRoutes(protected)
+	calendar.RegisterRoutes(protected)
+	campaign.RegisterRoutes(protected)
+	scheduler.RegisterRoutes(protected)
 
 	// Register public routes (no authentication)
 	pdfrender.RegisterPublicRoutes(r)
@@ -101,6 +111,13 @@ func main() {
 
 	logger.Info("API-Routen erfolgreich registriert")
 
+	// Hintergrundjobs (Sessions, PDF-Temp, Backups) starten
+	if err := scheduler.RegisterDefaultJobs(scheduler.Default); err != nil {
+		logger.Error("Fehler beim Registrieren der Hintergrundjobs: %s", err.Error())
+	} else {
+		scheduler.Default.Start(context.Background())
+	}
+
 	// Server starten
 	serverAddress := cfg.GetServerAddress()
 	logger.Info("Server startet auf Adresse: %s", serverAddress)
@@ -32,6 +32,15 @@ type Config struct {
 	// PDF Templates
 	TemplatesDir  string // Directory where PDF templates are stored
 	ExportTempDir string // Directory for temporary PDF exports
+
+	// Backups (Hintergrundjobs)
+	BackupDir  string // Verzeichnis für automatische Datenbank-Backups
+	BackupKeep int    // Anzahl aufzubewahrender Backups
+
+	// Charakterbilder
+	MediaMaxBytes  int // Maximale Größe eines hochgeladenen Bildes in Bytes
+	MediaThumbSize int // Kantenlänge der Vorschaubilder in Pixeln
+	MediaMaxPixels int // Maximale Bildfläche (Breite × Höhe) in Pixeln
 }
 
 // Cfg ist die globale Konfigurationsvariable
@@ -46,16 +55,21 @@ func init() {
 // defaultConfig gibt die Standard-Konfiguration zurück
 func defaultConfig() *Config {
 	return &Config{
-		ServerPort:    "8180",
-		DatabaseURL:   "",
-		DatabaseType:  "mysql",
-		DebugMode:     false,
-		LogLevel:      "INFO",
-		Environment:   "production",
-		DevTesting:    "no",                    // Default to "no", can be overridden in tests
-		FrontendURL:   "http://localhost:5173", // Default frontend URL for development
-		TemplatesDir:  "./templates",           // Default templates directory
-		ExportTempDir: "./xporttemp",           // Default export temp directory
+		ServerPort:     "8180",
+		DatabaseURL:    "",
+		DatabaseType:   "mysql",
+		DebugMode:      false,
+		LogLevel:       "INFO",
+		Environment:    "production",
+		DevTesting:     "no",                    // Default to "no", can be overridden in tests
+		FrontendURL:    "http://localhost:5173", // Default frontend URL for development
+		TemplatesDir:   "./templates",           // Default templates directory
+		ExportTempDir:  "./xporttemp",           // Default export temp directory
+		BackupDir:      "./backups",             // Default backup directory
+		BackupKeep:     7,                       // Default: eine Woche tägliche Backups
+		MediaMaxBytes:  2 << 20,                 // Default: 2 MiB je Bild
+		MediaThumbSize: 128,                     // Default: 128x128 Pixel
+		MediaMaxPixels: 4096 * 4096,             // Default: 16 Megapixel
 	}
 }
 
@@ -136,6 +150,33 @@ func LoadConfig() *Config {
 		config.ExportTempDir = exportTempDir
 	}
 
+	// Backups
+	if backupDir := os.Getenv("BACKUP_DIR"); backupDir != "" {
+		config.BackupDir = backupDir
+	}
+	if backupKeep := os.Getenv("BACKUP_KEEP"); backupKeep != "" {
+		if keep, err := strconv.Atoi(backupKeep); err == nil && keep > 0 {
+			config.BackupKeep = keep
+		}
+	}
+
+	// Charakterbilder
+	if maxBytes := os.Getenv("MEDIA_MAX_BYTES"); maxBytes != "" {
+		if size, err := strconv.Atoi(maxBytes); err == nil && size > 0 {
+			config.MediaMaxBytes = size
+		}
+	}
+	if thumbSize := os.Getenv("MEDIA_THUMB_SIZE"); thumbSize != "" {
+		if size, err := strconv.Atoi(thumbSize); err == nil && size > 0 {
+			config.MediaThumbSize = size
+		}
+	}
+	if maxPixels := os.Getenv("MEDIA_MAX_PIXELS"); maxPixels != "" {
+		if pixels, err := strconv.Atoi(maxPixels); err == nil && pixels > 0 {
+			config.MediaMaxPixels = pixels
+		}
+	}
+
 	fmt.Printf("DEBUG LoadConfig - Finale Config: Environment='%s', DevTesting='%s', DatabaseType='%s'\n Complete: %v\n",
 		config.Environment, config.DevTesting, config.DatabaseType, config)
 
@@ -123,10 +123,20 @@ func (s *StringArray) Scan(value interface{}) error {
 		return nil
 	}
 
-	bytes, ok := value.([]byte)
-	if !ok {
+	// Je nach Treiber und Herkunft der Daten (z.B. importierte Dumps) liegt der Wert als TEXT vor
+	var bytes []byte
+	switch v := value.(type) {
+	case []byte:
+		bytes = v
+	case string:
+		bytes = []byte(v)
+	default:
 		return errors.New("failed to convert database value to []byte")
 	}
+	if len(bytes) == 0 {
+		*s = []string{}
+		return nil
+	}
 
 	return json.Unmarshal(bytes, s) // Deserialize JSON to []string
 }
@@ -0,0 +1,579 @@
+package encounter
+
+import (
+	"bamort/character"
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// CreateEncounterRequest repräsentiert eine neue Begegnung
+type CreateEncounterRequest struct {
+	Name        string `json:"name" binding:"required"`
+	GameMasters []uint `json:"game_masters,omitempty"`
+}
+
+// AddParticipantRequest fügt einen Spielercharakter (character_id) oder einen NSC hinzu
+type AddParticipantRequest struct {
+	CharacterID uint                     `json:"character_id,omitempty"`
+	Name        string                   `json:"name,omitempty"`
+	Lp          int                      `json:"lp,omitempty"`
+	Ap          int                      `json:"ap,omitempty"`
+	Abwehr      int                      `json:"abwehr,omitempty"`
+	Attacks     []models.EncounterAttack `json:"attacks,omitempty"`
+	Initiative  int                      `json:"initiative,omitempty"`
+}
+
+// InitiativeRequest setzt Initiativewerte oder lässt sie serverseitig würfeln
+type InitiativeRequest struct {
+	Values map[uint]int `json:"values,omitempty"` // Teilnehmer-ID -> Initiative
+	Roll   bool         `json:"roll,omitempty"`   // fehlende Werte mit 1d20 würfeln
+}
+
+// VitalChangeRequest ändert LP/AP eines Teilnehmers
+type VitalChangeRequest struct {
+	LpDelta int `json:"lp_delta"`
+	ApDelta int `json:"ap_delta"`
+}
+
+func respondWithError(c *gin.Context, status int, message string) {
+	logger.Warn("HTTP Fehler %d: %s", status, message)
+	c.JSON(status, gin.H{"error": message})
+}
+
+// canWrite prüft, ob der Benutzer die Begegnung leiten darf
+func canWrite(enc *models.Encounter, userID uint) bool {
+	return enc.UserID == userID || slices.Contains(enc.GameMasters, userID)
+}
+
+// playerEncounterIDs liefert die IDs der Begegnungen, in denen ein Charakter des Benutzers teilnimmt
+func playerEncounterIDs(userID uint) *gorm.DB {
+	return database.DB.Model(&models.EncounterParticipant{}).
+		Select("enc_participants.encounter_id").
+		Joins("JOIN char_chars ON char_chars.id = enc_participants.character_id").
+		Where("char_chars.user_id = ?", userID)
+}
+
+// visibleEncounters grenzt auf Begegnungen ein, die der Benutzer leitet oder in denen er mitspielt
+// game_masters ist eine JSON-Liste wie [3,12]; die Muster treffen die ID nur als ganzes Element.
+func visibleEncounters(userID uint) func(db *gorm.DB) *gorm.DB {
+	id := strconv.FormatUint(uint64(userID), 10)
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("user_id = ? OR game_masters = ? OR game_masters LIKE ? OR game_masters LIKE ? OR game_masters LIKE ? OR id IN (?)",
+			userID, "["+id+"]", "["+id+",%", "%,"+id+"]", "%,"+id+",%", playerEncounterIDs(userID))
+	}
+}
+
+// canRead prüft, ob der Benutzer die Begegnung sehen darf (Spielleiter oder Spieler eines Teilnehmers)
+func canRead(enc *models.Encounter, userID uint) bool {
+	if canWrite(enc, userID) {
+		return true
+	}
+	var count int64
+	playerEncounterIDs(userID).Where("enc_participants.encounter_id = ?", enc.ID).Count(&count)
+	return count > 0
+}
+
+// loadEncounter lädt eine Begegnung mit Teilnehmern in Initiative-Reihenfolge und prüft die Rechte
+func loadEncounter(c *gin.Context, write bool) (*models.Encounter, bool) {
+	var enc models.Encounter
+	err := database.DB.
+		Preload("Participants", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC, id ASC") }).
+		First(&enc, c.Param("id")).Error
+	if err != nil {
+		respondWithError(c, http.StatusNotFound, "Encounter not found")
+		return nil, false
+	}
+
+	userID := c.GetUint("userID")
+	if write && !canWrite(&enc, userID) {
+		respondWithError(c, http.StatusForbidden, "Only game masters can modify this encounter")
+		return nil, false
+	}
+	if !write && !canRead(&enc, userID) {
+		respondWithError(c, http.StatusForbidden, "You are not allowed to view this encounter")
+		return nil, false
+	}
+	return &enc, true
+}
+
+// findParticipant sucht einen Teilnehmer der Begegnung anhand der URL
+func findParticipant(c *gin.Context, enc *models.Encounter) (*models.EncounterParticipant, bool) {
+	id, err := strconv.ParseUint(c.Param("participantId"), 10, 32)
+	if err == nil {
+		for i := range enc.Participants {
+			if enc.Participants[i].ID == uint(id) {
+				return &enc.Participants[i], true
+			}
+		}
+	}
+	respondWithError(c, http.StatusNotFound, "Participant not found")
+	return nil, false
+}
+
+// saveEncounter speichert die Begegnung und alle Teilnehmer
+func saveEncounter(tx *gorm.DB, enc *models.Encounter) error {
+	if err := tx.Omit(clause.Associations).Save(enc).Error; err != nil {
+		return fmt.Errorf("failed to save encounter: %w", err)
+	}
+	for i := range enc.Participants {
+		if err := tx.Save(&enc.Participants[i]).Error; err != nil {
+			return fmt.Errorf("failed to save participant %s: %w", enc.Participants[i].Name, err)
+		}
+	}
+	return nil
+}
+
+// canWriteCharacter prüft, ob der Benutzer LP/AP eines Charakters ändern darf
+// (eigener Charakter oder Freigabe mit Schreibrecht; öffentlich oder lesend geteilt genügt nicht)
+func canWriteCharacter(tx *gorm.DB, characterID, userID uint) bool {
+	var char models.Char
+	if err := tx.Select("id", "user_id").First(&char, characterID).Error; err != nil {
+		return false
+	}
+	if char.UserID == userID {
+		return true
+	}
+	var share models.CharShare
+	return tx.Where("character_id = ? AND user_id = ? AND permission = ?", characterID, userID, "write").First(&share).Error == nil
+}
+
+// participantFromCharacter übernimmt LP/AP, Abwehr und Angriffe eines Spielercharakters
+func participantFromCharacter(char *models.Char) models.EncounterParticipant {
+	static := character.CalculateStaticFieldsForCharacter(char)
+	attacks := make([]models.EncounterAttack, 0, len(char.Waffen))
+	for i := range char.Waffen {
+		var baseWeapon *models.Weapon
+		candidate := &models.Weapon{}
+		if err := candidate.First(char.Waffen[i].Name); err == nil && candidate.ID > 0 {
+			baseWeapon = candidate
+		}
+		attacks = append(attacks, models.EncounterAttack{
+			Name:   char.Waffen[i].Name,
+			Value:  character.CalculateWeaponAttack(char, &char.Waffen[i], baseWeapon, static).Total,
+			Damage: character.FormatWeaponDamage(baseWeapon, static.SchadensBonus, char.Waffen[i].Schb),
+		})
+	}
+
+	return models.EncounterParticipant{
+		CharacterID: char.ID,
+		Name:        char.Name,
+		LpMax:       char.Lp.Max,
+		Lp:          char.Lp.Value,
+		LpStart:     char.Lp.Value,
+		ApMax:       char.Ap.Max,
+		Ap:          char.Ap.Value,
+		ApStart:     char.Ap.Value,
+		Abwehr:      static.Abwehr,
+		Attacks:     attacks,
+		Conditions:  []models.EncounterCondition{},
+	}
+}
+
+// ListEncounters listet alle Begegnungen, die der Benutzer leitet oder in denen er mitspielt
+func ListEncounters(c *gin.Context) {
+	userID := c.GetUint("userID")
+
+	encounters := make([]models.Encounter, 0)
+	err := database.DB.Scopes(visibleEncounters(userID)).
+		Preload("Participants", func(db *gorm.DB) *gorm.DB { return db.Order("position ASC, id ASC") }).
+		Order("updated_at DESC").Find(&encounters).Error
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve encounters")
+		return
+	}
+	c.JSON(http.StatusOK, encounters)
+}
+
+// CreateEncounter legt eine neue Begegnung an
+func CreateEncounter(c *gin.Context) {
+	var req CreateEncounterRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	enc := models.Encounter{
+		Name:         req.Name,
+		UserID:       c.GetUint("userID"),
+		GameMasters:  req.GameMasters,
+		Status:       models.EncounterStatusPreparing,
+		Participants: []models.EncounterParticipant{},
+	}
+	if enc.GameMasters == nil {
+		enc.GameMasters = []uint{}
+	}
+	if err := database.DB.Create(&enc).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to create encounter")
+		return
+	}
+
+	c.JSON(http.StatusCreated, enc)
+}
+
+// GetEncounter gibt den aktuellen Stand einer Begegnung zurück
+func GetEncounter(c *gin.Context) {
+	enc, ok := loadEncounter(c, false)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, enc)
+}
+
+// DeleteEncounter löscht eine Begegnung ohne Zurückschreiben der LP/AP
+func DeleteEncounter(c *gin.Context) {
+	enc, ok := loadEncounter(c, true)
+	if !ok {
+		return
+	}
+	if enc.UserID != c.GetUint("userID") {
+		respondWithError(c, http.StatusForbidden, "Only the creator can delete this encounter")
+		return
+	}
+
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("encounter_id = ?", enc.ID).Delete(&models.EncounterParticipant{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(enc).Error
+	})
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to delete encounter")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Encounter deleted"})
+}
+
+// AddParticipant fügt einen Spielercharakter oder NSC hinzu
+func AddParticipant(c *gin.Context) {
+	enc, ok := loadEncounter(c, true)
+	if !ok {
+		return
+	}
+	if enc.Status == models.EncounterStatusFinished {
+		respondWithError(c, http.StatusBadRequest, "Encounter is already finished")
+		return
+	}
+
+	var req AddParticipantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var participant models.EncounterParticipant
+	if req.CharacterID != 0 {
+		for _, p := range enc.Participants {
+			if p.CharacterID == req.CharacterID {
+				respondWithError(c, http.StatusConflict, "Character is already part of this encounter")
+				return
+			}
+		}
+		var char models.Char
+		if err := char.FirstID(strconv.FormatUint(uint64(req.CharacterID), 10)); err != nil {
+			respondWithError(c, http.StatusNotFound, "Character not found")
+			return
+		}
+		if !character.CanReadCharacter(&char, c.GetUint("userID")) {
+			respondWithError(c, http.StatusForbidden, "Character is not available to you")
+			return
+		}
+		participant = participantFromCharacter(&char)
+	} else {
+		if req.Name == "" || req.Lp <= 0 {
+			respondWithError(c, http.StatusBadRequest, "NPCs need a name and LP")
+			return
+		}
+		participant = models.EncounterParticipant{
+			Name:       req.Name,
+			LpMax:      req.Lp,
+			Lp:         req.Lp,
+			LpStart:    req.Lp,
+			ApMax:      req.Ap,
+			Ap:         req.Ap,
+			ApStart:    req.Ap,
+			Abwehr:     req.Abwehr,
+			Attacks:    req.Attacks,
+			Conditions: []models.EncounterCondition{},
+		}
+		if participant.Attacks == nil {
+			participant.Attacks = []models.EncounterAttack{}
+		}
+	}
+	participant.EncounterID = enc.ID
+	participant.Initiative = req.Initiative
+	participant.Position = len(enc.Participants)
+
+	if err := database.DB.Create(&participant).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to add participant")
+		return
+	}
+
+	c.JSON(http.StatusCreated, participant)
+}
+
+// RemoveParticipant entfernt einen Teilnehmer aus der Begegnung
+func RemoveParticipant(c *gin.Context) {
+	enc, ok := loadEncounter(c, true)
+	if !ok {
+		return
+	}
+	participant, ok := findParticipant(c, enc)
+	if !ok {
+		return
+	}
+	if enc.Status == models.EncounterStatusActive {
+		respondWithError(c, http.StatusBadRequest, "Participants can only be removed before the encounter starts; mark them as defeated instead")
+		return
+	}
+
+	if err := database.DB.Delete(participant).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to remove participant")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Participant removed"})
+}
+
+// SetInitiative setzt Initiativewerte und sortiert die Reihenfolge neu
+func SetInitiative(c *gin.Context) {
+	enc, ok := loadEncounter(c, true)
+	if !ok {
+		return
+	}
+	if enc.Status != models.EncounterStatusPreparing {
+		respondWithError(c, http.StatusBadRequest, "Initiative can only be set before the encounter starts")
+		return
+	}
+
+	var req InitiativeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	for i := range enc.Participants {
+		p := &enc.Participants[i]
+		if value, ok := req.Values[p.ID]; ok {
+			p.Initiative = value
+		} else if req.Roll {
+			result, err := character.RollDice("1d20")
+			if err != nil {
+				respondWithError(c, http.StatusInternalServerError, err.Error())
+				return
+			}
+			p.Initiative = result.Total
+		}
+	}
+	sortByInitiative(enc.Participants)
+
+	if err := saveEncounter(database.DB, enc); err != nil {
+		respondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, enc)
+}
+
+// StartEncounter beginnt die erste Runde
+func StartEncounter(c *gin.Context) {
+	enc, ok := loadEncounter(c, true)
+	if !ok {
+		return
+	}
+	if err := startEncounter(enc); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := saveEncounter(database.DB, enc); err != nil {
+		respondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, enc)
+}
+
+// NextTurn gibt den Zug an den nächsten Teilnehmer weiter
+func NextTurn(c *gin.Context) {
+	enc, ok := loadEncounter(c, true)
+	if !ok {
+		return
+	}
+	expired, err := advanceTurn(enc)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := saveEncounter(database.DB, enc); err != nil {
+		respondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"encounter":          enc,
+		"expired_conditions": expired,
+	})
+}
+
+// ChangeParticipantVitals ändert LP/AP eines Teilnehmers während der Begegnung
+func ChangeParticipantVitals(c *gin.Context) {
+	enc, ok := loadEncounter(c, true)
+	if !ok {
+		return
+	}
+	participant, ok := findParticipant(c, enc)
+	if !ok {
+		return
+	}
+	if enc.Status == models.EncounterStatusFinished {
+		respondWithError(c, http.StatusBadRequest, "Encounter is already finished")
+		return
+	}
+
+	var req VitalChangeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	applyVitalChange(participant, req.LpDelta, req.ApDelta)
+	if err := database.DB.Save(participant).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to update participant")
+		return
+	}
+	c.JSON(http.StatusOK, participant)
+}
+
+// AddParticipantCondition setzt einen Zustand mit optionaler Dauer in Runden
+func AddParticipantCondition(c *gin.Context) {
+	enc, ok := loadEncounter(c, true)
+	if !ok {
+		return
+	}
+	participant, ok := findParticipant(c, enc)
+	if !ok {
+		return
+	}
+
+	var cond models.EncounterCondition
+	if err := c.ShouldBindJSON(&cond); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return
+	}
+	if cond.Name == "" || cond.Rounds < 0 {
+		respondWithError(c, http.StatusBadRequest, "Condition needs a name and a non-negative duration")
+		return
+	}
+
+	addCondition(participant, cond)
+	if err := database.DB.Save(participant).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to update participant")
+		return
+	}
+	c.JSON(http.StatusOK, participant)
+}
+
+// RemoveParticipantCondition hebt einen Zustand auf
+func RemoveParticipantCondition(c *gin.Context) {
+	enc, ok := loadEncounter(c, true)
+	if !ok {
+		return
+	}
+	participant, ok := findParticipant(c, enc)
+	if !ok {
+		return
+	}
+
+	if !removeCondition(participant, c.Param("condition")) {
+		respondWithError(c, http.StatusNotFound, "Condition not found")
+		return
+	}
+	if err := database.DB.Save(participant).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to update participant")
+		return
+	}
+	c.JSON(http.StatusOK, participant)
+}
+
+// writeBackVitals überträgt die LP/AP-Änderungen eines Teilnehmers auf den Charakter
+// Es wird die Differenz seit dem Hinzufügen angewendet, damit zwischenzeitliche
+// Änderungen am Charakter erhalten bleiben.
+func writeBackVitals(tx *gorm.DB, enc *models.Encounter, p *models.EncounterParticipant, userID uint) error {
+	var lp models.Lp
+	if err := tx.Where("character_id = ?", p.CharacterID).First(&lp).Error; err != nil {
+		return fmt.Errorf("failed to load LP of %s: %w", p.Name, err)
+	}
+	var ap models.Ap
+	if err := tx.Where("character_id = ?", p.CharacterID).First(&ap).Error; err != nil {
+		return fmt.Errorf("failed to load AP of %s: %w", p.Name, err)
+	}
+
+	notes := fmt.Sprintf("Begegnung '%s' (Runde %d)", enc.Name, enc.Round)
+	oldLP, oldAP := lp.Value, ap.Value
+	lp.Value = min(lp.Value+p.Lp-p.LpStart, lp.Max)
+	ap.Value = max(min(ap.Value+p.Ap-p.ApStart, ap.Max), 0)
+
+	if lp.Value != oldLP {
+		if err := tx.Save(&lp).Error; err != nil {
+			return fmt.Errorf("failed to save LP of %s: %w", p.Name, err)
+		}
+		if err := character.CreateAuditLogEntryTx(tx, p.CharacterID, "lp", oldLP, lp.Value, character.ReasonEncounter, userID, notes); err != nil {
+			return fmt.Errorf("failed to create audit log entry for LP of %s: %w", p.Name, err)
+		}
+	}
+	if ap.Value != oldAP {
+		if err := tx.Save(&ap).Error; err != nil {
+			return fmt.Errorf("failed to save AP of %s: %w", p.Name, err)
+		}
+		if err := character.CreateAuditLogEntryTx(tx, p.CharacterID, "ap", oldAP, ap.Value, character.ReasonEncounter, userID, notes); err != nil {
+			return fmt.Errorf("failed to create audit log entry for AP of %s: %w", p.Name, err)
+		}
+	}
+	return nil
+}
+
+// FinishEncounter beendet die Begegnung und schreibt LP/AP der Spielercharaktere zurück
+// Zurückgeschrieben wird nur bei Charakteren, die der Spielleiter ändern darf; die übrigen bleiben unverändert.
+func FinishEncounter(c *gin.Context) {
+	enc, ok := loadEncounter(c, true)
+	if !ok {
+		return
+	}
+	if enc.Status == models.EncounterStatusFinished {
+		respondWithError(c, http.StatusBadRequest, "Encounter is already finished")
+		return
+	}
+
+	userID := c.GetUint("userID")
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		for i := range enc.Participants {
+			if enc.Participants[i].IsNPC() {
+				continue
+			}
+			if !canWriteCharacter(tx, enc.Participants[i].CharacterID, userID) {
+				logger.Info("Begegnung %d: LP/AP von %s nicht zurückgeschrieben, keine Schreibberechtigung für Charakter %d", enc.ID, enc.Participants[i].Name, enc.Participants[i].CharacterID)
+				continue
+			}
+			if err := writeBackVitals(tx, enc, &enc.Participants[i], userID); err != nil {
+				return err
+			}
+		}
+		now := time.Now()
+		enc.Status = models.EncounterStatusFinished
+		enc.FinishedAt = &now
+		return saveEncounter(tx, enc)
+	})
+	if err != nil {
+		logger.Error("Fehler beim Abschließen der Begegnung %d: %s", enc.ID, err.Error())
+		respondWithError(c, http.StatusInternalServerError, "Failed to finish encounter")
+		return
+	}
+
+	logger.Info("Begegnung %d '%s' nach %d Runden beendet", enc.ID, enc.Name, enc.Round)
+	c.JSON(http.StatusOK, enc)
+}
@@ -0,0 +1,197 @@
+package encounter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func buildJSONContext(t *testing.T, method string, body any, userID uint, params map[string]string) (*gin.Context, *httptest.ResponseRecorder) {
+	var buf bytes.Buffer
+	if body != nil {
+		require.NoError(t, json.NewEncoder(&buf).Encode(body))
+	}
+
+	req, err := http.NewRequest(method, "/", &buf)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	ctx, _ := gin.CreateTestContext(w)
+	ctx.Request = req
+	ctx.Set("userID", userID)
+
+	for k, v := range params {
+		ctx.Params = append(ctx.Params, gin.Param{Key: k, Value: v})
+	}
+
+	return ctx, w
+}
+
+func createCharacterWithVitals(t *testing.T, ownerID uint, lp, ap int) models.Char {
+	char := models.Char{
+		BamortBase: models.BamortBase{Name: fmt.Sprintf("Held-%d", ownerID)},
+		UserID:     ownerID,
+		Typ:        "Krieger",
+		Rasse:      "Mensch",
+		Grad:       1,
+	}
+	require.NoError(t, database.DB.Create(&char).Error)
+	require.NoError(t, database.DB.Create(&models.Lp{CharacterID: char.ID, Max: lp, Value: lp}).Error)
+	require.NoError(t, database.DB.Create(&models.Ap{CharacterID: char.ID, Max: ap, Value: ap}).Error)
+	return char
+}
+
+func TestEncounterFlow(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+
+	require.NoError(t, models.MigrateStructure())
+
+	const gmID, playerID, strangerID, bystanderID uint = 301, 302, 303, 304
+	hero := createCharacterWithVitals(t, playerID, 14, 20)
+	hero.Public = true
+	require.NoError(t, database.DB.Save(&hero).Error)
+	require.NoError(t, database.DB.Create(&models.CharShare{CharacterID: hero.ID, UserID: gmID, Permission: "write"}).Error)
+	// öffentlich, aber ohne Schreibrecht für den Spielleiter
+	bystander := createCharacterWithVitals(t, bystanderID, 12, 18)
+	bystander.Public = true
+	require.NoError(t, database.DB.Save(&bystander).Error)
+
+	ctx, w := buildJSONContext(t, http.MethodPost, map[string]any{"name": "Hinterhalt"}, gmID, nil)
+	CreateEncounter(ctx)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var enc models.Encounter
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &enc))
+	params := map[string]string{"id": fmt.Sprint(enc.ID)}
+
+	ctx, w = buildJSONContext(t, http.MethodPost, map[string]any{"character_id": hero.ID, "initiative": 12}, gmID, params)
+	AddParticipant(ctx)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var heroParticipant models.EncounterParticipant
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &heroParticipant))
+	assert.Equal(t, 14, heroParticipant.Lp)
+
+	ctx, w = buildJSONContext(t, http.MethodPost, map[string]any{"character_id": bystander.ID, "initiative": 3}, gmID, params)
+	AddParticipant(ctx)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var bystanderParticipant models.EncounterParticipant
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &bystanderParticipant))
+
+	ctx, w = buildJSONContext(t, http.MethodPost, map[string]any{
+		"name": "Räuber", "lp": 8, "ap": 10, "abwehr": 11, "initiative": 15,
+		"attacks": []map[string]any{{"name": "Keule", "value": 7, "damage": "1W6"}},
+	}, gmID, params)
+	AddParticipant(ctx)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+
+	t.Run("strangers can neither read nor modify", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodGet, nil, strangerID, params)
+		GetEncounter(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		ctx, w = buildJSONContext(t, http.MethodPost, nil, strangerID, params)
+		StartEncounter(ctx)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("players of participating characters can read", func(t *testing.T) {
+		ctx, w := buildJSONContext(t, http.MethodGet, nil, playerID, params)
+		GetEncounter(ctx)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	ctx, w = buildJSONContext(t, http.MethodPost, nil, gmID, params)
+	StartEncounter(ctx)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &enc))
+	assert.Equal(t, "Räuber", enc.Participants[enc.Turn].Name, "highest initiative starts")
+
+	heroParams := map[string]string{"id": fmt.Sprint(enc.ID), "participantId": fmt.Sprint(heroParticipant.ID)}
+	ctx, w = buildJSONContext(t, http.MethodPut, map[string]any{"lp_delta": -5, "ap_delta": -6}, gmID, heroParams)
+	ChangeParticipantVitals(ctx)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	bystanderParams := map[string]string{"id": fmt.Sprint(enc.ID), "participantId": fmt.Sprint(bystanderParticipant.ID)}
+	ctx, w = buildJSONContext(t, http.MethodPut, map[string]any{"lp_delta": -4}, gmID, bystanderParams)
+	ChangeParticipantVitals(ctx)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	// zwischenzeitliche Heilung am Charakter bleibt beim Zurückschreiben erhalten
+	require.NoError(t, database.DB.Model(&models.Lp{}).Where("character_id = ?", hero.ID).Update("max", 16).Error)
+	require.NoError(t, database.DB.Model(&models.Lp{}).Where("character_id = ?", hero.ID).Update("value", 16).Error)
+
+	ctx, w = buildJSONContext(t, http.MethodPost, nil, gmID, params)
+	FinishEncounter(ctx)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var lp models.Lp
+	require.NoError(t, database.DB.Where("character_id = ?", hero.ID).First(&lp).Error)
+	assert.Equal(t, 11, lp.Value)
+	var ap models.Ap
+	require.NoError(t, database.DB.Where("character_id = ?", hero.ID).First(&ap).Error)
+	assert.Equal(t, 14, ap.Value)
+
+	var entries []models.AuditLogEntry
+	require.NoError(t, database.DB.Where("character_id = ? AND reason = ?", hero.ID, "encounter").Find(&entries).Error)
+	assert.Len(t, entries, 2)
+
+	var bystanderLP models.Lp
+	require.NoError(t, database.DB.Where("character_id = ?", bystander.ID).First(&bystanderLP).Error)
+	assert.Equal(t, 12, bystanderLP.Value, "characters without write access keep their vitals")
+
+	ctx, w = buildJSONContext(t, http.MethodPost, nil, gmID, params)
+	FinishEncounter(ctx)
+	assert.Equal(t, http.StatusBadRequest, w.Code, "finishing twice must not write back again")
+}
+
+func TestListEncountersVisibility(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+
+	require.NoError(t, models.MigrateStructure())
+
+	const ownerID, gmID, playerID, strangerID uint = 401, 12, 403, 1
+	hero := createCharacterWithVitals(t, playerID, 14, 20)
+
+	led := models.Encounter{Name: "Überfall", UserID: ownerID, GameMasters: []uint{3, gmID}}
+	require.NoError(t, database.DB.Create(&led).Error)
+	require.NoError(t, database.DB.Create(&models.EncounterParticipant{EncounterID: led.ID, CharacterID: hero.ID, Name: hero.Name}).Error)
+	other := models.Encounter{Name: "Taverne", UserID: ownerID, GameMasters: []uint{}}
+	require.NoError(t, database.DB.Create(&other).Error)
+
+	list := func(userID uint) []string {
+		ctx, w := buildJSONContext(t, http.MethodGet, nil, userID, nil)
+		ListEncounters(ctx)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var encounters []models.Encounter
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &encounters))
+		names := make([]string, 0, len(encounters))
+		for _, enc := range encounters {
+			names = append(names, enc.Name)
+		}
+		return names
+	}
+
+	assert.ElementsMatch(t, []string{"Überfall", "Taverne"}, list(ownerID))
+	assert.Equal(t, []string{"Überfall"}, list(gmID))
+	assert.Equal(t, []string{"Überfall"}, list(playerID))
+	assert.Empty(t, list(strangerID), "user 1 must not match game master 12")
+}
@@ -0,0 +1,26 @@
+package encounter
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.RouterGroup) {
+	encGrp := r.Group("/encounters")
+	encGrp.GET("", ListEncounters)
+	encGrp.POST("", CreateEncounter)
+	encGrp.GET("/:id", GetEncounter)
+	encGrp.DELETE("/:id", DeleteEncounter)
+
+	// Teilnehmer (Spielercharaktere und NSC)
+	encGrp.POST("/:id/participants", AddParticipant)
+	encGrp.DELETE("/:id/participants/:participantId", RemoveParticipant)
+	encGrp.PUT("/:id/participants/:participantId/vitals", ChangeParticipantVitals)
+	encGrp.POST("/:id/participants/:participantId/conditions", AddParticipantCondition)
+	encGrp.DELETE("/:id/participants/:participantId/conditions/:condition", RemoveParticipantCondition)
+
+	// Ablauf: Initiative, Start, Zugwechsel, Abschluss
+	encGrp.POST("/:id/initiative", SetInitiative)
+	encGrp.POST("/:id/start", StartEncounter)
+	encGrp.POST("/:id/next-turn", NextTurn)
+	encGrp.POST("/:id/finish", FinishEncounter)
+}
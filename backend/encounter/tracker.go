@@ -0,0 +1,142 @@
+package encounter
+
+import (
+	"bamort/models"
+	"fmt"
+	"slices"
+	"sort"
+)
+
+// ExpiredCondition beschreibt einen Zustand, der beim Rundenwechsel abgelaufen ist
+type ExpiredCondition struct {
+	ParticipantID uint   `json:"participant_id"`
+	Participant   string `json:"participant"`
+	Condition     string `json:"condition"`
+}
+
+// sortByInitiative sortiert die Teilnehmer absteigend nach Initiative und nummeriert die Positionen
+// Bei Gleichstand bleibt die Reihenfolge des Hinzufügens erhalten.
+func sortByInitiative(participants []models.EncounterParticipant) {
+	sort.SliceStable(participants, func(i, j int) bool {
+		return participants[i].Initiative > participants[j].Initiative
+	})
+	for i := range participants {
+		participants[i].Position = i
+	}
+}
+
+// sortByPosition stellt die gespeicherte Initiative-Reihenfolge wieder her
+func sortByPosition(participants []models.EncounterParticipant) {
+	sort.SliceStable(participants, func(i, j int) bool {
+		return participants[i].Position < participants[j].Position
+	})
+}
+
+// startEncounter beginnt die erste Runde beim Teilnehmer mit der höchsten Initiative
+func startEncounter(enc *models.Encounter) error {
+	if enc.Status != models.EncounterStatusPreparing {
+		return fmt.Errorf("begegnung ist nicht in Vorbereitung")
+	}
+	if len(enc.Participants) == 0 {
+		return fmt.Errorf("begegnung hat keine Teilnehmer")
+	}
+	sortByInitiative(enc.Participants)
+	enc.Status = models.EncounterStatusActive
+	enc.Round = 1
+	enc.Turn = firstActiveTurn(enc.Participants, 0)
+	return nil
+}
+
+// firstActiveTurn sucht ab Index start den nächsten nicht besiegten Teilnehmer
+func firstActiveTurn(participants []models.EncounterParticipant, start int) int {
+	for i := start; i < len(participants); i++ {
+		if !participants[i].Defeated {
+			return i
+		}
+	}
+	return -1
+}
+
+// advanceTurn gibt den Zug an den nächsten Teilnehmer weiter
+// Besiegte Teilnehmer werden übersprungen. Nach dem letzten Teilnehmer beginnt
+// eine neue Runde, dabei laufen Zustände mit begrenzter Dauer ab.
+func advanceTurn(enc *models.Encounter) ([]ExpiredCondition, error) {
+	if enc.Status != models.EncounterStatusActive {
+		return nil, fmt.Errorf("begegnung ist nicht aktiv")
+	}
+	sortByPosition(enc.Participants)
+
+	next := firstActiveTurn(enc.Participants, enc.Turn+1)
+	if next >= 0 {
+		enc.Turn = next
+		return []ExpiredCondition{}, nil
+	}
+
+	next = firstActiveTurn(enc.Participants, 0)
+	if next < 0 {
+		return nil, fmt.Errorf("alle Teilnehmer sind besiegt")
+	}
+	enc.Round++
+	enc.Turn = next
+	return tickConditions(enc.Participants), nil
+}
+
+// tickConditions verringert die Restdauer aller Zustände um eine Runde und entfernt abgelaufene
+func tickConditions(participants []models.EncounterParticipant) []ExpiredCondition {
+	expired := make([]ExpiredCondition, 0)
+	for i := range participants {
+		remaining := make([]models.EncounterCondition, 0, len(participants[i].Conditions))
+		for _, cond := range participants[i].Conditions {
+			if cond.Rounds == 0 {
+				remaining = append(remaining, cond)
+				continue
+			}
+			cond.Rounds--
+			if cond.Rounds <= 0 {
+				expired = append(expired, ExpiredCondition{
+					ParticipantID: participants[i].ID,
+					Participant:   participants[i].Name,
+					Condition:     cond.Name,
+				})
+				continue
+			}
+			remaining = append(remaining, cond)
+		}
+		participants[i].Conditions = remaining
+	}
+	return expired
+}
+
+// addCondition setzt einen Zustand, ein vorhandener gleichen Namens wird ersetzt
+func addCondition(p *models.EncounterParticipant, cond models.EncounterCondition) {
+	p.Conditions = slices.DeleteFunc(p.Conditions, func(c models.EncounterCondition) bool {
+		return c.Name == cond.Name
+	})
+	p.Conditions = append(p.Conditions, cond)
+}
+
+// removeCondition entfernt einen Zustand und meldet, ob er vorhanden war
+func removeCondition(p *models.EncounterParticipant, name string) bool {
+	before := len(p.Conditions)
+	p.Conditions = slices.DeleteFunc(p.Conditions, func(c models.EncounterCondition) bool {
+		return c.Name == name
+	})
+	return len(p.Conditions) != before
+}
+
+// applyVitalChange ändert LP/AP eines Teilnehmers, AP bleiben zwischen 0 und Maximum, LP höchstens beim Maximum
+// Ein Teilnehmer mit LP <= 0 gilt als besiegt.
+func applyVitalChange(p *models.EncounterParticipant, lpDelta, apDelta int) {
+	p.Lp += lpDelta
+	if p.Lp > p.LpMax {
+		p.Lp = p.LpMax
+	}
+	p.Ap += apDelta
+	if p.Ap > p.ApMax {
+		p.Ap = p.ApMax
+	}
+	if p.Ap < 0 {
+		p.Ap = 0
+	}
+	p.Defeated = p.Lp <= 0
+}
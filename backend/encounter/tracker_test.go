@@ -0,0 +1,80 @@
+package encounter
+
+import (
+	"testing"
+
+	"bamort/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestEncounter() *models.Encounter {
+	return &models.Encounter{
+		Status: models.EncounterStatusPreparing,
+		Participants: []models.EncounterParticipant{
+			{ID: 1, Name: "Ork", Initiative: 8, LpMax: 10, Lp: 10},
+			{ID: 2, Name: "Krieger", Initiative: 15, LpMax: 12, Lp: 12, ApMax: 20, Ap: 20},
+			{ID: 3, Name: "Goblin", Initiative: 8, LpMax: 5, Lp: 5},
+		},
+	}
+}
+
+func TestStartEncounterSortsByInitiative(t *testing.T) {
+	enc := newTestEncounter()
+
+	require.NoError(t, startEncounter(enc))
+
+	assert.Equal(t, models.EncounterStatusActive, enc.Status)
+	assert.Equal(t, 1, enc.Round)
+	assert.Equal(t, 0, enc.Turn)
+	names := []string{enc.Participants[0].Name, enc.Participants[1].Name, enc.Participants[2].Name}
+	assert.Equal(t, []string{"Krieger", "Ork", "Goblin"}, names, "ties keep insertion order")
+	assert.Equal(t, 2, enc.Participants[2].Position)
+
+	assert.Error(t, startEncounter(enc), "an active encounter cannot be started again")
+	assert.Error(t, startEncounter(&models.Encounter{Status: models.EncounterStatusPreparing}))
+}
+
+func TestAdvanceTurnSkipsDefeatedAndTicksConditions(t *testing.T) {
+	enc := newTestEncounter()
+	require.NoError(t, startEncounter(enc))
+	addCondition(&enc.Participants[0], models.EncounterCondition{Name: "Benommen", Rounds: 1})
+	addCondition(&enc.Participants[1], models.EncounterCondition{Name: "Liegend"})
+	applyVitalChange(&enc.Participants[2], -5, 0)
+	require.True(t, enc.Participants[2].Defeated)
+
+	expired, err := advanceTurn(enc)
+	require.NoError(t, err)
+	assert.Empty(t, expired)
+	assert.Equal(t, 1, enc.Turn)
+
+	expired, err = advanceTurn(enc)
+	require.NoError(t, err)
+	assert.Equal(t, 2, enc.Round, "the defeated goblin is skipped")
+	assert.Equal(t, 0, enc.Turn)
+	require.Len(t, expired, 1)
+	assert.Equal(t, "Benommen", expired[0].Condition)
+	assert.Empty(t, enc.Participants[0].Conditions)
+	assert.Len(t, enc.Participants[1].Conditions, 1, "permanent conditions stay")
+}
+
+func TestConditionsAndVitals(t *testing.T) {
+	p := &models.EncounterParticipant{LpMax: 10, Lp: 10, ApMax: 8, Ap: 8}
+
+	addCondition(p, models.EncounterCondition{Name: "Blutend", Rounds: 3})
+	addCondition(p, models.EncounterCondition{Name: "Blutend", Rounds: 5})
+	require.Len(t, p.Conditions, 1)
+	assert.Equal(t, 5, p.Conditions[0].Rounds)
+	assert.True(t, removeCondition(p, "Blutend"))
+	assert.False(t, removeCondition(p, "Blutend"))
+
+	applyVitalChange(p, 5, -12)
+	assert.Equal(t, 10, p.Lp, "LP are capped at the maximum")
+	assert.Equal(t, 0, p.Ap, "AP do not drop below zero")
+	assert.False(t, p.Defeated)
+
+	applyVitalChange(p, -11, 0)
+	assert.Equal(t, -1, p.Lp)
+	assert.True(t, p.Defeated)
+}
@@ -67,22 +67,26 @@ type EquipmentUpdateRequest struct {
 func UpdateEquipmentWithCategories(equipmentID uint, req EquipmentUpdateRequest, gs *models.GameSystem) error {
 	// Start transaction
 	return database.DB.Transaction(func(tx *gorm.DB) error {
-		// Update equipment info
-		query := tx.Model(&models.Equipment{}).Where("id = ?", equipmentID)
-		if gs != nil {
-			req.Equipment.GameSystem = gs.Name
-			req.Equipment.GameSystemId = gs.ID
-			query = query.Where("game_system=? OR game_system_id=?", gs.Name, gs.ID)
-		}
-
-		if err := query.Updates(req.Equipment).Error; err != nil {
-			return err
-		}
-
-		return nil
+		return updateEquipmentTx(tx, equipmentID, req, gs)
 	})
 }
 
+// updateEquipmentTx ändert einen Ausrüstungsgegenstand innerhalb einer Transaktion
+// Ohne columns werden nur gesetzte Felder geschrieben, mit columns genau diese Spalten (auch Nullwerte).
+func updateEquipmentTx(tx *gorm.DB, equipmentID uint, req EquipmentUpdateRequest, gs *models.GameSystem, columns ...string) error {
+	// Das Modell trägt die ID, damit die Änderungshistorie den Datensatz zuordnen kann
+	query := tx.Model(&models.Equipment{ID: equipmentID, ChangeTracking: req.Equipment.ChangeTracking})
+	if gs != nil {
+		req.Equipment.GameSystem = gs.Name
+		req.Equipment.GameSystemId = gs.ID
+		query = query.Where("game_system=? OR game_system_id=?", gs.Name, gs.ID)
+	}
+	if len(columns) > 0 {
+		query = query.Select(columns)
+	}
+	return query.Updates(req.Equipment).Error
+}
+
 // ===== Handler Functions =====
 
 // GetEnhancedMDEquipment returns equipment with enhanced information
@@ -97,6 +101,10 @@ func GetEnhancedMDEquipment(c *gin.Context) {
 		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve equipment: "+err.Error())
 		return
 	}
+	equipments, ok = visibleItems(c, equipments)
+	if !ok {
+		return
+	}
 
 	// Also get learning sources for the dropdowns
 	var sources []models.Source
@@ -127,6 +135,9 @@ func GetEnhancedMDEquipmentItem(c *gin.Context) {
 		respondWithError(c, http.StatusNotFound, "Equipment not found")
 		return
 	}
+	if !isVisible(c, equipment) {
+		return
+	}
 
 	c.JSON(http.StatusOK, equipment)
 }
@@ -156,6 +167,7 @@ func UpdateEnhancedMDEquipmentItem(c *gin.Context) {
 
 	// Ensure the ID matches
 	req.Equipment.ID = uint(id)
+	req.Equipment.SetChangedBy(c.GetUint("userID"))
 
 	if err := UpdateEquipmentWithCategories(uint(id), req, gs); err != nil {
 		respondWithError(c, http.StatusInternalServerError, "Failed to update equipment: "+err.Error())
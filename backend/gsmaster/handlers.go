@@ -45,6 +45,18 @@ func resolveGameSystem(c *gin.Context) (*models.GameSystem, bool) {
 	return gs, true
 }
 
+// ChangeTracker setzt den Benutzer für die Änderungshistorie der Stammdaten
+type ChangeTracker interface {
+	SetChangedBy(userID uint)
+}
+
+// trackChange übernimmt den angemeldeten Benutzer in die Änderungshistorie, falls das Modell sie unterstützt
+func trackChange(c *gin.Context, item any) {
+	if tracker, ok := item.(ChangeTracker); ok {
+		tracker.SetChangedBy(c.GetUint("userID"))
+	}
+}
+
 type Creator interface {
 	Create() error
 }
@@ -75,6 +87,9 @@ func getMDItem[T any](c *gin.Context) {
 			respondWithError(c, http.StatusNotFound, "Item not found")
 			return
 		}
+		if scoped, ok := (interface{})(item).(scopedItem); ok && !isVisible(c, scoped) {
+			return
+		}
 		c.JSON(http.StatusOK, item)
 	} else {
 		respondWithError(c, http.StatusInternalServerError, "Item type does not support ID lookup")
@@ -88,7 +103,18 @@ func getMDItems[T any](c *gin.Context) {
 		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve items")
 		return
 	}
-	c.JSON(http.StatusOK, items)
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+	visible := make([]T, 0, len(items))
+	for _, item := range items {
+		if scoped, ok := (interface{})(item).(scopedItem); ok && !scoped.VisibleIn(scope) {
+			continue
+		}
+		visible = append(visible, item)
+	}
+	c.JSON(http.StatusOK, visible)
 }
 
 // Generic add handler
@@ -100,6 +126,7 @@ func addMDItem[T any](c *gin.Context) {
 		return
 	}
 
+	trackChange(c, item)
 	if creator, ok := (interface{})(item).(Creator); ok {
 		if err := creator.Create(); err != nil {
 			respondWithError(c, http.StatusInternalServerError, "Failed to create item: "+err.Error())
@@ -131,6 +158,7 @@ func updateMDItem[T any](c *gin.Context) {
 			return
 		}
 
+		trackChange(c, item)
 		if saver, ok := (interface{})(item).(Saver); ok {
 			if err := saver.Save(); err != nil {
 				respondWithError(c, http.StatusInternalServerError, "Failed to update item: "+err.Error())
@@ -160,6 +188,7 @@ func deleteMDItem[T any](c *gin.Context) {
 			return
 		}
 
+		trackChange(c, item)
 		if deleter, ok := (interface{})(item).(Deleter); ok {
 			if err := deleter.Delete(); err != nil {
 				respondWithError(c, http.StatusInternalServerError, "Failed to delete item: "+err.Error())
@@ -189,23 +218,27 @@ func GetMasterData(c *gin.Context) {
 	var err error
 	var ski models.Skill
 	var spe models.Spell
-	if err := database.DB.Find(&dta.Skills).Error; err != nil {
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+	if err := database.DB.Scopes(visibleTo(scope)).Find(&dta.Skills).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve Skills"})
 		return
 	}
-	if err := database.DB.Find(&dta.Weaponskills).Error; err != nil {
+	if err := database.DB.Scopes(visibleTo(scope)).Find(&dta.Weaponskills).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve Weaponskills"})
 		return
 	}
-	if err := database.DB.Find(&dta.Spell).Error; err != nil {
+	if err := database.DB.Scopes(visibleTo(scope)).Find(&dta.Spell).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve Spell"})
 		return
 	}
-	if err := database.DB.Find(&dta.Equipment).Error; err != nil {
+	if err := database.DB.Scopes(visibleTo(scope)).Find(&dta.Equipment).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve Equipment"})
 		return
 	}
-	if err := database.DB.Find(&dta.Weapons).Error; err != nil {
+	if err := database.DB.Scopes(visibleTo(scope)).Find(&dta.Weapons).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve Weapons"})
 		return
 	}
@@ -235,11 +268,15 @@ func GetMDSkills(c *gin.Context) {
 	var dta dtaStruct
 	var err error
 	var ski models.Skill
-	if err := database.DB.Find(&dta.Skills).Error; err != nil {
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+	if err := database.DB.Scopes(visibleTo(scope)).Find(&dta.Skills).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve Skills"})
 		return
 	}
-	if err := database.DB.Find(&dta.Weaponskills).Error; err != nil {
+	if err := database.DB.Scopes(visibleTo(scope)).Find(&dta.Weaponskills).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve Weaponskills"})
 		return
 	}
@@ -274,7 +311,11 @@ func GetMDWeaponSkills(c *gin.Context) {
 		Weaponskills []models.WeaponSkill `json:"weaponskills"`
 	}
 	var dta dtaStruct
-	if err := database.DB.Find(&dta.Weaponskills).Error; err != nil {
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+	if err := database.DB.Scopes(visibleTo(scope)).Find(&dta.Weaponskills).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve Weaponskills"})
 		return
 	}
@@ -335,8 +376,12 @@ func GetMDEquipments(c *gin.Context) {
 		return
 	}
 
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
 	var equipments []models.Equipment
-	if err := database.DB.Where("game_system=? OR game_system_id=?", gs.Name, gs.ID).Find(&equipments).Error; err != nil {
+	if err := database.DB.Where("game_system=? OR game_system_id=?", gs.Name, gs.ID).Scopes(visibleTo(scope)).Find(&equipments).Error; err != nil {
 		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve items")
 		return
 	}
@@ -361,6 +406,9 @@ func GetMDEquipment(c *gin.Context) {
 		respondWithError(c, http.StatusNotFound, "Item not found")
 		return
 	}
+	if !isVisible(c, equipment) {
+		return
+	}
 
 	c.JSON(http.StatusOK, equipment)
 }
@@ -437,7 +485,9 @@ func DeleteMDEquipment(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Where("(game_system=? OR game_system_id=?) AND id = ?", gs.Name, gs.ID, id).Delete(&models.Equipment{}).Error; err != nil {
+	equipment := &models.Equipment{ID: id}
+	trackChange(c, equipment)
+	if err := database.DB.Where("game_system=? OR game_system_id=?", gs.Name, gs.ID).Delete(equipment).Error; err != nil {
 		respondWithError(c, http.StatusInternalServerError, "Failed to delete item")
 		return
 	}
@@ -452,8 +502,12 @@ func GetMDWeapons(c *gin.Context) {
 		return
 	}
 
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
 	var weapons []models.Weapon
-	if err := database.DB.Where("game_system=? OR game_system_id=?", gs.Name, gs.ID).Find(&weapons).Error; err != nil {
+	if err := database.DB.Where("game_system=? OR game_system_id=?", gs.Name, gs.ID).Scopes(visibleTo(scope)).Find(&weapons).Error; err != nil {
 		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve items")
 		return
 	}
@@ -478,6 +532,9 @@ func GetMDWeapon(c *gin.Context) {
 		respondWithError(c, http.StatusNotFound, "Item not found")
 		return
 	}
+	if !isVisible(c, weapon) {
+		return
+	}
 
 	c.JSON(http.StatusOK, weapon)
 }
@@ -554,7 +611,9 @@ func DeleteMDWeapon(c *gin.Context) {
 		return
 	}
 
-	if err := database.DB.Where("(game_system=? OR game_system_id=?) AND id = ?", gs.Name, gs.ID, id).Delete(&models.Weapon{}).Error; err != nil {
+	weapon := &models.Weapon{Equipment: models.Equipment{ID: id}}
+	trackChange(c, weapon)
+	if err := database.DB.Where("game_system=? OR game_system_id=?", gs.Name, gs.ID).Delete(weapon).Error; err != nil {
 		respondWithError(c, http.StatusInternalServerError, "Failed to delete item")
 		return
 	}
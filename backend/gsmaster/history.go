@@ -0,0 +1,212 @@
+package gsmaster
+
+import (
+	"bamort/database"
+	"bamort/models"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// historyModels erzeugt für einen Stammdatentyp ein Modell mit ID und Änderungsinformationen
+// Die Hooks der Modelle schreiben die Änderungshistorie, siehe models/model_gsmaster_history.go.
+var historyModels = map[string]func(id uint, info models.ChangeTracking) interface{ TableName() string }{
+	"skill": func(id uint, info models.ChangeTracking) interface{ TableName() string } {
+		return &models.Skill{ID: id, ChangeTracking: info}
+	},
+	"weaponskill": func(id uint, info models.ChangeTracking) interface{ TableName() string } {
+		return &models.WeaponSkill{Skill: models.Skill{ID: id, ChangeTracking: info}}
+	},
+	"spell": func(id uint, info models.ChangeTracking) interface{ TableName() string } {
+		return &models.Spell{ID: id, ChangeTracking: info}
+	},
+	"equipment": func(id uint, info models.ChangeTracking) interface{ TableName() string } {
+		return &models.Equipment{ID: id, ChangeTracking: info}
+	},
+	"weapon": func(id uint, info models.ChangeTracking) interface{ TableName() string } {
+		return &models.Weapon{Equipment: models.Equipment{ID: id, ChangeTracking: info}}
+	},
+	"container": func(id uint, info models.ChangeTracking) interface{ TableName() string } {
+		return &models.Container{Equipment: models.Equipment{ID: id, ChangeTracking: info}}
+	},
+	"transportation": func(id uint, info models.ChangeTracking) interface{ TableName() string } {
+		return &models.Transportation{Container: models.Container{Equipment: models.Equipment{ID: id, ChangeTracking: info}}}
+	},
+	"believe": func(id uint, info models.ChangeTracking) interface{ TableName() string } {
+		return &models.Believe{ID: id, ChangeTracking: info}
+	},
+	"misc": func(id uint, info models.ChangeTracking) interface{ TableName() string } {
+		return &models.MiscLookup{ID: id, ChangeTracking: info}
+	},
+}
+
+var errHistoryEntryNotFound = errors.New("history entry not found")
+
+// GetMasterDataHistory liefert die Änderungshistorie eines Stammdatensatzes, neueste zuerst
+func GetMasterDataHistory(itemType string, itemID uint) ([]models.MasterDataChange, error) {
+	newModel, ok := historyModels[itemType]
+	if !ok {
+		return nil, fmt.Errorf("unknown type: %s", itemType)
+	}
+	var entries []models.MasterDataChange
+	err := database.DB.Where("item_table = ? AND item_id = ?", newModel(0, models.ChangeTracking{}).TableName(), itemID).
+		Order("id DESC").Find(&entries).Error
+	return entries, err
+}
+
+// RevertMasterData setzt einen Stammdatensatz auf den Stand eines Historieneintrags zurück
+// Maßgeblich ist der Zustand nach der Änderung des Eintrags; bei einem Löschvorgang der Zustand
+// davor, womit gelöschte Datensätze wiederhergestellt werden. Das Zurücksetzen erscheint selbst
+// als neuer Eintrag in der Historie mit Verweis auf den Ausgangseintrag.
+func RevertMasterData(itemType string, itemID, entryID, userID uint) (map[string]any, error) {
+	newModel, ok := historyModels[itemType]
+	if !ok {
+		return nil, fmt.Errorf("unknown type: %s", itemType)
+	}
+	info := models.ChangeTracking{ChangedBy: userID, RevertOf: entryID}
+	table := newModel(0, info).TableName()
+
+	var entry models.MasterDataChange
+	err := database.DB.Where("id = ? AND item_table = ? AND item_id = ?", entryID, table, itemID).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errHistoryEntryNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// json.Number erhält ganze Zahlen, statt sie als float64 zurückzuschreiben
+	snapshot := map[string]any{}
+	decoder := json.NewDecoder(bytes.NewReader(entry.Snapshot))
+	decoder.UseNumber()
+	if err := decoder.Decode(&snapshot); err != nil {
+		return nil, fmt.Errorf("invalid snapshot in history entry %d: %w", entryID, err)
+	}
+	delete(snapshot, "id")
+
+	var current map[string]any
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		if _, err := models.LoadMasterDataRow(tx, table, itemID); err == nil {
+			if err := tx.Model(newModel(itemID, info)).Updates(snapshot).Error; err != nil {
+				return err
+			}
+		} else {
+			// Gelöschter Datensatz: ohne Modell anlegen, damit die ID erhalten bleibt
+			snapshot["id"] = itemID
+			if err := tx.Table(table).Create(snapshot).Error; err != nil {
+				return err
+			}
+			row, err := models.LoadMasterDataRow(tx, table, itemID)
+			if err != nil {
+				return err
+			}
+			if err := models.RecordMasterDataChange(tx, table, itemID, models.MasterDataActionCreate, info, nil, row); err != nil {
+				return err
+			}
+		}
+		var err error
+		current, err = models.LoadMasterDataRow(tx, table, itemID)
+		return err
+	})
+	return current, err
+}
+
+// parseHistoryParams liest Typ und ID des Stammdatensatzes aus der URL
+func parseHistoryParams(c *gin.Context) (string, uint, bool) {
+	itemType := c.Param("type")
+	if _, ok := historyModels[itemType]; !ok {
+		respondWithError(c, http.StatusBadRequest, "Unknown type: "+itemType)
+		return "", 0, false
+	}
+	id, err := parseID(c)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "Invalid ID format")
+		return "", 0, false
+	}
+	return itemType, id, true
+}
+
+// GetMDHistory liefert die Änderungshistorie eines Stammdatensatzes
+func GetMDHistory(c *gin.Context) {
+	itemType, id, ok := parseHistoryParams(c)
+	if !ok {
+		return
+	}
+	entries, err := GetMasterDataHistory(itemType, id)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve history: "+err.Error())
+		return
+	}
+	visible, ok := historyItemVisible(c, itemType, id, entries)
+	if !ok {
+		return
+	}
+	if !visible {
+		respondWithError(c, http.StatusNotFound, "Item not found")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"type": itemType, "id": id, "entries": entries})
+}
+
+// historyItemVisible prüft wie proposalItemVisible, ob der Benutzer den Datensatz sehen darf
+// Gelöschte Datensätze werden nach dem Geltungsbereich im letzten Stand der Historie beurteilt.
+func historyItemVisible(c *gin.Context, itemType string, itemID uint, entries []models.MasterDataChange) (bool, bool) {
+	if _, scoped := historyModels[itemType](0, models.ChangeTracking{}).(interface {
+		VisibleIn(models.ContentScope) bool
+	}); !scoped {
+		return true, true
+	}
+	var itemScope models.HomebrewScope
+	row, err := loadHomebrewRow(itemType, itemID)
+	switch {
+	case err == nil:
+		itemScope = row.HomebrewScope
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		respondWithError(c, http.StatusInternalServerError, "Failed to load item")
+		return false, false
+	case len(entries) == 0:
+		return true, true
+	default:
+		if err := json.Unmarshal(entries[0].Snapshot, &itemScope); err != nil {
+			respondWithError(c, http.StatusInternalServerError, "Failed to read history snapshot")
+			return false, false
+		}
+	}
+	scope, ok := requestScope(c)
+	if !ok {
+		return false, false
+	}
+	return itemScope.VisibleIn(scope), true
+}
+
+// RevertMDHistory setzt einen Stammdatensatz auf einen Historieneintrag zurück
+func RevertMDHistory(c *gin.Context) {
+	itemType, id, ok := parseHistoryParams(c)
+	if !ok {
+		return
+	}
+	var request struct {
+		EntryID uint `json:"entry_id"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil || request.EntryID == 0 {
+		respondWithError(c, http.StatusBadRequest, "entry_id is required")
+		return
+	}
+
+	current, err := RevertMasterData(itemType, id, request.EntryID, c.GetUint("userID"))
+	if errors.Is(err, errHistoryEntryNotFound) {
+		respondWithError(c, http.StatusNotFound, "History entry not found for "+itemType+" "+strconv.FormatUint(uint64(id), 10))
+		return
+	}
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to revert: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Reverted", "type": itemType, "id": id, "item": current})
+}
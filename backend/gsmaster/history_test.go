@@ -0,0 +1,169 @@
+package gsmaster
+
+import (
+	"bamort/database"
+	"bamort/models"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMasterDataHistory(t *testing.T) {
+	setupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+	require.NoError(t, database.DB.Create(&models.GameSystem{Code: "M5", Name: "midgard", IsActive: true}).Error)
+
+	const maintainerID = 257
+	call := func(handler gin.HandlerFunc, method string, body any, params gin.Params) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		var payload []byte
+		if body != nil {
+			payload, _ = json.Marshal(body)
+		}
+		c.Request = httptest.NewRequest(method, "/", bytes.NewReader(payload))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = params
+		c.Set("userID", uint(maintainerID))
+		handler(c)
+		return w
+	}
+	history := func(itemType string, id uint) []models.MasterDataChange {
+		entries, err := GetMasterDataHistory(itemType, id)
+		require.NoError(t, err)
+		return entries
+	}
+
+	w := call(AddSpell, http.MethodPost, map[string]any{"name": "Historienzauber", "beschreibung": "alte Fassung", "level": 2}, nil)
+	require.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var spell models.Spell
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &spell))
+	idParam := gin.Params{{Key: "id", Value: fmt.Sprint(spell.ID)}}
+
+	t.Run("create and update are recorded with user and diff", func(t *testing.T) {
+		w := call(UpdateMDSpell, http.MethodPut, map[string]any{"beschreibung": "neue Fassung", "level": 3}, idParam)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		entries := history("spell", spell.ID)
+		require.Len(t, entries, 2)
+		assert.Equal(t, models.MasterDataActionUpdate, entries[0].Action)
+		assert.Equal(t, models.MasterDataActionCreate, entries[1].Action)
+		assert.EqualValues(t, maintainerID, entries[0].UserID)
+		assert.Equal(t, "gsm_spells", entries[0].ItemTable)
+
+		var changes map[string]models.FieldChange
+		require.NoError(t, json.Unmarshal(entries[0].Changes, &changes))
+		assert.Equal(t, "alte Fassung", changes["beschreibung"].Old)
+		assert.Equal(t, "neue Fassung", changes["beschreibung"].New)
+		assert.Contains(t, changes, "stufe")
+		assert.NotContains(t, changes, "name")
+
+		// Unveränderte Speicherungen erzeugen keinen Eintrag
+		w = call(UpdateMDSpell, http.MethodPut, map[string]any{"beschreibung": "neue Fassung"}, idParam)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Len(t, history("spell", spell.ID), 2)
+	})
+
+	t.Run("revert to an earlier version", func(t *testing.T) {
+		entries := history("spell", spell.ID)
+		created := entries[len(entries)-1]
+
+		current, err := RevertMasterData("spell", spell.ID, created.ID, maintainerID)
+		require.NoError(t, err)
+		assert.Equal(t, "alte Fassung", current["beschreibung"])
+
+		var reloaded models.Spell
+		require.NoError(t, database.DB.First(&reloaded, spell.ID).Error)
+		assert.Equal(t, "alte Fassung", reloaded.Beschreibung)
+		assert.Equal(t, 2, reloaded.Stufe)
+
+		entries = history("spell", spell.ID)
+		assert.Equal(t, created.ID, entries[0].RevertOf)
+
+		_, err = RevertMasterData("skill", spell.ID, created.ID, maintainerID)
+		assert.ErrorIs(t, err, errHistoryEntryNotFound)
+	})
+
+	t.Run("deleted items can be restored", func(t *testing.T) {
+		deleted := &models.Spell{ID: spell.ID}
+		deleted.SetChangedBy(maintainerID)
+		require.NoError(t, database.DB.Delete(deleted).Error)
+
+		entries := history("spell", spell.ID)
+		require.Equal(t, models.MasterDataActionDelete, entries[0].Action)
+
+		w = call(RevertMDHistory, http.MethodPost, map[string]any{"entry_id": entries[0].ID}, gin.Params{{Key: "type", Value: "spell"}, {Key: "id", Value: fmt.Sprint(spell.ID)}})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var restored models.Spell
+		require.NoError(t, database.DB.First(&restored, spell.ID).Error)
+		assert.Equal(t, "Historienzauber", restored.Name)
+		assert.Equal(t, models.MasterDataActionCreate, history("spell", spell.ID)[0].Action)
+	})
+
+	t.Run("enhanced updates and embedded models", func(t *testing.T) {
+		var weapon models.Weapon
+		require.NoError(t, database.DB.First(&weapon).Error)
+		w := call(DeleteMDWeapon, http.MethodDelete, nil, gin.Params{{Key: "id", Value: fmt.Sprint(weapon.ID)}})
+		require.Equal(t, http.StatusNoContent, w.Code, w.Body.String())
+		entries := history("weapon", weapon.ID)
+		require.Len(t, entries, 1)
+		assert.Equal(t, "gsm_weapons", entries[0].ItemTable)
+		assert.EqualValues(t, maintainerID, entries[0].UserID)
+
+		var skill models.Skill
+		require.NoError(t, database.DB.Where("name = ?", "Hören").First(&skill).Error)
+		req := SkillUpdateRequest{Skill: skill}
+		req.Skill.Beschreibung = "Geänderte Beschreibung"
+		req.Skill.SetChangedBy(maintainerID)
+		require.NoError(t, UpdateSkillWithCategories(skill.ID, req))
+		entries = history("skill", skill.ID)
+		require.Len(t, entries, 1)
+		assert.EqualValues(t, maintainerID, entries[0].UserID)
+
+		w = call(GetMDHistory, http.MethodGet, nil, gin.Params{{Key: "type", Value: "monster"}, {Key: "id", Value: "1"}})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("changes without primary key are recorded per item", func(t *testing.T) {
+		first := models.Believe{Name: "Historienglaube A", GameSystemId: 1}
+		second := models.Believe{Name: "Historienglaube B", GameSystemId: 1}
+		require.NoError(t, database.DB.Create(&first).Error)
+		require.NoError(t, database.DB.Create(&second).Error)
+
+		require.NoError(t, database.DB.Model(&models.Believe{}).Where("name LIKE ?", "Historienglaube %").
+			Update("beschreibung", "gemeinsam geändert").Error)
+		for _, id := range []uint{first.ID, second.ID} {
+			entries := history("believe", id)
+			require.Len(t, entries, 2)
+			assert.Equal(t, models.MasterDataActionUpdate, entries[0].Action)
+		}
+
+		require.NoError(t, database.DB.Where("id = ?", second.ID).Delete(&models.Believe{}).Error)
+		entries := history("believe", second.ID)
+		require.Len(t, entries, 3)
+		assert.Equal(t, models.MasterDataActionDelete, entries[0].Action)
+		assert.Len(t, history("believe", first.ID), 2)
+	})
+
+	t.Run("changes are rolled back when the history cannot be written", func(t *testing.T) {
+		require.NoError(t, database.DB.Migrator().RenameTable("gsm_history", "gsm_history_off"))
+		err := database.DB.Model(&spell).Update("beschreibung", "ohne Historie").Error
+		require.NoError(t, database.DB.Migrator().RenameTable("gsm_history_off", "gsm_history"))
+		require.Error(t, err)
+
+		var current models.Spell
+		require.NoError(t, database.DB.First(&current, spell.ID).Error)
+		assert.NotEqual(t, "ohne Historie", current.Beschreibung)
+	})
+}
@@ -0,0 +1,383 @@
+package gsmaster
+
+import (
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+// homebrewTypes sind die Stammdatentypen, die Spieler und Spielleiter als Hausregel anlegen können
+var homebrewTypes = map[string]bool{"skill": true, "spell": true, "equipment": true}
+
+// scopedItem ist ein Stammdatensatz mit Geltungsbereich, siehe models/model_homebrew.go
+type scopedItem interface {
+	VisibleIn(scope models.ContentScope) bool
+}
+
+// requestScope ermittelt, welche Hausregeln der anfragende Benutzer sieht; Maintainer sehen alle
+func requestScope(c *gin.Context) (models.ContentScope, bool) {
+	userID, maintainer := currentUser(c)
+	if maintainer {
+		return models.ContentScope{All: true}, true
+	}
+	scope, err := models.UserContentScope(userID)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to determine visible content: "+err.Error())
+		return scope, false
+	}
+	return scope, true
+}
+
+// visibleItems filtert Stammdaten auf die für den anfragenden Benutzer sichtbaren Datensätze
+func visibleItems[T scopedItem](c *gin.Context, items []T) ([]T, bool) {
+	scope, ok := requestScope(c)
+	if !ok {
+		return nil, false
+	}
+	return models.VisibleInScope(items, scope), true
+}
+
+// visibleTo schränkt eine Abfrage auf die im Geltungsbereich sichtbaren Stammdaten ein
+func visibleTo(scope models.ContentScope) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		condition, args := scope.Condition("")
+		return db.Where(condition, args...)
+	}
+}
+
+// isVisible prüft einen einzelnen Stammdatensatz; fremde Hausregeln gelten als nicht vorhanden
+func isVisible(c *gin.Context, item scopedItem) bool {
+	scope, ok := requestScope(c)
+	if !ok {
+		return false
+	}
+	if !item.VisibleIn(scope) {
+		respondWithError(c, http.StatusNotFound, "Item not found")
+		return false
+	}
+	return true
+}
+
+// homebrewRow sind Name und Geltungsbereich eines bestehenden Stammdatensatzes
+type homebrewRow struct {
+	Name string
+	models.HomebrewScope
+}
+
+// loadHomebrewRow lädt Name und Geltungsbereich eines Stammdatensatzes
+func loadHomebrewRow(itemType string, id uint) (*homebrewRow, error) {
+	var row homebrewRow
+	result := database.DB.Table(historyModels[itemType](0, models.ChangeTracking{}).TableName()).
+		Select("name, scope, scope_user_id, scope_campaign_id").Where("id = ?", id).Scan(&row)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &row, nil
+}
+
+// authorizeHomebrewScope prüft, ob der Benutzer Hausregeln in diesem Geltungsbereich pflegen darf
+// Eigene Hausregeln darf jeder anlegen, Kampagnen-Hausregeln nur der Spielleiter der Kampagne.
+// Bei Scope "user" wird der anfragende Benutzer als Besitzer eingetragen.
+func authorizeHomebrewScope(c *gin.Context, scope *models.HomebrewScope) bool {
+	userID, maintainer := currentUser(c)
+	if scope.Scope == models.ScopeUser && scope.ScopeUserID == 0 {
+		scope.ScopeUserID = userID
+	}
+	if err := scope.Validate(); err != nil {
+		respondWithError(c, http.StatusBadRequest, err.Error())
+		return false
+	}
+	switch scope.Scope {
+	case models.ScopeUser:
+		if scope.ScopeUserID != userID && !maintainer {
+			respondWithError(c, http.StatusForbidden, "You can only manage your own homebrew content")
+			return false
+		}
+	case models.ScopeCampaign:
+		var campaign models.Campaign
+		if err := database.DB.First(&campaign, scope.ScopeCampaignID).Error; err != nil {
+			respondWithError(c, http.StatusBadRequest, "Unknown campaign")
+			return false
+		}
+		if campaign.UserID != userID && !maintainer {
+			respondWithError(c, http.StatusForbidden, "Only the game master can manage homebrew content of this campaign")
+			return false
+		}
+	default:
+		respondWithError(c, http.StatusBadRequest, "Homebrew content needs scope user or campaign")
+		return false
+	}
+	return true
+}
+
+// homebrewNameTaken prüft, ob im Geltungsbereich bereits ein Datensatz dieses Namens sichtbar ist
+func homebrewNameTaken(itemType, name string, gs *models.GameSystem, scope models.HomebrewScope) bool {
+	viewer := models.ContentScope{UserID: scope.ScopeUserID}
+	if scope.ScopeCampaignID != 0 {
+		viewer.CampaignIDs = []uint{scope.ScopeCampaignID}
+	}
+	existing := historyModels[itemType](0, models.ChangeTracking{})
+	err := models.FirstVisible(existing, name, gs, viewer)
+	return err == nil
+}
+
+// parseHomebrewType liest den Stammdatentyp aus der URL
+func parseHomebrewType(c *gin.Context) (string, bool) {
+	itemType := c.Param("type")
+	if !homebrewTypes[itemType] {
+		respondWithError(c, http.StatusBadRequest, "Unknown homebrew type: "+itemType)
+		return "", false
+	}
+	return itemType, true
+}
+
+// loadManagedHomebrew lädt eine Hausregel und prüft, ob der Benutzer sie pflegen darf
+func loadManagedHomebrew(c *gin.Context) (string, uint, *homebrewRow, bool) {
+	itemType, ok := parseHomebrewType(c)
+	if !ok {
+		return "", 0, nil, false
+	}
+	id, err := parseID(c)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "Invalid ID format")
+		return "", 0, nil, false
+	}
+	row, err := loadHomebrewRow(itemType, id)
+	if err != nil || !row.IsHomebrew() {
+		respondWithError(c, http.StatusNotFound, "Homebrew item not found")
+		return "", 0, nil, false
+	}
+	scope, ok := requestScope(c)
+	if !ok {
+		return "", 0, nil, false
+	}
+	if !row.VisibleIn(scope) {
+		respondWithError(c, http.StatusNotFound, "Homebrew item not found")
+		return "", 0, nil, false
+	}
+	current := row.HomebrewScope
+	if !authorizeHomebrewScope(c, &current) {
+		return "", 0, nil, false
+	}
+	return itemType, id, row, true
+}
+
+// respondHomebrewItem gibt den aktuellen Stand einer Hausregel zurück
+func respondHomebrewItem(c *gin.Context, status int, itemType string, id uint) {
+	var item any
+	var err error
+	switch itemType {
+	case "skill":
+		item, err = GetSkillWithCategories(id)
+	case "spell":
+		item, err = GetSpellWithCategories(id)
+	case "equipment":
+		item, err = GetEquipmentWithCategories(id, nil)
+	}
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve homebrew item")
+		return
+	}
+	c.JSON(status, item)
+}
+
+// ListHomebrew listet die für den Benutzer sichtbaren Hausregeln, optional nur einer Kampagne (?campaign_id=)
+func ListHomebrew(c *gin.Context) {
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+	if campaignID := c.Query("campaign_id"); campaignID != "" {
+		var campaign models.Campaign
+		if err := database.DB.First(&campaign, campaignID).Error; err != nil {
+			respondWithError(c, http.StatusNotFound, "Campaign not found")
+			return
+		}
+		if !(models.HomebrewScope{Scope: models.ScopeCampaign, ScopeCampaignID: campaign.ID}).VisibleIn(scope) {
+			respondWithError(c, http.StatusForbidden, "You are not allowed to view this campaign")
+			return
+		}
+		scope = models.ContentScope{CampaignIDs: []uint{campaign.ID}}
+	}
+
+	condition, args := scope.Condition("")
+	homebrew := database.DB.Where("scope IN ?", []string{models.ScopeUser, models.ScopeCampaign}).Where(condition, args...).Order("name ASC")
+	var skills []models.Skill
+	var spells []models.Spell
+	var equipment []models.Equipment
+	if err := homebrew.Session(&gorm.Session{}).Find(&skills).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve homebrew skills")
+		return
+	}
+	if err := homebrew.Session(&gorm.Session{}).Find(&spells).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve homebrew spells")
+		return
+	}
+	if err := homebrew.Session(&gorm.Session{}).Find(&equipment).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve homebrew equipment")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"skills": skills, "spells": spells, "equipment": equipment})
+}
+
+// CreateHomebrew legt eine Hausregel (Fertigkeit, Zauber, Ausrüstung) für den Benutzer oder eine Kampagne an
+// Der Body entspricht den Enhanced-Endpunkten des Typs, ergänzt um scope und scope_campaign_id.
+func CreateHomebrew(c *gin.Context) {
+	itemType, ok := parseHomebrewType(c)
+	if !ok {
+		return
+	}
+	gs, ok := resolveGameSystem(c)
+	if !ok {
+		return
+	}
+	userID, _ := currentUser(c)
+
+	var skillReq SkillUpdateRequest
+	var spell models.Spell
+	var equipment models.Equipment
+	var name string
+	var scope *models.HomebrewScope
+	var bindErr error
+	switch itemType {
+	case "skill":
+		bindErr = c.ShouldBindJSON(&skillReq)
+		name, scope = skillReq.Skill.Name, &skillReq.Skill.HomebrewScope
+		skillReq.Skill.GameSystem, skillReq.Skill.GameSystemId = gs.Name, gs.ID
+		skillReq.Skill.SetChangedBy(userID)
+	case "spell":
+		bindErr = c.ShouldBindJSON(&spell)
+		name, scope = spell.Name, &spell.HomebrewScope
+		spell.GameSystem, spell.GameSystemId = gs.Name, gs.ID
+		spell.SetChangedBy(userID)
+	case "equipment":
+		bindErr = c.ShouldBindJSON(&equipment)
+		name, scope = equipment.Name, &equipment.HomebrewScope
+		equipment.GameSystem, equipment.GameSystemId = gs.Name, gs.ID
+		equipment.SetChangedBy(userID)
+	}
+	if bindErr != nil {
+		respondWithError(c, http.StatusBadRequest, "Invalid request: "+bindErr.Error())
+		return
+	}
+	if name == "" {
+		respondWithError(c, http.StatusBadRequest, "Name is required")
+		return
+	}
+	if !authorizeHomebrewScope(c, scope) {
+		return
+	}
+	if homebrewNameTaken(itemType, name, gs, *scope) {
+		respondWithError(c, http.StatusConflict, "An item with this name already exists: "+name)
+		return
+	}
+
+	var id uint
+	var err error
+	switch itemType {
+	case "skill":
+		id, err = CreateSkillWithCategories(skillReq)
+	case "spell":
+		err = spell.Create()
+		id = spell.ID
+	case "equipment":
+		err = equipment.Create()
+		id = equipment.ID
+	}
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to create homebrew item: "+err.Error())
+		return
+	}
+	logger.Info("Hausregel %s %d (%s) angelegt, Geltungsbereich %s", itemType, id, name, scope.Scope)
+	respondHomebrewItem(c, http.StatusCreated, itemType, id)
+}
+
+// UpdateHomebrew ändert eine Hausregel; der Geltungsbereich bleibt erhalten
+func UpdateHomebrew(c *gin.Context) {
+	itemType, id, row, ok := loadManagedHomebrew(c)
+	if !ok {
+		return
+	}
+	gs, ok := resolveGameSystem(c)
+	if !ok {
+		return
+	}
+	userID, _ := currentUser(c)
+
+	var err error
+	switch itemType {
+	case "skill":
+		var req SkillUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+			return
+		}
+		if req.Skill.Name != row.Name && homebrewNameTaken(itemType, req.Skill.Name, gs, row.HomebrewScope) {
+			respondWithError(c, http.StatusConflict, "An item with this name already exists: "+req.Skill.Name)
+			return
+		}
+		req.Skill.HomebrewScope = row.HomebrewScope
+		req.Skill.SetChangedBy(userID)
+		err = UpdateSkillWithCategories(id, req)
+	case "spell":
+		var req SpellUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+			return
+		}
+		if req.Spell.Name != "" && req.Spell.Name != row.Name && homebrewNameTaken(itemType, req.Spell.Name, gs, row.HomebrewScope) {
+			respondWithError(c, http.StatusConflict, "An item with this name already exists: "+req.Spell.Name)
+			return
+		}
+		req.Spell.HomebrewScope = row.HomebrewScope
+		req.Spell.SetChangedBy(userID)
+		err = UpdateSpellWithCategories(id, req)
+	case "equipment":
+		var req EquipmentUpdateRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+			return
+		}
+		if req.Equipment.Name != "" && req.Equipment.Name != row.Name && homebrewNameTaken(itemType, req.Equipment.Name, gs, row.HomebrewScope) {
+			respondWithError(c, http.StatusConflict, "An item with this name already exists: "+req.Equipment.Name)
+			return
+		}
+		req.Equipment.HomebrewScope = row.HomebrewScope
+		req.Equipment.SetChangedBy(userID)
+		err = UpdateEquipmentWithCategories(id, req, nil)
+	}
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to update homebrew item: "+err.Error())
+		return
+	}
+	respondHomebrewItem(c, http.StatusOK, itemType, id)
+}
+
+// DeleteHomebrew löscht eine Hausregel; Charaktere behalten bereits gelernte Einträge
+func DeleteHomebrew(c *gin.Context) {
+	itemType, id, _, ok := loadManagedHomebrew(c)
+	if !ok {
+		return
+	}
+	userID, _ := currentUser(c)
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		if itemType == "skill" {
+			if err := tx.Where("skill_id = ?", id).Delete(&models.SkillCategoryDifficulty{}).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Delete(historyModels[itemType](id, models.ChangeTracking{ChangedBy: userID})).Error
+	})
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to delete homebrew item: "+err.Error())
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,147 @@
+package gsmaster
+
+import (
+	"bamort/database"
+	"bamort/models"
+	"bamort/user"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHomebrewScope(t *testing.T) {
+	setupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+	require.NoError(t, database.DB.Create(&models.GameSystem{Code: "M5", Name: "midgard", IsActive: true}).Error)
+
+	player := &user.User{UserID: 261, Username: "tüftler", Role: user.RoleStandardUser}
+	gm := &user.User{UserID: 262, Username: "spielleiter", Role: user.RoleStandardUser}
+	other := &user.User{UserID: 263, Username: "fremder", Role: user.RoleStandardUser}
+	maintainer := &user.User{UserID: 264, Username: "pflege", Role: user.RoleMaintainer}
+
+	request := newMaintenanceClient().request
+	spellNames := func(as *user.User) []string {
+		code, body := request(as, http.MethodGet, "/spells", nil)
+		require.Equal(t, http.StatusOK, code)
+		var spells []models.Spell
+		require.NoError(t, json.Unmarshal(body, &spells))
+		names := []string{}
+		for _, spell := range spells {
+			names = append(names, spell.Name)
+		}
+		return names
+	}
+
+	campaign := models.Campaign{Name: "Runde am Freitag", GameSystem: "M5", UserID: gm.UserID}
+	require.NoError(t, database.DB.Create(&campaign).Error)
+	hero := models.Char{BamortBase: models.BamortBase{Name: "Gunnar"}, UserID: player.UserID, Typ: "Krieger", Rasse: "Mensch", Grad: 1, CampaignID: campaign.ID}
+	require.NoError(t, database.DB.Create(&hero).Error)
+
+	var spellID uint
+	t.Run("user homebrew is private", func(t *testing.T) {
+		code, _ := request(player, http.MethodPost, "/homebrew/spell", map[string]any{"name": "Sternenstaub", "scope": "global"})
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = request(player, http.MethodPost, "/homebrew/spell", map[string]any{"name": "Sternenstaub", "scope": "user", "scope_user_id": other.UserID})
+		assert.Equal(t, http.StatusForbidden, code)
+		code, _ = request(player, http.MethodPost, "/homebrew/spell", map[string]any{"name": "Feuerfinger", "scope": "user"})
+		assert.Equal(t, http.StatusConflict, code)
+
+		code, body := request(player, http.MethodPost, "/homebrew/spell", map[string]any{"name": "Sternenstaub", "scope": "user", "level": 2})
+		require.Equal(t, http.StatusCreated, code, string(body))
+		var spell models.Spell
+		require.NoError(t, json.Unmarshal(body, &spell))
+		assert.Equal(t, models.ScopeUser, spell.Scope)
+		assert.Equal(t, player.UserID, spell.ScopeUserID)
+		spellID = spell.ID
+
+		assert.Contains(t, spellNames(player), "Sternenstaub")
+		assert.Contains(t, spellNames(maintainer), "Sternenstaub")
+		assert.NotContains(t, spellNames(other), "Sternenstaub")
+		assert.Contains(t, spellNames(other), "Feuerfinger")
+
+		code, _ = request(other, http.MethodGet, fmt.Sprintf("/spells/%d", spellID), nil)
+		assert.Equal(t, http.StatusNotFound, code)
+		searchCount := func(as *user.User) float64 {
+			code, body := request(as, http.MethodGet, "/search?q=Sternenstaub&types=spell", nil)
+			require.Equal(t, http.StatusOK, code)
+			var response map[string]any
+			require.NoError(t, json.Unmarshal(body, &response))
+			return response["count"].(float64)
+		}
+		assert.Zero(t, searchCount(other))
+		assert.Equal(t, 1.0, searchCount(player))
+
+		code, _ = request(other, http.MethodDelete, fmt.Sprintf("/homebrew/spell/%d", spellID), nil)
+		assert.Equal(t, http.StatusNotFound, code)
+
+		code, _ = request(other, http.MethodGet, fmt.Sprintf("/history/spell/%d", spellID), nil)
+		assert.Equal(t, http.StatusNotFound, code)
+		code, body = request(player, http.MethodGet, fmt.Sprintf("/history/spell/%d", spellID), nil)
+		assert.Equal(t, http.StatusOK, code)
+		assert.Contains(t, string(body), "Sternenstaub")
+	})
+
+	t.Run("campaign homebrew is learnable inside the campaign", func(t *testing.T) {
+		var ccec models.ClassCategoryEPCost
+		require.NoError(t, database.DB.Where("skill_category = ?", "Alltag").First(&ccec).Error)
+		var category models.SkillCategory
+		require.NoError(t, database.DB.Where("name = ?", ccec.SCategory).First(&category).Error)
+		var difficulty models.SkillDifficulty
+		require.NoError(t, database.DB.Where("name = ?", "leicht").First(&difficulty).Error)
+
+		body := map[string]any{
+			"name": "Pilzkunde", "scope": "campaign", "scope_campaign_id": campaign.ID, "initialwert": 8,
+			"category_difficulties": []map[string]any{{"category_id": category.ID, "difficulty_id": difficulty.ID, "learn_cost": 1}},
+		}
+		code, _ := request(player, http.MethodPost, "/homebrew/skill", body)
+		assert.Equal(t, http.StatusForbidden, code)
+		code, response := request(gm, http.MethodPost, "/homebrew/skill", body)
+		require.Equal(t, http.StatusCreated, code, string(response))
+
+		info, err := models.GetSkillCategoryAndDifficultyNewSystem("Pilzkunde", ccec.CCLass, models.CharacterContentScope(&hero))
+		require.NoError(t, err)
+		assert.Equal(t, ccec.SCategory, info.CategoryName)
+		assert.Equal(t, 1, info.LearnCost)
+		assert.Equal(t, ccec.EPPerTE, info.EPPerTE)
+
+		_, err = models.GetSkillCategoryAndDifficultyNewSystem("Pilzkunde", ccec.CCLass)
+		assert.Error(t, err)
+		stranger := models.Char{UserID: other.UserID}
+		_, err = models.GetSkillCategoryAndDifficultyNewSystem("Pilzkunde", ccec.CCLass, models.CharacterContentScope(&stranger))
+		assert.Error(t, err)
+
+		code, response = request(player, http.MethodGet, fmt.Sprintf("/homebrew?campaign_id=%d", campaign.ID), nil)
+		require.Equal(t, http.StatusOK, code)
+		assert.Contains(t, string(response), "Pilzkunde")
+		assert.NotContains(t, string(response), "Sternenstaub")
+		code, _ = request(other, http.MethodGet, fmt.Sprintf("/homebrew?campaign_id=%d", campaign.ID), nil)
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("owner updates and deletes", func(t *testing.T) {
+		code, body := request(player, http.MethodPut, fmt.Sprintf("/homebrew/spell/%d", spellID), map[string]any{"name": "Sternenstaub", "level": 3, "scope": "global"})
+		require.Equal(t, http.StatusOK, code, string(body))
+		var spell models.Spell
+		require.NoError(t, database.DB.First(&spell, spellID).Error)
+		assert.Equal(t, 3, spell.Stufe)
+		assert.Equal(t, models.ScopeUser, spell.Scope)
+
+		code, _ = request(player, http.MethodDelete, fmt.Sprintf("/homebrew/spell/%d", spellID), nil)
+		assert.Equal(t, http.StatusNoContent, code)
+		assert.NotContains(t, spellNames(player), "Sternenstaub")
+
+		// gelöschte Hausregeln bleiben nach ihrem letzten Stand geschützt
+		code, _ = request(other, http.MethodGet, fmt.Sprintf("/history/spell/%d", spellID), nil)
+		assert.Equal(t, http.StatusNotFound, code)
+		code, _ = request(player, http.MethodGet, fmt.Sprintf("/history/spell/%d", spellID), nil)
+		assert.Equal(t, http.StatusOK, code)
+	})
+}
@@ -6,14 +6,15 @@ import (
 )
 
 type LernCostRequest struct {
-	CharId       uint   `json:"char_id" binding:"omitempty"`                      // Charakter-ID
-	Name         string `json:"name" binding:"omitempty"`                         // Name der Fertigkeit / des Zaubers
-	CurrentLevel int    `json:"current_level,omitempty"`                          // Aktueller Wert (nur für Verbesserung)
-	Type         string `json:"type" binding:"required,oneof=skill spell weapon"` // 'skill', 'spell' oder 'weapon' Waffenfertigkeiten sind normale Fertigkeiten (evtl. kann hier später der Name der Waffe angegeben werden )
-	Action       string `json:"action" binding:"required,oneof=learn improve"`    // 'learn' oder 'improve'
-	TargetLevel  int    `json:"target_level,omitempty"`                           // Zielwert (optional, für Kostenberechnung bis zu einem bestimmten Level)
-	UsePP        int    `json:"use_pp,omitempty"`                                 // Anzahl der zu verwendenden Praxispunkte
-	UseGold      int    `json:"use_gold,omitempty"`                               // Anzahl der zu verwendenden Goldstücke
+	CharId        uint   `json:"char_id" binding:"omitempty"`                      // Charakter-ID
+	Name          string `json:"name" binding:"omitempty"`                         // Name der Fertigkeit / des Zaubers
+	CurrentLevel  int    `json:"current_level,omitempty"`                          // Aktueller Wert (nur für Verbesserung)
+	Type          string `json:"type" binding:"required,oneof=skill spell weapon"` // 'skill', 'spell' oder 'weapon' Waffenfertigkeiten sind normale Fertigkeiten (evtl. kann hier später der Name der Waffe angegeben werden )
+	Action        string `json:"action" binding:"required,oneof=learn improve"`    // 'learn' oder 'improve'
+	TargetLevel   int    `json:"target_level,omitempty"`                           // Zielwert (optional, für Kostenberechnung bis zu einem bestimmten Level)
+	UsePP         int    `json:"use_pp,omitempty"`                                 // Anzahl der zu verwendenden Praxispunkte
+	UseGold       int    `json:"use_gold,omitempty"`                               // Anzahl der zu verwendenden Goldstücke
+	CheckDowntime bool   `json:"check_downtime,omitempty"`                         // Lernzeit gegen das Ausfallzeit-Konto prüfen
 	// Belohnungsoptionen
 	Reward *string `json:"reward" binding:"required,oneof=default noGold halveep halveepnoGold"` // Belohnungsoptionen Lernen als Belohnung
 	// default
@@ -0,0 +1,677 @@
+package gsmaster
+
+import (
+	"bamort/database"
+	"bamort/models"
+	"bamort/user"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"gorm.io/gorm"
+)
+
+var (
+	errProposalUnknownType  = errors.New("unknown item type")
+	errProposalNotFound     = errors.New("proposal not found")
+	errProposalItemNotFound = errors.New("item not found")
+	errProposalNoChanges    = errors.New("proposal contains no changes")
+	errProposalNotPending   = errors.New("proposal is not pending")
+	errProposalForbidden    = errors.New("not allowed to access this proposal")
+)
+
+// ProposalConflictError meldet Felder, die seit dem Vorschlag anderweitig geändert wurden
+type ProposalConflictError struct {
+	Fields []string
+}
+
+func (e *ProposalConflictError) Error() string {
+	return "item changed since the proposal was made: " + strings.Join(e.Fields, ", ")
+}
+
+// proposalType beschreibt, welche Felder eines Stammdatentyps vorgeschlagen werden dürfen,
+// wie der aktuelle Stand gelesen und wie ein freigegebener Vorschlag übernommen wird
+type proposalType struct {
+	fields map[string]bool
+	// newFields dürfen nur bei neuen Einträgen vorgeschlagen werden
+	newFields map[string]bool
+	// model liefert ein leeres Modell zur Prüfung der Feldtypen
+	model func() any
+	// load liefert den aktuellen Stand als Abbild der JSON-Felder
+	load func(id uint) (map[string]any, error)
+	// checkNew prüft einen vorgeschlagenen neuen Eintrag über die Feldtypen hinaus
+	checkNew func(tx *gorm.DB, fields map[string]any) error
+	// apply übernimmt den vollständigen neuen Stand in der Transaktion der Freigabe; id 0 legt einen neuen Eintrag an
+	apply func(tx *gorm.DB, id uint, gameSystemID uint, fields map[string]any, reviewerID uint) (uint, error)
+}
+
+// allows prüft, ob ein Feld bei einem bestehenden (itemID != 0) oder neuen Eintrag vorgeschlagen werden darf
+func (pt proposalType) allows(field string, itemID uint) bool {
+	return pt.fields[field] || (itemID == 0 && pt.newFields[field])
+}
+
+// proposalTypes nutzen dieselben Funktionen wie die erweiterten Update-Handler
+var proposalTypes = map[string]proposalType{
+	"skill": {
+		model: func() any { return &models.Skill{} },
+		// Felder, die UpdateSkillWithCategories schreibt
+		fields: fieldSet("name", "beschreibung", "initialwert", "basiswert", "bonuseigenschaft", "improvable", "innateskill", "source_id", "page_number"),
+		// Neue Fertigkeiten brauchen Kategorie und Schwierigkeit, sonst sind sie nicht lernbar
+		newFields: fieldSet("category", "difficulty"),
+		load: func(id uint) (map[string]any, error) {
+			skill, err := GetSkillWithCategories(id)
+			if err != nil {
+				return nil, err
+			}
+			return toFieldMap(skill.Skill)
+		},
+		checkNew: func(tx *gorm.DB, fields map[string]any) error {
+			_, err := proposedSkillCategory(tx, fields)
+			return err
+		},
+		apply: func(tx *gorm.DB, id uint, gameSystemID uint, fields map[string]any, reviewerID uint) (uint, error) {
+			var req SkillUpdateRequest
+			if err := fromFieldMap(fields, &req.Skill); err != nil {
+				return 0, err
+			}
+			req.Skill.SetChangedBy(reviewerID)
+			if id == 0 {
+				pair, err := proposedSkillCategory(tx, fields)
+				if err != nil {
+					return 0, err
+				}
+				req.Skill.GameSystemId = gameSystemID
+				req.CategoryDifficulties = []CategoryDifficultyPair{pair}
+				return createSkillTx(tx, req)
+			}
+			// Kategorien und Schwierigkeiten bleiben unverändert
+			current, err := GetSkillWithCategories(id)
+			if err != nil {
+				return 0, err
+			}
+			for _, cat := range current.Categories {
+				req.CategoryDifficulties = append(req.CategoryDifficulties, CategoryDifficultyPair{
+					CategoryID:   cat.CategoryID,
+					DifficultyID: cat.DifficultyID,
+					LearnCost:    cat.LearnCost,
+				})
+			}
+			req.Skill.ID = id
+			return id, updateSkillTx(tx, id, req)
+		},
+	},
+	"spell": {
+		model: func() any { return &models.Spell{} },
+		fields: fieldSet("name", "beschreibung", "source_id", "page_number", "bonus", "level", "ap", "art", "zauberdauer",
+			"reichweite", "wirkungsziel", "wirkungsbereich", "wirkungsdauer", "ursprung", "category", "learning_category"),
+		load: func(id uint) (map[string]any, error) {
+			spell, err := GetSpellWithCategories(id)
+			if err != nil {
+				return nil, err
+			}
+			return toFieldMap(spell.Spell)
+		},
+		apply: func(tx *gorm.DB, id uint, gameSystemID uint, fields map[string]any, reviewerID uint) (uint, error) {
+			var req SpellUpdateRequest
+			if err := fromFieldMap(fields, &req.Spell); err != nil {
+				return 0, err
+			}
+			req.Spell.SetChangedBy(reviewerID)
+			if id == 0 {
+				req.Spell.GameSystemId = gameSystemID
+				err := tx.Create(&req.Spell).Error
+				return req.Spell.ID, err
+			}
+			req.Spell.ID = id
+			// Alle vorschlagbaren Spalten schreiben, damit auch 0, leere Texte und false übernommen werden
+			return id, updateSpellTx(tx, id, req, "name", "beschreibung", "source_id", "page_number", "bonus", "stufe", "ap", "art",
+				"zauberdauer", "reichweite", "wirkungsziel", "wirkungsbereich", "wirkungsdauer", "ursprung", "category", "learning_category")
+		},
+	},
+	"equipment": {
+		model:  func() any { return &models.Equipment{} },
+		fields: fieldSet("name", "beschreibung", "source_id", "page_number", "gewicht", "wert", "personal_item"),
+		load: func(id uint) (map[string]any, error) {
+			equipment, err := GetEquipmentWithCategories(id, nil)
+			if err != nil {
+				return nil, err
+			}
+			return toFieldMap(equipment.Equipment)
+		},
+		apply: func(tx *gorm.DB, id uint, gameSystemID uint, fields map[string]any, reviewerID uint) (uint, error) {
+			var req EquipmentUpdateRequest
+			if err := fromFieldMap(fields, &req.Equipment); err != nil {
+				return 0, err
+			}
+			req.Equipment.SetChangedBy(reviewerID)
+			if id == 0 {
+				req.Equipment.GameSystemId = gameSystemID
+				err := tx.Create(&req.Equipment).Error
+				return req.Equipment.ID, err
+			}
+			req.Equipment.ID = id
+			// Alle vorschlagbaren Spalten schreiben, damit auch 0, leere Texte und false übernommen werden
+			return id, updateEquipmentTx(tx, id, req, nil, "name", "beschreibung", "source_id", "page_number", "gewicht", "wert", "personal_item")
+		},
+	},
+}
+
+// proposedSkillCategory löst Kategorie und Schwierigkeit einer vorgeschlagenen neuen Fertigkeit auf
+func proposedSkillCategory(tx *gorm.DB, fields map[string]any) (CategoryDifficultyPair, error) {
+	categoryName, _ := fields["category"].(string)
+	difficultyName, _ := fields["difficulty"].(string)
+	if strings.TrimSpace(categoryName) == "" || strings.TrimSpace(difficultyName) == "" {
+		return CategoryDifficultyPair{}, fmt.Errorf("category and difficulty are required for new skill")
+	}
+	var category models.SkillCategory
+	if err := tx.Where("name = ?", categoryName).First(&category).Error; err != nil {
+		return CategoryDifficultyPair{}, fmt.Errorf("unknown skill category %q", categoryName)
+	}
+	var difficulty models.SkillDifficulty
+	if err := tx.Where("name = ?", difficultyName).First(&difficulty).Error; err != nil {
+		return CategoryDifficultyPair{}, fmt.Errorf("unknown skill difficulty %q", difficultyName)
+	}
+	return CategoryDifficultyPair{CategoryID: category.ID, DifficultyID: difficulty.ID}, nil
+}
+
+func fieldSet(fields ...string) map[string]bool {
+	set := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		set[f] = true
+	}
+	return set
+}
+
+// toFieldMap wandelt ein Modell in ein Abbild seiner JSON-Felder
+func toFieldMap(value any) (map[string]any, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	fields := map[string]any{}
+	return fields, json.Unmarshal(data, &fields)
+}
+
+// fromFieldMap überträgt ein Abbild der JSON-Felder in ein Modell
+func fromFieldMap(fields map[string]any, target any) error {
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, target)
+}
+
+// sameFieldValue vergleicht Feldwerte; fehlende Felder (omitempty) gelten als Nullwert
+func sameFieldValue(a, b any) bool {
+	isZero := func(v any) bool {
+		switch v := v.(type) {
+		case nil:
+			return true
+		case string:
+			return v == ""
+		case float64:
+			return v == 0
+		case bool:
+			return !v
+		}
+		return false
+	}
+	if isZero(a) && isZero(b) {
+		return true
+	}
+	return fmt.Sprint(a) == fmt.Sprint(b)
+}
+
+// SubmitProposal speichert einen Änderungsvorschlag als Differenz zum aktuellen Stand
+// itemID 0 schlägt einen neuen Eintrag vor.
+func SubmitProposal(userID uint, itemType string, itemID uint, gameSystemID uint, proposed map[string]any, reason string) (*models.MasterDataProposal, error) {
+	pt, ok := proposalTypes[itemType]
+	if !ok {
+		return nil, errProposalUnknownType
+	}
+	for field := range proposed {
+		if !pt.allows(field, itemID) {
+			return nil, fmt.Errorf("field %q cannot be proposed for %s", field, itemType)
+		}
+	}
+
+	current := map[string]any{}
+	if itemID != 0 {
+		var err error
+		current, err = pt.load(itemID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errProposalItemNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if gsID, ok := current["game_system_id"].(float64); ok {
+			gameSystemID = uint(gsID)
+		}
+	} else if name, _ := proposed["name"].(string); strings.TrimSpace(name) == "" {
+		return nil, fmt.Errorf("name is required for new %s", itemType)
+	} else if pt.checkNew != nil {
+		if err := pt.checkNew(database.DB, proposed); err != nil {
+			return nil, err
+		}
+	}
+
+	changes := map[string]models.FieldChange{}
+	for field, value := range proposed {
+		if itemID != 0 && sameFieldValue(current[field], value) {
+			continue
+		}
+		changes[field] = models.FieldChange{Old: current[field], New: value}
+	}
+	if len(changes) == 0 {
+		return nil, errProposalNoChanges
+	}
+	// Typen der Felder prüfen, bevor der Vorschlag gespeichert wird
+	if err := pt.validate(changes); err != nil {
+		return nil, err
+	}
+
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+	proposal := &models.MasterDataProposal{
+		ItemType:     itemType,
+		ItemID:       itemID,
+		GameSystemId: gameSystemID,
+		Changes:      changesJSON,
+		Reason:       strings.TrimSpace(reason),
+		Status:       models.ProposalStatusPending,
+		UserID:       userID,
+	}
+	if err := database.DB.Create(proposal).Error; err != nil {
+		return nil, err
+	}
+	return proposal, nil
+}
+
+// validate prüft, ob die vorgeschlagenen Werte in das Modell passen
+func (pt proposalType) validate(changes map[string]models.FieldChange) error {
+	for field, change := range changes {
+		if err := fromFieldMap(map[string]any{field: change.New}, pt.model()); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", field, err)
+		}
+	}
+	return nil
+}
+
+// loadProposal lädt einen Vorschlag samt Kommentaren
+func loadProposal(id uint) (*models.MasterDataProposal, error) {
+	var proposal models.MasterDataProposal
+	err := database.DB.Preload("Comments", func(db *gorm.DB) *gorm.DB { return db.Order("id ASC") }).First(&proposal, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, errProposalNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &proposal, nil
+}
+
+// ApproveProposal übernimmt einen Vorschlag über die Update-Funktionen der erweiterten Handler
+// Wurden die betroffenen Felder seit dem Vorschlag geändert, wird nur mit force übernommen.
+func ApproveProposal(id, reviewerID uint, comment string, force bool) (*models.MasterDataProposal, error) {
+	proposal, err := loadProposal(id)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Status != models.ProposalStatusPending {
+		return nil, errProposalNotPending
+	}
+	pt, ok := proposalTypes[proposal.ItemType]
+	if !ok {
+		return nil, errProposalUnknownType
+	}
+	var changes map[string]models.FieldChange
+	if err := json.Unmarshal(proposal.Changes, &changes); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]any{}
+	if proposal.ItemID != 0 {
+		fields, err = pt.load(proposal.ItemID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errProposalItemNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		var conflicts []string
+		for field, change := range changes {
+			if !sameFieldValue(fields[field], change.Old) {
+				conflicts = append(conflicts, field)
+			}
+		}
+		if len(conflicts) > 0 && !force {
+			sort.Strings(conflicts)
+			return nil, &ProposalConflictError{Fields: conflicts}
+		}
+	}
+	for field, change := range changes {
+		fields[field] = change.New
+	}
+
+	// Übernahme und Statuswechsel in einer Transaktion: schlägt eines fehl, bleibt der Vorschlag
+	// unverändert offen; eine parallele Freigabe findet ihn nicht mehr als offen vor.
+	err = database.DB.Transaction(func(tx *gorm.DB) error {
+		appliedID, err := pt.apply(tx, proposal.ItemID, proposal.GameSystemId, fields, reviewerID)
+		if err != nil {
+			return err
+		}
+		return finishProposalTx(tx, proposal, models.ProposalStatusApproved, reviewerID, appliedID, comment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loadProposal(proposal.ID)
+}
+
+// RejectProposal lehnt einen Vorschlag ab
+func RejectProposal(id, reviewerID uint, comment string) (*models.MasterDataProposal, error) {
+	proposal, err := loadProposal(id)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Status != models.ProposalStatusPending {
+		return nil, errProposalNotPending
+	}
+	return finishProposal(proposal, models.ProposalStatusRejected, reviewerID, 0, comment)
+}
+
+// WithdrawProposal zieht einen offenen Vorschlag durch seinen Autor zurück
+func WithdrawProposal(id, userID uint) (*models.MasterDataProposal, error) {
+	proposal, err := loadProposal(id)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.UserID != userID {
+		return nil, errProposalForbidden
+	}
+	if proposal.Status != models.ProposalStatusPending {
+		return nil, errProposalNotPending
+	}
+	return finishProposal(proposal, models.ProposalStatusWithdrawn, 0, 0, "")
+}
+
+// finishProposal setzt den Abschlussstatus und speichert einen optionalen Kommentar
+func finishProposal(proposal *models.MasterDataProposal, status string, reviewerID, appliedID uint, comment string) (*models.MasterDataProposal, error) {
+	err := database.DB.Transaction(func(tx *gorm.DB) error {
+		return finishProposalTx(tx, proposal, status, reviewerID, appliedID, comment)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return loadProposal(proposal.ID)
+}
+
+// finishProposalTx schließt einen offenen Vorschlag innerhalb einer Transaktion ab
+// Die Bedingung auf den Status verhindert, dass ein Vorschlag zweimal abgeschlossen wird.
+func finishProposalTx(tx *gorm.DB, proposal *models.MasterDataProposal, status string, reviewerID, appliedID uint, comment string) error {
+	now := time.Now()
+	updates := map[string]any{"status": status}
+	if reviewerID != 0 {
+		updates["reviewer_id"] = reviewerID
+		updates["reviewed_at"] = &now
+	}
+	if appliedID != 0 {
+		updates["applied_item_id"] = appliedID
+	}
+	result := tx.Model(&models.MasterDataProposal{}).
+		Where("id = ? AND status = ?", proposal.ID, models.ProposalStatusPending).Updates(updates)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errProposalNotPending
+	}
+	if comment = strings.TrimSpace(comment); comment != "" {
+		return tx.Create(&models.MasterDataProposalComment{ProposalID: proposal.ID, UserID: reviewerID, Text: comment}).Error
+	}
+	return nil
+}
+
+// currentUser liefert den angemeldeten Benutzer aus dem Kontext der Auth-Middleware
+func currentUser(c *gin.Context) (uint, bool) {
+	if value, ok := c.Get("user"); ok {
+		if u, ok := value.(*user.User); ok {
+			return u.UserID, u.IsMaintainer()
+		}
+	}
+	return c.GetUint("userID"), false
+}
+
+// respondProposalError ordnet Fehler der Vorschläge einem HTTP-Status zu
+func respondProposalError(c *gin.Context, err error) {
+	var conflict *ProposalConflictError
+	switch {
+	case errors.As(err, &conflict):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "fields": conflict.Fields})
+	case errors.Is(err, errProposalNotFound), errors.Is(err, errProposalItemNotFound):
+		respondWithError(c, http.StatusNotFound, err.Error())
+	case errors.Is(err, errProposalForbidden):
+		respondWithError(c, http.StatusForbidden, err.Error())
+	case errors.Is(err, errProposalNotPending):
+		respondWithError(c, http.StatusConflict, err.Error())
+	default:
+		respondWithError(c, http.StatusBadRequest, err.Error())
+	}
+}
+
+// proposalItemVisible prüft, ob der Benutzer den Datensatz eines Vorschlags sehen darf
+// Fremde Hausregeln gelten wie bei den Stammdaten-Endpunkten als nicht vorhanden. Unbekannte
+// Typen und fehlende Datensätze meldet SubmitProposal selbst.
+func proposalItemVisible(c *gin.Context, itemType string, itemID uint) (bool, bool) {
+	if _, ok := proposalTypes[itemType]; !ok {
+		return true, true
+	}
+	row, err := loadHomebrewRow(itemType, itemID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return true, true
+	}
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to load item")
+		return false, false
+	}
+	scope, ok := requestScope(c)
+	if !ok {
+		return false, false
+	}
+	return row.VisibleIn(scope), true
+}
+
+// CreateProposal nimmt einen Änderungsvorschlag eines beliebigen angemeldeten Benutzers entgegen
+func CreateProposal(c *gin.Context) {
+	var request struct {
+		ItemType     string         `json:"item_type"`
+		ItemID       uint           `json:"item_id"`
+		GameSystemId uint           `json:"game_system_id"`
+		Fields       map[string]any `json:"fields"`
+		Reason       string         `json:"reason"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil {
+		respondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+	if request.ItemID == 0 && request.GameSystemId == 0 {
+		if gs := models.GetGameSystem(0, ""); gs != nil {
+			request.GameSystemId = gs.ID
+		}
+	}
+	if request.ItemID != 0 {
+		visible, ok := proposalItemVisible(c, request.ItemType, request.ItemID)
+		if !ok {
+			return
+		}
+		if !visible {
+			respondProposalError(c, errProposalItemNotFound)
+			return
+		}
+	}
+	userID, _ := currentUser(c)
+	proposal, err := SubmitProposal(userID, request.ItemType, request.ItemID, request.GameSystemId, request.Fields, request.Reason)
+	if err != nil {
+		respondProposalError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, proposal)
+}
+
+// ListProposals liefert Maintainern die Warteschlange aller Vorschläge, anderen Benutzern ihre eigenen
+// Parameter: status (z.B. pending), item_type
+func ListProposals(c *gin.Context) {
+	userID, maintainer := currentUser(c)
+	query := database.DB.Order("id ASC")
+	if !maintainer {
+		query = query.Where("user_id = ?", userID)
+	}
+	if status := c.Query("status"); status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if itemType := c.Query("item_type"); itemType != "" {
+		query = query.Where("item_type = ?", itemType)
+	}
+	proposals := []models.MasterDataProposal{}
+	if err := query.Find(&proposals).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve proposals")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"proposals": proposals})
+}
+
+// loadVisibleProposal lädt einen Vorschlag, den der Benutzer sehen darf (Autor oder Maintainer)
+func loadVisibleProposal(c *gin.Context) (*models.MasterDataProposal, bool) {
+	id, err := parseID(c)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "Invalid ID format")
+		return nil, false
+	}
+	proposal, err := loadProposal(id)
+	if err != nil {
+		respondProposalError(c, err)
+		return nil, false
+	}
+	if userID, maintainer := currentUser(c); !maintainer && proposal.UserID != userID {
+		respondProposalError(c, errProposalForbidden)
+		return nil, false
+	}
+	return proposal, true
+}
+
+// GetProposal liefert einen Vorschlag mit Kommentaren und aktuellem Stand des Datensatzes
+func GetProposal(c *gin.Context) {
+	proposal, ok := loadVisibleProposal(c)
+	if !ok {
+		return
+	}
+	var current map[string]any
+	if pt, ok := proposalTypes[proposal.ItemType]; ok && proposal.ItemID != 0 {
+		visible, ok := proposalItemVisible(c, proposal.ItemType, proposal.ItemID)
+		if !ok {
+			return
+		}
+		if visible {
+			current, _ = pt.load(proposal.ItemID)
+		}
+	}
+	c.JSON(http.StatusOK, gin.H{"proposal": proposal, "current": current})
+}
+
+// CommentProposal fügt einen Kommentar hinzu (Autor oder Maintainer)
+func CommentProposal(c *gin.Context) {
+	proposal, ok := loadVisibleProposal(c)
+	if !ok {
+		return
+	}
+	var request struct {
+		Text string `json:"text"`
+	}
+	if err := c.ShouldBindJSON(&request); err != nil || strings.TrimSpace(request.Text) == "" {
+		respondWithError(c, http.StatusBadRequest, "text is required")
+		return
+	}
+	userID, _ := currentUser(c)
+	comment := models.MasterDataProposalComment{ProposalID: proposal.ID, UserID: userID, Text: strings.TrimSpace(request.Text)}
+	if err := database.DB.Create(&comment).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to save comment")
+		return
+	}
+	c.JSON(http.StatusCreated, comment)
+}
+
+// WithdrawMDProposal zieht den eigenen offenen Vorschlag zurück
+func WithdrawMDProposal(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "Invalid ID format")
+		return
+	}
+	userID, _ := currentUser(c)
+	proposal, err := WithdrawProposal(id, userID)
+	if err != nil {
+		respondProposalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, proposal)
+}
+
+// reviewRequest ist der Inhalt von Freigabe und Ablehnung
+type reviewRequest struct {
+	Comment string `json:"comment"`
+	Force   bool   `json:"force"` // Freigabe trotz zwischenzeitlicher Änderungen
+}
+
+// ApproveMDProposal gibt einen Vorschlag frei und übernimmt ihn (nur Maintainer)
+func ApproveMDProposal(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "Invalid ID format")
+		return
+	}
+	var request reviewRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			respondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+			return
+		}
+	}
+	userID, _ := currentUser(c)
+	proposal, err := ApproveProposal(id, userID, request.Comment, request.Force)
+	if err != nil {
+		respondProposalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, proposal)
+}
+
+// RejectMDProposal lehnt einen Vorschlag ab (nur Maintainer)
+func RejectMDProposal(c *gin.Context) {
+	id, err := parseID(c)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "Invalid ID format")
+		return
+	}
+	var request reviewRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&request); err != nil {
+			respondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+			return
+		}
+	}
+	userID, _ := currentUser(c)
+	proposal, err := RejectProposal(id, userID, request.Comment)
+	if err != nil {
+		respondProposalError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, proposal)
+}
@@ -0,0 +1,224 @@
+package gsmaster
+
+import (
+	"bamort/database"
+	"bamort/models"
+	"bamort/user"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMasterDataProposals(t *testing.T) {
+	setupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+	require.NoError(t, database.DB.Create(&models.GameSystem{Code: "M5", Name: "midgard", IsActive: true}).Error)
+
+	player := &user.User{UserID: 258, Username: "spieler", Role: user.RoleStandardUser}
+	other := &user.User{UserID: 259, Username: "andere", Role: user.RoleStandardUser}
+	maintainer := &user.User{UserID: 260, Username: "pflege", Role: user.RoleMaintainer}
+
+	client := newMaintenanceClient()
+	request := func(as *user.User, method, path string, body any) (int, map[string]any) {
+		code, raw := client.request(as, method, path, body)
+		response := map[string]any{}
+		_ = json.Unmarshal(raw, &response)
+		return code, response
+	}
+	propose := func(as *user.User, body map[string]any) (int, uint) {
+		code, response := request(as, http.MethodPost, "/proposals", body)
+		id, _ := response["id"].(float64)
+		return code, uint(id)
+	}
+
+	var spell models.Spell
+	require.NoError(t, database.DB.Where("name = ?", "Feuerfinger").First(&spell).Error)
+	var skill models.Skill
+	require.NoError(t, database.DB.Where("name = ?", "Hören").First(&skill).Error)
+	skillBefore, err := GetSkillWithCategories(skill.ID)
+	require.NoError(t, err)
+
+	t.Run("players submit proposals as diffs", func(t *testing.T) {
+		code, _ := propose(player, map[string]any{"item_type": "spell", "item_id": spell.ID, "fields": map[string]any{"id": 1}})
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = propose(player, map[string]any{"item_type": "spell", "item_id": spell.ID, "fields": map[string]any{"name": spell.Name}})
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = propose(player, map[string]any{"item_type": "spell", "item_id": spell.ID, "fields": map[string]any{"level": "hoch"}})
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = propose(player, map[string]any{"item_type": "monster", "fields": map[string]any{"name": "Ork"}})
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, id := propose(player, map[string]any{"item_type": "spell", "item_id": spell.ID, "reason": "Seitenzahl laut Arkanum", "fields": map[string]any{"page_number": 123, "name": spell.Name}})
+		require.Equal(t, http.StatusCreated, code)
+
+		var proposal models.MasterDataProposal
+		require.NoError(t, database.DB.First(&proposal, id).Error)
+		assert.Equal(t, models.ProposalStatusPending, proposal.Status)
+		var changes map[string]models.FieldChange
+		require.NoError(t, json.Unmarshal(proposal.Changes, &changes))
+		assert.Len(t, changes, 1)
+		assert.EqualValues(t, 123, changes["page_number"].New)
+	})
+
+	t.Run("queue and visibility", func(t *testing.T) {
+		code, response := request(maintainer, http.MethodGet, "/proposals?status=pending", nil)
+		require.Equal(t, http.StatusOK, code)
+		queue := response["proposals"].([]any)
+		require.Len(t, queue, 1)
+		id := uint(queue[0].(map[string]any)["id"].(float64))
+
+		_, response = request(other, http.MethodGet, "/proposals", nil)
+		assert.Empty(t, response["proposals"])
+		code, _ = request(other, http.MethodGet, fmt.Sprintf("/proposals/%d", id), nil)
+		assert.Equal(t, http.StatusForbidden, code)
+
+		code, _ = request(maintainer, http.MethodPost, fmt.Sprintf("/proposals/%d/comments", id), map[string]any{"text": "Welche Auflage?"})
+		assert.Equal(t, http.StatusCreated, code)
+		code, _ = request(player, http.MethodPost, fmt.Sprintf("/proposals/%d/comments", id), map[string]any{"text": "Zweite Auflage"})
+		assert.Equal(t, http.StatusCreated, code)
+		code, response = request(player, http.MethodGet, fmt.Sprintf("/proposals/%d", id), nil)
+		require.Equal(t, http.StatusOK, code)
+		assert.Len(t, response["proposal"].(map[string]any)["comments"], 2)
+		assert.NotNil(t, response["current"])
+
+		// Freigeben dürfen nur Maintainer
+		code, _ = request(player, http.MethodPost, fmt.Sprintf("/proposals/%d/approve", id), nil)
+		assert.Equal(t, http.StatusForbidden, code)
+	})
+
+	t.Run("approval applies the change", func(t *testing.T) {
+		var proposal models.MasterDataProposal
+		require.NoError(t, database.DB.Where("item_type = ? AND status = ?", "spell", models.ProposalStatusPending).First(&proposal).Error)
+
+		code, response := request(maintainer, http.MethodPost, fmt.Sprintf("/proposals/%d/approve", proposal.ID), map[string]any{"comment": "Danke!"})
+		require.Equal(t, http.StatusOK, code, response)
+		assert.Equal(t, models.ProposalStatusApproved, response["status"])
+		assert.EqualValues(t, maintainer.UserID, response["reviewer_id"])
+
+		var updated models.Spell
+		require.NoError(t, database.DB.First(&updated, spell.ID).Error)
+		assert.Equal(t, 123, updated.PageNumber)
+		assert.Equal(t, spell.Name, updated.Name)
+
+		entries, err := GetMasterDataHistory("spell", spell.ID)
+		require.NoError(t, err)
+		require.NotEmpty(t, entries)
+		assert.EqualValues(t, maintainer.UserID, entries[0].UserID)
+
+		code, _ = request(maintainer, http.MethodPost, fmt.Sprintf("/proposals/%d/reject", proposal.ID), nil)
+		assert.Equal(t, http.StatusConflict, code)
+	})
+
+	t.Run("conflicting changes need force", func(t *testing.T) {
+		code, id := propose(player, map[string]any{"item_type": "skill", "item_id": skill.ID, "fields": map[string]any{"beschreibung": "Vorschlag"}})
+		require.Equal(t, http.StatusCreated, code)
+
+		current, err := GetSkillWithCategories(skill.ID)
+		require.NoError(t, err)
+		req := SkillUpdateRequest{Skill: current.Skill}
+		req.Skill.Beschreibung = "Zwischenzeitlich geändert"
+		for _, cat := range current.Categories {
+			req.CategoryDifficulties = append(req.CategoryDifficulties, CategoryDifficultyPair{CategoryID: cat.CategoryID, DifficultyID: cat.DifficultyID, LearnCost: cat.LearnCost})
+		}
+		require.NoError(t, UpdateSkillWithCategories(skill.ID, req))
+
+		code, response := request(maintainer, http.MethodPost, fmt.Sprintf("/proposals/%d/approve", id), nil)
+		require.Equal(t, http.StatusConflict, code)
+		assert.Equal(t, []any{"beschreibung"}, response["fields"])
+
+		code, _ = request(maintainer, http.MethodPost, fmt.Sprintf("/proposals/%d/approve", id), map[string]any{"force": true})
+		require.Equal(t, http.StatusOK, code)
+		after, err := GetSkillWithCategories(skill.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Vorschlag", after.Beschreibung)
+		assert.Len(t, after.Categories, len(skillBefore.Categories))
+	})
+
+	t.Run("new items, rejection and withdrawal", func(t *testing.T) {
+		code, id := propose(player, map[string]any{"item_type": "equipment", "fields": map[string]any{"name": "Wanderstab", "gewicht": 1.5, "wert": 2}})
+		require.Equal(t, http.StatusCreated, code)
+		code, response := request(maintainer, http.MethodPost, fmt.Sprintf("/proposals/%d/approve", id), nil)
+		require.Equal(t, http.StatusOK, code, response)
+		appliedID := uint(response["applied_item_id"].(float64))
+		var equipment models.Equipment
+		require.NoError(t, database.DB.First(&equipment, appliedID).Error)
+		assert.Equal(t, "Wanderstab", equipment.Name)
+		assert.Equal(t, 1.5, equipment.Gewicht)
+
+		_, rejected := propose(player, map[string]any{"item_type": "spell", "item_id": spell.ID, "fields": map[string]any{"bonus": 5}})
+		code, response = request(maintainer, http.MethodPost, fmt.Sprintf("/proposals/%d/reject", rejected), map[string]any{"comment": "Widerspricht dem Regelwerk"})
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, models.ProposalStatusRejected, response["status"])
+		assert.Len(t, response["comments"], 1)
+
+		_, withdrawn := propose(player, map[string]any{"item_type": "spell", "item_id": spell.ID, "fields": map[string]any{"bonus": 6}})
+		code, _ = request(other, http.MethodPost, fmt.Sprintf("/proposals/%d/withdraw", withdrawn), nil)
+		assert.Equal(t, http.StatusForbidden, code)
+		code, response = request(player, http.MethodPost, fmt.Sprintf("/proposals/%d/withdraw", withdrawn), nil)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, models.ProposalStatusWithdrawn, response["status"])
+	})
+
+	t.Run("zero values are applied", func(t *testing.T) {
+		code, id := propose(player, map[string]any{"item_type": "spell", "item_id": spell.ID, "fields": map[string]any{"page_number": 0, "beschreibung": ""}})
+		require.Equal(t, http.StatusCreated, code)
+		code, response := request(maintainer, http.MethodPost, fmt.Sprintf("/proposals/%d/approve", id), nil)
+		require.Equal(t, http.StatusOK, code, response)
+		var updated models.Spell
+		require.NoError(t, database.DB.First(&updated, spell.ID).Error)
+		assert.Equal(t, 0, updated.PageNumber)
+		assert.Empty(t, updated.Beschreibung)
+
+		equipment := models.Equipment{Name: "Amulett", GameSystem: "midgard", PersonalItem: true}
+		require.NoError(t, database.DB.Create(&equipment).Error)
+		code, id = propose(player, map[string]any{"item_type": "equipment", "item_id": equipment.ID, "fields": map[string]any{"personal_item": false}})
+		require.Equal(t, http.StatusCreated, code)
+		code, response = request(maintainer, http.MethodPost, fmt.Sprintf("/proposals/%d/approve", id), nil)
+		require.Equal(t, http.StatusOK, code, response)
+		require.NoError(t, database.DB.First(&equipment, equipment.ID).Error)
+		assert.False(t, equipment.PersonalItem)
+
+		code, _ = request(maintainer, http.MethodPost, fmt.Sprintf("/proposals/%d/approve", id), nil)
+		assert.Equal(t, http.StatusConflict, code, "a proposal is applied only once")
+	})
+
+	t.Run("new skills need category and difficulty", func(t *testing.T) {
+		code, _ := propose(player, map[string]any{"item_type": "skill", "fields": map[string]any{"name": "Pilzkunde"}})
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = propose(player, map[string]any{"item_type": "skill", "fields": map[string]any{"name": "Pilzkunde", "category": "Pilzsammeln", "difficulty": "leicht"}})
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = propose(player, map[string]any{"item_type": "skill", "item_id": skill.ID, "fields": map[string]any{"category": "Freiland"}})
+		assert.Equal(t, http.StatusBadRequest, code, "categories of existing skills are not proposable")
+
+		code, id := propose(player, map[string]any{"item_type": "skill", "fields": map[string]any{"name": "Pilzkunde", "category": "Freiland", "difficulty": "leicht"}})
+		require.Equal(t, http.StatusCreated, code)
+		code, response := request(maintainer, http.MethodPost, fmt.Sprintf("/proposals/%d/approve", id), nil)
+		require.Equal(t, http.StatusOK, code, response)
+		created, err := GetSkillWithCategories(uint(response["applied_item_id"].(float64)))
+		require.NoError(t, err)
+		require.Len(t, created.Categories, 1)
+		assert.Equal(t, "Freiland", created.Categories[0].CategoryName)
+		assert.Equal(t, "leicht", created.Categories[0].DifficultyName)
+	})
+
+	t.Run("private homebrew of others is not proposable", func(t *testing.T) {
+		private := models.Spell{Name: "Geheimzauber", GameSystem: "midgard", HomebrewScope: models.HomebrewScope{Scope: models.ScopeUser, ScopeUserID: other.UserID}}
+		require.NoError(t, database.DB.Create(&private).Error)
+		code, _ := propose(player, map[string]any{"item_type": "spell", "item_id": private.ID, "fields": map[string]any{"bonus": 3}})
+		assert.Equal(t, http.StatusNotFound, code)
+
+		code, id := propose(other, map[string]any{"item_type": "spell", "item_id": private.ID, "fields": map[string]any{"bonus": 3}})
+		require.Equal(t, http.StatusCreated, code)
+		code, response := request(other, http.MethodGet, fmt.Sprintf("/proposals/%d", id), nil)
+		require.Equal(t, http.StatusOK, code)
+		assert.NotNil(t, response["current"])
+	})
+}
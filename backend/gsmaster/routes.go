@@ -10,6 +10,22 @@ func RegisterRoutes(r *gin.RouterGroup) {
 	maintGrp := r.Group("/maintenance")
 
 	maintGrp.GET("", GetMasterData)
+	maintGrp.GET("/search", SearchMD)                // Suche über alle Stammdaten
+	maintGrp.GET("/history/:type/:id", GetMDHistory) // Änderungshistorie eines Stammdatensatzes
+	// Änderungsvorschläge: einreichen, kommentieren und zurückziehen darf jeder angemeldete Benutzer
+	maintGrp.GET("/proposals", ListProposals)
+	maintGrp.GET("/proposals/:id", GetProposal)
+	maintGrp.POST("/proposals", CreateProposal)
+	maintGrp.POST("/proposals/:id/comments", CommentProposal)
+	maintGrp.POST("/proposals/:id/withdraw", WithdrawMDProposal)
+	// Hausregeln pflegen Spieler für sich selbst und Spielleiter für ihre Kampagnen
+	maintGrp.GET("/homebrew", ListHomebrew) // ?campaign_id=
+	maintGrp.POST("/homebrew/:type", CreateHomebrew)
+	maintGrp.PUT("/homebrew/:type/:id", UpdateHomebrew)
+	maintGrp.DELETE("/homebrew/:type/:id", DeleteHomebrew)
+	// Aktive Quellenbücher je Benutzer bzw. Kampagne (?campaign_id=, ändern nur der Spielleiter)
+	maintGrp.GET("/source-selection", GetSourceSelection)
+	maintGrp.PUT("/source-selection", UpdateSourceSelection)
 	maintGrp.GET("/skills", GetMDSkills)
 	maintGrp.GET("/skills-enhanced", GetEnhancedMDSkills) // New enhanced endpoint
 	maintGrp.GET("/skills/:id", GetMDSkill)
@@ -58,5 +74,10 @@ func RegisterRoutes(r *gin.RouterGroup) {
 		maintGrp.PUT("/weapons-enhanced/:id", UpdateEnhancedMDWeapon) // New enhanced endpoint
 		maintGrp.POST("/weapons", AddWeapon)
 		maintGrp.DELETE("/weapons/:id", DeleteMDWeapon)
+
+		maintGrp.POST("/history/:type/:id/revert", RevertMDHistory) // Auf einen Historieneintrag zurücksetzen
+
+		maintGrp.POST("/proposals/:id/approve", ApproveMDProposal)
+		maintGrp.POST("/proposals/:id/reject", RejectMDProposal)
 	}
 }
@@ -0,0 +1,430 @@
+package gsmaster
+
+import (
+	"bamort/database"
+	"bamort/models"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	searchDefaultLimit = 50
+	searchMaxLimit     = 200
+	searchSnippetRunes = 200
+)
+
+// searchTables ordnet die Suchtypen ihren Stammdatentabellen zu
+// Die Reihenfolge bestimmt die Sortierung bei gleicher Bewertung.
+// Scoped kennzeichnet Tabellen mit Geltungsbereich für Hausregeln.
+var searchTables = []struct {
+	Type   string
+	Table  string
+	Scoped bool
+}{
+	{"skill", (&models.Skill{}).TableName(), true},
+	{"weaponskill", (&models.WeaponSkill{}).TableName(), true},
+	{"spell", (&models.Spell{}).TableName(), true},
+	{"equipment", (&models.Equipment{}).TableName(), true},
+	{"weapon", (&models.Weapon{}).TableName(), true},
+	{"container", (&models.Container{}).TableName(), true},
+	{"believe", (&models.Believe{}).TableName(), false},
+	{"misc", (&models.MiscLookup{}).TableName(), false},
+}
+
+// SearchResult ist ein Treffer der Stammdatensuche
+type SearchResult struct {
+	Type         string `json:"type"`
+	ID           uint   `json:"id"`
+	Name         string `json:"name"`
+	Beschreibung string `json:"beschreibung,omitempty"`
+	Key          string `json:"key,omitempty"` // nur bei misc: Schlüssel der Auswahlliste
+	GameSystem   string `json:"game_system,omitempty"`
+	GameSystemId uint   `json:"game_system_id,omitempty"`
+	SourceID     uint   `json:"source_id,omitempty"`
+	Score        int    `json:"score"`
+	MatchedField string `json:"matched_field"` // "name" oder "beschreibung"
+}
+
+// SearchOptions sind die Filter der Stammdatensuche
+type SearchOptions struct {
+	Query      string
+	Types      []string
+	GameSystem *models.GameSystem
+	SourceID   uint
+	Limit      int
+	Scope      models.ContentScope // sichtbare Hausregeln; der Nullwert findet nur globale Datensätze
+}
+
+// searchRow ist eine Zeile der Kandidatenabfrage, gleich für alle Stammdatentabellen
+type searchRow struct {
+	ID           uint
+	Name         string
+	Beschreibung string
+	Key          string
+	GameSystem   string
+	GameSystemId uint
+	SourceID     uint
+}
+
+// searchReplacements bildet Umlaute, ß und gängige Akzente auf ASCII ab
+var searchReplacements = []string{
+	"ä", "ae", "ö", "oe", "ü", "ue", "ß", "ss",
+	"à", "a", "á", "a", "â", "a", "ã", "a", "å", "a",
+	"è", "e", "é", "e", "ê", "e", "ë", "e",
+	"ì", "i", "í", "i", "î", "i", "ï", "i",
+	"ò", "o", "ó", "o", "ô", "o", "õ", "o", "ø", "o",
+	"ù", "u", "ú", "u", "û", "u", "ñ", "n", "ç", "c",
+}
+
+// vowelReplacements fassen ae/oe/ue zusammen, damit "Horen", "Hoeren" und "Hören" gleich behandelt werden
+var vowelReplacements = []string{"ae", "a", "oe", "o", "ue", "u"}
+
+var (
+	searchReplacer = strings.NewReplacer(searchReplacements...)
+	vowelReplacer  = strings.NewReplacer(vowelReplacements...)
+)
+
+// NormalizeSearchText bringt einen Text in die Vergleichsform der Suche:
+// Kleinschreibung, Umlaute und ß aufgelöst, Satzzeichen als Leerzeichen.
+// Beide Seiten des Vergleichs werden gleich behandelt, daher ist die Form nur zum Vergleichen gedacht.
+func NormalizeSearchText(text string) string {
+	text = searchReplacer.Replace(strings.ToLower(text))
+	text = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return r
+		}
+		return ' '
+	}, text)
+	return strings.Join(strings.Fields(vowelReplacer.Replace(text)), " ")
+}
+
+// levenshtein berechnet den Editierabstand zweier Wörter
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// allowedTypos liefert die erlaubten Tippfehler für ein Suchwort
+func allowedTypos(word string) int {
+	switch n := len([]rune(word)); {
+	case n < 4:
+		return 0
+	case n < 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// fuzzyWordDistance liefert den kleinsten Abstand eines Suchworts zu einem Wort (oder Wortanfang) des Textes
+// Liefert -1, wenn kein Wort nahe genug ist.
+func fuzzyWordDistance(queryWord string, words []string) int {
+	best := -1
+	limit := allowedTypos(queryWord)
+	queryLen := len([]rune(queryWord))
+	for _, word := range words {
+		distance := levenshtein(queryWord, word)
+		// Auch Wortanfänge zählen, damit "feurbal" noch "Feuerball" findet
+		if wr := []rune(word); len(wr) > queryLen {
+			distance = min(distance, levenshtein(queryWord, string(wr[:queryLen])))
+		}
+		if distance <= limit && (best < 0 || distance < best) {
+			best = distance
+		}
+	}
+	return best
+}
+
+// scoreSearchText bewertet einen normalisierten Namen und eine Beschreibung gegen die Suchanfrage
+// Höhere Werte sind bessere Treffer, 0 bedeutet kein Treffer.
+func scoreSearchText(query string, queryWords []string, name, beschreibung string) (int, string) {
+	switch {
+	case name == query:
+		return 100, "name"
+	case strings.HasPrefix(name, query):
+		return 90, "name"
+	case strings.Contains(" "+name, " "+query):
+		return 80, "name"
+	case strings.Contains(name, query):
+		return 70, "name"
+	}
+
+	nameWords := strings.Fields(name)
+	allContained, typos := true, 0
+	for _, word := range queryWords {
+		if !strings.Contains(name, word) {
+			allContained = false
+			distance := fuzzyWordDistance(word, nameWords)
+			if distance < 0 {
+				typos = -1
+				break
+			}
+			typos += distance
+		}
+	}
+	if allContained {
+		return 60, "name"
+	}
+	if typos > 0 {
+		return max(50-10*typos, 30), "name"
+	}
+
+	if beschreibung != "" {
+		if strings.Contains(beschreibung, query) {
+			return 20, "beschreibung"
+		}
+		for _, word := range queryWords {
+			if !strings.Contains(beschreibung, word) {
+				return 0, ""
+			}
+		}
+		return 10, "beschreibung"
+	}
+	return 0, ""
+}
+
+// snippet kürzt eine Beschreibung für die Trefferliste
+func snippet(text string) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) <= searchSnippetRunes {
+		return string(runes)
+	}
+	return strings.TrimSpace(string(runes[:searchSnippetRunes])) + "…"
+}
+
+// normalizedSearchSQL bildet die Buchstaben von NormalizeSearchText in SQL nach
+// LOWER kennt unter SQLite nur ASCII, daher werden auch die großen Umlaute und Akzente ersetzt.
+// Satzzeichen bleiben stehen; sie liegen nie innerhalb eines Suchwortes und stören den LIKE-Vergleich nicht.
+func normalizedSearchSQL(column string) string {
+	expr := "LOWER(COALESCE(" + column + ", ''))"
+	replace := func(from, to string) {
+		expr = "REPLACE(" + expr + ", '" + from + "', '" + to + "')"
+	}
+	for i := 0; i < len(searchReplacements); i += 2 {
+		from, to := searchReplacements[i], searchReplacements[i+1]
+		if upper := strings.ToUpper(from); upper != from {
+			replace(upper, to)
+		}
+		replace(from, to)
+	}
+	for i := 0; i < len(vowelReplacements); i += 2 {
+		replace(vowelReplacements[i], vowelReplacements[i+1])
+	}
+	return expr
+}
+
+// searchWordPieces teilt ein Suchwort in so viele Stücke, dass bei den erlaubten Tippfehlern
+// mindestens eines unverändert im Treffer vorkommt
+func searchWordPieces(word string) []string {
+	runes := []rune(word)
+	count := allowedTypos(word) + 1
+	pieces := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		pieces = append(pieces, string(runes[i*len(runes)/count:(i+1)*len(runes)/count]))
+	}
+	return pieces
+}
+
+// loadSearchRows lädt die Kandidaten einer Stammdatentabelle, gefiltert nach Spielsystem und Quelle
+// Die Datenbank liefert nur Zeilen, in denen jedes Suchwort mit einem seiner Stücke in Name oder Beschreibung
+// vorkommt. Bewertet wird in Go, damit Umlaute und Tippfehler unter SQLite und MariaDB gleich behandelt werden.
+func loadSearchRows(searchType, table string, scoped bool, opts SearchOptions, queryWords []string) ([]searchRow, error) {
+	candidates := database.DB.Table(table)
+	if searchType == "misc" {
+		candidates = candidates.Select("id, value AS name, '' AS beschreibung, `key` AS `key`, game_system, game_system_id, source_id, " +
+			normalizedSearchSQL("value") + " AS search_name, '' AS search_text")
+		if opts.GameSystem != nil {
+			candidates = candidates.Where("game_system_id = ? OR game_system_id = 0 OR game_system_id IS NULL", opts.GameSystem.ID)
+		}
+	} else {
+		candidates = candidates.Select("id, name, beschreibung, '' AS `key`, game_system, game_system_id, source_id, " +
+			normalizedSearchSQL("name") + " AS search_name, " + normalizedSearchSQL("beschreibung") + " AS search_text")
+		if opts.GameSystem != nil {
+			candidates = candidates.Where("game_system = ? OR game_system_id = ?", opts.GameSystem.Name, opts.GameSystem.ID)
+		}
+	}
+	if opts.SourceID != 0 {
+		candidates = candidates.Where("source_id = ?", opts.SourceID)
+	}
+	if scoped {
+		candidates = candidates.Scopes(visibleTo(opts.Scope))
+	}
+
+	query := database.DB.Table("(?) AS candidates", candidates).
+		Select("id, name, beschreibung, `key`, game_system, game_system_id, source_id")
+	for _, word := range queryWords {
+		var conditions []string
+		var args []any
+		for _, piece := range searchWordPieces(word) {
+			conditions = append(conditions, "search_name LIKE ? OR search_text LIKE ?")
+			args = append(args, "%"+piece+"%", "%"+piece+"%")
+		}
+		query = query.Where(strings.Join(conditions, " OR "), args...)
+	}
+
+	var rows []searchRow
+	err := query.Scan(&rows).Error
+	return rows, err
+}
+
+// SearchMasterData durchsucht Fertigkeiten, Waffenfertigkeiten, Zauber, Ausrüstung, Waffen,
+// Behältnisse, Glaubensrichtungen und Auswahllisten nach Name und Beschreibung
+func SearchMasterData(opts SearchOptions) ([]SearchResult, error) {
+	query := NormalizeSearchText(opts.Query)
+	queryWords := strings.Fields(query)
+	if len(queryWords) == 0 {
+		return []SearchResult{}, nil
+	}
+	if opts.Limit <= 0 || opts.Limit > searchMaxLimit {
+		opts.Limit = searchDefaultLimit
+	}
+	wanted := make(map[string]bool, len(opts.Types))
+	for _, t := range opts.Types {
+		wanted[t] = true
+	}
+
+	results := []SearchResult{}
+	typeOrder := make(map[string]int, len(searchTables))
+	for i, st := range searchTables {
+		typeOrder[st.Type] = i
+		if len(wanted) > 0 && !wanted[st.Type] {
+			continue
+		}
+		rows, err := loadSearchRows(st.Type, st.Table, st.Scoped, opts, queryWords)
+		if err != nil {
+			return nil, err
+		}
+		for _, row := range rows {
+			score, field := scoreSearchText(query, queryWords, NormalizeSearchText(row.Name), NormalizeSearchText(row.Beschreibung))
+			if score == 0 {
+				continue
+			}
+			results = append(results, SearchResult{
+				Type:         st.Type,
+				ID:           row.ID,
+				Name:         row.Name,
+				Beschreibung: snippet(row.Beschreibung),
+				Key:          row.Key,
+				GameSystem:   row.GameSystem,
+				GameSystemId: row.GameSystemId,
+				SourceID:     row.SourceID,
+				Score:        score,
+				MatchedField: field,
+			})
+		}
+	}
+
+	// Beste Treffer zuerst, bei gleicher Bewertung kürzere Namen, dann alphabetisch
+	sort.SliceStable(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if len(a.Name) != len(b.Name) {
+			return len(a.Name) < len(b.Name)
+		}
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		return typeOrder[a.Type] < typeOrder[b.Type]
+	})
+	if len(results) > opts.Limit {
+		results = results[:opts.Limit]
+	}
+	return results, nil
+}
+
+// SearchMD ist der Suchendpunkt über alle Stammdaten
+// Parameter: q (Suchtext), types (kommagetrennt), game_system/game_system_id, source (Code) oder source_id, limit
+func SearchMD(c *gin.Context) {
+	q := strings.TrimSpace(c.Query("q"))
+	if len([]rune(q)) < 2 {
+		respondWithError(c, http.StatusBadRequest, "Search text must have at least 2 characters")
+		return
+	}
+
+	opts := SearchOptions{Query: q, Limit: searchDefaultLimit}
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
+	opts.Scope = scope
+	if types := c.Query("types"); types != "" {
+		known := make(map[string]bool, len(searchTables))
+		for _, st := range searchTables {
+			known[st.Type] = true
+		}
+		for _, t := range strings.Split(types, ",") {
+			t = strings.ToLower(strings.TrimSpace(t))
+			if t == "" {
+				continue
+			}
+			if !known[t] {
+				respondWithError(c, http.StatusBadRequest, "Unknown type: "+t)
+				return
+			}
+			opts.Types = append(opts.Types, t)
+		}
+	}
+
+	if c.Query("game_system_id") != "" || c.Query("game_system") != "" {
+		gs, ok := resolveGameSystem(c)
+		if !ok {
+			return
+		}
+		opts.GameSystem = gs
+	}
+
+	if sourceID := c.Query("source_id"); sourceID != "" {
+		id, err := strconv.ParseUint(sourceID, 10, 32)
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, "Invalid source_id")
+			return
+		}
+		opts.SourceID = uint(id)
+	} else if code := c.Query("source"); code != "" {
+		var source models.Source
+		if err := database.DB.Where("code = ?", code).First(&source).Error; err != nil {
+			respondWithError(c, http.StatusBadRequest, "Unknown source: "+code)
+			return
+		}
+		opts.SourceID = source.ID
+	}
+
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 {
+			respondWithError(c, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		opts.Limit = min(n, searchMaxLimit)
+	}
+
+	results, err := SearchMasterData(opts)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to search master data: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"query": q, "results": results, "count": len(results)})
+}
@@ -0,0 +1,137 @@
+package gsmaster
+
+import (
+	"bamort/database"
+	"bamort/models"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeSearchText(t *testing.T) {
+	assert.Equal(t, NormalizeSearchText("Hören"), NormalizeSearchText("hoeren"))
+	assert.Equal(t, NormalizeSearchText("Hören"), NormalizeSearchText("HOREN"))
+	assert.Equal(t, NormalizeSearchText("Fluß"), NormalizeSearchText("fluss"))
+	assert.Equal(t, NormalizeSearchText("Café"), NormalizeSearchText("cafe"))
+	assert.Equal(t, "zaubern zu lande", NormalizeSearchText("  Zaubern (zu Lande)!"))
+}
+
+func TestScoreSearchText(t *testing.T) {
+	score := func(query, name, beschreibung string) int {
+		q := NormalizeSearchText(query)
+		s, _ := scoreSearchText(q, strings.Fields(q), NormalizeSearchText(name), NormalizeSearchText(beschreibung))
+		return s
+	}
+	exact := score("Hören", "Hören", "")
+	prefix := score("Feuer", "Feuerball", "")
+	word := score("ball", "Feuer Ball", "")
+	inner := score("ball", "Feuerball", "")
+	fuzzy := score("Feuerbsll", "Feuerball", "")
+	description := score("Flammen", "Feuerball", "Eine Kugel aus Flammen")
+
+	assert.Greater(t, exact, prefix)
+	assert.Greater(t, prefix, word)
+	assert.Greater(t, word, inner)
+	assert.Greater(t, inner, fuzzy)
+	assert.Greater(t, fuzzy, description)
+	assert.Greater(t, description, 0)
+	assert.Zero(t, score("Schwimmen", "Feuerball", "Eine Kugel aus Flammen"))
+	// Kurze Wörter werden nicht unscharf verglichen
+	assert.Zero(t, score("Rot", "Tor", ""))
+}
+
+func TestSearchMD(t *testing.T) {
+	setupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+	require.NoError(t, database.DB.Create(&models.GameSystem{Code: "M5", Name: "midgard", IsActive: true}).Error)
+
+	router := gin.New()
+	RegisterRoutes(router.Group("/api"))
+	search := func(query string) (int, []SearchResult) {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, "/api/maintenance/search?"+query, nil)
+		router.ServeHTTP(w, req)
+		var response struct {
+			Results []SearchResult `json:"results"`
+		}
+		if w.Code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		}
+		return w.Code, response.Results
+	}
+
+	t.Run("umlaut insensitive and ranked", func(t *testing.T) {
+		code, results := search("q=hoeren&types=skill")
+		require.Equal(t, http.StatusOK, code)
+		require.NotEmpty(t, results)
+		assert.Equal(t, "Hören", results[0].Name)
+		assert.Equal(t, "skill", results[0].Type)
+		assert.Equal(t, "name", results[0].MatchedField)
+		for i := 1; i < len(results); i++ {
+			assert.GreaterOrEqual(t, results[i-1].Score, results[i].Score)
+		}
+	})
+
+	t.Run("fuzzy match across types", func(t *testing.T) {
+		code, results := search("q=Feurfinger&limit=5")
+		require.Equal(t, http.StatusOK, code)
+		require.NotEmpty(t, results)
+		assert.Equal(t, "Feuerfinger", results[0].Name)
+		assert.Equal(t, "spell", results[0].Type)
+		assert.LessOrEqual(t, len(results), 5)
+	})
+
+	t.Run("filters by game system and source", func(t *testing.T) {
+		code, results := search("q=hoeren&types=skill&game_system=M5&source_id=1")
+		require.Equal(t, http.StatusOK, code)
+		assert.NotEmpty(t, results)
+
+		code, results = search("q=hoeren&types=skill&source_id=999999")
+		require.Equal(t, http.StatusOK, code)
+		assert.Empty(t, results)
+
+		code, _ = search("q=hoeren&game_system=unbekannt")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+
+	t.Run("database pre-filter keeps every match", func(t *testing.T) {
+		for _, q := range []string{"hoeren", "Feurfinger", "Fluss", "Schwert", "Schwertr", "Flammen", "Ro"} {
+			query := NormalizeSearchText(q)
+			queryWords := strings.Fields(query)
+			opts := SearchOptions{Query: q, Limit: searchMaxLimit}
+			found, err := SearchMasterData(opts)
+			require.NoError(t, err)
+
+			// ohne Suchwörter liefert loadSearchRows alle Zeilen; bewertet wird hier wie in SearchMasterData
+			expected := 0
+			for _, st := range searchTables {
+				rows, err := loadSearchRows(st.Type, st.Table, st.Scoped, opts, nil)
+				require.NoError(t, err)
+				for _, row := range rows {
+					if score, _ := scoreSearchText(query, queryWords, NormalizeSearchText(row.Name), NormalizeSearchText(row.Beschreibung)); score > 0 {
+						expected++
+					}
+				}
+			}
+			assert.Equal(t, min(expected, searchMaxLimit), len(found), q)
+		}
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		code, _ := search("q=h")
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = search("q=hoeren&types=monster")
+		assert.Equal(t, http.StatusBadRequest, code)
+		code, _ = search("q=hoeren&limit=abc")
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
@@ -97,56 +97,13 @@ type CategoryDifficultyPair struct {
 
 // CreateSkillWithCategories creates a new skill with category-difficulty relationships
 func CreateSkillWithCategories(req SkillUpdateRequest) (uint, error) {
-	// Validate required fields
-	if req.Skill.Name == "" {
-		return 0, fmt.Errorf("skill name is required")
-	}
-
 	var skillID uint
 
 	// Start transaction
 	err := database.DB.Transaction(func(tx *gorm.DB) error {
-		// Create skill
-		if err := tx.Create(&req.Skill).Error; err != nil {
-			return err
-		}
-
-		skillID = req.Skill.ID
-
-		// Create category-difficulty relationships
-		for _, cd := range req.CategoryDifficulties {
-			// Get category and difficulty names for denormalized fields
-			var category models.SkillCategory
-			if err := tx.First(&category, cd.CategoryID).Error; err != nil {
-				return fmt.Errorf("category not found: %w", err)
-			}
-
-			var difficulty models.SkillDifficulty
-			if err := tx.First(&difficulty, cd.DifficultyID).Error; err != nil {
-				return fmt.Errorf("difficulty not found: %w", err)
-			}
-
-			learnCost := cd.LearnCost
-			if learnCost == 0 {
-				// Use default based on difficulty
-				learnCost = getDefaultLearnCost(difficulty.Name)
-			}
-
-			scd := models.SkillCategoryDifficulty{
-				SkillID:           skillID,
-				SkillCategoryID:   cd.CategoryID,
-				SkillDifficultyID: cd.DifficultyID,
-				LearnCost:         learnCost,
-				SCategory:         category.Name,
-				SDifficulty:       difficulty.Name,
-			}
-
-			if err := tx.Create(&scd).Error; err != nil {
-				return err
-			}
-		}
-
-		return nil
+		var err error
+		skillID, err = createSkillTx(tx, req)
+		return err
 	})
 
 	if err != nil {
@@ -156,59 +113,85 @@ func CreateSkillWithCategories(req SkillUpdateRequest) (uint, error) {
 	return skillID, nil
 }
 
+// createSkillTx legt eine Fertigkeit mit ihren Kategorien innerhalb einer Transaktion an
+func createSkillTx(tx *gorm.DB, req SkillUpdateRequest) (uint, error) {
+	// Validate required fields
+	if req.Skill.Name == "" {
+		return 0, fmt.Errorf("skill name is required")
+	}
+
+	// Create skill
+	if err := tx.Create(&req.Skill).Error; err != nil {
+		return 0, err
+	}
+
+	return req.Skill.ID, createSkillCategoriesTx(tx, req.Skill.ID, req.CategoryDifficulties)
+}
+
 // UpdateSkillWithCategories updates a skill and its category-difficulty relationships
 func UpdateSkillWithCategories(skillID uint, req SkillUpdateRequest) error {
 	// Start transaction
 	return database.DB.Transaction(func(tx *gorm.DB) error {
-		// Update skill basic info - use Select to explicitly include boolean fields
-		// This ensures false values are also updated (GORM skips zero values by default in Updates)
-		if err := tx.Model(&models.Skill{}).Where("id = ?", skillID).
-			Select("name", "beschreibung", "game_system", "initialwert", "basis_wert",
-				"bonuseigenschaft", "improvable", "innate_skill", "source_id", "page_number").
-			Updates(req.Skill).Error; err != nil {
-			return err
+		return updateSkillTx(tx, skillID, req)
+	})
+}
+
+// updateSkillTx ändert eine Fertigkeit samt Kategorien innerhalb einer Transaktion
+func updateSkillTx(tx *gorm.DB, skillID uint, req SkillUpdateRequest) error {
+	// Update skill basic info - use Select to explicitly include boolean fields
+	// This ensures false values are also updated (GORM skips zero values by default in Updates)
+	// Das Modell trägt die ID, damit die Änderungshistorie den Datensatz zuordnen kann
+	if err := tx.Model(&models.Skill{ID: skillID, ChangeTracking: req.Skill.ChangeTracking}).
+		Select("name", "beschreibung", "game_system", "initialwert", "basis_wert",
+			"bonuseigenschaft", "improvable", "innate_skill", "source_id", "page_number").
+		Updates(req.Skill).Error; err != nil {
+		return err
+	}
+
+	// Delete existing category-difficulty relationships
+	if err := tx.Where("skill_id = ?", skillID).Delete(&models.SkillCategoryDifficulty{}).Error; err != nil {
+		return err
+	}
+
+	// Create new relationships
+	return createSkillCategoriesTx(tx, skillID, req.CategoryDifficulties)
+}
+
+// createSkillCategoriesTx legt die Kategorie-Schwierigkeits-Zuordnungen einer Fertigkeit an
+func createSkillCategoriesTx(tx *gorm.DB, skillID uint, pairs []CategoryDifficultyPair) error {
+	for _, cd := range pairs {
+		// Get category and difficulty names for denormalized fields
+		var category models.SkillCategory
+		if err := tx.First(&category, cd.CategoryID).Error; err != nil {
+			return fmt.Errorf("category not found: %w", err)
 		}
 
-		// Delete existing category-difficulty relationships
-		if err := tx.Where("skill_id = ?", skillID).Delete(&models.SkillCategoryDifficulty{}).Error; err != nil {
-			return err
+		var difficulty models.SkillDifficulty
+		if err := tx.First(&difficulty, cd.DifficultyID).Error; err != nil {
+			return fmt.Errorf("difficulty not found: %w", err)
 		}
 
-		// Create new relationships
-		for _, cd := range req.CategoryDifficulties {
-			// Get category and difficulty names for denormalized fields
-			var category models.SkillCategory
-			if err := tx.First(&category, cd.CategoryID).Error; err != nil {
-				return fmt.Errorf("category not found: %w", err)
-			}
-
-			var difficulty models.SkillDifficulty
-			if err := tx.First(&difficulty, cd.DifficultyID).Error; err != nil {
-				return fmt.Errorf("difficulty not found: %w", err)
-			}
-
-			learnCost := cd.LearnCost
-			if learnCost == 0 {
-				// Use default based on difficulty
-				learnCost = getDefaultLearnCost(difficulty.Name)
-			}
-
-			scd := models.SkillCategoryDifficulty{
-				SkillID:           skillID,
-				SkillCategoryID:   cd.CategoryID,
-				SkillDifficultyID: cd.DifficultyID,
-				LearnCost:         learnCost,
-				SCategory:         category.Name,
-				SDifficulty:       difficulty.Name,
-			}
-
-			if err := tx.Create(&scd).Error; err != nil {
-				return err
-			}
+		learnCost := cd.LearnCost
+		if learnCost == 0 {
+			// Use default based on difficulty
+			learnCost = getDefaultLearnCost(difficulty.Name)
 		}
 
-		return nil
-	})
+		scd := models.SkillCategoryDifficulty{
+			SkillID:           skillID,
+			SkillCategoryID:   cd.CategoryID,
+			SkillDifficultyID: cd.DifficultyID,
+			LearnCost:         learnCost,
+			SCategory:         category.Name,
+			SDifficulty:       difficulty.Name,
+		}
+
+		if err := tx.Create(&scd).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
 }
 
 // getDefaultLearnCost returns default LE cost based on difficulty
@@ -236,6 +219,10 @@ func GetEnhancedMDSkills(c *gin.Context) {
 		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve skills: "+err.Error())
 		return
 	}
+	skills, ok := visibleItems(c, skills)
+	if !ok {
+		return
+	}
 
 	// Also get learning sources and difficulties for the dropdowns
 	var sources []models.Source
@@ -269,6 +256,9 @@ func GetEnhancedMDSkill(c *gin.Context) {
 		respondWithError(c, http.StatusNotFound, "Skill not found")
 		return
 	}
+	if !isVisible(c, skill) {
+		return
+	}
 
 	c.JSON(http.StatusOK, skill)
 }
@@ -290,6 +280,7 @@ func UpdateEnhancedMDSkill(c *gin.Context) {
 
 	// Ensure the ID matches
 	req.Skill.ID = uint(id)
+	req.Skill.SetChangedBy(c.GetUint("userID"))
 
 	if err := UpdateSkillWithCategories(uint(id), req); err != nil {
 		respondWithError(c, http.StatusInternalServerError, "Failed to update skill: "+err.Error())
@@ -315,6 +306,7 @@ func CreateEnhancedMDSkill(c *gin.Context) {
 	}
 
 	// Create the skill
+	req.Skill.SetChangedBy(c.GetUint("userID"))
 	skillID, err := CreateSkillWithCategories(req)
 	if err != nil {
 		respondWithError(c, http.StatusInternalServerError, "Failed to create skill: "+err.Error())
@@ -0,0 +1,132 @@
+package gsmaster
+
+import (
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SelectableSource ist ein Quellenbuch mit seinem Zustand in der geltenden Auswahl
+type SelectableSource struct {
+	models.Source
+	Selected bool `json:"selected"`
+}
+
+// SourceSelectionResponse zeigt, welche Quellenbücher für eine Kampagne oder einen Benutzer aktiv sind
+type SourceSelectionResponse struct {
+	CampaignID uint               `json:"campaign_id,omitempty"`
+	GameSystem string             `json:"game_system"`
+	Origin     string             `json:"origin"` // campaign, user oder global (keine eigene Auswahl)
+	Sources    []SelectableSource `json:"sources"`
+}
+
+// SourceSelectionRequest setzt die aktiven Quellenbücher; eine leere Liste setzt auf die globale Vorgabe zurück
+type SourceSelectionRequest struct {
+	SourceIDs []uint `json:"source_ids"`
+}
+
+// sourceSelectionTarget bestimmt Selector und Spielsystem aus ?campaign_id= oder dem Benutzer
+// Kampagnen sehen alle Mitglieder; ändern darf nur der Spielleiter.
+func sourceSelectionTarget(c *gin.Context, write bool) (models.SourceSelector, *models.GameSystem, bool) {
+	userID := c.GetUint("userID")
+	campaignParam := c.Query("campaign_id")
+	if campaignParam == "" {
+		gs, ok := resolveGameSystem(c)
+		return models.SourceSelector{UserID: userID}, gs, ok
+	}
+
+	campaignID, err := strconv.ParseUint(campaignParam, 10, 32)
+	if err != nil {
+		respondWithError(c, http.StatusBadRequest, "Invalid campaign_id")
+		return models.SourceSelector{}, nil, false
+	}
+	var campaign models.Campaign
+	if err := database.DB.First(&campaign, campaignID).Error; err != nil {
+		respondWithError(c, http.StatusNotFound, "Campaign not found")
+		return models.SourceSelector{}, nil, false
+	}
+	if write && campaign.UserID != userID {
+		respondWithError(c, http.StatusForbidden, "Only the game master can choose the sources of this campaign")
+		return models.SourceSelector{}, nil, false
+	}
+	if !write && !campaign.IsMember(userID) {
+		respondWithError(c, http.StatusForbidden, "You are not allowed to view this campaign")
+		return models.SourceSelector{}, nil, false
+	}
+	gs := models.GetGameSystem(0, campaign.GameSystem)
+	if gs == nil {
+		respondWithError(c, http.StatusInternalServerError, "Unknown game system "+campaign.GameSystem)
+		return models.SourceSelector{}, nil, false
+	}
+	return models.SourceSelector{CampaignID: campaign.ID}, gs, true
+}
+
+// respondSourceSelection gibt alle Quellenbücher des Spielsystems mit ihrem Zustand zurück
+func respondSourceSelection(c *gin.Context, selector models.SourceSelector, gs *models.GameSystem) {
+	active, err := models.GetActiveSources(gs.Name, selector)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve source selection")
+		return
+	}
+	var sources []models.Source
+	if err := database.DB.Where("game_system = ? OR game_system_id = ?", gs.Name, gs.ID).Order("is_core DESC, code ASC").Find(&sources).Error; err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve sources")
+		return
+	}
+
+	response := SourceSelectionResponse{CampaignID: selector.CampaignID, GameSystem: gs.Name, Origin: active.Origin, Sources: []SelectableSource{}}
+	for _, source := range sources {
+		response.Sources = append(response.Sources, SelectableSource{Source: source, Selected: active.Contains(source.ID)})
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// GetSourceSelection zeigt die aktiven Quellenbücher des Benutzers oder einer Kampagne (?campaign_id=)
+func GetSourceSelection(c *gin.Context) {
+	selector, gs, ok := sourceSelectionTarget(c, false)
+	if !ok {
+		return
+	}
+	respondSourceSelection(c, selector, gs)
+}
+
+// UpdateSourceSelection wählt die aktiven Quellenbücher des Benutzers oder einer Kampagne (?campaign_id=)
+// Sie ersetzt für Verfügbarkeits- und Erstellungsendpunkte das globale Source.IsActive.
+func UpdateSourceSelection(c *gin.Context) {
+	selector, gs, ok := sourceSelectionTarget(c, true)
+	if !ok {
+		return
+	}
+	var req SourceSelectionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondWithError(c, http.StatusBadRequest, "Invalid request: "+err.Error())
+		return
+	}
+
+	seen := map[uint]bool{}
+	sourceIDs := []uint{}
+	for _, id := range req.SourceIDs {
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		var source models.Source
+		err := database.DB.Where("id = ? AND (game_system = ? OR game_system_id = ?)", id, gs.Name, gs.ID).First(&source).Error
+		if err != nil {
+			respondWithError(c, http.StatusBadRequest, "Unknown source for "+gs.Name+": "+strconv.FormatUint(uint64(id), 10))
+			return
+		}
+		sourceIDs = append(sourceIDs, id)
+	}
+
+	if err := models.SetSourceSelection(gs.Name, selector, sourceIDs); err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to save source selection: "+err.Error())
+		return
+	}
+	logger.Info("Quellenauswahl gespeichert (Kampagne %d, Benutzer %d): %d Quellenbücher", selector.CampaignID, selector.UserID, len(sourceIDs))
+	respondSourceSelection(c, selector, gs)
+}
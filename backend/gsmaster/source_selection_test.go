@@ -0,0 +1,109 @@
+package gsmaster
+
+import (
+	"bamort/database"
+	"bamort/models"
+	"bamort/user"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSourceSelection(t *testing.T) {
+	setupTestEnvironment(t)
+	gin.SetMode(gin.TestMode)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+	require.NoError(t, database.DB.Create(&models.GameSystem{Code: "M5", Name: "midgard", IsActive: true}).Error)
+
+	player := &user.User{UserID: 269, Username: "arkanist", Role: user.RoleStandardUser}
+	gm := &user.User{UserID: 270, Username: "meister", Role: user.RoleStandardUser}
+
+	client := newMaintenanceClient()
+	request := func(as *user.User, method, path string, body any) (int, SourceSelectionResponse) {
+		code, raw := client.request(as, method, path, body)
+		var response SourceSelectionResponse
+		if code == http.StatusOK {
+			require.NoError(t, json.Unmarshal(raw, &response))
+		}
+		return code, response
+	}
+	selected := func(response SourceSelectionResponse) []string {
+		codes := []string{}
+		for _, source := range response.Sources {
+			if source.Selected {
+				codes = append(codes, source.Code)
+			}
+		}
+		return codes
+	}
+
+	campaign := models.Campaign{Name: "Arkanum-Runde", GameSystem: "M5", UserID: gm.UserID}
+	require.NoError(t, database.DB.Create(&campaign).Error)
+	hero := models.Char{BamortBase: models.BamortBase{Name: "Ilvy"}, UserID: player.UserID, Typ: "Magier", Rasse: "Mensch", Grad: 1, CampaignID: campaign.ID}
+	require.NoError(t, database.DB.Create(&hero).Error)
+	campaignPath := fmt.Sprintf("/source-selection?campaign_id=%d", campaign.ID)
+
+	t.Run("without selection the global flag applies", func(t *testing.T) {
+		code, response := request(player, http.MethodGet, "/source-selection", nil)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, models.SourceOriginGlobal, response.Origin)
+		assert.Contains(t, selected(response), "ARK")
+		assert.Contains(t, selected(response), "MYS")
+	})
+
+	t.Run("user selection", func(t *testing.T) {
+		code, _ := request(player, http.MethodPut, "/source-selection", SourceSelectionRequest{SourceIDs: []uint{1, 99999}})
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		code, response := request(player, http.MethodPut, "/source-selection", SourceSelectionRequest{SourceIDs: []uint{1, 1}})
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, models.SourceOriginUser, response.Origin)
+		assert.Equal(t, []string{"KOD"}, selected(response))
+
+		code, response = request(gm, http.MethodGet, "/source-selection", nil)
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, models.SourceOriginGlobal, response.Origin, "selection is per user")
+
+		skills, err := models.GetSkillsByActiveSources("midgard", models.SourceSelector{UserID: player.UserID})
+		require.NoError(t, err)
+		require.NotEmpty(t, skills)
+		for _, skill := range skills {
+			assert.Contains(t, []uint{0, 1}, skill.SourceID, skill.Name)
+		}
+	})
+
+	t.Run("campaign selection", func(t *testing.T) {
+		code, _ := request(player, http.MethodPut, campaignPath, SourceSelectionRequest{SourceIDs: []uint{2}})
+		assert.Equal(t, http.StatusForbidden, code, "only the game master chooses")
+
+		code, response := request(gm, http.MethodPut, campaignPath, SourceSelectionRequest{SourceIDs: []uint{1, 2}})
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, models.SourceOriginCampaign, response.Origin)
+		assert.ElementsMatch(t, []string{"KOD", "ARK"}, selected(response))
+
+		code, response = request(player, http.MethodGet, campaignPath, nil)
+		require.Equal(t, http.StatusOK, code, "members may view")
+		assert.ElementsMatch(t, []string{"KOD", "ARK"}, selected(response))
+
+		spells, err := models.GetSpellsByActiveSources("midgard", models.CharacterSourceSelector(&hero))
+		require.NoError(t, err)
+		require.NotEmpty(t, spells)
+		for _, spell := range spells {
+			assert.Contains(t, []uint{0, 1, 2}, spell.SourceID, spell.Name)
+		}
+	})
+
+	t.Run("empty list resets to the global flag", func(t *testing.T) {
+		code, response := request(gm, http.MethodPut, campaignPath, SourceSelectionRequest{SourceIDs: []uint{}})
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, models.SourceOriginGlobal, response.Origin)
+		assert.Contains(t, selected(response), "MYS")
+	})
+}
@@ -57,15 +57,22 @@ type SpellUpdateRequest struct {
 func UpdateSpellWithCategories(spellID uint, req SpellUpdateRequest) error {
 	// Start transaction
 	return database.DB.Transaction(func(tx *gorm.DB) error {
-		// Update spell info
-		if err := tx.Model(&models.Spell{}).Where("id = ?", spellID).Updates(req.Spell).Error; err != nil {
-			return err
-		}
-
-		return nil
+		return updateSpellTx(tx, spellID, req)
 	})
 }
 
+// updateSpellTx ändert einen Zauber innerhalb einer Transaktion
+// Ohne columns werden nur gesetzte Felder geschrieben (GORM überspringt Nullwerte in Updates),
+// mit columns genau diese Spalten, auch wenn der neue Wert 0, leer oder false ist.
+func updateSpellTx(tx *gorm.DB, spellID uint, req SpellUpdateRequest, columns ...string) error {
+	// Das Modell trägt die ID, damit die Änderungshistorie den Datensatz zuordnen kann
+	query := tx.Model(&models.Spell{ID: spellID, ChangeTracking: req.Spell.ChangeTracking})
+	if len(columns) > 0 {
+		query = query.Select(columns)
+	}
+	return query.Updates(req.Spell).Error
+}
+
 // ===== Handler Functions =====
 
 // GetEnhancedMDSpells returns spells with enhanced information
@@ -75,6 +82,10 @@ func GetEnhancedMDSpells(c *gin.Context) {
 		respondWithError(c, http.StatusInternalServerError, "Failed to retrieve spells: "+err.Error())
 		return
 	}
+	spells, ok := visibleItems(c, spells)
+	if !ok {
+		return
+	}
 
 	// Also get learning sources and categories for the dropdowns
 	var sources []models.Source
@@ -109,6 +120,9 @@ func GetEnhancedMDSpell(c *gin.Context) {
 		respondWithError(c, http.StatusNotFound, "Spell not found")
 		return
 	}
+	if !isVisible(c, spell) {
+		return
+	}
 
 	c.JSON(http.StatusOK, spell)
 }
@@ -130,6 +144,7 @@ func UpdateEnhancedMDSpell(c *gin.Context) {
 
 	// Ensure the ID matches
 	req.Spell.ID = uint(id)
+	req.Spell.SetChangedBy(c.GetUint("userID"))
 
 	if err := UpdateSpellWithCategories(uint(id), req); err != nil {
 		respondWithError(c, http.StatusInternalServerError, "Failed to update spell: "+err.Error())
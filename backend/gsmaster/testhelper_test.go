@@ -0,0 +1,41 @@
+package gsmaster
+
+import (
+	"bamort/user"
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maintenanceClient ruft die Stammdaten-Routen nacheinander als verschiedene Benutzer auf
+type maintenanceClient struct {
+	router *gin.Engine
+	actor  *user.User
+}
+
+// newMaintenanceClient registriert die Routen hinter einer Middleware, die den jeweiligen Benutzer setzt
+func newMaintenanceClient() *maintenanceClient {
+	client := &maintenanceClient{router: gin.New()}
+	api := client.router.Group("/api", func(c *gin.Context) {
+		c.Set("user", client.actor)
+		c.Set("userID", client.actor.UserID)
+	})
+	RegisterRoutes(api)
+	return client
+}
+
+// request sendet body als JSON an /api/maintenance+path und liefert Status und Antworttext
+func (client *maintenanceClient) request(as *user.User, method, path string, body any) (int, []byte) {
+	client.actor = as
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, "/api/maintenance"+path, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	client.router.ServeHTTP(w, req)
+	return w.Code, w.Body.Bytes()
+}
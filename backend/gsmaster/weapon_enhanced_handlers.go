@@ -26,8 +26,12 @@ func GetEnhancedMDWeapons(c *gin.Context) {
 		Sources []models.Source  `json:"sources"`
 	}
 
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
 	var weapons []models.Weapon
-	if err := database.DB.Where("game_system=? OR game_system_id=?", gs.Name, gs.ID).Find(&weapons).Error; err != nil {
+	if err := database.DB.Where("game_system=? OR game_system_id=?", gs.Name, gs.ID).Scopes(visibleTo(scope)).Find(&weapons).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve weapons"})
 		return
 	}
@@ -82,6 +86,9 @@ func GetEnhancedMDWeapon(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Weapon not found"})
 		return
 	}
+	if !isVisible(c, weapon) {
+		return
+	}
 
 	var source models.Source
 	var sourceCode string
@@ -128,6 +135,7 @@ func UpdateEnhancedMDWeapon(c *gin.Context) {
 	weapon.ID = uint(id)
 	weapon.GameSystem = gs.Name
 	weapon.GameSystemId = gs.ID
+	weapon.SetChangedBy(c.GetUint("userID"))
 
 	// Update the weapon
 	if err := weapon.Save(); err != nil {
@@ -25,8 +25,12 @@ func GetEnhancedMDWeaponSkills(c *gin.Context) {
 		Difficulties []models.SkillDifficulty `json:"difficulties"`
 	}
 
+	scope, ok := requestScope(c)
+	if !ok {
+		return
+	}
 	var weaponSkills []models.WeaponSkill
-	if err := database.DB.Find(&weaponSkills).Error; err != nil {
+	if err := database.DB.Scopes(visibleTo(scope)).Find(&weaponSkills).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve weapon skills"})
 		return
 	}
@@ -105,6 +109,9 @@ func GetEnhancedMDWeaponSkill(c *gin.Context) {
 		c.JSON(http.StatusNotFound, gin.H{"error": "Weapon skill not found"})
 		return
 	}
+	if !isVisible(c, weaponSkill) {
+		return
+	}
 
 	var source models.Source
 	var sourceCode string
@@ -160,6 +167,7 @@ func UpdateEnhancedMDWeaponSkill(c *gin.Context) {
 	weaponSkill.PageNumber = req.PageNumber
 	weaponSkill.GameSystem = req.GameSystem
 	weaponSkill.ID = uint(id)
+	weaponSkill.SetChangedBy(c.GetUint("userID"))
 
 	if err := database.DB.Save(&weaponSkill).Error; err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update weapon skill"})
@@ -1,6 +1,7 @@
 package importer
 
 import (
+	"bamort/media"
 	"bamort/models"
 	"encoding/csv"
 	"encoding/json"
@@ -26,7 +27,7 @@ func ExportCharToVTT(char *models.Char) (*CharacterImport, error) {
 	vtt.Gewicht = char.Gewicht
 	vtt.Glaube = char.Glaube
 	vtt.Hand = char.Hand
-	vtt.Image = char.Image
+	vtt.Image = media.PortraitDataURI(char)
 
 	// LP
 	vtt.Lp.Max = char.Lp.Max
@@ -541,3 +541,11 @@ func TestImportBelieve2GSMaster(t *testing.T) {
 	err = CheckBelieve2GSMaster(character)
 	assert.NoError(t, err, "Expected no error when checkimg Transüportations against gsmaster")
 }
+
+func TestIsImprovableSkill(t *testing.T) {
+	assert.False(t, isImprovableSkill("Sehen"), "innate senses are not improvable")
+	assert.False(t, isImprovableSkill("Nachtsicht"))
+	assert.True(t, isImprovableSkill("Wahrnehmung"), "learnable skills stay improvable")
+	assert.True(t, isImprovableSkill("Trinken"))
+	assert.True(t, isImprovableSkill("Klettern"))
+}
@@ -1,9 +1,13 @@
 package importer
 
 import (
+	"bamort/database"
+	"bamort/logger"
+	"bamort/media"
 	"bamort/models"
 	"encoding/json"
 	"os"
+	"strings"
 )
 
 func readImportChar(fileName string) (*CharacterImport, error) {
@@ -248,6 +252,12 @@ func ImportVTTJSON(fileName string, userID uint) (*models.Char, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Eingebettete Bilder in die Medientabelle übernehmen
+	if strings.HasPrefix(char.Image, "data:") {
+		if err := media.MigrateCharacterImage(database.DB, &char); err != nil {
+			logger.Warn("Bild von Charakter %d konnte nicht übernommen werden: %s", char.ID, err.Error())
+		}
+	}
 	// Fix contained in links
 	for i := range char.Ausruestung {
 		err := char.Ausruestung[i].LinkContainer()
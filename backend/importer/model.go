@@ -184,32 +184,11 @@ type CharacterImport struct {
 	Image              string             `json:"image,omitempty"`
 }
 
-const (
-	SKILL_HOEREN       = "Hören"
-	SKILL_NACHTSICHT   = "Nachtsicht"
-	SKILL_RIECHEN      = "Riechen"
-	SKILL_SECHSTERSINN = "Sechster Sinn"
-	SKILL_SEHEN        = "Sehen"
-	SKILL_WAHRNEHMUNG  = "Wahrnehmung"
-)
-
-var nonImprovableSkills = []string{
-	SKILL_HOEREN,
-	SKILL_NACHTSICHT,
-	SKILL_RIECHEN,
-	SKILL_SECHSTERSINN,
-	SKILL_SEHEN,
-	SKILL_WAHRNEHMUNG,
-}
-
+// isImprovableSkill prüft anhand der besonderen Fähigkeiten, ob eine Fertigkeit gesteigert werden kann
 func isImprovableSkill(name string) bool {
-	for _, skill := range nonImprovableSkills {
-		if skill == name {
-			return false
-		}
-	}
-	return true
+	return !models.IsInnateSkill(name)
 }
+
 func TransformImportFertigkeit2GSDMaster(object *Fertigkeit) (*models.Skill, error) {
 	gsmobj := models.Skill{}
 
@@ -0,0 +1,109 @@
+package maintenance
+
+import (
+	"bamort/character"
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConsistencyJobResult fasst die Stapelprüfung aller Charaktere zusammen
+type ConsistencyJobResult struct {
+	Checked  int                            `json:"checked"`
+	Invalid  int                            `json:"invalid"` // Charaktere mit mindestens einem Fehler
+	Errors   int                            `json:"errors"`
+	Warnings int                            `json:"warnings"`
+	Fixed    map[uint][]string              `json:"fixed,omitempty"` // angewendete Korrekturen je Charakter
+	Failed   map[uint]string                `json:"failed,omitempty"`
+	Reports  []*character.ConsistencyReport `json:"reports"`
+}
+
+// runConsistencyJob prüft alle Charaktere und wendet auf Wunsch die automatischen Korrekturen an
+// Die Stammdaten werden je Spielsystem nur einmal geladen.
+func runConsistencyJob(applyFixes bool, includeValid bool, userID uint) (*ConsistencyJobResult, error) {
+	var ids []uint
+	if err := database.DB.Model(&models.Char{}).Order("id ASC").Pluck("id", &ids).Error; err != nil {
+		return nil, fmt.Errorf("failed to list characters: %w", err)
+	}
+
+	result := &ConsistencyJobResult{
+		Fixed:   map[uint][]string{},
+		Failed:  map[uint]string{},
+		Reports: make([]*character.ConsistencyReport, 0),
+	}
+	indexes := map[uint]*character.MasterDataIndex{}
+
+	for _, id := range ids {
+		var char models.Char
+		if err := char.FirstID(fmt.Sprint(id)); err != nil {
+			result.Failed[id] = err.Error()
+			continue
+		}
+
+		index, ok := indexes[char.GameSystemId]
+		if !ok {
+			gs := models.GetGameSystem(char.GameSystemId, char.GameSystem)
+			if gs == nil {
+				gs = models.GetGameSystem(0, "")
+			}
+			var err error
+			if index, err = character.LoadMasterDataIndex(gs); err != nil {
+				return nil, err
+			}
+			indexes[char.GameSystemId] = index
+		}
+
+		report, err := character.ValidateCharacter(&char, index)
+		if err != nil {
+			result.Failed[id] = err.Error()
+			continue
+		}
+
+		if applyFixes && len(report.Issues) > 0 {
+			applied, err := character.ApplyConsistencyFixes(report, nil, userID)
+			if err != nil {
+				result.Failed[id] = err.Error()
+				continue
+			}
+			if len(applied) > 0 {
+				result.Fixed[id] = applied
+			}
+		}
+
+		result.Checked++
+		result.Errors += report.Errors
+		result.Warnings += report.Warnings
+		if !report.Valid {
+			result.Invalid++
+		}
+		if includeValid || len(report.Issues) > 0 {
+			result.Reports = append(result.Reports, report)
+		}
+	}
+	return result, nil
+}
+
+// CheckCharacterConsistency prüft alle Charaktere (?all=true liefert auch Berichte ohne Befund)
+func CheckCharacterConsistency(c *gin.Context) {
+	respondConsistencyJob(c, false)
+}
+
+// FixCharacterConsistency prüft alle Charaktere und wendet die automatischen Korrekturen an
+func FixCharacterConsistency(c *gin.Context) {
+	respondConsistencyJob(c, true)
+}
+
+func respondConsistencyJob(c *gin.Context, applyFixes bool) {
+	result, err := runConsistencyJob(applyFixes, c.Query("all") == "true", c.GetUint("userID"))
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	logger.Info("Konsistenzprüfung: %d Charaktere geprüft, %d fehlerhaft, %d korrigiert", result.Checked, result.Invalid, len(result.Fixed))
+	c.JSON(http.StatusOK, result)
+}
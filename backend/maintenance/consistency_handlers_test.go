@@ -0,0 +1,55 @@
+package maintenance
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckCharacterConsistency(t *testing.T) {
+	token, router, _ := setupMaintenanceTest(t)
+	require.NoError(t, models.MigrateStructure())
+
+	char := models.Char{BamortBase: models.BamortBase{Name: "Konsistenztest"}, UserID: 1, Typ: "Krieger", Rasse: "Mensch", Grad: 1}
+	require.NoError(t, database.DB.Create(&char).Error)
+	require.NoError(t, database.DB.Create(&models.EqAusruestung{
+		BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Fackel"}, CharacterID: char.ID, UserID: 1},
+		ContainedIn:     424242,
+	}).Error)
+
+	run := func(method, path string) ConsistencyJobResult {
+		req, err := http.NewRequest(method, path, nil)
+		require.NoError(t, err)
+		req.Header.Set("Authorization", "Bearer "+token)
+
+		resp := httptest.NewRecorder()
+		router.ServeHTTP(resp, req)
+
+		require.Equal(t, http.StatusOK, resp.Code, resp.Body.String())
+		var result ConsistencyJobResult
+		require.NoError(t, json.Unmarshal(resp.Body.Bytes(), &result))
+		return result
+	}
+
+	// Die Prüfung per GET ändert nichts, auch nicht mit dem früheren ?fix=true
+	result := run(http.MethodGet, "/api/maintenance/character-consistency?fix=true")
+	assert.Positive(t, result.Checked)
+	assert.Empty(t, result.Fixed)
+	var untouched models.EqAusruestung
+	require.NoError(t, database.DB.Where("character_id = ?", char.ID).First(&untouched).Error)
+	assert.Equal(t, uint(424242), untouched.ContainedIn)
+
+	result = run(http.MethodPost, "/api/maintenance/character-consistency/fix")
+	assert.Contains(t, result.Fixed, char.ID)
+
+	var item models.EqAusruestung
+	require.NoError(t, database.DB.Where("character_id = ?", char.ID).First(&item).Error)
+	assert.Zero(t, item.ContainedIn, "dangling container reference is removed")
+}
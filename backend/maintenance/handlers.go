@@ -5,6 +5,7 @@ import (
 	"bamort/database"
 	"bamort/gamesystem"
 	"bamort/logger"
+	"bamort/media"
 	"bamort/models"
 	"bamort/user"
 	"fmt"
@@ -95,6 +96,13 @@ func migrateDataIfNeeded(db *gorm.DB) error {
 		return fmt.Errorf("failed to migrate spell learning categories: %w", err)
 	}
 
+	// Base64-Bilder aus char_chars in die Medientabelle verschieben
+	logger.Debug("Migriere Charakterbilder...")
+	if _, err = media.MigrateCharacterImages(db); err != nil {
+		logger.Error("Fehler beim Migrieren der Charakterbilder: %s", err.Error())
+		return fmt.Errorf("failed to migrate character images: %w", err)
+	}
+
 	logger.Info("Datenmigration erfolgreich abgeschlossen")
 	return nil
 }
@@ -265,6 +273,11 @@ func copyMariaDBToSQLite(mariaDB, sqliteDB *gorm.DB) error {
 		&models.Transportation{},
 		&models.Believe{},
 
+		// Änderungshistorie und Änderungsvorschläge der Stammdaten
+		&models.MasterDataChange{},
+		&models.MasterDataProposal{},
+		&models.MasterDataProposalComment{},
+
 		// Charaktere (Basis)
 		&models.Char{},
 
@@ -298,6 +311,40 @@ func copyMariaDBToSQLite(mariaDB, sqliteDB *gorm.DB) error {
 		// Char Shares (abhängig von Char und User)
 		&models.CharShare{},
 
+		// Würfelhistorie (abhängig von Char)
+		&models.DiceRoll{},
+
+		// Charaktervorlagen (abhängig von User)
+		&models.CharTemplate{},
+
+		// Notizen zu Charakteren (abhängig von Char)
+		&models.CharNote{},
+
+		// Steigerungspläne (abhängig von Char)
+		&models.AdvancementPlan{},
+
+		// Ausfallzeit-Konto (abhängig von Char)
+		&models.DowntimeEntry{},
+
+		// Charakterbilder (abhängig von Char)
+		&models.MediaFile{},
+
+		// Spielkalender (Charaktere verweisen über calendar_id darauf)
+		&models.GameCalendar{},
+
+		// Kampagnen (Charaktere und Hausregel-Stammdaten verweisen über campaign_id darauf)
+		&models.Campaign{},
+		// Quellenauswahl je Kampagne bzw. Benutzer (abhängig von Source und Campaign)
+		&models.SourceSelection{},
+
+		// Begegnungen (Teilnehmer abhängig von Encounter und Char)
+		&models.Encounter{},
+		&models.EncounterParticipant{},
+
+		// Hintergrundjobs (Laufhistorie und Pausenzustand)
+		&models.SchedulerJobRun{},
+		&models.SchedulerJobState{},
+
 		// View-Strukturen ohne eigene Tabellen werden nicht kopiert:
 		// SkillLearningInfo, SpellLearningInfo, CharList, FeChar, etc.
 	}
@@ -601,6 +648,16 @@ func PopulateClassLearningPoints(c *gin.Context) {
 }
 */
 
+// MigrateCharacterImages verschiebt eingebettete Base64-Bilder in die Medientabelle
+func MigrateCharacterImages(c *gin.Context) {
+	migrated, err := media.MigrateCharacterImages(database.DB)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Failed to migrate character images: "+err.Error())
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Character images migrated", "migrated": migrated})
+}
+
 func ReconnectDataBase(c *gin.Context) {
 	logger.Info("Führe Datenbank-Reconnect durch...")
 
@@ -809,6 +866,48 @@ func copySQLiteToMariaDB(sqliteDB, mariaDB *gorm.DB) error {
 
 		// Audit Logging (abhängig von Char)
 		&models.AuditLogEntry{},
+
+		// Würfelhistorie (abhängig von Char)
+		&models.DiceRoll{},
+
+		// Begegnungen (Teilnehmer abhängig von Encounter und Char)
+		&models.Encounter{},
+		&models.EncounterParticipant{},
+
+		// Charaktervorlagen (abhängig von User)
+		&models.CharTemplate{},
+
+		// Hintergrundjobs (Laufhistorie und Pausenzustand)
+		&models.SchedulerJobRun{},
+		&models.SchedulerJobState{},
+
+		// Notizen zu Charakteren (abhängig von Char)
+		&models.CharNote{},
+
+		// Steigerungspläne (abhängig von Char)
+		&models.AdvancementPlan{},
+
+		// Ausfallzeit-Konto (abhängig von Char)
+		&models.DowntimeEntry{},
+
+		// Spielkalender (Charaktere verweisen über calendar_id darauf)
+		&models.GameCalendar{},
+
+		// Charakterbilder (abhängig von Char)
+		&models.MediaFile{},
+
+		// Änderungshistorie der Stammdaten
+		&models.MasterDataChange{},
+
+		// Änderungsvorschläge zu Stammdaten (Kommentare abhängig vom Vorschlag)
+		&models.MasterDataProposal{},
+		&models.MasterDataProposalComment{},
+
+		// Kampagnen (Charaktere und Hausregel-Stammdaten verweisen über campaign_id darauf)
+		&models.Campaign{},
+
+		// Quellenauswahl je Kampagne bzw. Benutzer (abhängig von Source und Campaign)
+		&models.SourceSelection{},
 	}
 
 	logger.Info("Kopiere Daten für %d Tabellen von SQLite zu MariaDB...", len(tables))
@@ -1113,6 +1212,102 @@ func copyTableDataReverse(sourceDB, targetDB *gorm.DB, model interface{}) error
 				return fmt.Errorf("failed to read batch from source: %w", err)
 			}
 			records = batch
+		case *models.DiceRoll:
+			var batch []models.DiceRoll
+			if err := sourceDB.Limit(batchSize).Offset(offset).Find(&batch).Error; err != nil {
+				return fmt.Errorf("failed to read batch from source: %w", err)
+			}
+			records = batch
+		case *models.Encounter:
+			var batch []models.Encounter
+			if err := sourceDB.Limit(batchSize).Offset(offset).Find(&batch).Error; err != nil {
+				return fmt.Errorf("failed to read batch from source: %w", err)
+			}
+			records = batch
+		case *models.EncounterParticipant:
+			var batch []models.EncounterParticipant
+			if err := sourceDB.Limit(batchSize).Offset(offset).Find(&batch).Error; err != nil {
+				return fmt.Errorf("failed to read batch from source: %w", err)
+			}
+			records = batch
+		case *models.CharTemplate:
+			var batch []models.CharTemplate
+			if err := sourceDB.Limit(batchSize).Offset(offset).Find(&batch).Error; err != nil {
+				return fmt.Errorf("failed to read batch from source: %w", err)
+			}
+			records = batch
+		case *models.SchedulerJobRun:
+			var batch []models.SchedulerJobRun
+			if err := sourceDB.Limit(batchSize).Offset(offset).Find(&batch).Error; err != nil {
+				return fmt.Errorf("failed to read batch from source: %w", err)
+			}
+			records = batch
+		case *models.SchedulerJobState:
+			var batch []models.SchedulerJobState
+			if err := sourceDB.Limit(batchSize).Offset(offset).Find(&batch).Error; err != nil {
+				return fmt.Errorf("failed to read batch from source: %w", err)
+			}
+			records = batch
+		case *models.CharNote:
+			var batch []models.CharNote
+			if err := sourceDB.Limit(batchSize).Offset(offset).Find(&batch).Error; err != nil {
+				return fmt.Errorf("failed to read batch from source: %w", err)
+			}
+			records = batch
+		case *models.AdvancementPlan:
+			var batch []models.AdvancementPlan
+			if err := sourceDB.Limit(batchSize).Offset(offset).Find(&batch).Error; err != nil {
+				return fmt.Errorf("failed to read batch from source: %w", err)
+			}
+			records = batch
+		case *models.DowntimeEntry:
+			var batch []models.DowntimeEntry
+			if err := sourceDB.Limit(batchSize).Offset(offset).Find(&batch).Error; err != nil {
+				return fmt.Errorf("failed to read batch from source: %w", err)
+			}
+			records = batch
+		case *models.GameCalendar:
+			var batch []models.GameCalendar
+			if err := sourceDB.Limit(batchSize).Offset(offset).Find(&batch).Error; err != nil {
+				return fmt.Errorf("failed to read batch from source: %w", err)
+			}
+			records = batch
+		case *models.MediaFile:
+			var batch []models.MediaFile
+			if err := sourceDB.Limit(batchSize).Offset(offset).Find(&batch).Error; err != nil {
+				return fmt.Errorf("failed to read batch from source: %w", err)
+			}
+			records = batch
+		case *models.MasterDataChange:
+			var batch []models.MasterDataChange
+			if err := sourceDB.Limit(batchSize).Offset(offset).Find(&batch).Error; err != nil {
+				return fmt.Errorf("failed to read batch from source: %w", err)
+			}
+			records = batch
+		case *models.MasterDataProposal:
+			var batch []models.MasterDataProposal
+			if err := sourceDB.Limit(batchSize).Offset(offset).Find(&batch).Error; err != nil {
+				return fmt.Errorf("failed to read batch from source: %w", err)
+			}
+			records = batch
+		case *models.MasterDataProposalComment:
+			var batch []models.MasterDataProposalComment
+			if err := sourceDB.Limit(batchSize).Offset(offset).Find(&batch).Error; err != nil {
+				return fmt.Errorf("failed to read batch from source: %w", err)
+			}
+			records = batch
+		case *models.Campaign:
+			var batch []models.Campaign
+			if err := sourceDB.Limit(batchSize).Offset(offset).Find(&batch).Error; err != nil {
+				return fmt.Errorf("failed to read batch from source: %w", err)
+			}
+			records = batch
+		case *models.SourceSelection:
+			var batch []models.SourceSelection
+			if err := sourceDB.Limit(batchSize).Offset(offset).Find(&batch).Error; err != nil {
+				return fmt.Errorf("failed to read batch from source: %w", err)
+			}
+			records = batch
 		default:
 			return fmt.Errorf("unsupported model type: %T", model)
 		}
@@ -1137,6 +1332,48 @@ func clearMariaDBData(db *gorm.DB) error {
 	// Clear tables in reverse order due to foreign key constraints
 	// (reverse of the insertion order in copySQLiteToMariaDB)
 	tables := []interface{}{
+		// Quellenauswahl je Kampagne bzw. Benutzer (abhängig von Source und Campaign)
+		&models.SourceSelection{},
+
+		// Kampagnen (Charaktere und Hausregel-Stammdaten verweisen über campaign_id darauf)
+		&models.Campaign{},
+
+		// Änderungsvorschläge zu Stammdaten (Kommentare abhängig vom Vorschlag)
+		&models.MasterDataProposalComment{},
+		&models.MasterDataProposal{},
+
+		// Änderungshistorie der Stammdaten
+		&models.MasterDataChange{},
+
+		// Charakterbilder (abhängig von Char)
+		&models.MediaFile{},
+
+		// Spielkalender (Charaktere verweisen über calendar_id darauf)
+		&models.GameCalendar{},
+
+		// Ausfallzeit-Konto (abhängig von Char)
+		&models.DowntimeEntry{},
+
+		// Steigerungspläne (abhängig von Char)
+		&models.AdvancementPlan{},
+
+		// Notizen zu Charakteren (abhängig von Char)
+		&models.CharNote{},
+
+		// Hintergrundjobs (Laufhistorie und Pausenzustand)
+		&models.SchedulerJobState{},
+		&models.SchedulerJobRun{},
+
+		// Charaktervorlagen (abhängig von User)
+		&models.CharTemplate{},
+
+		// Begegnungen (Teilnehmer abhängig von Encounter und Char)
+		&models.EncounterParticipant{},
+		&models.Encounter{},
+
+		// Würfelhistorie (abhängig von Char)
+		&models.DiceRoll{},
+
 		// Audit Logging und Character Creation Sessions (abhängig von Char) - zuerst löschen
 		&models.AuditLogEntry{},
 		&models.CharacterCreationSession{},
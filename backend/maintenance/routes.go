@@ -25,7 +25,10 @@ func RegisterRoutes(r *gin.RouterGroup) {
 		charGrp.GET("/mktestdata", MakeTestdataFromLive)
 		charGrp.GET("/reconndb", ReconnectDataBase) // Datenbank neu verbinden
 		charGrp.GET("/reloadenv", ReloadENV)
+		charGrp.GET("/character-consistency", CheckCharacterConsistency)     // Konsistenzprüfung aller Charaktere (?all=true)
+		charGrp.POST("/character-consistency/fix", FixCharacterConsistency)  // Automatische Korrekturen für alle Charaktere anwenden
 		charGrp.POST("/transfer-sqlite-to-mariadb", TransferSQLiteToMariaDB) // Transfer data from SQLite to MariaDB
+		charGrp.POST("/migrate-character-images", MigrateCharacterImages)    // Base64-Bilder in die Medientabelle verschieben
 		//charGrp.POST("/populate-class-learning-points", PopulateClassLearningPoints) // Populate class learning points from hardcoded data
 		/*
 			//nur zur einmaligen Ausführung, um das Lernkosten-System zu initialisieren
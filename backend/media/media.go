@@ -0,0 +1,296 @@
+package media
+
+import (
+	"bamort/config"
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/image/draw"
+	_ "golang.org/x/image/webp"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrEmpty           = errors.New("Keine Bilddaten übermittelt")
+	ErrTooLarge        = errors.New("Bild ist zu groß")
+	ErrUnsupportedType = errors.New("Bildformat wird nicht unterstützt")
+	ErrInvalidImage    = errors.New("Bilddaten sind ungültig")
+)
+
+// allowedMimeTypes sind die Bildformate, die hochgeladen werden dürfen
+var allowedMimeTypes = map[string]bool{
+	"image/png":  true,
+	"image/jpeg": true,
+	"image/gif":  true,
+	"image/webp": true,
+}
+
+// MaxBytes liefert die erlaubte Größe eines Bildes
+func MaxBytes() int {
+	if config.Cfg != nil && config.Cfg.MediaMaxBytes > 0 {
+		return config.Cfg.MediaMaxBytes
+	}
+	return 2 << 20
+}
+
+// MaxPixels liefert die erlaubte Bildfläche (Breite × Höhe)
+func MaxPixels() int {
+	if config.Cfg != nil && config.Cfg.MediaMaxPixels > 0 {
+		return config.Cfg.MediaMaxPixels
+	}
+	return 4096 * 4096
+}
+
+// thumbSize liefert die Kantenlänge der Vorschaubilder
+func thumbSize() int {
+	if config.Cfg != nil && config.Cfg.MediaThumbSize > 0 {
+		return config.Cfg.MediaThumbSize
+	}
+	return 128
+}
+
+// DecodeDataURI liest ein Bild aus einer Data-URI ("data:image/png;base64,...") oder reinem Base64
+func DecodeDataURI(value string) ([]byte, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, ErrEmpty
+	}
+	if strings.HasPrefix(value, "data:") {
+		comma := strings.IndexByte(value, ',')
+		if comma < 0 || !strings.HasSuffix(value[:comma], ";base64") {
+			return nil, ErrInvalidImage
+		}
+		value = value[comma+1:]
+	}
+	// Grobe Größenprüfung vor dem Dekodieren, Base64 ist etwa 4/3 so groß
+	if base64.StdEncoding.DecodedLen(len(value)) > MaxBytes()+3 {
+		return nil, ErrTooLarge
+	}
+	data, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidImage
+	}
+	return data, nil
+}
+
+// DataURI kodiert Bilddaten als Data-URI zum Einbetten in HTML
+func DataURI(data []byte, mimeType string) string {
+	return fmt.Sprintf("data:%s;base64,%s", mimeType, base64.StdEncoding.EncodeToString(data))
+}
+
+// Prepare prüft Größe und Format eines Bildes und erzeugt das Vorschaubild
+// Das Format wird am Inhalt erkannt, nicht an Dateiname oder Angaben des Clients.
+func Prepare(data []byte, kind string) (*models.MediaFile, error) {
+	if len(data) == 0 {
+		return nil, ErrEmpty
+	}
+	if len(data) > MaxBytes() {
+		return nil, fmt.Errorf("%w: %d Bytes, erlaubt sind %d Bytes", ErrTooLarge, len(data), MaxBytes())
+	}
+	mimeType := http.DetectContentType(data)
+	if !allowedMimeTypes[mimeType] {
+		return nil, fmt.Errorf("%w: %s", ErrUnsupportedType, mimeType)
+	}
+	// Abmessungen aus dem Bildkopf prüfen, bevor das Bild dekodiert wird: auch kleine Dateien
+	// können riesige Abmessungen angeben und beim Dekodieren Gigabytes belegen.
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidImage, err.Error())
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, fmt.Errorf("%w: %dx%d Pixel", ErrInvalidImage, cfg.Width, cfg.Height)
+	}
+	if cfg.Width > MaxPixels()/cfg.Height {
+		return nil, fmt.Errorf("%w: %dx%d Pixel, erlaubt sind %d Pixel", ErrTooLarge, cfg.Width, cfg.Height, MaxPixels())
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidImage, err.Error())
+	}
+
+	thumb, thumbType, err := makeThumbnail(img, mimeType)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(data)
+	return &models.MediaFile{
+		Kind:          kind,
+		MimeType:      mimeType,
+		Size:          len(data),
+		Width:         img.Bounds().Dx(),
+		Height:        img.Bounds().Dy(),
+		Checksum:      hex.EncodeToString(sum[:]),
+		Data:          data,
+		ThumbMimeType: thumbType,
+		Thumbnail:     thumb,
+	}, nil
+}
+
+// makeThumbnail verkleinert ein Bild unter Beibehaltung des Seitenverhältnisses
+// PNG und GIF bleiben wegen der Transparenz PNG, alle anderen Formate werden JPEG.
+func makeThumbnail(img image.Image, mimeType string) ([]byte, string, error) {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if size := thumbSize(); width > size || height > size {
+		if width >= height {
+			width, height = size, max(1, height*size/width)
+		} else {
+			width, height = max(1, width*size/height), size
+		}
+	}
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Over, nil)
+
+	var buf bytes.Buffer
+	if mimeType == "image/png" || mimeType == "image/gif" {
+		if err := png.Encode(&buf, dst); err != nil {
+			return nil, "", err
+		}
+		return buf.Bytes(), "image/png", nil
+	}
+	if err := jpeg.Encode(&buf, dst, &jpeg.Options{Quality: 85}); err != nil {
+		return nil, "", err
+	}
+	return buf.Bytes(), "image/jpeg", nil
+}
+
+// PortraitURL ist die authentifizierte Adresse des Charakterbildes
+// Die Medien-ID dient als Cache-Buster, wenn das Bild ersetzt wird.
+func PortraitURL(characterID, mediaID uint) string {
+	return fmt.Sprintf("/api/characters/%d/image?v=%d", characterID, mediaID)
+}
+
+// SavePortraitTx speichert ein neues Charakterbild, löscht das alte und setzt Char.Image auf die Bildadresse
+func SavePortraitTx(tx *gorm.DB, characterID, userID uint, data []byte) (*models.MediaFile, error) {
+	file, err := Prepare(data, models.MediaKindPortrait)
+	if err != nil {
+		return nil, err
+	}
+	file.CharacterID = characterID
+	file.UserID = userID
+
+	if err := tx.Where("character_id = ? AND kind = ?", characterID, models.MediaKindPortrait).Delete(&models.MediaFile{}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Create(file).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&models.Char{}).Where("id = ?", characterID).Update("image", PortraitURL(characterID, file.ID)).Error; err != nil {
+		return nil, err
+	}
+	return file, nil
+}
+
+// DeletePortraitTx entfernt das Charakterbild
+func DeletePortraitTx(tx *gorm.DB, characterID uint) error {
+	if err := tx.Where("character_id = ? AND kind = ?", characterID, models.MediaKindPortrait).Delete(&models.MediaFile{}).Error; err != nil {
+		return err
+	}
+	return tx.Model(&models.Char{}).Where("id = ?", characterID).Update("image", "").Error
+}
+
+// CopyPortraitTx übernimmt das Charakterbild eines Charakters für einen anderen (z.B. beim Klonen)
+// Liefert die neue Bildadresse oder "", wenn der Quellcharakter kein gespeichertes Bild hat.
+func CopyPortraitTx(tx *gorm.DB, fromCharacterID, toCharacterID, userID uint) (string, error) {
+	var file models.MediaFile
+	err := tx.Where("character_id = ? AND kind = ?", fromCharacterID, models.MediaKindPortrait).Order("id DESC").First(&file).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	file.ID = 0
+	file.CharacterID = toCharacterID
+	file.UserID = userID
+	file.CreatedAt = time.Time{}
+	if err := tx.Create(&file).Error; err != nil {
+		return "", err
+	}
+	url := PortraitURL(toCharacterID, file.ID)
+	return url, tx.Model(&models.Char{}).Where("id = ?", toCharacterID).Update("image", url).Error
+}
+
+// LoadPortrait lädt das Charakterbild; mit thumbnail=true nur das Vorschaubild
+func LoadPortrait(characterID uint, thumbnail bool) (*models.MediaFile, error) {
+	omit := "thumbnail"
+	if thumbnail {
+		omit = "data"
+	}
+	var file models.MediaFile
+	err := database.DB.Omit(omit).
+		Where("character_id = ? AND kind = ?", characterID, models.MediaKindPortrait).
+		Order("id DESC").First(&file).Error
+	if err != nil {
+		return nil, err
+	}
+	return &file, nil
+}
+
+// PortraitDataURI liefert das Charakterbild als Data-URI zum Einbetten in PDFs und Exporte
+// Andere Bildangaben (noch nicht migrierte Base64-Bilder, externe Adressen) werden unverändert übernommen.
+func PortraitDataURI(char *models.Char) string {
+	if !strings.HasPrefix(char.Image, fmt.Sprintf("/api/characters/%d/image", char.ID)) || database.DB == nil {
+		return char.Image
+	}
+	file, err := LoadPortrait(char.ID, false)
+	if err != nil {
+		return ""
+	}
+	return DataURI(file.Data, file.MimeType)
+}
+
+// MigrateCharacterImage überführt ein Base64-Bild aus Char.Image in die Medientabelle
+// und setzt Char.Image auf die neue Bildadresse.
+func MigrateCharacterImage(db *gorm.DB, char *models.Char) error {
+	data, err := DecodeDataURI(char.Image)
+	if err != nil {
+		return err
+	}
+	var file *models.MediaFile
+	err = db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		file, err = SavePortraitTx(tx, char.ID, char.UserID, data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	char.Image = PortraitURL(char.ID, file.ID)
+	return nil
+}
+
+// MigrateCharacterImages überführt alle Base64-Bilder aus Char.Image in die Medientabelle
+// Bilder, die nicht gelesen werden können, bleiben unverändert und werden protokolliert.
+func MigrateCharacterImages(db *gorm.DB) (int, error) {
+	var chars []models.Char
+	if err := db.Select("id", "user_id", "image").Where("image LIKE ?", "data:%").Find(&chars).Error; err != nil {
+		return 0, err
+	}
+	migrated := 0
+	for i := range chars {
+		if err := MigrateCharacterImage(db, &chars[i]); err != nil {
+			logger.Warn("Bild von Charakter %d konnte nicht migriert werden: %s", chars[i].ID, err.Error())
+			continue
+		}
+		migrated++
+	}
+	if migrated > 0 {
+		logger.Info("%d Charakterbilder in die Medientabelle migriert", migrated)
+	}
+	return migrated, nil
+}
@@ -0,0 +1,111 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testJPEG(t *testing.T, width, height int) []byte {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: color.RGBA{R: 30, G: 90, B: 160, A: 255}}, image.Point{}, draw.Src)
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, nil))
+	return buf.Bytes()
+}
+
+func testPNG(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestDecodeDataURI(t *testing.T) {
+	data, err := DecodeDataURI("data:image/png;base64,aGFsbG8=")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hallo"), data)
+
+	data, err = DecodeDataURI("aGFsbG8=")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("hallo"), data)
+
+	_, err = DecodeDataURI("data:image/png,hallo")
+	assert.ErrorIs(t, err, ErrInvalidImage)
+	_, err = DecodeDataURI("   ")
+	assert.ErrorIs(t, err, ErrEmpty)
+}
+
+func TestPrepareCreatesThumbnail(t *testing.T) {
+	file, err := Prepare(testJPEG(t, 200, 400), models.MediaKindPortrait)
+	require.NoError(t, err)
+	assert.Equal(t, "image/jpeg", file.MimeType)
+	assert.Equal(t, 200, file.Width)
+	assert.Equal(t, 400, file.Height)
+	assert.Len(t, file.Checksum, 64)
+
+	assert.Equal(t, "image/jpeg", file.ThumbMimeType)
+	thumb, format, err := image.DecodeConfig(bytes.NewReader(file.Thumbnail))
+	require.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, 64, thumb.Width)
+	assert.Equal(t, 128, thumb.Height)
+
+	// Ein kleines PNG mit riesigen Abmessungen im Kopf wird vor dem Dekodieren abgelehnt
+	header := testPNG(t, 1, 1)
+	binary.BigEndian.PutUint32(header[16:], 50000)
+	binary.BigEndian.PutUint32(header[20:], 50000)
+	binary.BigEndian.PutUint32(header[29:], crc32.ChecksumIEEE(header[12:29]))
+	_, err = Prepare(header, models.MediaKindPortrait)
+	assert.ErrorIs(t, err, ErrTooLarge)
+
+	_, err = Prepare([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"), models.MediaKindPortrait)
+	assert.ErrorIs(t, err, ErrUnsupportedType)
+}
+
+func TestMigrateCharacterImages(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+
+	original := testJPEG(t, 40, 30)
+	legacy := models.Char{BamortBase: models.BamortBase{Name: "Altbild"}, UserID: 256, Typ: "Krieger", Rasse: "Mensch", Grad: 1, Image: DataURI(original, "image/jpeg")}
+	broken := models.Char{BamortBase: models.BamortBase{Name: "Kaputt"}, UserID: 256, Typ: "Krieger", Rasse: "Mensch", Grad: 1, Image: "data:image/png;base64,###"}
+	require.NoError(t, database.DB.Create(&legacy).Error)
+	require.NoError(t, database.DB.Create(&broken).Error)
+
+	// Die Testdatenbank enthält bereits Charaktere mit eingebetteten Bildern
+	var embedded int64
+	require.NoError(t, database.DB.Model(&models.Char{}).Where("image LIKE ?", "data:%").Count(&embedded).Error)
+
+	migrated, err := MigrateCharacterImages(database.DB)
+	require.NoError(t, err)
+	assert.EqualValues(t, embedded-1, migrated)
+
+	var reloaded models.Char
+	require.NoError(t, database.DB.First(&reloaded, legacy.ID).Error)
+	file, err := LoadPortrait(legacy.ID, false)
+	require.NoError(t, err)
+	assert.Equal(t, PortraitURL(legacy.ID, file.ID), reloaded.Image)
+	assert.Equal(t, original, file.Data)
+	assert.Equal(t, DataURI(original, "image/jpeg"), PortraitDataURI(&reloaded))
+
+	// Nicht lesbare Bilder bleiben erhalten
+	var unchanged models.Char
+	require.NoError(t, database.DB.First(&unchanged, broken.ID).Error)
+	assert.Equal(t, "data:image/png;base64,###", unchanged.Image)
+	assert.Equal(t, unchanged.Image, PortraitDataURI(&unchanged))
+}
@@ -46,6 +46,26 @@ func MigrateStructure(db ...*gorm.DB) error {
 	if err != nil {
 		return err
 	}
+	err = encounterMigrateStructure(targetDB)
+	if err != nil {
+		return err
+	}
+	err = schedulerMigrateStructure(targetDB)
+	if err != nil {
+		return err
+	}
+	err = calendarMigrateStructure(targetDB)
+	if err != nil {
+		return err
+	}
+	err = campaignMigrateStructure(targetDB)
+	if err != nil {
+		return err
+	}
+	err = mediaMigrateStructure(targetDB)
+	if err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -87,6 +107,9 @@ func gsMasterMigrateStructure(db ...*gorm.DB) error {
 		&Transportation{},
 		&Believe{},
 		&MiscLookup{},
+		&MasterDataChange{},
+		&MasterDataProposal{},
+		&MasterDataProposalComment{},
 	)
 	if err != nil {
 		return err
@@ -115,10 +138,19 @@ func characterMigrateStructure(db ...*gorm.DB) error {
 		&Vermoegen{},
 		&CharacterCreationSession{},
 		&CharShare{},
+		&DiceRoll{},
+		&CharTemplate{},
+		&CharNote{},
+		&AdvancementPlan{},
+		&DowntimeEntry{},
 	)
 	if err != nil {
 		return err
 	}
+	err = characterSearchIndexes(targetDB)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -196,6 +228,99 @@ func learningMigrateStructure(db ...*gorm.DB) error {
 	return nil
 }
 
+func encounterMigrateStructure(db ...*gorm.DB) error {
+	// Use provided DB or default to database.DB
+	var targetDB *gorm.DB
+	if len(db) > 0 && db[0] != nil {
+		targetDB = db[0]
+	} else {
+		targetDB = database.DB
+	}
+
+	err := targetDB.AutoMigrate(
+		&Encounter{},
+		&EncounterParticipant{},
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func schedulerMigrateStructure(db ...*gorm.DB) error {
+	// Use provided DB or default to database.DB
+	var targetDB *gorm.DB
+	if len(db) > 0 && db[0] != nil {
+		targetDB = db[0]
+	} else {
+		targetDB = database.DB
+	}
+
+	err := targetDB.AutoMigrate(
+		&SchedulerJobRun{},
+		&SchedulerJobState{},
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func calendarMigrateStructure(db ...*gorm.DB) error {
+	// Use provided DB or default to database.DB
+	var targetDB *gorm.DB
+	if len(db) > 0 && db[0] != nil {
+		targetDB = db[0]
+	} else {
+		targetDB = database.DB
+	}
+
+	err := targetDB.AutoMigrate(
+		&GameCalendar{},
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func campaignMigrateStructure(db ...*gorm.DB) error {
+	// Use provided DB or default to database.DB
+	var targetDB *gorm.DB
+	if len(db) > 0 && db[0] != nil {
+		targetDB = db[0]
+	} else {
+		targetDB = database.DB
+	}
+
+	err := targetDB.AutoMigrate(
+		&Campaign{},
+		&SourceSelection{},
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func mediaMigrateStructure(db ...*gorm.DB) error {
+	// Use provided DB or default to database.DB
+	var targetDB *gorm.DB
+	if len(db) > 0 && db[0] != nil {
+		targetDB = db[0]
+	} else {
+		targetDB = database.DB
+	}
+
+	err := targetDB.AutoMigrate(
+		&MediaFile{},
+	)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
 func MigrateDataIfNeeded(db ...*gorm.DB) error {
 	// Use provided DB or default to database.DB
 	var targetDB *gorm.DB
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// Status eines gespeicherten Steigerungsplans
+const (
+	AdvancementPlanDraft    = "draft"    // gespeichert, noch nicht ausgeführt
+	AdvancementPlanExecuted = "executed" // alle Schritte wurden übernommen
+)
+
+// AdvancementPlanStep ist ein Schritt eines Steigerungsplans (Lernen oder Verbessern)
+type AdvancementPlanStep struct {
+	Action      string `json:"action"`                 // learn oder improve
+	Type        string `json:"type"`                   // skill, weapon oder spell
+	Name        string `json:"name"`                   // Fertigkeit, Waffenfertigkeit oder Zauber
+	TargetLevel int    `json:"target_level,omitempty"` // Zielwert, Standard: ein Wert mehr bzw. 1 beim Lernen
+	UsePP       int    `json:"use_pp,omitempty"`       // einzusetzende Praxispunkte der Fertigkeit
+	UseGold     int    `json:"use_gold,omitempty"`     // Gold, das EP ersetzt (10 GS je EP, höchstens die Hälfte)
+	Reward      string `json:"reward,omitempty"`       // Lernen als Belohnung: noGold, halveep, halveepnoGold, spruchrolle
+}
+
+// AdvancementPlan ist eine gespeicherte Folge von Lern- und Verbesserungsschritten für einen Charakter
+type AdvancementPlan struct {
+	ID          uint                  `gorm:"primaryKey" json:"id"`
+	CharacterID uint                  `gorm:"index;not null" json:"character_id"`
+	UserID      uint                  `gorm:"index" json:"user_id"`
+	Name        string                `json:"name"`
+	Steps       []AdvancementPlanStep `gorm:"type:text;serializer:json" json:"steps"`
+	Status      string                `gorm:"size:20;default:draft" json:"status"`
+	ExecutedAt  *time.Time            `json:"executed_at,omitempty"`
+	CreatedAt   time.Time             `json:"created_at"`
+	UpdatedAt   time.Time             `json:"updated_at"`
+}
+
+func (object *AdvancementPlan) TableName() string {
+	dbPrefix := "char"
+	return dbPrefix + "_" + "advancement_plans"
+}
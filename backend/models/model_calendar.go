@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+)
+
+// GameCalendar ist der Spielkalender einer Runde
+// Das aktuelle Datum wird als fortlaufende Tagesnummer gespeichert; Monate, Wochentage und
+// Jahreszählung kommen aus der Kalenderdefinition des Spielsystems.
+type GameCalendar struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	Name       string    `json:"name"`
+	GameSystem string    `gorm:"size:20;index;default:M5" json:"game_system"` // Code des Spielsystems
+	UserID     uint      `gorm:"index;not null" json:"user_id"`               // Spielleiter, der den Kalender führt
+	CurrentDay int       `json:"current_day"`                                 // Tagesnummer des aktuellen Spieltags
+	JoinCode   string    `gorm:"size:32" json:"-"`                            // Beitrittscode, nur für den Spielleiter sichtbar
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+func (object *GameCalendar) TableName() string {
+	dbPrefix := "cal"
+	return dbPrefix + "_" + "calendars"
+}
@@ -0,0 +1,45 @@
+package models
+
+import (
+	"bamort/database"
+	"time"
+)
+
+// Campaign ist eine Spielrunde mit Spielleiter und den Charakteren der Spieler
+// Spieler gehören über ihre Charaktere (Char.CampaignID) zur Kampagne. Beitreten kann nur,
+// wer den Beitrittscode vom Spielleiter erhalten hat.
+type Campaign struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	Name        string    `json:"name"`
+	GameSystem  string    `gorm:"size:20;index;default:M5" json:"game_system"` // Code des Spielsystems
+	Description string    `gorm:"type:text" json:"description,omitempty"`
+	UserID      uint      `gorm:"index;not null" json:"user_id"` // Spielleiter
+	JoinCode    string    `gorm:"size:32" json:"-"`              // Beitrittscode, nur für den Spielleiter sichtbar
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (object *Campaign) TableName() string {
+	dbPrefix := "camp"
+	return dbPrefix + "_" + "campaigns"
+}
+
+// IsMember prüft, ob der Benutzer die Kampagne leitet oder einen Charakter darin spielt
+func (object *Campaign) IsMember(userID uint) bool {
+	if object.UserID == userID {
+		return true
+	}
+	var count int64
+	database.DB.Model(&Char{}).Where("campaign_id = ? AND user_id = ?", object.ID, userID).Count(&count)
+	return count > 0
+}
+
+// CampaignIDsForUser liefert die Kampagnen, die der Benutzer leitet oder in denen seine Charaktere spielen
+func CampaignIDsForUser(userID uint) ([]uint, error) {
+	var ids []uint
+	err := database.DB.Model(&Campaign{}).
+		Where("user_id = ? OR id IN (?)", userID,
+			database.DB.Model(&Char{}).Select("campaign_id").Where("user_id = ? AND campaign_id > 0", userID)).
+		Order("id ASC").Pluck("id", &ids).Error
+	return ids, err
+}
@@ -2,6 +2,7 @@ package models
 
 import (
 	"bamort/database"
+	"slices"
 	"strings"
 )
 
@@ -26,6 +27,37 @@ type SkAngeboreneFertigkeit struct {
 	SkFertigkeit
 }
 
+// SpecialAbilitySkills sind die Fertigkeiten aus der Tabelle der besonderen Fähigkeiten (W100).
+// Sie werden bei der Erschaffung ausgewürfelt.
+var SpecialAbilitySkills = []string{
+	"Sehen",
+	"Hören",
+	"Riechen",
+	"Sechster Sinn",
+	"Nachtsicht",
+	"Gute Reflexe",
+	"Richtungssinn",
+	"Robustheit",
+	"Schmerzunempfindlichkeit",
+	"Trinken",
+	"Wachgabe",
+	"Wahrnehmung",
+	"Einprägen",
+	"Berserkergang",
+}
+
+// LearnableSpecialAbilities sind besondere Fähigkeiten, die auch als gewöhnliche Fertigkeit
+// gelernt und gesteigert werden können; die besondere Fähigkeit verbessert nur den Startwert.
+var LearnableSpecialAbilities = []string{
+	"Trinken",
+	"Wahrnehmung",
+}
+
+// IsInnateSkill prüft, ob eine Fertigkeit angeboren und damit nicht steigerbar ist
+func IsInnateSkill(name string) bool {
+	return slices.Contains(SpecialAbilitySkills, name) && !slices.Contains(LearnableSpecialAbilities, name)
+}
+
 type SkZauber struct {
 	BamortCharTrait
 	Beschreibung string `json:"beschreibung"`
@@ -77,14 +77,14 @@ type Vermoegen struct {
 type Char struct {
 	BamortBase
 	GameSystem   string    `gorm:"column:game_system;index;default:midgard" json:"game_system"`
-	GameSystemId uint      `json:"game_system_id,omitempty"`
+	GameSystemId uint      `gorm:"index" json:"game_system_id,omitempty"`
 	UserID       uint      `gorm:"index;not null;default:1" json:"user_id"`
 	User         user.User `gorm:"foreignKey:UserID;references:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"user"`
-	Rasse        string    `json:"rasse"`
-	Typ          string    `json:"typ"`
+	Rasse        string    `gorm:"index" json:"rasse"`
+	Typ          string    `gorm:"index" json:"typ"`
 	Alter        int       `json:"alter"`
 	Anrede       string    `json:"anrede"`
-	Grad         int       `json:"grad"`
+	Grad         int       `gorm:"index" json:"grad"`
 	Gender       string    `json:"gender"`
 	SocialClass  string    `json:"social_class"`
 	Groesse      int       `json:"groesse"`
@@ -92,7 +92,10 @@ type Char struct {
 	Herkunft     string    `json:"origin"`
 	Glaube       string    `json:"glaube"`
 	Hand         string    `json:"hand"`
-	Public       bool      `json:"public"`
+	Public       bool      `gorm:"index" json:"public"`
+	CalendarID   uint      `gorm:"index" json:"calendar_id,omitempty"` // Spielkalender, in dem der Charakter altert
+	BirthDay     *int      `json:"birth_day,omitempty"`                // Geburtstag als Tagesnummer im Spielkalender
+	CampaignID   uint      `gorm:"index" json:"campaign_id,omitempty"` // Kampagne, in der der Charakter spielt
 	// Static derived values (can increase with grade)
 	ResistenzKoerper   int                  `json:"resistenz_koerper"`
 	ResistenzGeist     int                  `json:"resistenz_geist"`
@@ -125,10 +128,17 @@ type CharList struct {
 	Rasse        string `json:"rasse"`
 	Typ          string `json:"typ"`
 	Grad         int    `json:"grad"`
+	Gender       string `json:"gender"`
+	SocialClass  string `json:"social_class"`
+	Herkunft     string `json:"origin"`
+	Glaube       string `json:"glaube"`
 	Owner        string `json:"owner"`
 	Public       bool   `json:"public"`
 }
 
+// charListColumns sind die Spalten für Charakterlisten (ohne Bild und Unterelemente)
+const charListColumns = "char_chars.id, char_chars.name, char_chars.user_id, char_chars.rasse, char_chars.typ, char_chars.grad, char_chars.gender, char_chars.social_class, char_chars.herkunft, char_chars.glaube, char_chars.public, char_chars.game_system, char_chars.game_system_id, COALESCE(NULLIF(users.display_name, ''), users.username) as owner"
+
 type FeChar struct {
 	Char
 	Git               int                       `json:"git"` // GiftToleranz
@@ -265,7 +275,7 @@ func FindSharedCharList(userID uint) ([]CharList, error) {
 	var chars []CharList
 	gs := GetGameSystem(0, "midgard")
 	err := database.DB.Table("char_chars").
-		Select("char_chars.id, char_chars.name, char_chars.user_id, char_chars.rasse, char_chars.typ, char_chars.grad, char_chars.public, char_chars.game_system, char_chars.game_system_id, COALESCE(NULLIF(users.display_name, ''), users.username) as owner").
+		Select(charListColumns).
 		Joins("LEFT JOIN users ON char_chars.user_id = users.user_id").
 		Joins("INNER JOIN char_shares ON char_shares.character_id = char_chars.id").
 		Where("char_shares.user_id = ? AND (char_chars.game_system = ? OR char_chars.game_system_id = ?)", userID, gs.Name, gs.ID).
@@ -280,7 +290,7 @@ func FindPublicCharList() ([]CharList, error) {
 	var chars []CharList
 	gs := GetGameSystem(0, "midgard")
 	err := database.DB.Table("char_chars").
-		Select("char_chars.id, char_chars.name, char_chars.user_id, char_chars.rasse, char_chars.typ, char_chars.grad, char_chars.public, char_chars.game_system, char_chars.game_system_id, COALESCE(NULLIF(users.display_name, ''), users.username) as owner").
+		Select(charListColumns).
 		Joins("LEFT JOIN users ON char_chars.user_id = users.user_id").
 		Where("char_chars.public = ? AND (char_chars.game_system = ? OR char_chars.game_system_id = ?)", true, gs.Name, gs.ID).
 		Find(&chars).Error
@@ -295,7 +305,7 @@ func FindCharListByUserID(userID uint) ([]CharList, error) {
 	var chars []CharList
 	gs := GetGameSystem(0, "midgard")
 	err := database.DB.Table("char_chars").
-		Select("char_chars.id, char_chars.name, char_chars.user_id, char_chars.rasse, char_chars.typ, char_chars.grad, char_chars.public, char_chars.game_system, char_chars.game_system_id, COALESCE(NULLIF(users.display_name, ''), users.username) as owner").
+		Select(charListColumns).
 		Joins("LEFT JOIN users ON char_chars.user_id = users.user_id").
 		Where("char_chars.user_id = ? AND (char_chars.game_system = ? OR char_chars.game_system_id = ?)", userID, gs.Name, gs.ID).
 		Find(&chars).Error
@@ -21,15 +21,18 @@ type CharacterCreationSession struct {
 	Herkunft      string                  `json:"herkunft"`
 	Stand         string                  `json:"stand"`
 	Glaube        string                  `json:"glaube"`
+	CampaignID    uint                    `json:"campaign_id,omitempty"` // Kampagne des neuen Charakters, bestimmt Quellenauswahl und Hausregeln
 	Attributes    AttributesData          `json:"attributes" gorm:"type:text;serializer:json"`
 	DerivedValues DerivedValuesData       `json:"derived_values" gorm:"type:text;serializer:json"`
 	Skills        CharacterCreationSkills `json:"skills" gorm:"type:text;serializer:json"`
 	Spells        CharacterCreationSpells `json:"spells" gorm:"type:text;serializer:json"`
 	SkillPoints   SkillPointsData         `json:"skill_points" gorm:"type:text;serializer:json"`
-	CreatedAt     time.Time               `json:"created_at"`
-	UpdatedAt     time.Time               `json:"updated_at"`
-	ExpiresAt     time.Time               `json:"expires_at"`
-	CurrentStep   int                     `json:"current_step"` // 1=Basic, 2=Attributes, 3=Derived, 4=Skills
+	// Besondere Fähigkeiten (W100), werden beim Abschluss als angeborene Fertigkeiten gespeichert
+	SpecialAbilities CharacterCreationAbilities `json:"special_abilities" gorm:"type:text;serializer:json"`
+	CreatedAt        time.Time                  `json:"created_at"`
+	UpdatedAt        time.Time                  `json:"updated_at"`
+	ExpiresAt        time.Time                  `json:"expires_at"`
+	CurrentStep      int                        `json:"current_step"` // 1=Basic, 2=Attributes, 3=Derived, 4=Skills
 }
 
 // AttributesData speichert die Grundwerte
@@ -84,9 +87,20 @@ type CharacterCreationSpell struct {
 	Cost int    `json:"cost"`
 }
 
+// CharacterCreationAbility repräsentiert eine gewürfelte oder frei gewählte besondere Fähigkeit
+// Ein Wurf von 100 wird als "Freie Wahl" gespeichert, bis der Spieler eine Fähigkeit ausgewählt hat.
+type CharacterCreationAbility struct {
+	Name     string `json:"name"`
+	Modifier int    `json:"modifier"`
+	Roll     int    `json:"roll,omitempty"`    // W100-Wurf
+	RollID   uint   `json:"roll_id,omitempty"` // gespeicherter Serverwurf
+	Chosen   bool   `json:"chosen,omitempty"`  // durch Wurf 100 frei gewählt
+}
+
 // Slice types for GORM JSON handling
 type CharacterCreationSkills []CharacterCreationSkill
 type CharacterCreationSpells []CharacterCreationSpell
+type CharacterCreationAbilities []CharacterCreationAbility
 
 // ClassCategoryLearningPoints stores the learning points distribution for a character class
 type ClassCategoryLearningPoints struct {
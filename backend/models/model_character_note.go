@@ -0,0 +1,53 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Arten von Charakternotizen
+const (
+	NoteTypeBackstory = "backstory" // Hintergrundgeschichte
+	NoteTypeContact   = "contact"   // NSC-Kontakt, Feind, Schuldner usw.
+	NoteTypeQuest     = "quest"     // Aufträge und Abenteuernotizen
+	NoteTypeMisc      = "misc"      // Sonstiges
+)
+
+// Sichtbarkeit von Charakternotizen
+const (
+	NoteVisibilityPrivate = "private" // nur der Besitzer des Charakters
+	NoteVisibilityGM      = "gm"      // Besitzer und Spielleiter
+	NoteVisibilityPublic  = "public"  // alle, die den Charakter sehen dürfen
+)
+
+var noteTypes = []string{NoteTypeBackstory, NoteTypeContact, NoteTypeQuest, NoteTypeMisc}
+var noteVisibilities = []string{NoteVisibilityPrivate, NoteVisibilityGM, NoteVisibilityPublic}
+
+// CharNote ist eine erzählerische Notiz zu einem Charakter (Text in Markdown)
+type CharNote struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CharacterID uint      `gorm:"index;not null" json:"character_id"`
+	UserID      uint      `gorm:"index" json:"user_id"` // Verfasser
+	Type        string    `gorm:"size:20;index;default:misc" json:"type"`
+	Title       string    `json:"title"`
+	Text        string    `gorm:"type:text" json:"text"`
+	Visibility  string    `gorm:"size:20;default:private" json:"visibility"`
+	GameDay     *int      `json:"game_day,omitempty"` // Spieltag als Tagesnummer im Spielkalender
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (object *CharNote) TableName() string {
+	dbPrefix := "char"
+	return dbPrefix + "_" + "notes"
+}
+
+// ValidNoteType prüft die Art einer Notiz
+func ValidNoteType(noteType string) bool {
+	return slices.Contains(noteTypes, noteType)
+}
+
+// ValidNoteVisibility prüft die Sichtbarkeit einer Notiz
+func ValidNoteVisibility(visibility string) bool {
+	return slices.Contains(noteVisibilities, visibility)
+}
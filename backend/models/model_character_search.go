@@ -0,0 +1,162 @@
+package models
+
+import (
+	"bamort/database"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// Sichtbarkeit in der Charaktersuche
+const (
+	CharScopeAll    = "all"    // eigene, öffentliche und geteilte Charaktere
+	CharScopeOwn    = "own"    // nur eigene Charaktere
+	CharScopeOthers = "others" // öffentliche und geteilte Charaktere anderer Benutzer
+)
+
+// charSearchSortColumns bildet die erlaubten Sortierschlüssel auf Spalten ab (Texte ohne Groß-/Kleinschreibung)
+var charSearchSortColumns = map[string]string{
+	"id":    "char_chars.id",
+	"name":  "LOWER(char_chars.name)",
+	"rasse": "char_chars.rasse",
+	"typ":   "char_chars.typ",
+	"grad":  "char_chars.grad",
+	"owner": "LOWER(owner)",
+}
+
+// CharSearchFilter beschreibt eine Suche in den sichtbaren Charakteren eines Benutzers
+type CharSearchFilter struct {
+	Query      string // Teilstring im Namen, ohne Beachtung der Groß-/Kleinschreibung
+	Rasse      string
+	Typ        string
+	MinGrad    int
+	MaxGrad    int
+	GameSystem *GameSystem
+	OwnerID    uint
+	CampaignID uint
+	Scope      string
+	Sort       string
+	Desc       bool
+	Limit      int
+	Offset     int
+}
+
+// CharSearchResult enthält eine Seite der Suchergebnisse und die Gesamtzahlen
+type CharSearchResult struct {
+	Items      []CharList `json:"items"`
+	Total      int64      `json:"total"`
+	OwnCount   int64      `json:"own_count"`
+	OtherCount int64      `json:"other_count"`
+	Limit      int        `json:"limit"`
+	Offset     int        `json:"offset"`
+	NextOffset *int       `json:"next_offset,omitempty"`
+}
+
+// ValidCharSearchSort prüft, ob nach dem Schlüssel sortiert werden kann
+func ValidCharSearchSort(sort string) bool {
+	_, ok := charSearchSortColumns[sort]
+	return ok
+}
+
+// charSearchQuery baut die gefilterte Abfrage ohne Sortierung und Seitenbegrenzung
+func charSearchQuery(userID uint, filter CharSearchFilter) *gorm.DB {
+	query := database.DB.Table("char_chars").
+		Joins("LEFT JOIN users ON char_chars.user_id = users.user_id")
+
+	sharedWithUser := database.DB.Table("char_shares").Select("character_id").Where("user_id = ?", userID)
+	switch filter.Scope {
+	case CharScopeOwn:
+		query = query.Where("char_chars.user_id = ?", userID)
+	case CharScopeOthers:
+		query = query.Where("char_chars.user_id <> ? AND (char_chars.public = ? OR char_chars.id IN (?))", userID, true, sharedWithUser)
+	default:
+		query = query.Where("char_chars.user_id = ? OR char_chars.public = ? OR char_chars.id IN (?)", userID, true, sharedWithUser)
+	}
+
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		query = query.Where("LOWER(char_chars.name) LIKE ?", "%"+strings.ToLower(q)+"%")
+	}
+	if filter.Rasse != "" {
+		query = query.Where("char_chars.rasse = ?", filter.Rasse)
+	}
+	if filter.Typ != "" {
+		query = query.Where("char_chars.typ = ?", filter.Typ)
+	}
+	if filter.MinGrad > 0 {
+		query = query.Where("char_chars.grad >= ?", filter.MinGrad)
+	}
+	if filter.MaxGrad > 0 {
+		query = query.Where("char_chars.grad <= ?", filter.MaxGrad)
+	}
+	if filter.GameSystem != nil {
+		query = query.Where("(char_chars.game_system = ? OR char_chars.game_system_id = ?)", filter.GameSystem.Name, filter.GameSystem.ID)
+	}
+	if filter.OwnerID != 0 {
+		query = query.Where("char_chars.user_id = ?", filter.OwnerID)
+	}
+	if filter.CampaignID != 0 {
+		query = query.Where("char_chars.campaign_id = ?", filter.CampaignID)
+	}
+	return query
+}
+
+// SearchCharList sucht in allen Charakteren, die der Benutzer sehen darf
+// (eigene, öffentliche und mit ihm geteilte), und liefert eine Seite mit Gesamtzahlen.
+func SearchCharList(userID uint, filter CharSearchFilter) (*CharSearchResult, error) {
+	result := &CharSearchResult{Items: []CharList{}, Limit: filter.Limit, Offset: filter.Offset}
+
+	var counts struct {
+		Total int64
+		Own   int64
+	}
+	err := charSearchQuery(userID, filter).
+		Select("COUNT(*) AS total, COALESCE(SUM(CASE WHEN char_chars.user_id = ? THEN 1 ELSE 0 END), 0) AS own", userID).
+		Scan(&counts).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to count characters: %w", err)
+	}
+	result.Total = counts.Total
+	result.OwnCount = counts.Own
+	result.OtherCount = counts.Total - counts.Own
+
+	column, ok := charSearchSortColumns[filter.Sort]
+	if !ok {
+		column = charSearchSortColumns["name"]
+	}
+	direction := "ASC"
+	if filter.Desc {
+		direction = "DESC"
+	}
+
+	err = charSearchQuery(userID, filter).
+		Select(charListColumns).
+		Order(column + " " + direction).
+		Order("char_chars.id ASC").
+		Limit(filter.Limit).
+		Offset(filter.Offset).
+		Find(&result.Items).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to search characters: %w", err)
+	}
+
+	if next := filter.Offset + len(result.Items); int64(next) < result.Total {
+		result.NextOffset = &next
+	}
+	return result, nil
+}
+
+// characterSearchIndexes legt Indizes für die Namenssuche an
+// Der Name liegt in BamortBase und kann daher nicht per Tag nur für Charaktere indiziert werden;
+// MySQL/MariaDB benötigen für Textspalten eine Präfixlänge.
+func characterSearchIndexes(db *gorm.DB) error {
+	const indexName = "idx_char_chars_name"
+	if db.Migrator().HasIndex(&Char{}, indexName) {
+		return nil
+	}
+	column := "name"
+	if db.Dialector.Name() == "mysql" {
+		column = "name(100)"
+	}
+	return db.Exec(fmt.Sprintf("CREATE INDEX %s ON char_chars (%s)", indexName, column)).Error
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+)
+
+// CharTemplate ist eine wiederverwendbare Vorlage, aus der neue Charaktere erzeugt werden
+// Character enthält einen vollständigen Schnappschuss des Quellcharakters.
+type CharTemplate struct {
+	ID           uint      `gorm:"primaryKey" json:"id"`
+	Name         string    `gorm:"index" json:"name"`
+	Description  string    `json:"description"`
+	UserID       uint      `gorm:"index;not null" json:"user_id"`
+	Public       bool      `json:"public"`
+	GameSystem   string    `gorm:"column:game_system;index" json:"game_system"`
+	GameSystemId uint      `json:"game_system_id,omitempty"`
+	SourceCharID uint      `json:"source_char_id,omitempty"` // Charakter, aus dem die Vorlage erstellt wurde
+	Character    Char      `gorm:"column:snapshot;type:text;serializer:json" json:"character"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (object *CharTemplate) TableName() string {
+	dbPrefix := "char"
+	return dbPrefix + "_" + "templates"
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+)
+
+// DiceRoll speichert einen serverseitig ausgeführten Würfelwurf
+// Über den gespeicherten Seed lässt sich jeder Wurf nachträglich reproduzieren.
+type DiceRoll struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	CharacterID uint       `gorm:"index" json:"character_id,omitempty"` // 0 bei Würfen während der Charaktererstellung
+	SessionID   string     `gorm:"index" json:"session_id,omitempty"`   // Charaktererstellungs-Session
+	UserID      uint       `gorm:"index" json:"user_id"`                // wer hat gewürfelt
+	Expression  string     `gorm:"not null" json:"expression"`          // z.B. "2d6+3"
+	Purpose     string     `json:"purpose,omitempty"`                   // z.B. "pa", "lp_max", "Klettern"
+	Rolls       []int      `gorm:"type:text;serializer:json" json:"rolls"`
+	Modifier    int        `json:"modifier"`
+	Total       int        `json:"total"`
+	Seed        int64      `json:"seed"`
+	Timestamp   time.Time  `gorm:"autoCreateTime" json:"timestamp"`
+	ConsumedAt  *time.Time `json:"consumed_at,omitempty"` // Übernahme in die Charaktererstellung; jeder Wurf zählt nur einmal
+	// CreationSlot ist "Session:Feld" bei Würfen für ein Feld der Charaktererstellung, sonst NULL
+	// Der eindeutige Index verhindert, dass gleichzeitige Anfragen für ein Feld mehrfach würfeln.
+	CreationSlot *string `gorm:"uniqueIndex;size:100" json:"-"`
+}
+
+func (object *DiceRoll) TableName() string {
+	dbPrefix := "char"
+	return dbPrefix + "_" + "dice_rolls"
+}
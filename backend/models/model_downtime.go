@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+)
+
+// Arten von Einträgen im Ausfallzeit-Konto
+const (
+	DowntimeGrant      = "grant"      // freie Zeit zwischen den Abenteuern
+	DowntimeTraining   = "training"   // Lernen und Verbessern
+	DowntimeCorrection = "correction" // manuelle Korrektur
+)
+
+// DowntimeEntry ist eine Buchung im Ausfallzeit-Konto eines Charakters (in Tagen)
+// Gutschriften sind positiv, Lernzeiten negativ; der Kontostand ist die Summe aller Buchungen.
+type DowntimeEntry struct {
+	ID          uint      `gorm:"primaryKey" json:"id"`
+	CharacterID uint      `gorm:"index;not null" json:"character_id"`
+	UserID      uint      `gorm:"index" json:"user_id"`
+	Kind        string    `gorm:"size:20;index" json:"kind"`
+	Days        int       `json:"days"`
+	Activity    string    `json:"activity,omitempty"`              // gelernte Fertigkeit oder gelernter Zauber
+	Reason      string    `gorm:"size:50" json:"reason,omitempty"` // Audit-Grund der Lernaktion
+	Notes       string    `json:"notes,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (object *DowntimeEntry) TableName() string {
+	dbPrefix := "char"
+	return dbPrefix + "_" + "downtime"
+}
@@ -0,0 +1,76 @@
+package models
+
+import (
+	"time"
+)
+
+// Status einer Begegnung
+const (
+	EncounterStatusPreparing = "preparing"
+	EncounterStatusActive    = "active"
+	EncounterStatusFinished  = "finished"
+)
+
+// Encounter ist eine Kampfbegegnung mit Initiative-Reihenfolge und Runden
+type Encounter struct {
+	ID           uint                   `gorm:"primaryKey" json:"id"`
+	Name         string                 `json:"name"`
+	UserID       uint                   `gorm:"index;not null" json:"user_id"`                 // Spielleiter, der die Begegnung angelegt hat
+	GameMasters  []uint                 `gorm:"type:text;serializer:json" json:"game_masters"` // weitere Spielleiter mit Schreibrecht
+	Status       string                 `gorm:"default:preparing" json:"status"`               // preparing, active, finished
+	Round        int                    `json:"round"`                                         // aktuelle Runde, 0 vor dem Start
+	Turn         int                    `json:"turn"`                                          // Index des Teilnehmers, der am Zug ist
+	Participants []EncounterParticipant `gorm:"foreignKey:EncounterID;constraint:OnDelete:CASCADE" json:"participants"`
+	CreatedAt    time.Time              `json:"created_at"`
+	UpdatedAt    time.Time              `json:"updated_at"`
+	FinishedAt   *time.Time             `json:"finished_at,omitempty"`
+}
+
+// EncounterParticipant ist ein Spielercharakter oder ein Nichtspielercharakter in einer Begegnung
+type EncounterParticipant struct {
+	ID          uint                 `gorm:"primaryKey" json:"id"`
+	EncounterID uint                 `gorm:"index;not null" json:"encounter_id"`
+	CharacterID uint                 `gorm:"index" json:"character_id,omitempty"` // 0 bei NSC
+	Name        string               `json:"name"`
+	Initiative  int                  `json:"initiative"`
+	Position    int                  `json:"position"` // Reihenfolge nach Initiative
+	LpMax       int                  `json:"lp_max"`
+	Lp          int                  `json:"lp"`
+	LpStart     int                  `json:"lp_start"` // LP beim Hinzufügen, für das Zurückschreiben
+	ApMax       int                  `json:"ap_max"`
+	Ap          int                  `json:"ap"`
+	ApStart     int                  `json:"ap_start"`
+	Abwehr      int                  `json:"abwehr"`
+	Attacks     []EncounterAttack    `gorm:"type:text;serializer:json" json:"attacks"`
+	Conditions  []EncounterCondition `gorm:"type:text;serializer:json" json:"conditions"`
+	Defeated    bool                 `json:"defeated"`
+}
+
+// EncounterAttack ist ein Angriff eines Teilnehmers
+type EncounterAttack struct {
+	Name   string `json:"name"`
+	Value  int    `json:"value"`  // Angriffswert
+	Damage string `json:"damage"` // Schadensausdruck, z.B. "1W6+1"
+}
+
+// EncounterCondition ist ein Zustand mit optionaler Dauer in Runden
+type EncounterCondition struct {
+	Name   string `json:"name"`
+	Rounds int    `json:"rounds"` // verbleibende Runden, 0 = bis zur Aufhebung
+	Note   string `json:"note,omitempty"`
+}
+
+func (object *Encounter) TableName() string {
+	dbPrefix := "enc"
+	return dbPrefix + "_" + "encounters"
+}
+
+func (object *EncounterParticipant) TableName() string {
+	dbPrefix := "enc"
+	return dbPrefix + "_" + "participants"
+}
+
+// IsNPC gibt an, ob der Teilnehmer kein gespeicherter Charakter ist
+func (object *EncounterParticipant) IsNPC() bool {
+	return object.CharacterID == 0
+}
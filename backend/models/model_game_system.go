@@ -1,6 +1,10 @@
 package models
 
-import "bamort/database"
+import (
+	"bamort/database"
+	"bamort/logger"
+	"encoding/json"
+)
 
 type GameSystem struct {
 	ID          uint   `gorm:"primaryKey"`
@@ -8,8 +12,10 @@ type GameSystem struct {
 	Name        string `gorm:"size:255;not null"`
 	Description string `gorm:"type:text"`
 	IsActive    bool   `gorm:"default:true;not null"`
-	CreatedAt   int64  `gorm:"autoCreateTime"`
-	ModifiedAt  int64  `gorm:"autoUpdateTime"`
+	// Rules enthält die Regelsätze des Spielsystems (z.B. "vitals", "calendar") als JSON
+	Rules      map[string]json.RawMessage `gorm:"type:text;serializer:json"`
+	CreatedAt  int64                      `gorm:"autoCreateTime"`
+	ModifiedAt int64                      `gorm:"autoUpdateTime"`
 }
 
 // TableName sets the table name for SchemaVersion
@@ -41,3 +47,29 @@ func (gs *GameSystem) FirstByID(id uint) error {
 	}
 	return database.DB.First(gs, "id = ?", id).Error
 }
+
+// GameSystemRules liefert den Regelsatz key eines Spielsystems
+// Im Spielsystem hinterlegte Werte überschreiben die Vorgaben feldweise; fehlt der Regelsatz, gelten die Vorgaben.
+func GameSystemRules[T any](gs *GameSystem, key string, defaults T) T {
+	if gs == nil {
+		return defaults
+	}
+	raw, ok := gs.Rules[key]
+	if !ok {
+		return defaults
+	}
+	// Vorgaben über JSON kopieren, damit Slices der Vorgaben nicht überschrieben werden
+	var rules T
+	data, err := json.Marshal(defaults)
+	if err == nil {
+		err = json.Unmarshal(data, &rules)
+	}
+	if err == nil {
+		err = json.Unmarshal(raw, &rules)
+	}
+	if err != nil {
+		logger.Error("Regelsatz %s des Spielsystems %s ist ungültig: %s", key, gs.Code, err.Error())
+		return defaults
+	}
+	return rules
+}
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"encoding/json"
 	"testing"
 
 	"bamort/database"
@@ -13,6 +14,7 @@ func TestGameSystem_Methods(t *testing.T) {
 	// Initialize test DB and migrations
 	database.SetupTestDB(true)
 	defer database.ResetTestDB()
+	require.NoError(t, MigrateStructure())
 
 	// Ensure default exists (use FirstOrCreate to avoid unique constraint)
 	defaultGS := GameSystem{}
@@ -73,3 +75,25 @@ func TestGameSystem_Methods(t *testing.T) {
 		assert.Equal(t, "M5", found.Code)
 	})
 }
+
+func TestGameSystemRules(t *testing.T) {
+	type testRules struct {
+		Divisor int      `json:"divisor"`
+		Names   []string `json:"names"`
+	}
+	defaults := testRules{Divisor: 2, Names: []string{"St", "Gs"}}
+
+	assert.Equal(t, defaults, GameSystemRules(nil, "test", defaults))
+	assert.Equal(t, defaults, GameSystemRules(&GameSystem{Code: "M5"}, "test", defaults))
+
+	gs := &GameSystem{Code: "X", Rules: map[string]json.RawMessage{
+		"test": json.RawMessage(`{"names":["Ko"]}`),
+	}}
+	rules := GameSystemRules(gs, "test", defaults)
+	assert.Equal(t, 2, rules.Divisor, "nicht hinterlegte Felder behalten die Vorgabe")
+	assert.Equal(t, []string{"Ko"}, rules.Names)
+	assert.Equal(t, []string{"St", "Gs"}, defaults.Names, "die Vorgaben dürfen nicht verändert werden")
+
+	gs.Rules["test"] = json.RawMessage(`{"divisor":"zwei"}`)
+	assert.Equal(t, defaults, GameSystemRules(gs, "test", defaults))
+}
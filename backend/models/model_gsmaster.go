@@ -46,21 +46,23 @@ type LearnCost struct {
 }
 
 type Skill struct {
-	ID               uint   `gorm:"primaryKey" json:"id"`
-	GameSystem       string `gorm:"column:game_system;index" json:"game_system"`
-	GameSystemId     uint   `json:"game_system_id,omitempty"`
-	Name             string `gorm:"type:varchar(255);index" json:"name"`
-	Beschreibung     string `json:"beschreibung"`
-	Quelle           string `json:"quelle"`                           // Deprecated: Für Rückwärtskompatibilität
-	SourceID         uint   `gorm:"index" json:"source_id,omitempty"` // Verweis auf strukturierte Quelle
-	PageNumber       int    `json:"page_number,omitempty"`            // Seitenzahl im Quellenbuch
-	Initialwert      int    `gorm:"default:5" json:"initialwert"`
-	BasisWert        int    `gorm:"default:0" json:"basiswert"`
-	Bonuseigenschaft string `json:"bonuseigenschaft,omitempty"`
-	Improvable       bool   `json:"improvable"`
-	InnateSkill      bool   `json:"innateskill"`
-	Category         string `json:"category"`
-	Difficulty       string `json:"difficulty"`
+	ID               uint                `gorm:"primaryKey" json:"id"`
+	GameSystem       string              `gorm:"column:game_system;index" json:"game_system"`
+	GameSystemId     uint                `json:"game_system_id,omitempty"`
+	Name             string              `gorm:"type:varchar(255);index" json:"name"`
+	Beschreibung     string              `json:"beschreibung"`
+	Quelle           string              `json:"quelle"`                           // Deprecated: Für Rückwärtskompatibilität
+	SourceID         uint                `gorm:"index" json:"source_id,omitempty"` // Verweis auf strukturierte Quelle
+	PageNumber       int                 `json:"page_number,omitempty"`            // Seitenzahl im Quellenbuch
+	Initialwert      int                 `gorm:"default:5" json:"initialwert"`
+	BasisWert        int                 `gorm:"default:0" json:"basiswert"`
+	Bonuseigenschaft string              `json:"bonuseigenschaft,omitempty"`
+	Improvable       bool                `json:"improvable"`
+	InnateSkill      bool                `json:"innateskill"`
+	Category         string              `json:"category"`
+	Difficulty       string              `json:"difficulty"`
+	HomebrewScope                        // Geltungsbereich (global, Benutzer, Kampagne), siehe model_homebrew.go
+	ChangeTracking   `gorm:"-" json:"-"` // Änderungshistorie, siehe model_gsmaster_history.go
 }
 
 type WeaponSkill struct {
@@ -68,40 +70,44 @@ type WeaponSkill struct {
 }
 
 type Spell struct {
-	ID               uint   `gorm:"primaryKey" json:"id"`
-	GameSystem       string `gorm:"column:game_system;index" json:"game_system"`
-	GameSystemId     uint   `json:"game_system_id,omitempty"`
-	Name             string `gorm:"type:varchar(255);index" json:"name"`
-	Beschreibung     string `json:"beschreibung"`
-	Quelle           string `json:"quelle"`                           // Deprecated: Für Rückwärtskompatibilität
-	SourceID         uint   `gorm:"index" json:"source_id,omitempty"` // Verweis auf strukturierte Quelle
-	PageNumber       int    `json:"page_number,omitempty"`            // Seitenzahl im Quellenbuch
-	Bonus            int    `json:"bonus"`
-	Stufe            int    `json:"level"`
-	AP               string `gorm:"default:1"  json:"ap"`
-	Art              string `gorm:"default:Gestenzauber" json:"art"`
-	Zauberdauer      string `gorm:"default:10 sec" json:"zauberdauer"`
-	Reichweite       string `json:"reichweite"` // in m
-	Wirkungsziel     string `json:"wirkungsziel"`
-	Wirkungsbereich  string `json:"wirkungsbereich"`
-	Wirkungsdauer    string `json:"wirkungsdauer"`
-	Ursprung         string `json:"ursprung"`
-	Category         string `gorm:"default:normal" json:"category"` // spell_school
-	LearningCategory string `gorm:"type:varchar(25);index" json:"learning_category"`
+	ID               uint                `gorm:"primaryKey" json:"id"`
+	GameSystem       string              `gorm:"column:game_system;index" json:"game_system"`
+	GameSystemId     uint                `json:"game_system_id,omitempty"`
+	Name             string              `gorm:"type:varchar(255);index" json:"name"`
+	Beschreibung     string              `json:"beschreibung"`
+	Quelle           string              `json:"quelle"`                           // Deprecated: Für Rückwärtskompatibilität
+	SourceID         uint                `gorm:"index" json:"source_id,omitempty"` // Verweis auf strukturierte Quelle
+	PageNumber       int                 `json:"page_number,omitempty"`            // Seitenzahl im Quellenbuch
+	Bonus            int                 `json:"bonus"`
+	Stufe            int                 `json:"level"`
+	AP               string              `gorm:"default:1"  json:"ap"`
+	Art              string              `gorm:"default:Gestenzauber" json:"art"`
+	Zauberdauer      string              `gorm:"default:10 sec" json:"zauberdauer"`
+	Reichweite       string              `json:"reichweite"` // in m
+	Wirkungsziel     string              `json:"wirkungsziel"`
+	Wirkungsbereich  string              `json:"wirkungsbereich"`
+	Wirkungsdauer    string              `json:"wirkungsdauer"`
+	Ursprung         string              `json:"ursprung"`
+	Category         string              `gorm:"default:normal" json:"category"` // spell_school
+	LearningCategory string              `gorm:"type:varchar(25);index" json:"learning_category"`
+	HomebrewScope                        // Geltungsbereich (global, Benutzer, Kampagne), siehe model_homebrew.go
+	ChangeTracking   `gorm:"-" json:"-"` // Änderungshistorie, siehe model_gsmaster_history.go
 }
 
 type Equipment struct {
-	ID           uint    `gorm:"primaryKey" json:"id"`
-	GameSystem   string  `gorm:"column:game_system;index" json:"game_system"`
-	GameSystemId uint    `json:"game_system_id,omitempty"`
-	Name         string  `gorm:"type:varchar(255);index" json:"name"`
-	Beschreibung string  `json:"beschreibung"`
-	Quelle       string  `json:"quelle"`                           // Deprecated: Für Rückwärtskompatibilität
-	SourceID     uint    `gorm:"index" json:"source_id,omitempty"` // Verweis auf strukturierte Quelle
-	PageNumber   int     `json:"page_number,omitempty"`            // Seitenzahl im Quellenbuch
-	Gewicht      float64 `json:"gewicht"`                          // in kg
-	Wert         float64 `json:"wert"`                             // in Gold
-	PersonalItem bool    `gorm:"default:false" json:"personal_item"`
+	ID             uint                `gorm:"primaryKey" json:"id"`
+	GameSystem     string              `gorm:"column:game_system;index" json:"game_system"`
+	GameSystemId   uint                `json:"game_system_id,omitempty"`
+	Name           string              `gorm:"type:varchar(255);index" json:"name"`
+	Beschreibung   string              `json:"beschreibung"`
+	Quelle         string              `json:"quelle"`                           // Deprecated: Für Rückwärtskompatibilität
+	SourceID       uint                `gorm:"index" json:"source_id,omitempty"` // Verweis auf strukturierte Quelle
+	PageNumber     int                 `json:"page_number,omitempty"`            // Seitenzahl im Quellenbuch
+	Gewicht        float64             `json:"gewicht"`                          // in kg
+	Wert           float64             `json:"wert"`                             // in Gold
+	PersonalItem   bool                `gorm:"default:false" json:"personal_item"`
+	HomebrewScope                      // Geltungsbereich (global, Benutzer, Kampagne), siehe model_homebrew.go
+	ChangeTracking `gorm:"-" json:"-"` // Änderungshistorie, siehe model_gsmaster_history.go
 }
 
 type Weapon struct {
@@ -124,25 +130,27 @@ type Transportation struct {
 }
 
 type Believe struct {
-	ID           uint   `gorm:"primaryKey" json:"id"`
-	GameSystem   string `gorm:"column:game_system;index" json:"game_system"`
-	Name         string `gorm:"type:varchar(255);index" json:"name"`
-	Beschreibung string `json:"beschreibung"`
-	Quelle       string `json:"quelle"`                           // Deprecated: Für Rückwärtskompatibilität
-	SourceID     uint   `gorm:"index" json:"source_id,omitempty"` // Verweis auf strukturierte Quelle
-	PageNumber   int    `json:"page_number,omitempty"`            // Seitenzahl im Quellenbuch
-	GameSystemId uint   `json:"game_system_id,omitempty"`
+	ID             uint                `gorm:"primaryKey" json:"id"`
+	GameSystem     string              `gorm:"column:game_system;index" json:"game_system"`
+	Name           string              `gorm:"type:varchar(255);index" json:"name"`
+	Beschreibung   string              `json:"beschreibung"`
+	Quelle         string              `json:"quelle"`                           // Deprecated: Für Rückwärtskompatibilität
+	SourceID       uint                `gorm:"index" json:"source_id,omitempty"` // Verweis auf strukturierte Quelle
+	PageNumber     int                 `json:"page_number,omitempty"`            // Seitenzahl im Quellenbuch
+	GameSystemId   uint                `json:"game_system_id,omitempty"`
+	ChangeTracking `gorm:"-" json:"-"` // Änderungshistorie, siehe model_gsmaster_history.go
 }
 
 // MiscLookup represents miscellaneous lookup values like gender, race, origin, etc.
 type MiscLookup struct {
-	ID           uint   `gorm:"primaryKey" json:"id"`
-	GameSystem   string `gorm:"column:game_system;index" json:"game_system"`
-	GameSystemId uint   `json:"game_system_id,omitempty"`
-	Key          string `gorm:"column:key;type:varchar(50);index;not null" json:"key"`
-	Value        string `gorm:"type:varchar(255);not null" json:"value"`
-	SourceID     uint   `json:"source_id,omitempty"`
-	PageNumber   int    `json:"page_number,omitempty"`
+	ID             uint                `gorm:"primaryKey" json:"id"`
+	GameSystem     string              `gorm:"column:game_system;index" json:"game_system"`
+	GameSystemId   uint                `json:"game_system_id,omitempty"`
+	Key            string              `gorm:"column:key;type:varchar(50);index;not null" json:"key"`
+	Value          string              `gorm:"type:varchar(255);not null" json:"value"`
+	SourceID       uint                `json:"source_id,omitempty"`
+	PageNumber     int                 `json:"page_number,omitempty"`
+	ChangeTracking `gorm:"-" json:"-"` // Änderungshistorie, siehe model_gsmaster_history.go
 }
 
 func (object *Skill) TableName() string {
@@ -813,7 +821,8 @@ func (object *Believe) First(name string) error {
 }
 
 // GetBelievesByActiveSources gibt Glaubensrichtungen nach aktiven Quellen zurück
-func GetBelievesByActiveSources(gameSystem string) ([]Believe, error) {
+// Mit Selector gilt die Quellenauswahl der Kampagne bzw. des Benutzers statt des globalen Flags.
+func GetBelievesByActiveSources(gameSystem string, sel ...SourceSelector) ([]Believe, error) {
 	var believes []Believe
 	gs := GetGameSystem(0, gameSystem)
 	if gs == nil {
@@ -824,9 +833,14 @@ func GetBelievesByActiveSources(gameSystem string) ([]Believe, error) {
 		// return empty slice if no valid game system found
 		return believes, fmt.Errorf("No GameSystem ID or Name found for %s", gameSystem)
 	}
-	err := database.DB.
-		Joins("LEFT JOIN gsm_lit_sources ON gsm_believes.source_id = gsm_lit_sources.id").
-		Where("(gsm_believes.game_system = ? or gsm_believes.game_system_id=?) AND (gsm_lit_sources.is_active = ? OR gsm_believes.source_id IS NULL)", gs.Name, gs.ID, true).
+	active, err := GetActiveSources(gs.Name, sel...)
+	if err != nil {
+		return believes, err
+	}
+	condition, args := active.Condition("gsm_believes")
+	err = database.DB.
+		Where("(gsm_believes.game_system = ? or gsm_believes.game_system_id=?)", gs.Name, gs.ID).
+		Where(condition, args...).
 		Order("gsm_believes.name ASC").
 		Find(&believes).Error
 	return believes, err
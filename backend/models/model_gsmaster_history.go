@@ -0,0 +1,318 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	MasterDataActionCreate = "create"
+	MasterDataActionUpdate = "update"
+	MasterDataActionDelete = "delete"
+)
+
+// historyBeforeKey ist der Schlüssel für den Zustand vor einer Änderung zwischen Before- und After-Hook
+// Die Hooks erhalten jeweils eine neue Session auf demselben Statement, daher liegt der Zustand in
+// dessen Settings statt in InstanceSet.
+func historyBeforeKey(table string, id uint) string {
+	return fmt.Sprintf("gsm_history:before:%s:%d", table, id)
+}
+
+// ChangeTracking wird von Handlern gesetzt, bevor Stammdaten geschrieben werden,
+// und von den GORM-Hooks in die Änderungshistorie übernommen. Es wird nicht gespeichert.
+type ChangeTracking struct {
+	ChangedBy uint `gorm:"-" json:"-"` // Benutzer, der die Änderung vornimmt (0 = System, z.B. Import)
+	RevertOf  uint `gorm:"-" json:"-"` // Historieneintrag, auf den zurückgesetzt wird
+}
+
+// SetChangedBy setzt den Benutzer für die Änderungshistorie
+func (ct *ChangeTracking) SetChangedBy(userID uint) {
+	ct.ChangedBy = userID
+}
+
+// MasterDataChange ist ein Eintrag der Änderungshistorie der Stammdaten
+type MasterDataChange struct {
+	ID        uint            `gorm:"primaryKey" json:"id"`
+	ItemTable string          `gorm:"size:64;index:idx_gsm_history_item" json:"item_table"`
+	ItemID    uint            `gorm:"index:idx_gsm_history_item" json:"item_id"`
+	Action    string          `gorm:"size:16" json:"action"` // create, update, delete
+	UserID    uint            `gorm:"index" json:"user_id"`
+	RevertOf  uint            `json:"revert_of,omitempty"`
+	Changes   json.RawMessage `gorm:"type:text" json:"changes"`  // {"spalte": {"old": ..., "new": ...}}
+	Snapshot  json.RawMessage `gorm:"type:text" json:"snapshot"` // Zustand nach der Änderung, bei delete der Zustand davor
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func (object *MasterDataChange) TableName() string {
+	dbPrefix := "gsm"
+	return dbPrefix + "_" + "history"
+}
+
+// FieldChange ist die Änderung einer Spalte
+type FieldChange struct {
+	Old any `json:"old"`
+	New any `json:"new"`
+}
+
+// LoadMasterDataRow lädt einen Stammdatensatz als Spaltenabbild
+func LoadMasterDataRow(tx *gorm.DB, table string, id uint) (map[string]any, error) {
+	row := map[string]any{}
+	err := tx.Session(&gorm.Session{NewDB: true}).Table(table).Where("id = ?", id).Take(&row).Error
+	if err != nil {
+		return nil, err
+	}
+	// Textspalten kommen je nach Treiber als []byte und würden sonst als Base64 gespeichert
+	for key, value := range row {
+		if b, ok := value.([]byte); ok {
+			row[key] = string(b)
+		}
+	}
+	return row, nil
+}
+
+// diffMasterDataRows vergleicht zwei Spaltenabbilder
+func diffMasterDataRows(before, after map[string]any) map[string]FieldChange {
+	changes := map[string]FieldChange{}
+	for key, newValue := range after {
+		oldValue := before[key]
+		if fmt.Sprint(oldValue) != fmt.Sprint(newValue) {
+			changes[key] = FieldChange{Old: oldValue, New: newValue}
+		}
+	}
+	for key, oldValue := range before {
+		if _, ok := after[key]; !ok {
+			changes[key] = FieldChange{Old: oldValue}
+		}
+	}
+	return changes
+}
+
+// RecordMasterDataChange schreibt einen Historieneintrag
+// before und after sind Spaltenabbilder; bei create ist before nil, bei delete ist after nil.
+// Ein Fehler wird zurückgegeben, damit die Hooks die Änderung samt Transaktion zurückrollen:
+// Stammdaten sollen sich nicht ohne Eintrag in der Historie ändern.
+func RecordMasterDataChange(tx *gorm.DB, table string, id uint, action string, info ChangeTracking, before, after map[string]any) error {
+	if before == nil {
+		before = map[string]any{}
+	}
+	snapshot := after
+	if after == nil {
+		snapshot, after = before, map[string]any{}
+	}
+	changes := diffMasterDataRows(before, after)
+	if action == MasterDataActionUpdate && len(changes) == 0 {
+		return nil
+	}
+
+	changesJSON, err := json.Marshal(changes)
+	if err != nil {
+		return fmt.Errorf("änderungshistorie für %s %d konnte nicht erstellt werden: %w", table, id, err)
+	}
+	snapshotJSON, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("änderungshistorie für %s %d konnte nicht erstellt werden: %w", table, id, err)
+	}
+	entry := MasterDataChange{
+		ItemTable: table,
+		ItemID:    id,
+		Action:    action,
+		UserID:    info.ChangedBy,
+		RevertOf:  info.RevertOf,
+		Changes:   changesJSON,
+		Snapshot:  snapshotJSON,
+	}
+	if err := tx.Session(&gorm.Session{NewDB: true}).Create(&entry).Error; err != nil {
+		return fmt.Errorf("änderungshistorie für %s %d konnte nicht gespeichert werden: %w", table, id, err)
+	}
+	return nil
+}
+
+// historyAfterCreate, historyBeforeChange und historyAfterChange sind die gemeinsame
+// Umsetzung der Hooks aller Stammdatenmodelle. Die Tabelle kommt aus dem Statement,
+// damit eingebettete Modelle (z.B. Weapon in Equipment) in ihrer eigenen Tabelle landen.
+// Fehler brechen die Änderung ab, GORM rollt dann die Transaktion zurück.
+func historyAfterCreate(tx *gorm.DB, id uint, info ChangeTracking) error {
+	if id == 0 {
+		return nil
+	}
+	after, err := LoadMasterDataRow(tx, tx.Statement.Table, id)
+	if err != nil {
+		return fmt.Errorf("änderungshistorie: %s %d nach dem Anlegen nicht lesbar: %w", tx.Statement.Table, id, err)
+	}
+	return RecordMasterDataChange(tx, tx.Statement.Table, id, MasterDataActionCreate, info, nil, after)
+}
+
+// historyAffectedIDs liefert die Datensätze einer Änderung
+// Ohne Primärschlüssel im Modell (z.B. Where(...).Delete(&Skill{})) werden sie über die Bedingung
+// des Statements ermittelt, damit auch solche Änderungen je Datensatz erfasst werden.
+func historyAffectedIDs(tx *gorm.DB, id uint) ([]uint, error) {
+	if id != 0 {
+		return []uint{id}, nil
+	}
+	where, ok := tx.Statement.Clauses["WHERE"]
+	if !ok {
+		return nil, fmt.Errorf("änderungshistorie: änderung an %s ohne Bedingung wird nicht unterstützt", tx.Statement.Table)
+	}
+	var ids []uint
+	err := tx.Session(&gorm.Session{NewDB: true}).Table(tx.Statement.Table).
+		Clauses(where.Expression).Pluck("id", &ids).Error
+	return ids, err
+}
+
+func historyBeforeChange(tx *gorm.DB, id uint) error {
+	ids, err := historyAffectedIDs(tx, id)
+	if err != nil {
+		return err
+	}
+	if id == 0 {
+		tx.Statement.Settings.Store(historyBeforeKey(tx.Statement.Table, 0), ids)
+	}
+	for _, itemID := range ids {
+		before, err := LoadMasterDataRow(tx, tx.Statement.Table, itemID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue // nichts zu ändern, z.B. Save auf einen neuen Datensatz
+		}
+		if err != nil {
+			return fmt.Errorf("änderungshistorie: %s %d vor der Änderung nicht lesbar: %w", tx.Statement.Table, itemID, err)
+		}
+		tx.Statement.Settings.Store(historyBeforeKey(tx.Statement.Table, itemID), before)
+	}
+	return nil
+}
+
+func historyAfterChange(tx *gorm.DB, id uint, action string, info ChangeTracking) error {
+	ids := []uint{id}
+	if id == 0 {
+		value, ok := tx.Statement.Settings.LoadAndDelete(historyBeforeKey(tx.Statement.Table, 0))
+		if !ok {
+			return nil
+		}
+		ids = value.([]uint)
+	}
+	for _, itemID := range ids {
+		value, ok := tx.Statement.Settings.LoadAndDelete(historyBeforeKey(tx.Statement.Table, itemID))
+		if !ok {
+			continue
+		}
+		before := value.(map[string]any)
+		after, err := LoadMasterDataRow(tx, tx.Statement.Table, itemID)
+		if action == MasterDataActionDelete {
+			// Besteht der Datensatz noch, hat die Bedingung des Löschens nicht gegriffen
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				err = RecordMasterDataChange(tx, tx.Statement.Table, itemID, action, info, before, nil)
+			} else if err == nil {
+				continue
+			}
+		} else if err == nil {
+			err = RecordMasterDataChange(tx, tx.Statement.Table, itemID, action, info, before, after)
+		}
+		if err != nil {
+			return fmt.Errorf("änderungshistorie: %s %d: %w", tx.Statement.Table, itemID, err)
+		}
+	}
+	return nil
+}
+
+func (object *Skill) AfterCreate(tx *gorm.DB) error {
+	return historyAfterCreate(tx, object.ID, object.ChangeTracking)
+}
+
+func (object *Skill) BeforeUpdate(tx *gorm.DB) error {
+	return historyBeforeChange(tx, object.ID)
+}
+
+func (object *Skill) AfterUpdate(tx *gorm.DB) error {
+	return historyAfterChange(tx, object.ID, MasterDataActionUpdate, object.ChangeTracking)
+}
+
+func (object *Skill) BeforeDelete(tx *gorm.DB) error {
+	return historyBeforeChange(tx, object.ID)
+}
+
+func (object *Skill) AfterDelete(tx *gorm.DB) error {
+	return historyAfterChange(tx, object.ID, MasterDataActionDelete, object.ChangeTracking)
+}
+
+func (object *Spell) AfterCreate(tx *gorm.DB) error {
+	return historyAfterCreate(tx, object.ID, object.ChangeTracking)
+}
+
+func (object *Spell) BeforeUpdate(tx *gorm.DB) error {
+	return historyBeforeChange(tx, object.ID)
+}
+
+func (object *Spell) AfterUpdate(tx *gorm.DB) error {
+	return historyAfterChange(tx, object.ID, MasterDataActionUpdate, object.ChangeTracking)
+}
+
+func (object *Spell) BeforeDelete(tx *gorm.DB) error {
+	return historyBeforeChange(tx, object.ID)
+}
+
+func (object *Spell) AfterDelete(tx *gorm.DB) error {
+	return historyAfterChange(tx, object.ID, MasterDataActionDelete, object.ChangeTracking)
+}
+
+func (object *Equipment) AfterCreate(tx *gorm.DB) error {
+	return historyAfterCreate(tx, object.ID, object.ChangeTracking)
+}
+
+func (object *Equipment) BeforeUpdate(tx *gorm.DB) error {
+	return historyBeforeChange(tx, object.ID)
+}
+
+func (object *Equipment) AfterUpdate(tx *gorm.DB) error {
+	return historyAfterChange(tx, object.ID, MasterDataActionUpdate, object.ChangeTracking)
+}
+
+func (object *Equipment) BeforeDelete(tx *gorm.DB) error {
+	return historyBeforeChange(tx, object.ID)
+}
+
+func (object *Equipment) AfterDelete(tx *gorm.DB) error {
+	return historyAfterChange(tx, object.ID, MasterDataActionDelete, object.ChangeTracking)
+}
+
+func (object *Believe) AfterCreate(tx *gorm.DB) error {
+	return historyAfterCreate(tx, object.ID, object.ChangeTracking)
+}
+
+func (object *Believe) BeforeUpdate(tx *gorm.DB) error {
+	return historyBeforeChange(tx, object.ID)
+}
+
+func (object *Believe) AfterUpdate(tx *gorm.DB) error {
+	return historyAfterChange(tx, object.ID, MasterDataActionUpdate, object.ChangeTracking)
+}
+
+func (object *Believe) BeforeDelete(tx *gorm.DB) error {
+	return historyBeforeChange(tx, object.ID)
+}
+
+func (object *Believe) AfterDelete(tx *gorm.DB) error {
+	return historyAfterChange(tx, object.ID, MasterDataActionDelete, object.ChangeTracking)
+}
+
+func (object *MiscLookup) AfterCreate(tx *gorm.DB) error {
+	return historyAfterCreate(tx, object.ID, object.ChangeTracking)
+}
+
+func (object *MiscLookup) BeforeUpdate(tx *gorm.DB) error {
+	return historyBeforeChange(tx, object.ID)
+}
+
+func (object *MiscLookup) AfterUpdate(tx *gorm.DB) error {
+	return historyAfterChange(tx, object.ID, MasterDataActionUpdate, object.ChangeTracking)
+}
+
+func (object *MiscLookup) BeforeDelete(tx *gorm.DB) error {
+	return historyBeforeChange(tx, object.ID)
+}
+
+func (object *MiscLookup) AfterDelete(tx *gorm.DB) error {
+	return historyAfterChange(tx, object.ID, MasterDataActionDelete, object.ChangeTracking)
+}
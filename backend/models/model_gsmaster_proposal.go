@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+const (
+	ProposalStatusPending   = "pending"
+	ProposalStatusApproved  = "approved"
+	ProposalStatusRejected  = "rejected"
+	ProposalStatusWithdrawn = "withdrawn"
+)
+
+// MasterDataProposal ist ein Änderungsvorschlag eines Spielers für die Stammdaten
+// Gespeichert wird nur die Differenz; übernommen wird sie erst bei der Freigabe durch einen Maintainer.
+type MasterDataProposal struct {
+	ID            uint            `gorm:"primaryKey" json:"id"`
+	ItemType      string          `gorm:"size:16;index" json:"item_type"` // skill, spell, equipment
+	ItemID        uint            `gorm:"index" json:"item_id"`           // 0 = neuer Eintrag
+	GameSystemId  uint            `json:"game_system_id,omitempty"`
+	Changes       json.RawMessage `gorm:"type:text" json:"changes"` // {"feld": {"old": ..., "new": ...}} nach JSON-Feldnamen
+	Reason        string          `gorm:"type:text" json:"reason"`
+	Status        string          `gorm:"size:16;index;default:pending" json:"status"`
+	UserID        uint            `gorm:"index" json:"user_id"`
+	ReviewerID    uint            `json:"reviewer_id,omitempty"`
+	ReviewedAt    *time.Time      `json:"reviewed_at,omitempty"`
+	AppliedItemID uint            `json:"applied_item_id,omitempty"` // ID des angelegten oder geänderten Datensatzes
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+
+	Comments []MasterDataProposalComment `gorm:"foreignKey:ProposalID;constraint:OnDelete:CASCADE" json:"comments,omitempty"`
+}
+
+func (object *MasterDataProposal) TableName() string {
+	dbPrefix := "gsm"
+	return dbPrefix + "_" + "proposals"
+}
+
+// MasterDataProposalComment ist ein Kommentar zu einem Änderungsvorschlag (Rückfragen, Begründung der Entscheidung)
+type MasterDataProposalComment struct {
+	ID         uint      `gorm:"primaryKey" json:"id"`
+	ProposalID uint      `gorm:"index" json:"proposal_id"`
+	UserID     uint      `json:"user_id"`
+	Text       string    `gorm:"type:text" json:"text"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+func (object *MasterDataProposalComment) TableName() string {
+	dbPrefix := "gsm"
+	return dbPrefix + "_" + "proposal_comments"
+}
@@ -0,0 +1,181 @@
+package models
+
+import (
+	"bamort/database"
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ScopeGlobal   = "global"
+	ScopeUser     = "user"
+	ScopeCampaign = "campaign"
+)
+
+// HomebrewScope legt fest, für wen ein Stammdatensatz gilt
+// Globale Datensätze gelten für alle; Hausregeln eines Benutzers oder einer Kampagne sind nur
+// dort sichtbar und erlernbar. Ein leerer Scope (Altbestand) zählt als global.
+type HomebrewScope struct {
+	Scope           string `gorm:"size:10;index;default:global" json:"scope,omitempty"`
+	ScopeUserID     uint   `gorm:"index" json:"scope_user_id,omitempty"`     // Besitzer bei Scope "user"
+	ScopeCampaignID uint   `gorm:"index" json:"scope_campaign_id,omitempty"` // Kampagne bei Scope "campaign"
+}
+
+// IsHomebrew meldet, ob der Datensatz eine Hausregel eines Benutzers oder einer Kampagne ist
+func (h HomebrewScope) IsHomebrew() bool {
+	return h.Scope == ScopeUser || h.Scope == ScopeCampaign
+}
+
+// VisibleIn prüft, ob der Datensatz im Geltungsbereich sichtbar ist
+func (h HomebrewScope) VisibleIn(scope ContentScope) bool {
+	switch h.Scope {
+	case ScopeUser:
+		return scope.All || (scope.UserID != 0 && h.ScopeUserID == scope.UserID)
+	case ScopeCampaign:
+		if scope.All {
+			return true
+		}
+		for _, id := range scope.CampaignIDs {
+			if id == h.ScopeCampaignID {
+				return true
+			}
+		}
+		return false
+	default:
+		return true
+	}
+}
+
+// Validate prüft Scope-Wert und Besitzer; ein leerer Scope wird zu "global"
+func (h *HomebrewScope) Validate() error {
+	switch h.Scope {
+	case "", ScopeGlobal:
+		h.Scope = ScopeGlobal
+		h.ScopeUserID = 0
+		h.ScopeCampaignID = 0
+	case ScopeUser:
+		if h.ScopeUserID == 0 {
+			return fmt.Errorf("scope_user_id is required for scope %q", ScopeUser)
+		}
+		h.ScopeCampaignID = 0
+	case ScopeCampaign:
+		if h.ScopeCampaignID == 0 {
+			return fmt.Errorf("scope_campaign_id is required for scope %q", ScopeCampaign)
+		}
+		h.ScopeUserID = 0
+	default:
+		return fmt.Errorf("invalid scope: %s", h.Scope)
+	}
+	return nil
+}
+
+// ContentScope beschreibt, welche Hausregeln ein Betrachter sieht
+// Der Nullwert sieht nur globale Datensätze.
+type ContentScope struct {
+	All         bool   // Maintainer sehen alle Datensätze
+	UserID      uint   // eigene Hausregeln
+	CampaignIDs []uint // Hausregeln dieser Kampagnen
+}
+
+// Condition liefert die SQL-Bedingung für sichtbare Datensätze; alias ist der Tabellenalias oder leer
+func (scope ContentScope) Condition(alias string) (string, []any) {
+	if scope.All {
+		return "1 = 1", nil
+	}
+	prefix := ""
+	if alias != "" {
+		prefix = alias + "."
+	}
+	parts := []string{prefix + "scope IS NULL", prefix + "scope IN ?"}
+	args := []any{[]string{"", ScopeGlobal}}
+	if scope.UserID != 0 {
+		parts = append(parts, "("+prefix+"scope = ? AND "+prefix+"scope_user_id = ?)")
+		args = append(args, ScopeUser, scope.UserID)
+	}
+	if len(scope.CampaignIDs) > 0 {
+		parts = append(parts, "("+prefix+"scope = ? AND "+prefix+"scope_campaign_id IN ?)")
+		args = append(args, ScopeCampaign, scope.CampaignIDs)
+	}
+	return "(" + strings.Join(parts, " OR ") + ")", args
+}
+
+// lookupScope liefert den optionalen Geltungsbereich einer Abfrage; ohne Angabe nur globale Datensätze
+func lookupScope(scope []ContentScope) ContentScope {
+	if len(scope) > 0 {
+		return scope[0]
+	}
+	return ContentScope{}
+}
+
+// CharacterContentScope ist der Geltungsbereich eines Charakters: Hausregeln seines Spielers
+// und der Kampagne, in der er spielt
+func CharacterContentScope(char *Char) ContentScope {
+	scope := ContentScope{UserID: char.UserID}
+	if char.CampaignID != 0 {
+		scope.CampaignIDs = []uint{char.CampaignID}
+	}
+	return scope
+}
+
+// UserContentScope ist der Geltungsbereich eines Benutzers: eigene Hausregeln und die aller
+// Kampagnen, die er leitet oder in denen seine Charaktere spielen
+func UserContentScope(userID uint) (ContentScope, error) {
+	campaignIDs, err := CampaignIDsForUser(userID)
+	if err != nil {
+		return ContentScope{}, err
+	}
+	return ContentScope{UserID: userID, CampaignIDs: campaignIDs}, nil
+}
+
+// VisibleInScope filtert Stammdaten auf die im Geltungsbereich sichtbaren Datensätze
+func VisibleInScope[T interface{ VisibleIn(ContentScope) bool }](items []T, scope ContentScope) []T {
+	visible := make([]T, 0, len(items))
+	for _, item := range items {
+		if item.VisibleIn(scope) {
+			visible = append(visible, item)
+		}
+	}
+	return visible
+}
+
+// FirstVisible sucht einen Stammdatensatz (Fertigkeit, Zauber, Ausrüstung, ...) nach Namen
+// unter den im Geltungsbereich sichtbaren Datensätzen; Hausregeln haben Vorrang.
+func FirstVisible(dest any, name string, gs *GameSystem, scope ContentScope) error {
+	if name == "" {
+		return fmt.Errorf("name cannot be empty")
+	}
+	condition, args := scope.Condition("")
+	return database.DB.Where("(game_system=? OR game_system_id=?) AND name = ?", gs.Name, gs.ID, name).
+		Where(condition, args...).
+		Order(homebrewFirst("")).Order("id ASC").First(dest).Error
+}
+
+// homebrewFirst sortiert Hausregeln vor globale Datensätze gleichen Namens
+func homebrewFirst(alias string) string {
+	prefix := ""
+	if alias != "" {
+		prefix = alias + "."
+	}
+	return "CASE WHEN " + prefix + "scope IN ('" + ScopeUser + "', '" + ScopeCampaign + "') THEN 0 ELSE 1 END"
+}
+
+// scopedModels sind alle Stammdatenmodelle mit Geltungsbereich
+func scopedModels() []any {
+	return []any{&Skill{}, &WeaponSkill{}, &Spell{}, &Equipment{}, &Weapon{}, &Container{}, &Transportation{}}
+}
+
+// TransferCampaignHomebrew macht die Hausregeln einer Kampagne zu Hausregeln eines Benutzers
+// Wird beim Löschen einer Kampagne mit dem Spielleiter aufgerufen, damit nichts verwaist.
+func TransferCampaignHomebrew(tx *gorm.DB, campaignID, userID uint) error {
+	for _, model := range scopedModels() {
+		err := tx.Session(&gorm.Session{SkipHooks: true}).Model(model).
+			Where("scope = ? AND scope_campaign_id = ?", ScopeCampaign, campaignID).
+			Updates(map[string]any{"scope": ScopeUser, "scope_user_id": userID, "scope_campaign_id": 0}).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -147,6 +147,7 @@ type SkillImprovementCost struct {
 type SkillLearningInfo struct {
 	SkillID          uint   `json:"skill_id"`
 	SkillName        string `json:"skill_name"`
+	SourceID         uint   `json:"source_id,omitempty"`
 	CategoryID       uint   `json:"category_id"`
 	CategoryName     string `json:"category_name"`
 	DifficultyID     uint   `json:"difficulty_id"`
@@ -164,6 +165,7 @@ type SkillLearningInfo struct {
 type SpellLearningInfo struct {
 	SpellID          uint   `json:"spell_id"`
 	SpellName        string `json:"spell_name"`
+	SourceID         uint   `json:"source_id,omitempty"`
 	SpellLevel       int    `json:"spell_level"`
 	SchoolID         uint   `json:"school_id"`
 	SchoolName       string `json:"school_name"`
@@ -520,15 +522,37 @@ func GetEPPerLEForClassAndSpellSchool(classCode string, schoolName string) (int,
 	return result.EPPerLE, nil
 }
 
-// GetSkillCategoryAndDifficultyNewSystem findet die beste Kategorie für eine Fertigkeit basierend auf niedrigsten EP-Kosten
-func GetSkillCategoryAndDifficultyNewSystem(skillName string, classCode string) (*SkillLearningInfo, error) {
+// skillLearningTables nennt die Stamm- und Lerntabellen von Fertigkeiten bzw. Waffenfertigkeiten
+type skillLearningTables struct {
+	difficulties string // Kategorie/Schwierigkeit je Fertigkeit
+	skillColumn  string // Fremdschlüssel in difficulties
+	skills       string // Stammdaten
+}
+
+var (
+	skillTables       = skillLearningTables{"learning_skill_category_difficulties", "skill_id", "gsm_skills"}
+	weaponSkillTables = skillLearningTables{"learning_weaponskill_category_difficulties", "weapon_skill_id", "gsm_weaponskills"}
+)
+
+// querySkillLearningInfos liest Kategorien und Schwierigkeiten einer Klasse, die günstigste zuerst
+// Ist skillName leer, werden alle Fertigkeiten (bzw. Waffenfertigkeiten) in einer Abfrage geladen.
+func querySkillLearningInfos(tables skillLearningTables, skillName, classCode string, scope []ContentScope) ([]SkillLearningInfo, *GameSystem, error) {
 	var results []SkillLearningInfo
 	gs := GetGameSystem(0, "midgard")
+	scopeCondition, scopeArgs := lookupScope(scope).Condition("s")
+	nameCondition := "1 = 1"
+	args := []any{classCode, gs.Name, gs.ID}
+	if skillName != "" {
+		nameCondition = "s.name = ?"
+		args = append(args, skillName)
+	}
+	args = append(args, scopeArgs...)
 
 	err := database.DB.Raw(`
 		SELECT 
-			scd.skill_id,
+			s.id as skill_id,
 			s.name as skill_name,
+			s.source_id as source_id,
 			s.game_system as game_system,
 			s.game_system_id as game_system_id,
 			scd.skill_category as category_name,
@@ -538,30 +562,72 @@ func GetSkillCategoryAndDifficultyNewSystem(skillName string, classCode string)
 			ccec.character_class as class_name,
 			ccec.ep_per_te,
 			(scd.learn_cost * ccec.ep_per_te) as total_cost
-		FROM learning_skill_category_difficulties scd
+		FROM `+tables.difficulties+` scd
 		JOIN learning_class_category_ep_costs ccec ON scd.skill_category = ccec.skill_category
-		JOIN gsm_skills s ON scd.skill_id = s.id
-		WHERE s.name = ? AND ccec.character_class = ? AND (s.game_system = ? OR s.game_system_id = ?)
-		ORDER BY total_cost ASC
-	`, skillName, classCode, gs.Name, gs.ID).Scan(&results).Error
+		JOIN `+tables.skills+` s ON scd.`+tables.skillColumn+` = s.id
+		WHERE ccec.character_class = ? AND (s.game_system = ? OR s.game_system_id = ?) AND `+nameCondition+` AND `+scopeCondition+`
+		ORDER BY `+homebrewFirst("s")+`, total_cost ASC
+	`, args...).Scan(&results).Error
+	return results, gs, err
+}
 
+// firstSkillLearningInfo liefert den ersten (günstigsten) Eintrag mit aufgelöstem Spielsystem
+func firstSkillLearningInfo(results []SkillLearningInfo, err error) (*SkillLearningInfo, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	for i := range results {
-		gs := GetGameSystem(results[i].GameSystemId, results[i].GameSystem)
-		results[i].GameSystemId = gs.ID
-		results[i].GameSystem = gs.Name
-	}
-
 	if len(results) == 0 {
 		return nil, gorm.ErrRecordNotFound
 	}
-
+	gs := GetGameSystem(results[0].GameSystemId, results[0].GameSystem)
+	results[0].GameSystemId = gs.ID
+	results[0].GameSystem = gs.Name
 	return &results[0], nil
 }
 
+// cheapestSkillLearningInfos behält je Fertigkeit den ersten (günstigsten) Eintrag
+func cheapestSkillLearningInfos(results []SkillLearningInfo, gs *GameSystem, err error) (map[string]SkillLearningInfo, error) {
+	if err != nil {
+		return nil, err
+	}
+	infos := make(map[string]SkillLearningInfo, len(results))
+	for _, info := range results {
+		if _, ok := infos[info.SkillName]; ok {
+			continue
+		}
+		info.GameSystemId = gs.ID
+		info.GameSystem = gs.Name
+		infos[info.SkillName] = info
+	}
+	return infos, nil
+}
+
+// GetSkillCategoryAndDifficultyNewSystem findet die beste Kategorie für eine Fertigkeit basierend auf niedrigsten EP-Kosten
+// Hausregeln werden nur im angegebenen Geltungsbereich gefunden (meist CharacterContentScope);
+// ohne Angabe nur globale Fertigkeiten.
+func GetSkillCategoryAndDifficultyNewSystem(skillName string, classCode string, scope ...ContentScope) (*SkillLearningInfo, error) {
+	results, _, err := querySkillLearningInfos(skillTables, skillName, classCode, scope)
+	return firstSkillLearningInfo(results, err)
+}
+
+// GetWeaponSkillLearningInfoNewSystem findet Kategorie und Schwierigkeit einer Waffenfertigkeit mit den niedrigsten EP-Kosten
+// Gegenstück zu GetSkillCategoryAndDifficultyNewSystem für learning_weaponskill_category_difficulties.
+func GetWeaponSkillLearningInfoNewSystem(skillName string, classCode string, scope ...ContentScope) (*SkillLearningInfo, error) {
+	results, _, err := querySkillLearningInfos(weaponSkillTables, skillName, classCode, scope)
+	return firstSkillLearningInfo(results, err)
+}
+
+// GetSkillLearningInfosNewSystem liefert die günstigste Kategorie aller Fertigkeiten einer Klasse nach Namen
+// Für Listen statt einer Abfrage je Fertigkeit mit GetSkillCategoryAndDifficultyNewSystem.
+func GetSkillLearningInfosNewSystem(classCode string, scope ...ContentScope) (map[string]SkillLearningInfo, error) {
+	return cheapestSkillLearningInfos(querySkillLearningInfos(skillTables, "", classCode, scope))
+}
+
+// GetWeaponSkillLearningInfosNewSystem liefert die günstigste Kategorie aller Waffenfertigkeiten einer Klasse nach Namen
+func GetWeaponSkillLearningInfosNewSystem(classCode string, scope ...ContentScope) (map[string]SkillLearningInfo, error) {
+	return cheapestSkillLearningInfos(querySkillLearningInfos(weaponSkillTables, "", classCode, scope))
+}
+
 // GetSkillInfoCategoryAndDifficultyNewSystem holt die Informationen für eine spezifische Kategorie/Schwierigkeit
 func GetSkillInfoCategoryAndDifficultyNewSystem(skillName, category, difficulty, classCode string) (*SkillLearningInfo, error) {
 	var result SkillLearningInfo
@@ -597,14 +663,24 @@ func GetSkillInfoCategoryAndDifficultyNewSystem(skillName, category, difficulty,
 	return &result, nil
 }
 
-// GetSpellLearningInfoNewSystem holt alle Informationen für das Erlernen eines Zaubers
-func GetSpellLearningInfoNewSystem(spellName string, classCode string) (*SpellLearningInfo, error) {
-	var result SpellLearningInfo
+// querySpellLearningInfos liest die Lerninformationen der Zauber, die eine Klasse lernen kann
+// Ist spellName leer, werden alle Zauber in einer Abfrage geladen; Hausregeln stehen vorne.
+func querySpellLearningInfos(spellName, classCode string, scope []ContentScope) ([]SpellLearningInfo, error) {
+	var results []SpellLearningInfo
+	scopeCondition, scopeArgs := lookupScope(scope).Condition("s")
+	nameCondition := "1 = 1"
+	args := []any{classCode}
+	if spellName != "" {
+		nameCondition = "s.name = ?"
+		args = append(args, spellName)
+	}
+	args = append(args, scopeArgs...)
 
 	err := database.DB.Raw(`
 		SELECT 
 			s.id as spell_id,
 			s.name as spell_name,
+			s.source_id as source_id,
 			s.game_system as game_system,
 			s.game_system_id as game_system_id,
 			s.stufe as spell_level,
@@ -616,17 +692,25 @@ func GetSpellLearningInfoNewSystem(spellName string, classCode string) (*SpellLe
 		FROM gsm_spells s
 		JOIN learning_class_spell_school_ep_costs cssec ON COALESCE(NULLIF(s.category, ''), s.learning_category) = cssec.spell_school
 		LEFT JOIN learning_spell_level_le_costs sllc ON s.stufe = sllc.level AND (sllc.game_system = s.game_system OR sllc.game_system_id = s.game_system_id OR sllc.game_system_id IS NULL)
-		WHERE s.name = ? AND cssec.character_class = ?
-	`, spellName, classCode).Scan(&result).Error
+		WHERE cssec.character_class = ? AND `+nameCondition+` AND `+scopeCondition+`
+		ORDER BY `+homebrewFirst("s")+`
+	`, args...).Scan(&results).Error
+	return results, err
+}
 
+// GetSpellLearningInfoNewSystem holt alle Informationen für das Erlernen eines Zaubers
+// Hausregeln werden wie bei GetSkillCategoryAndDifficultyNewSystem nur im Geltungsbereich gefunden.
+func GetSpellLearningInfoNewSystem(spellName string, classCode string, scope ...ContentScope) (*SpellLearningInfo, error) {
+	results, err := querySpellLearningInfos(spellName, classCode, scope)
 	if err != nil {
 		return nil, err
 	}
 
 	// Validate that we found a spell (spell_id should be > 0)
-	if result.SpellID == 0 {
+	if len(results) == 0 || results[0].SpellID == 0 {
 		return nil, gorm.ErrRecordNotFound
 	}
+	result := results[0]
 
 	// Validate spell level - level 0 is not a valid spell level
 	if result.SpellLevel <= 0 {
@@ -640,7 +724,33 @@ func GetSpellLearningInfoNewSystem(spellName string, classCode string) (*SpellLe
 	return &result, nil
 }
 
-// GetImprovementCost holt die Verbesserungskosten für eine Fertigkeit
+// GetSpellLearningInfosNewSystem liefert die Lerninformationen aller Zauber einer Klasse nach Namen
+// Zauber ohne gültige Stufe fehlen wie bei GetSpellLearningInfoNewSystem.
+func GetSpellLearningInfosNewSystem(classCode string, scope ...ContentScope) (map[string]SpellLearningInfo, error) {
+	results, err := querySpellLearningInfos("", classCode, scope)
+	if err != nil {
+		return nil, err
+	}
+	systems := map[string]*GameSystem{} // Spielsysteme nur einmal je Kombination auflösen
+	infos := make(map[string]SpellLearningInfo, len(results))
+	for _, info := range results {
+		if _, ok := infos[info.SpellName]; ok || info.SpellLevel <= 0 {
+			continue
+		}
+		key := fmt.Sprintf("%d/%s", info.GameSystemId, info.GameSystem)
+		if _, ok := systems[key]; !ok {
+			systems[key] = GetGameSystem(info.GameSystemId, info.GameSystem)
+		}
+		if gs := systems[key]; gs != nil {
+			info.GameSystemId = gs.ID
+			info.GameSystem = gs.Name
+		}
+		infos[info.SpellName] = info
+	}
+	return infos, nil
+}
+
+// GetImprovementCost holt die Verbesserungskosten für eine Fertigkeit oder Waffenfertigkeit
 func GetImprovementCost(skillName string, categoryName string, difficultyName string, currentLevel int) (int, error) {
 	var result SkillImprovementCost
 
@@ -649,13 +759,20 @@ func GetImprovementCost(skillName string, categoryName string, difficultyName st
 		FROM skill_improvement_cost2 sic
 		JOIN learning_skill_categories lc ON lc.id = sic.skill_category_id
 		JOIN learning_skill_difficulties ld ON ld.id = sic.skill_difficulty_id
-		JOIN learning_skill_category_difficulties scd ON scd.skill_category = lc.name AND scd.skill_difficulty = ld.name
-		JOIN gsm_skills s ON scd.skill_id = s.id
-		WHERE s.name = ?
-		  AND lc.name = ?
+		WHERE lc.name = ?
 		  AND ld.name = ?
 		  AND sic.current_level = ?
-	`, skillName, categoryName, difficultyName, currentLevel).Scan(&result).Error
+		  AND (EXISTS (
+				SELECT 1 FROM learning_skill_category_difficulties scd
+				JOIN gsm_skills s ON scd.skill_id = s.id
+				WHERE scd.skill_category = lc.name AND scd.skill_difficulty = ld.name AND s.name = ?
+			) OR EXISTS (
+				SELECT 1 FROM learning_weaponskill_category_difficulties wscd
+				JOIN gsm_weaponskills w ON wscd.weapon_skill_id = w.id
+				WHERE wscd.skill_category = lc.name AND wscd.skill_difficulty = ld.name AND w.name = ?
+			))
+		LIMIT 1
+	`, categoryName, difficultyName, currentLevel, skillName, skillName).Scan(&result).Error
 
 	if err != nil {
 		return 0, err
@@ -694,31 +811,49 @@ func GetSourcesByGameSystem(gameSystem string) ([]Source, error) {
 }
 
 // GetSkillsByActiveSources gibt alle Fertigkeiten zurück, die in aktiven Quellen definiert sind
-func GetSkillsByActiveSources(gameSystem string) ([]Skill, error) {
+// Mit Selector gilt die Quellenauswahl der Kampagne bzw. des Benutzers statt des globalen Flags.
+func GetSkillsByActiveSources(gameSystem string, sel ...SourceSelector) ([]Skill, error) {
+	active, err := GetActiveSources(gameSystem, sel...)
+	if err != nil {
+		return nil, err
+	}
+	condition, args := active.Condition("gsm_skills")
 	var skills []Skill
-	err := database.DB.
-		Joins("LEFT JOIN gsm_lit_sources ON gsm_skills.source_id = gsm_lit_sources.id").
-		Where("gsm_skills.game_system = ? AND (gsm_lit_sourcesis_active = ? OR gsm_skills.source_id IS NULL)", gameSystem, true).
+	err = database.DB.
+		Where("gsm_skills.game_system = ?", gameSystem).
+		Where(condition, args...).
 		Find(&skills).Error
 	return skills, err
 }
 
 // GetSpellsByActiveSources gibt alle Zauber zurück, die in aktiven Quellen definiert sind
-func GetSpellsByActiveSources(gameSystem string) ([]Spell, error) {
+// Mit Selector gilt die Quellenauswahl der Kampagne bzw. des Benutzers statt des globalen Flags.
+func GetSpellsByActiveSources(gameSystem string, sel ...SourceSelector) ([]Spell, error) {
+	active, err := GetActiveSources(gameSystem, sel...)
+	if err != nil {
+		return nil, err
+	}
+	condition, args := active.Condition("gsm_spells")
 	var spells []Spell
-	err := database.DB.
-		Joins("LEFT JOIN gsm_lit_sources ON gsm_spells.source_id = gsm_lit_sources.id").
-		Where("gsm_spells.game_system = ? AND (gsm_lit_sources.is_active = ? OR gsm_spells.source_id IS NULL)", gameSystem, true).
+	err = database.DB.
+		Where("gsm_spells.game_system = ?", gameSystem).
+		Where(condition, args...).
 		Find(&spells).Error
 	return spells, err
 }
 
 // GetCharacterClassesByActiveSources gibt alle Charakterklassen zurück, die in aktiven Quellen definiert sind
-func GetCharacterClassesByActiveSources(gameSystem string) ([]CharacterClass, error) {
+// Mit Selector gilt die Quellenauswahl der Kampagne bzw. des Benutzers statt des globalen Flags.
+func GetCharacterClassesByActiveSources(gameSystem string, sel ...SourceSelector) ([]CharacterClass, error) {
+	active, err := GetActiveSources(gameSystem, sel...)
+	if err != nil {
+		return nil, err
+	}
+	condition, args := active.Condition("gsm_character_classes")
 	var classes []CharacterClass
-	err := database.DB.
-		Joins("LEFT JOIN gsm_lit_sources ON gsm_character_classes.source_id = gsm_lit_sources.id").
-		Where("gsm_character_classes.game_system = ? AND (gsm_lit_sources.is_active = ? OR gsm_character_classes.source_id IS NULL)", gameSystem, true).
+	err = database.DB.
+		Where("gsm_character_classes.game_system = ?", gameSystem).
+		Where(condition, args...).
 		Find(&classes).Error
 	return classes, err
 }
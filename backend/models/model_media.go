@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+)
+
+// Arten von Mediendateien
+const (
+	MediaKindPortrait = "portrait" // Charakterbild
+)
+
+// MediaFile ist eine gespeicherte Bilddatei mit Vorschaubild
+// Die Binärdaten werden nur beim Ausliefern geladen und nie als JSON ausgegeben.
+type MediaFile struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	CharacterID   uint      `gorm:"index" json:"character_id"`
+	UserID        uint      `gorm:"index" json:"user_id"` // hochladender Benutzer
+	Kind          string    `gorm:"size:20;index" json:"kind"`
+	MimeType      string    `gorm:"size:50" json:"mime_type"`
+	Size          int       `json:"size"` // Bytes des Originals
+	Width         int       `json:"width"`
+	Height        int       `json:"height"`
+	Checksum      string    `gorm:"size:64" json:"checksum"` // SHA-256 des Originals, dient als ETag
+	Data          []byte    `json:"-"`
+	ThumbMimeType string    `gorm:"size:50" json:"thumb_mime_type"`
+	Thumbnail     []byte    `json:"-"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (object *MediaFile) TableName() string {
+	dbPrefix := "media"
+	return dbPrefix + "_" + "files"
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"time"
+)
+
+// Status eines Joblaufs
+const (
+	JobRunStatusRunning = "running"
+	JobRunStatusSuccess = "success"
+	JobRunStatusError   = "error"
+)
+
+// Auslöser eines Joblaufs
+const (
+	JobTriggerSchedule = "schedule"
+	JobTriggerManual   = "manual"
+)
+
+// SchedulerJobRun protokolliert einen Lauf eines Hintergrundjobs
+type SchedulerJobRun struct {
+	ID          uint       `gorm:"primaryKey" json:"id"`
+	JobName     string     `gorm:"index;size:100;not null" json:"job_name"`
+	Trigger     string     `gorm:"size:20" json:"trigger"` // schedule, manual
+	TriggeredBy uint       `json:"triggered_by,omitempty"` // Benutzer bei manuellem Start
+	Status      string     `gorm:"size:20;index" json:"status"`
+	Message     string     `gorm:"type:text" json:"message,omitempty"`
+	Error       string     `gorm:"type:text" json:"error,omitempty"`
+	StartedAt   time.Time  `gorm:"index" json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at,omitempty"`
+	DurationMs  int64      `json:"duration_ms"`
+}
+
+// SchedulerJobState speichert den Pausenzustand eines Jobs über Neustarts hinweg
+type SchedulerJobState struct {
+	JobName   string    `gorm:"primaryKey;size:100" json:"job_name"`
+	Paused    bool      `json:"paused"`
+	UpdatedBy uint      `json:"updated_by,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (object *SchedulerJobRun) TableName() string {
+	dbPrefix := "sys"
+	return dbPrefix + "_" + "job_runs"
+}
+
+func (object *SchedulerJobState) TableName() string {
+	dbPrefix := "sys"
+	return dbPrefix + "_" + "job_states"
+}
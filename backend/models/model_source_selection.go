@@ -0,0 +1,164 @@
+package models
+
+import (
+	"bamort/database"
+
+	"gorm.io/gorm"
+)
+
+const (
+	SourceOriginCampaign = "campaign"
+	SourceOriginUser     = "user"
+	SourceOriginGlobal   = "global"
+)
+
+// SourceSelection ist ein Quellenbuch, das eine Kampagne oder ein Benutzer aktiviert hat
+// Die Auswahl gilt je Spielsystem: Hat die Kampagne bzw. der Benutzer für ein Spielsystem
+// kein Werk gewählt, gilt dort weiterhin das globale Source.IsActive.
+type SourceSelection struct {
+	ID         uint   `gorm:"primaryKey" json:"id"`
+	CampaignID uint   `gorm:"uniqueIndex:idx_source_selection" json:"campaign_id,omitempty"`
+	UserID     uint   `gorm:"uniqueIndex:idx_source_selection" json:"user_id,omitempty"` // nur bei Auswahl ohne Kampagne
+	SourceID   uint   `gorm:"uniqueIndex:idx_source_selection;not null" json:"source_id"`
+	Source     Source `gorm:"foreignKey:SourceID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"source"`
+}
+
+func (object *SourceSelection) TableName() string {
+	dbPrefix := "gsm"
+	return dbPrefix + "_" + "source_selections"
+}
+
+// SourceSelector bestimmt, wessen Quellenauswahl gilt
+// Spielt ein Charakter in einer Kampagne, entscheidet deren Auswahl, sonst die seines Spielers.
+type SourceSelector struct {
+	CampaignID uint
+	UserID     uint
+}
+
+// CharacterSourceSelector liefert die maßgebliche Quellenauswahl eines Charakters
+func CharacterSourceSelector(char *Char) SourceSelector {
+	if char.CampaignID != 0 {
+		return SourceSelector{CampaignID: char.CampaignID}
+	}
+	return SourceSelector{UserID: char.UserID}
+}
+
+// owner liefert Kampagne und Benutzer, unter denen die Auswahl gespeichert ist
+func (sel SourceSelector) owner() (uint, uint) {
+	if sel.CampaignID != 0 {
+		return sel.CampaignID, 0
+	}
+	return 0, sel.UserID
+}
+
+// origin benennt die Herkunft einer eigenen Auswahl
+func (sel SourceSelector) origin() string {
+	if sel.CampaignID != 0 {
+		return SourceOriginCampaign
+	}
+	return SourceOriginUser
+}
+
+// ActiveSources ist die aufgelöste Quellenauswahl für ein Spielsystem
+type ActiveSources struct {
+	IDs    []uint `json:"source_ids"`
+	Origin string `json:"origin"` // campaign, user oder global
+}
+
+// Contains prüft, ob ein Quellenbuch aktiv ist; Datensätze ohne Quelle sind immer verfügbar
+func (active ActiveSources) Contains(sourceID uint) bool {
+	if sourceID == 0 {
+		return true
+	}
+	for _, id := range active.IDs {
+		if id == sourceID {
+			return true
+		}
+	}
+	return false
+}
+
+// Condition liefert die SQL-Bedingung auf die source_id-Spalte der Tabelle
+func (active ActiveSources) Condition(table string) (string, []any) {
+	column := table + ".source_id"
+	if len(active.IDs) == 0 {
+		return "(" + column + " IS NULL OR " + column + " = 0)", nil
+	}
+	return "(" + column + " IS NULL OR " + column + " = 0 OR " + column + " IN ?)", []any{active.IDs}
+}
+
+// sourcesOfGameSystem schränkt Quellen auf ein Spielsystem ein (Name oder ID)
+func sourcesOfGameSystem(db *gorm.DB, gameSystem string) *gorm.DB {
+	gs := GetGameSystem(0, gameSystem)
+	if gs == nil {
+		return db.Where("gsm_lit_sources.game_system = ?", gameSystem)
+	}
+	return db.Where("(gsm_lit_sources.game_system = ? OR gsm_lit_sources.game_system_id = ?)", gs.Name, gs.ID)
+}
+
+// GetSourceSelection liefert die eigene Auswahl einer Kampagne oder eines Benutzers für ein Spielsystem
+// Eine leere Liste bedeutet, dass keine Auswahl getroffen wurde.
+func GetSourceSelection(gameSystem string, sel SourceSelector) ([]Source, error) {
+	campaignID, userID := sel.owner()
+	var sources []Source
+	err := sourcesOfGameSystem(database.DB.Model(&Source{}), gameSystem).
+		Joins("JOIN gsm_source_selections ON gsm_source_selections.source_id = gsm_lit_sources.id").
+		Where("gsm_source_selections.campaign_id = ? AND gsm_source_selections.user_id = ?", campaignID, userID).
+		Order("gsm_lit_sources.is_core DESC, gsm_lit_sources.code ASC").
+		Find(&sources).Error
+	return sources, err
+}
+
+// SetSourceSelection ersetzt die Auswahl einer Kampagne oder eines Benutzers für ein Spielsystem
+// Eine leere Liste setzt auf das globale Source.IsActive zurück.
+func SetSourceSelection(gameSystem string, sel SourceSelector, sourceIDs []uint) error {
+	campaignID, userID := sel.owner()
+	return database.DB.Transaction(func(tx *gorm.DB) error {
+		var existing []uint
+		err := sourcesOfGameSystem(tx.Model(&Source{}), gameSystem).
+			Where("gsm_lit_sources.id IN (?)", tx.Model(&SourceSelection{}).Select("source_id").
+				Where("campaign_id = ? AND user_id = ?", campaignID, userID)).
+			Pluck("gsm_lit_sources.id", &existing).Error
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			err := tx.Where("campaign_id = ? AND user_id = ? AND source_id IN ?", campaignID, userID, existing).
+				Delete(&SourceSelection{}).Error
+			if err != nil {
+				return err
+			}
+		}
+		for _, id := range sourceIDs {
+			if err := tx.Create(&SourceSelection{CampaignID: campaignID, UserID: userID, SourceID: id}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetActiveSources löst die aktiven Quellenbücher eines Spielsystems auf
+// Ohne Selector oder ohne eigene Auswahl gilt das globale Source.IsActive.
+func GetActiveSources(gameSystem string, sel ...SourceSelector) (ActiveSources, error) {
+	if len(sel) > 0 && (sel[0].CampaignID != 0 || sel[0].UserID != 0) {
+		selected, err := GetSourceSelection(gameSystem, sel[0])
+		if err != nil {
+			return ActiveSources{}, err
+		}
+		if len(selected) > 0 {
+			active := ActiveSources{Origin: sel[0].origin()}
+			for _, source := range selected {
+				active.IDs = append(active.IDs, source.ID)
+			}
+			return active, nil
+		}
+	}
+
+	active := ActiveSources{Origin: SourceOriginGlobal}
+	err := sourcesOfGameSystem(database.DB.Model(&Source{}), gameSystem).
+		Where("gsm_lit_sources.is_active = ?", true).
+		Order("gsm_lit_sources.id ASC").
+		Pluck("gsm_lit_sources.id", &active.IDs).Error
+	return active, err
+}
@@ -134,6 +134,16 @@ func ExportCharacterToPDF(c *gin.Context) {
 		allPDFs = append(allPDFs, pdf)
 	}
 
+	// Page 5: Notes, only if the character has public notes
+	if len(viewModel.Notes) > 0 {
+		page5PDFs, err := RenderPageWithContinuations(viewModel, "page_5.html", 5, currentDate, loader, renderer)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render page 5: " + err.Error()})
+			return
+		}
+		allPDFs = append(allPDFs, page5PDFs...)
+	}
+
 	// Merge PDFs if needed
 	var finalPDF []byte
 	if len(allPDFs) == 1 {
@@ -3,8 +3,10 @@ package pdfrender
 import (
 	"fmt"
 
+	"bamort/calendar"
 	"bamort/character"
 	"bamort/database"
+	"bamort/media"
 	"bamort/models"
 )
 
@@ -17,6 +19,7 @@ func MapCharacterToViewModel(char *models.Char) (*CharacterSheetViewModel, error
 		MagicItems:  make([]MagicItemViewModel, 0),
 		Equipment:   make([]EquipmentViewModel, 0),
 		GameResults: make([]GameResultViewModel, 0),
+		Notes:       make([]NoteViewModel, 0),
 	}
 
 	// Map basic character info
@@ -32,7 +35,7 @@ func MapCharacterToViewModel(char *models.Char) (*CharacterSheetViewModel, error
 		Herkunft:    char.Herkunft,
 		Glaube:      char.Glaube,
 		SocialClass: char.SocialClass,
-		IconBase64:  "", // Will be set later if image exists
+		IconBase64:  media.PortraitDataURI(char), // inlined at render time, the sheet has no API access
 		Vermoegen: WealthInfo{
 			Goldstuecke:   char.Vermoegen.Goldstuecke,
 			Silberstuecke: char.Vermoegen.Silberstuecke,
@@ -40,6 +43,15 @@ func MapCharacterToViewModel(char *models.Char) (*CharacterSheetViewModel, error
 		},
 	}
 
+	// Map in-game birth date
+	var calDef *calendar.Definition
+	if _, def, ok := calendar.CharacterCalendar(char); ok {
+		calDef = &def
+		if char.BirthDay != nil {
+			vm.Character.Birthdate = def.Format(*char.BirthDay)
+		}
+	}
+
 	// Map attributes
 	vm.Attributes = mapAttributes(char)
 
@@ -58,9 +70,41 @@ func MapCharacterToViewModel(char *models.Char) (*CharacterSheetViewModel, error
 	// Map equipment
 	vm.Equipment = mapEquipment(char)
 
+	// Map notes
+	vm.Notes = mapNotes(char, calDef)
+
 	return vm, nil
 }
 
+// noteTypeLabels are the headings of the note types on the notes page
+var noteTypeLabels = map[string]string{
+	models.NoteTypeBackstory: "Hintergrund",
+	models.NoteTypeContact:   "Kontakt",
+	models.NoteTypeQuest:     "Auftrag",
+	models.NoteTypeMisc:      "Sonstiges",
+}
+
+// mapNotes loads the public notes of a character
+// Private and GM notes are left out because the sheet may be shared.
+// With a game calendar the in-game date of each note is printed as well.
+func mapNotes(char *models.Char, calDef *calendar.Definition) []NoteViewModel {
+	notes := make([]NoteViewModel, 0)
+	if database.DB == nil || char.ID == 0 {
+		return notes
+	}
+	var charNotes []models.CharNote
+	database.DB.Where("character_id = ? AND visibility = ?", char.ID, models.NoteVisibilityPublic).
+		Order("type ASC, created_at ASC, id ASC").Find(&charNotes)
+	for _, note := range charNotes {
+		entry := NoteViewModel{Type: note.Type, Label: noteTypeLabels[note.Type], Title: note.Title, Text: note.Text}
+		if calDef != nil && note.GameDay != nil {
+			entry.Date = calDef.Format(*note.GameDay)
+		}
+		notes = append(notes, entry)
+	}
+	return notes
+}
+
 // mapAttributes extracts attribute values from Eigenschaften slice
 func mapAttributes(char *models.Char) AttributeValues {
 	attrs := AttributeValues{}
@@ -179,24 +223,12 @@ func mapSkills(char *models.Char) []SkillViewModel {
 }
 
 // mapWeapons converts equipped weapons to WeaponViewModel
-// EW = Waffenfertigkeit.Fertigkeitswert + Character.AngriffBonus + Weapon.Anb
+// EW = Waffenfertigkeit.Fertigkeitswert + Character.AngriffBonus + Weapon.Anb (+ specialization bonus)
 func mapWeapons(char *models.Char) []WeaponViewModel {
 	weapons := make([]WeaponViewModel, 0, len(char.Waffen)+1)
 
-	// Calculate character's bonuses using character logic
-	attrs := mapAttributes(char)
-	bonusValues := character.CalculateStaticFieldsLogic(character.CalculateStaticFieldsRequest{
-		St:    attrs.St,
-		Gs:    attrs.Gs,
-		Gw:    attrs.Gw,
-		Ko:    attrs.Ko,
-		In:    attrs.In,
-		Zt:    attrs.Zt,
-		Au:    attrs.Au,
-		Rasse: char.Rasse,
-		Typ:   char.Typ,
-		Grad:  char.Grad,
-	})
+	// Calculate character's bonuses using character logic (shared with the combat helper)
+	bonusValues := character.CalculateStaticFieldsForCharacter(char)
 
 	// Add Raufen as the first weapon
 	raufenDamage := fmt.Sprintf("1W6%+d", bonusValues.SchadensBonus)
@@ -207,14 +239,9 @@ func mapWeapons(char *models.Char) []WeaponViewModel {
 		IsRanged: false,
 	})
 
-	// Create a map of weapon skills for quick lookup
-	weaponSkills := make(map[string]int)
-	for _, skill := range char.Waffenfertigkeiten {
-		weaponSkills[skill.Name] = skill.Fertigkeitswert
-	}
-
 	// Iterate over equipped weapons
-	for _, equippedWeapon := range char.Waffen {
+	for i := range char.Waffen {
+		equippedWeapon := &char.Waffen[i]
 		vm := WeaponViewModel{
 			Name: equippedWeapon.Name,
 		}
@@ -224,15 +251,14 @@ func mapWeapons(char *models.Char) []WeaponViewModel {
 		err := baseWeapon.First(equippedWeapon.Name)
 
 		if err == nil && baseWeapon.ID > 0 {
-			// Calculate attack value: skill + character bonus + weapon bonus
-			skillValue := 0
-			if baseWeapon.SkillRequired != "" {
-				skillValue = weaponSkills[baseWeapon.SkillRequired]
-			}
-			vm.Value = skillValue + bonusValues.AngriffsBonus + equippedWeapon.Anb
+			// Calculate attack value: skill + character bonus + weapon bonus + specialization bonus
+			attack := character.CalculateWeaponAttack(char, equippedWeapon, baseWeapon, bonusValues)
+			vm.Value = attack.Total
+			vm.IsSpecialized = attack.Specialized
+			vm.SpecializationBonus = attack.SpecializationBonus
 
 			// Calculate damage: Base weapon damage + character bonus + weapon damage bonus
-			vm.Damage = calculateWeaponDamageWithBase(baseWeapon, bonusValues.SchadensBonus, equippedWeapon.Schb)
+			vm.Damage = character.FormatWeaponDamage(baseWeapon, bonusValues.SchadensBonus, equippedWeapon.Schb)
 
 			// Add range information for ranged weapons
 			if baseWeapon.IsRanged() {
@@ -244,7 +270,10 @@ func mapWeapons(char *models.Char) []WeaponViewModel {
 			}
 		} else {
 			// Weapon not found in gsm_weapons, use basic info
-			vm.Value = bonusValues.AngriffsBonus + equippedWeapon.Anb
+			attack := character.CalculateWeaponAttack(char, equippedWeapon, nil, bonusValues)
+			vm.Value = attack.Total
+			vm.IsSpecialized = attack.Specialized
+			vm.SpecializationBonus = attack.SpecializationBonus
 		}
 
 		weapons = append(weapons, vm)
@@ -352,28 +381,6 @@ func calculateAttributeBonus(value int) int {
 	return 0
 }
 
-// calculateWeaponDamageWithBase calculates the total damage string using an already-loaded weapon
-// Format: BaseDamage+TotalBonus, e.g., "1W6+3"
-// TotalBonus = Character's SchadenBonus + Weapon's Schadensbonus (Schb)
-func calculateWeaponDamageWithBase(baseWeapon *models.Weapon, schadenBonus int, weaponSchb int) string {
-	baseDamage := baseWeapon.Damage
-	if baseDamage == "" {
-		return ""
-	}
-
-	// Calculate total damage bonus
-	totalBonus := schadenBonus + weaponSchb
-
-	// Format the damage string
-	if totalBonus > 0 {
-		return fmt.Sprintf("%s+%d", baseDamage, totalBonus)
-	} else if totalBonus < 0 {
-		return fmt.Sprintf("%s%d", baseDamage, totalBonus)
-	}
-
-	return baseDamage
-}
-
 // calculateWeaponDamage calculates the total damage string for a weapon
 // Format: BaseDamage+TotalBonus, e.g., "1W6+3"
 // TotalBonus = Character's SchadenBonus + Weapon's Schadensbonus (Schb)
@@ -385,7 +392,7 @@ func calculateWeaponDamage(weaponName string, schadenBonus int, weaponSchb int)
 		masterWeapon := &models.Weapon{}
 		err := masterWeapon.First(weaponName)
 		if err == nil && masterWeapon.ID > 0 && masterWeapon.Damage != "" {
-			return calculateWeaponDamageWithBase(masterWeapon, schadenBonus, weaponSchb)
+			return character.FormatWeaponDamage(masterWeapon, schadenBonus, weaponSchb)
 		}
 	}
 
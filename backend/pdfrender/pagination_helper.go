@@ -153,6 +153,9 @@ func PreparePaginatedPageData(viewModel *CharacterSheetViewModel, templateName s
 		// The template has complex logic showing containers on left, worn items and container sections on right
 		// Don't truncate based on capacity - let the template handle all items
 		pageData.Equipment = viewModel.Equipment
+	} else if templateName == "page_5.html" {
+		// The notes are free text of any length; the browser breaks the page itself
+		pageData.Notes = viewModel.Notes
 	}
 
 	return pageData, nil
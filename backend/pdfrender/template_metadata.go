@@ -59,6 +59,7 @@ func LoadTemplateSetFromFiles(templateDir string) (TemplateSet, error) {
 		{"page_3.2.html", "spell", "Fortsetzung Zauberseite"},
 		{"page_4.html", "equip", "Ausrüstungsseite"},
 		{"page_4.2.html", "equip", "Fortsetzung Ausrüstungsseite"},
+		{"page_5.html", "notes", "Notizen"},
 	}
 
 	// Load each template file and parse its metadata
@@ -214,6 +215,14 @@ func getHardcodedTemplateSet() TemplateSet {
 				},
 				Path: "templates/Default_A4_Quer/page_4.html",
 			},
+			{
+				Metadata: TemplateMetadata{
+					Name:        "page_5.html",
+					PageType:    "notes",
+					Description: "Notizen",
+				},
+				Path: "templates/Default_A4_Quer/page_5.html",
+			},
 		},
 	}
 }
@@ -166,6 +166,46 @@ func TestRenderTemplate_WithSkills(t *testing.T) {
 	}
 }
 
+func TestRenderTemplate_WithNotes(t *testing.T) {
+	// Arrange
+	loader := NewTemplateLoader("../templates/Default_A4_Quer")
+	err := loader.LoadTemplates()
+	if err != nil {
+		t.Fatalf("Failed to load templates: %v", err)
+	}
+
+	viewModel := &CharacterSheetViewModel{
+		Character: CharacterInfo{Name: "Test"},
+		Notes: []NoteViewModel{
+			{Type: "contact", Label: "Kontakt", Title: "Wirt Gundolf", Text: "Schuldet uns **20 GS**", Date: "3. Tag"},
+		},
+	}
+
+	// Act
+	distributions, err := NewPaginator(DefaultA4QuerTemplateSet()).PaginateMultiList(map[string]interface{}{}, "page_5.html")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	data, err := PreparePaginatedPageData(viewModel, "page_5.html", 5, "18.12.2025")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	html, err := loader.RenderTemplate("page_5.html", data)
+
+	// Assert
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if len(distributions) != 0 {
+		t.Errorf("Expected the notes page to be rendered as a single page, got %d distributions", len(distributions))
+	}
+	for _, expected := range []string{"Kontakt: Wirt Gundolf", "Schuldet uns **20 GS**", "3. Tag"} {
+		if !strings.Contains(html, expected) {
+			t.Errorf("Expected HTML to contain %q", expected)
+		}
+	}
+}
+
 func TestLoadTemplate_InvalidPath(t *testing.T) {
 	// Arrange
 	loader := NewTemplateLoader("/invalid/path")
@@ -13,6 +13,7 @@ type CharacterSheetViewModel struct {
 	MagicItems    []MagicItemViewModel
 	Equipment     []EquipmentViewModel
 	GameResults   []GameResultViewModel
+	Notes         []NoteViewModel
 	Meta          PageMeta
 }
 
@@ -22,7 +23,7 @@ type CharacterInfo struct {
 	Player      string
 	Type        string // Charaktertyp (z.B. "Krieger", "Magier")
 	Grade       int
-	Birthdate   string
+	Birthdate   string // Geburtsdatum im Spielkalender, leer ohne Kalender
 	Age         int
 	Hand        string // "rechts." oder "links." händig
 	Height      int    // in cm
@@ -124,6 +125,9 @@ type WeaponViewModel struct {
 	Notes      string // Besondere Eigenschaften
 	IsRanged   bool   // Fernkampfwaffe ja/nein
 	IsMagical  bool   // Magische Waffe ja/nein
+	// Spezialwaffe ja/nein, der Zuschlag ist in Value enthalten
+	IsSpecialized       bool
+	SpecializationBonus int
 }
 
 // SpellViewModel represents a spell for display
@@ -177,6 +181,15 @@ type GameResultViewModel struct {
 	Notes       string
 }
 
+// NoteViewModel represents a public character note (backstory, contact, quest)
+type NoteViewModel struct {
+	Type  string // backstory, contact, quest, misc
+	Label string // Bezeichnung der Art für den Bogen
+	Title string
+	Text  string // Markdown
+	Date  string // Spieltag im Spielkalender, leer ohne Kalender
+}
+
 // PageMeta contains metadata about the current page
 type PageMeta struct {
 	Date           string
@@ -209,6 +222,7 @@ type PageData struct {
 	MagicItems     []MagicItemViewModel
 	Equipment      []EquipmentViewModel
 	GameResults    []GameResultViewModel
+	Notes          []NoteViewModel // Public notes (page_5)
 
 	Meta PageMeta
 }
@@ -0,0 +1,168 @@
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule berechnet den nächsten Ausführungszeitpunkt eines Jobs
+type Schedule interface {
+	Next(after time.Time) time.Time
+}
+
+// everySchedule führt einen Job in festen Abständen aus ("@every 10m")
+type everySchedule struct {
+	interval time.Duration
+}
+
+func (s everySchedule) Next(after time.Time) time.Time {
+	return after.Add(s.interval)
+}
+
+// cronSchedule bildet einen fünfteiligen Cron-Ausdruck ab (Minute Stunde Tag Monat Wochentag)
+type cronSchedule struct {
+	minutes  map[int]bool
+	hours    map[int]bool
+	days     map[int]bool
+	months   map[int]bool
+	weekdays map[int]bool
+	// Sind Tag und Wochentag beide eingeschränkt, genügt es, wenn einer passt (wie bei cron)
+	dayRestricted     bool
+	weekdayRestricted bool
+}
+
+var scheduleAliases = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// ParseSchedule liest einen Cron-Ausdruck mit fünf Feldern (*, */n, a-b, a-b/n, Listen)
+// sowie die Kurzformen @hourly, @daily, @weekly, @monthly und "@every <Dauer>".
+func ParseSchedule(expr string) (Schedule, error) {
+	expr = strings.TrimSpace(expr)
+	if rest, ok := strings.CutPrefix(expr, "@every "); ok {
+		interval, err := time.ParseDuration(strings.TrimSpace(rest))
+		if err != nil || interval < time.Second {
+			return nil, fmt.Errorf("ungültiges Intervall: %s", rest)
+		}
+		return everySchedule{interval: interval}, nil
+	}
+	if alias, ok := scheduleAliases[expr]; ok {
+		expr = alias
+	}
+
+	fields := strings.Fields(expr)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron-Ausdruck benötigt 5 Felder: %q", expr)
+	}
+
+	bounds := [][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	sets := make([]map[int]bool, 5)
+	for i, field := range fields {
+		set, err := parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("feld %d (%s): %w", i+1, field, err)
+		}
+		sets[i] = set
+	}
+	// Sonntag darf als 0 oder 7 angegeben werden
+	if sets[4][7] {
+		sets[4][0] = true
+		delete(sets[4], 7)
+	}
+
+	return &cronSchedule{
+		minutes:           sets[0],
+		hours:             sets[1],
+		days:              sets[2],
+		months:            sets[3],
+		weekdays:          sets[4],
+		dayRestricted:     fields[2] != "*",
+		weekdayRestricted: fields[4] != "*",
+	}, nil
+}
+
+func parseCronField(field string, low, high int) (map[int]bool, error) {
+	set := map[int]bool{}
+	for _, part := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepPart)
+			if err != nil || n <= 0 {
+				return nil, fmt.Errorf("ungültige Schrittweite %q", stepPart)
+			}
+			step = n
+		}
+
+		start, end := low, high
+		switch {
+		case rangePart == "*":
+		case strings.Contains(rangePart, "-"):
+			a, b, _ := strings.Cut(rangePart, "-")
+			var errA, errB error
+			start, errA = strconv.Atoi(a)
+			end, errB = strconv.Atoi(b)
+			if errA != nil || errB != nil {
+				return nil, fmt.Errorf("ungültiger Bereich %q", rangePart)
+			}
+		default:
+			n, err := strconv.Atoi(rangePart)
+			if err != nil {
+				return nil, fmt.Errorf("ungültiger Wert %q", rangePart)
+			}
+			start = n
+			end = n
+			if hasStep {
+				end = high
+			}
+		}
+		if start < low || end > high || start > end {
+			return nil, fmt.Errorf("wert außerhalb von %d-%d", low, high)
+		}
+		for v := start; v <= end; v += step {
+			set[v] = true
+		}
+	}
+	return set, nil
+}
+
+func (s *cronSchedule) dayMatches(t time.Time) bool {
+	day := s.days[t.Day()]
+	weekday := s.weekdays[int(t.Weekday())]
+	if s.dayRestricted && s.weekdayRestricted {
+		return day || weekday
+	}
+	return day && weekday
+}
+
+// Next liefert den ersten passenden Minutenzeitpunkt nach after
+func (s *cronSchedule) Next(after time.Time) time.Time {
+	t := after.Truncate(time.Minute).Add(time.Minute)
+	// Mehr als fünf Jahre Suche bedeutet einen nie zutreffenden Ausdruck (z.B. 31. Februar)
+	limit := t.AddDate(5, 0, 0)
+	for t.Before(limit) {
+		if !s.months[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.hours[t.Hour()] {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, t.Location())
+			continue
+		}
+		if !s.minutes[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
@@ -0,0 +1,40 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSchedule(t *testing.T) {
+	base := time.Date(2024, time.March, 15, 10, 7, 30, 0, time.UTC) // Freitag
+
+	tests := []struct {
+		expr string
+		want time.Time
+	}{
+		{"*/15 * * * *", time.Date(2024, time.March, 15, 10, 15, 0, 0, time.UTC)},
+		{"0 * * * *", time.Date(2024, time.March, 15, 11, 0, 0, 0, time.UTC)},
+		{"30 3 * * *", time.Date(2024, time.March, 16, 3, 30, 0, 0, time.UTC)},
+		{"0 9-17/4 * * *", time.Date(2024, time.March, 15, 13, 0, 0, 0, time.UTC)},
+		{"0 0 * * 7", time.Date(2024, time.March, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1 * 1", time.Date(2024, time.March, 18, 0, 0, 0, 0, time.UTC)},
+		{"5,10 10 * * *", time.Date(2024, time.March, 15, 10, 10, 0, 0, time.UTC)},
+		{"@daily", time.Date(2024, time.March, 16, 0, 0, 0, 0, time.UTC)},
+		{"@every 10m", base.Add(10 * time.Minute)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			schedule, err := ParseSchedule(tt.expr)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, schedule.Next(base))
+		})
+	}
+
+	for _, invalid := range []string{"", "* * * *", "60 * * * *", "*/0 * * * *", "5-1 * * * *", "@every 1ms", "a * * * *"} {
+		_, err := ParseSchedule(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
@@ -0,0 +1,84 @@
+package scheduler
+
+import (
+	"bamort/logger"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+func respondWithError(c *gin.Context, status int, message string) {
+	logger.Warn("HTTP Fehler %d: %s", status, message)
+	c.JSON(status, gin.H{"error": message})
+}
+
+// ListJobs liefert alle Hintergrundjobs mit Zeitplan, Zustand und letztem Lauf
+func ListJobs(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"jobs": Default.Jobs()})
+}
+
+// GetJobRuns liefert die Laufhistorie eines Jobs (?limit=, Standard 20)
+func GetJobRuns(c *gin.Context) {
+	name := c.Param("name")
+	if !Default.HasJob(name) {
+		respondWithError(c, http.StatusNotFound, "Job nicht gefunden")
+		return
+	}
+	limit := 20
+	if value := c.Query("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed <= 0 {
+			respondWithError(c, http.StatusBadRequest, "Ungültiges Limit")
+			return
+		}
+		limit = min(parsed, 500)
+	}
+
+	runs, err := Default.Runs(name, limit)
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Laufhistorie konnte nicht geladen werden")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"job": name, "runs": runs})
+}
+
+// TriggerJob führt einen Job sofort aus und liefert das Ergebnis des Laufs
+func TriggerJob(c *gin.Context) {
+	run, err := Default.Trigger(c.Request.Context(), c.Param("name"), c.GetUint("userID"))
+	switch {
+	case errors.Is(err, ErrUnknownJob):
+		respondWithError(c, http.StatusNotFound, "Job nicht gefunden")
+	case errors.Is(err, ErrJobRunning):
+		respondWithError(c, http.StatusConflict, "Job läuft bereits")
+	case err != nil:
+		respondWithError(c, http.StatusInternalServerError, err.Error())
+	default:
+		c.JSON(http.StatusOK, run)
+	}
+}
+
+// PauseJob verhindert weitere geplante Läufe eines Jobs
+func PauseJob(c *gin.Context) {
+	setJobPaused(c, true)
+}
+
+// ResumeJob nimmt geplante Läufe eines Jobs wieder auf
+func ResumeJob(c *gin.Context) {
+	setJobPaused(c, false)
+}
+
+func setJobPaused(c *gin.Context, paused bool) {
+	name := c.Param("name")
+	err := Default.SetPaused(name, paused, c.GetUint("userID"))
+	if errors.Is(err, ErrUnknownJob) {
+		respondWithError(c, http.StatusNotFound, "Job nicht gefunden")
+		return
+	}
+	if err != nil {
+		respondWithError(c, http.StatusInternalServerError, "Jobzustand konnte nicht gespeichert werden")
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"job": name, "paused": paused})
+}
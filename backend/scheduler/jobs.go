@@ -0,0 +1,108 @@
+package scheduler
+
+import (
+	"bamort/config"
+	"bamort/database"
+	"bamort/models"
+	"bamort/pdfrender"
+	"bamort/transfer"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// Namen der Standardjobs
+const (
+	JobExpireCreationSessions = "expire-creation-sessions"
+	JobCleanupPDFTemp         = "cleanup-pdf-temp"
+	JobRotateBackups          = "rotate-backups"
+)
+
+// pdfTempMaxAge entspricht der Aufbewahrungsdauer von CleanupExportTemp
+const pdfTempMaxAge = 7 * 24 * time.Hour
+
+// RegisterDefaultJobs registriert die Aufräumjobs des Servers
+func RegisterDefaultJobs(s *Scheduler) error {
+	jobs := []Job{
+		{
+			Name:        JobExpireCreationSessions,
+			Description: "Abgelaufene Charaktererstellungs-Sessions löschen",
+			Schedule:    "*/15 * * * *",
+			Run:         expireCreationSessions,
+		},
+		{
+			Name:        JobCleanupPDFTemp,
+			Description: "Temporäre PDF-Exporte älter als 7 Tage löschen",
+			Schedule:    "0 * * * *",
+			Run:         cleanupPDFTemp,
+		},
+		{
+			Name:        JobRotateBackups,
+			Description: "Datenbank-Backup erstellen und alte Backups entfernen",
+			Schedule:    "30 3 * * *",
+			Run:         rotateBackups,
+		},
+	}
+	for _, job := range jobs {
+		if err := s.Register(job); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func expireCreationSessions(ctx context.Context) (string, error) {
+	result := database.DB.Where("expires_at < ?", time.Now()).Delete(&models.CharacterCreationSession{})
+	if result.Error != nil {
+		return "", result.Error
+	}
+	return fmt.Sprintf("%d abgelaufene Sessions gelöscht", result.RowsAffected), nil
+}
+
+func cleanupPDFTemp(ctx context.Context) (string, error) {
+	count, err := pdfrender.CleanupOldFiles(config.Cfg.ExportTempDir, pdfTempMaxAge)
+	if os.IsNotExist(err) {
+		return "Exportverzeichnis nicht vorhanden", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d temporäre Dateien gelöscht", count), nil
+}
+
+func rotateBackups(ctx context.Context) (string, error) {
+	result, err := transfer.ExportDatabase(config.Cfg.BackupDir)
+	if err != nil {
+		return "", err
+	}
+	removed, err := pruneBackups(config.Cfg.BackupDir, config.Cfg.BackupKeep)
+	if err != nil {
+		return "", fmt.Errorf("backup %s erstellt, Aufräumen fehlgeschlagen: %w", result.Filename, err)
+	}
+	return fmt.Sprintf("Backup %s mit %d Datensätzen erstellt, %d alte Backups entfernt", result.Filename, result.RecordCount, removed), nil
+}
+
+// pruneBackups behält die neuesten keep Datenbankexporte im Verzeichnis
+// Die Dateinamen enthalten einen Zeitstempel, daher genügt eine Sortierung nach Namen.
+func pruneBackups(dir string, keep int) (int, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "database_export_*.json"))
+	if err != nil {
+		return 0, err
+	}
+	if len(files) <= keep {
+		return 0, nil
+	}
+	sort.Strings(files)
+
+	removed := 0
+	for _, file := range files[:len(files)-keep] {
+		if err := os.Remove(file); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
@@ -0,0 +1,17 @@
+package scheduler
+
+import (
+	"bamort/user"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterRoutes(r *gin.RouterGroup) {
+	jobGrp := r.Group("/admin/jobs")
+	jobGrp.Use(user.RequireAdmin())
+	jobGrp.GET("", ListJobs)                  // Alle Jobs mit Zeitplan, Pausenzustand, nächstem und letztem Lauf
+	jobGrp.GET("/:name/runs", GetJobRuns)     // Laufhistorie (?limit=...)
+	jobGrp.POST("/:name/trigger", TriggerJob) // Job sofort ausführen
+	jobGrp.POST("/:name/pause", PauseJob)     // Geplante Läufe aussetzen
+	jobGrp.POST("/:name/resume", ResumeJob)   // Geplante Läufe fortsetzen
+}
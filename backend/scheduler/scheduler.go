@@ -0,0 +1,312 @@
+package scheduler
+
+import (
+	"bamort/database"
+	"bamort/logger"
+	"bamort/models"
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrUnknownJob = errors.New("unbekannter Job")
+	ErrJobRunning = errors.New("job läuft bereits")
+)
+
+// Job beschreibt eine regelmäßig auszuführende Aufgabe
+// Run liefert eine kurze Zusammenfassung für die Laufhistorie.
+type Job struct {
+	Name        string
+	Description string
+	Schedule    string
+	Run         func(ctx context.Context) (string, error)
+}
+
+// JobStatus ist die Sicht auf einen Job für die Admin-API
+type JobStatus struct {
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	Schedule    string                  `json:"schedule"`
+	Paused      bool                    `json:"paused"`
+	Running     bool                    `json:"running"`
+	NextRun     *time.Time              `json:"next_run,omitempty"`
+	LastRun     *models.SchedulerJobRun `json:"last_run,omitempty"`
+}
+
+type jobEntry struct {
+	job      Job
+	schedule Schedule
+	next     time.Time
+	paused   bool
+	running  bool
+}
+
+// Scheduler führt registrierte Jobs im Serverprozess nach ihrem Zeitplan aus
+type Scheduler struct {
+	mu      sync.Mutex
+	jobs    map[string]*jobEntry
+	tick    time.Duration
+	now     func() time.Time
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+// Default ist der vom Server verwendete Scheduler
+var Default = New()
+
+// New erstellt einen leeren Scheduler, der alle 30 Sekunden fällige Jobs prüft
+func New() *Scheduler {
+	return &Scheduler{
+		jobs: map[string]*jobEntry{},
+		tick: 30 * time.Second,
+		now:  time.Now,
+	}
+}
+
+// Register fügt einen Job hinzu; der Zeitplan wird sofort geprüft
+func (s *Scheduler) Register(job Job) error {
+	if job.Name == "" || job.Run == nil {
+		return fmt.Errorf("job benötigt Name und Funktion")
+	}
+	schedule, err := ParseSchedule(job.Schedule)
+	if err != nil {
+		return fmt.Errorf("job %s: %w", job.Name, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, exists := s.jobs[job.Name]; exists {
+		return fmt.Errorf("job %s ist bereits registriert", job.Name)
+	}
+	s.jobs[job.Name] = &jobEntry{job: job, schedule: schedule, next: schedule.Next(s.now())}
+	return nil
+}
+
+// Start lädt gespeicherte Pausenzustände und startet die Zeitplanprüfung im Hintergrund
+func (s *Scheduler) Start(ctx context.Context) {
+	s.loadStates()
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.mu.Lock()
+	s.cancel = cancel
+	s.mu.Unlock()
+
+	s.running.Add(1)
+	go func() {
+		defer s.running.Done()
+		ticker := time.NewTicker(s.tick)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.runDue(ctx)
+			}
+		}
+	}()
+	logger.Info("Scheduler gestartet mit %d Jobs", len(s.jobs))
+}
+
+// Stop beendet die Zeitplanprüfung und wartet auf laufende Jobs
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	cancel := s.cancel
+	s.cancel = nil
+	s.mu.Unlock()
+	if cancel != nil {
+		cancel()
+	}
+	s.running.Wait()
+}
+
+func (s *Scheduler) loadStates() {
+	if database.DB == nil {
+		return
+	}
+	var states []models.SchedulerJobState
+	if err := database.DB.Find(&states).Error; err != nil {
+		logger.Warn("Scheduler: Jobzustände konnten nicht geladen werden: %s", err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, state := range states {
+		if entry, ok := s.jobs[state.JobName]; ok {
+			entry.paused = state.Paused
+		}
+	}
+}
+
+// runDue startet alle fälligen, nicht pausierten und nicht laufenden Jobs
+func (s *Scheduler) runDue(ctx context.Context) {
+	now := s.now()
+	s.mu.Lock()
+	var due []*jobEntry
+	for _, entry := range s.jobs {
+		if entry.next.IsZero() || now.Before(entry.next) {
+			continue
+		}
+		entry.next = entry.schedule.Next(now)
+		if entry.paused || entry.running {
+			continue
+		}
+		entry.running = true
+		due = append(due, entry)
+	}
+	s.mu.Unlock()
+
+	for _, entry := range due {
+		s.running.Add(1)
+		go func(entry *jobEntry) {
+			defer s.running.Done()
+			s.execute(ctx, entry, models.JobTriggerSchedule, 0)
+		}(entry)
+	}
+}
+
+// Trigger führt einen Job sofort aus und liefert den protokollierten Lauf
+func (s *Scheduler) Trigger(ctx context.Context, name string, userID uint) (*models.SchedulerJobRun, error) {
+	s.mu.Lock()
+	entry, ok := s.jobs[name]
+	if !ok {
+		s.mu.Unlock()
+		return nil, ErrUnknownJob
+	}
+	if entry.running {
+		s.mu.Unlock()
+		return nil, ErrJobRunning
+	}
+	entry.running = true
+	s.mu.Unlock()
+
+	return s.execute(ctx, entry, models.JobTriggerManual, userID), nil
+}
+
+// execute führt den Job aus und schreibt Beginn und Ergebnis in die Laufhistorie
+func (s *Scheduler) execute(ctx context.Context, entry *jobEntry, trigger string, userID uint) *models.SchedulerJobRun {
+	defer func() {
+		s.mu.Lock()
+		entry.running = false
+		s.mu.Unlock()
+	}()
+
+	run := &models.SchedulerJobRun{
+		JobName:     entry.job.Name,
+		Trigger:     trigger,
+		TriggeredBy: userID,
+		Status:      models.JobRunStatusRunning,
+		StartedAt:   s.now(),
+	}
+	if database.DB != nil {
+		if err := database.DB.Create(run).Error; err != nil {
+			logger.Warn("Scheduler: Lauf von %s konnte nicht protokolliert werden: %s", entry.job.Name, err.Error())
+		}
+	}
+
+	message, err := safeRun(ctx, entry.job)
+
+	finished := s.now()
+	run.FinishedAt = &finished
+	run.DurationMs = finished.Sub(run.StartedAt).Milliseconds()
+	run.Message = message
+	if err != nil {
+		run.Status = models.JobRunStatusError
+		run.Error = err.Error()
+		logger.Error("Scheduler: Job %s fehlgeschlagen: %s", entry.job.Name, err.Error())
+	} else {
+		run.Status = models.JobRunStatusSuccess
+		logger.Info("Scheduler: Job %s erfolgreich (%d ms): %s", entry.job.Name, run.DurationMs, message)
+	}
+	if database.DB != nil && run.ID != 0 {
+		if err := database.DB.Save(run).Error; err != nil {
+			logger.Warn("Scheduler: Ergebnis von %s konnte nicht gespeichert werden: %s", entry.job.Name, err.Error())
+		}
+	}
+	return run
+}
+
+// safeRun fängt Panics eines Jobs ab, damit der Scheduler weiterläuft
+func safeRun(ctx context.Context, job Job) (message string, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	return job.Run(ctx)
+}
+
+// SetPaused pausiert einen Job oder setzt ihn fort; der Zustand bleibt über Neustarts erhalten
+func (s *Scheduler) SetPaused(name string, paused bool, userID uint) error {
+	s.mu.Lock()
+	entry, ok := s.jobs[name]
+	if ok {
+		entry.paused = paused
+	}
+	s.mu.Unlock()
+	if !ok {
+		return ErrUnknownJob
+	}
+	if database.DB == nil {
+		return nil
+	}
+
+	state := models.SchedulerJobState{JobName: name, Paused: paused, UpdatedBy: userID, UpdatedAt: s.now()}
+	return database.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "job_name"}},
+		DoUpdates: clause.AssignmentColumns([]string{"paused", "updated_by", "updated_at"}),
+	}).Create(&state).Error
+}
+
+// Jobs liefert alle registrierten Jobs, sortiert nach Namen, mit ihrem letzten Lauf
+func (s *Scheduler) Jobs() []JobStatus {
+	s.mu.Lock()
+	statuses := make([]JobStatus, 0, len(s.jobs))
+	for _, entry := range s.jobs {
+		status := JobStatus{
+			Name:        entry.job.Name,
+			Description: entry.job.Description,
+			Schedule:    entry.job.Schedule,
+			Paused:      entry.paused,
+			Running:     entry.running,
+		}
+		if !entry.next.IsZero() {
+			next := entry.next
+			status.NextRun = &next
+		}
+		statuses = append(statuses, status)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	for i := range statuses {
+		if runs, err := s.Runs(statuses[i].Name, 1); err == nil && len(runs) > 0 {
+			statuses[i].LastRun = &runs[0]
+		}
+	}
+	return statuses
+}
+
+// HasJob prüft, ob ein Job registriert ist
+func (s *Scheduler) HasJob(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.jobs[name]
+	return ok
+}
+
+// Runs liefert die letzten Läufe eines Jobs, neueste zuerst
+func (s *Scheduler) Runs(name string, limit int) ([]models.SchedulerJobRun, error) {
+	var runs []models.SchedulerJobRun
+	if database.DB == nil {
+		return runs, nil
+	}
+	err := database.DB.Where("job_name = ?", name).Order("started_at DESC, id DESC").Limit(limit).Find(&runs).Error
+	return runs, err
+}
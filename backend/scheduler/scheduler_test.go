@@ -0,0 +1,165 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"bamort/database"
+	"bamort/models"
+	"bamort/testutils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setupSchedulerTest(t *testing.T) {
+	testutils.SetupTestEnvironment(t)
+	database.SetupTestDB(true, true)
+	t.Cleanup(database.ResetTestDB)
+	require.NoError(t, models.MigrateStructure())
+}
+
+func TestTriggerRecordsRunHistory(t *testing.T) {
+	setupSchedulerTest(t)
+
+	s := New()
+	require.NoError(t, s.Register(Job{Name: "ok", Schedule: "@hourly", Run: func(ctx context.Context) (string, error) {
+		return "erledigt", nil
+	}}))
+	require.NoError(t, s.Register(Job{Name: "broken", Schedule: "@hourly", Run: func(ctx context.Context) (string, error) {
+		return "", errors.New("kaputt")
+	}}))
+	require.NoError(t, s.Register(Job{Name: "panics", Schedule: "@hourly", Run: func(ctx context.Context) (string, error) {
+		panic("boom")
+	}}))
+	assert.Error(t, s.Register(Job{Name: "ok", Schedule: "@hourly", Run: func(ctx context.Context) (string, error) { return "", nil }}), "duplicate names are rejected")
+
+	run, err := s.Trigger(context.Background(), "ok", 7)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobRunStatusSuccess, run.Status)
+	assert.Equal(t, models.JobTriggerManual, run.Trigger)
+	assert.Equal(t, uint(7), run.TriggeredBy)
+	assert.Equal(t, "erledigt", run.Message)
+	require.NotNil(t, run.FinishedAt)
+
+	run, err = s.Trigger(context.Background(), "broken", 7)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobRunStatusError, run.Status)
+	assert.Equal(t, "kaputt", run.Error)
+
+	run, err = s.Trigger(context.Background(), "panics", 7)
+	require.NoError(t, err)
+	assert.Equal(t, models.JobRunStatusError, run.Status)
+	assert.Contains(t, run.Error, "boom")
+
+	_, err = s.Trigger(context.Background(), "missing", 7)
+	assert.ErrorIs(t, err, ErrUnknownJob)
+
+	runs, err := s.Runs("broken", 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, "kaputt", runs[0].Error)
+
+	statuses := s.Jobs()
+	require.Len(t, statuses, 3)
+	assert.Equal(t, "broken", statuses[0].Name)
+	require.NotNil(t, statuses[0].LastRun)
+	assert.Equal(t, models.JobRunStatusError, statuses[0].LastRun.Status)
+	assert.NotNil(t, statuses[0].NextRun)
+}
+
+func TestPausedJobsAreSkippedAndPersisted(t *testing.T) {
+	setupSchedulerTest(t)
+
+	var calls atomic.Int32
+	job := Job{Name: "counter", Schedule: "@every 1s", Run: func(ctx context.Context) (string, error) {
+		calls.Add(1)
+		return "", nil
+	}}
+
+	s := New()
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	require.NoError(t, s.Register(job))
+	require.NoError(t, s.SetPaused("counter", true, 1))
+
+	now = now.Add(2 * time.Second)
+	s.runDue(context.Background())
+	s.running.Wait()
+	assert.Equal(t, int32(0), calls.Load(), "paused job is not run")
+
+	// Ein neuer Scheduler übernimmt den gespeicherten Zustand
+	restarted := New()
+	require.NoError(t, restarted.Register(job))
+	restarted.loadStates()
+	assert.True(t, restarted.Jobs()[0].Paused)
+
+	require.NoError(t, s.SetPaused("counter", false, 1))
+	now = now.Add(2 * time.Second)
+	s.runDue(context.Background())
+	s.running.Wait()
+	assert.Equal(t, int32(1), calls.Load())
+
+	runs, err := s.Runs("counter", 10)
+	require.NoError(t, err)
+	require.Len(t, runs, 1)
+	assert.Equal(t, models.JobTriggerSchedule, runs[0].Trigger)
+
+	assert.ErrorIs(t, s.SetPaused("missing", true, 1), ErrUnknownJob)
+}
+
+func TestStartRunsDueJobs(t *testing.T) {
+	setupSchedulerTest(t)
+
+	done := make(chan struct{}, 1)
+	s := New()
+	s.tick = 10 * time.Millisecond
+	require.NoError(t, s.Register(Job{Name: "tick", Schedule: "@every 1s", Run: func(ctx context.Context) (string, error) {
+		select {
+		case done <- struct{}{}:
+		default:
+		}
+		return "", nil
+	}}))
+
+	s.Start(context.Background())
+	defer s.Stop()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("job was not run by the scheduler")
+	}
+}
+
+func TestPruneBackups(t *testing.T) {
+	dir := t.TempDir()
+	names := []string{
+		"database_export_20240101_030000.json",
+		"database_export_20240102_030000.json",
+		"database_export_20240103_030000.json",
+		"other.json",
+	}
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte("{}"), 0644))
+	}
+
+	removed, err := pruneBackups(dir, 2)
+	require.NoError(t, err)
+	assert.Equal(t, 1, removed)
+	assert.NoFileExists(t, filepath.Join(dir, names[0]))
+	assert.FileExists(t, filepath.Join(dir, names[1]))
+	assert.FileExists(t, filepath.Join(dir, "other.json"), "unrelated files are kept")
+}
+
+func TestRegisterDefaultJobs(t *testing.T) {
+	s := New()
+	require.NoError(t, RegisterDefaultJobs(s))
+	for _, name := range []string{JobExpireCreationSessions, JobCleanupPDFTemp, JobRotateBackups} {
+		assert.True(t, s.HasJob(name), name)
+	}
+}
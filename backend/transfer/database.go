@@ -66,6 +66,45 @@ type DatabaseExport struct {
 	SkillCategoryDifficulties []models.SkillCategoryDifficulty `json:"learning_skill_category_difficulties"`
 	SkillImprovementCosts     []models.SkillImprovementCost    `json:"learning_skill_improvement_costs"`
 	AuditLogEntries           []models.AuditLogEntry           `json:"audit_log_entries"`
+
+	// Master data history and proposals
+	MasterDataChanges          []models.MasterDataChange          `json:"gsm_history"`
+	MasterDataProposals        []models.MasterDataProposal        `json:"gsm_proposals"`
+	MasterDataProposalComments []models.MasterDataProposalComment `json:"gsm_proposal_comments"`
+
+	// Campaigns, calendars and source selections
+	Campaigns        []CampaignExport         `json:"camp_campaigns"`
+	GameCalendars    []models.GameCalendar    `json:"cal_calendars"`
+	SourceSelections []models.SourceSelection `json:"gsm_source_selections"`
+
+	// Character play data
+	DiceRolls        []models.DiceRoll        `json:"char_dice_rolls"`
+	CharNotes        []models.CharNote        `json:"char_notes"`
+	DowntimeEntries  []models.DowntimeEntry   `json:"char_downtime"`
+	AdvancementPlans []models.AdvancementPlan `json:"char_advancement_plans"`
+	CharTemplates    []models.CharTemplate    `json:"char_templates"`
+
+	// Encounters
+	Encounters            []models.Encounter            `json:"enc_encounters"`
+	EncounterParticipants []models.EncounterParticipant `json:"enc_participants"`
+
+	// Media
+	MediaFiles []MediaFileExport `json:"media_files"`
+}
+
+// MediaFileExport contains a media file including its binary data,
+// which models.MediaFile leaves out of its JSON representation
+type MediaFileExport struct {
+	models.MediaFile
+	Data      []byte `json:"data"`
+	Thumbnail []byte `json:"thumbnail"`
+}
+
+// CampaignExport contains a campaign including its join code,
+// which models.Campaign leaves out of its JSON representation
+type CampaignExport struct {
+	models.Campaign
+	JoinCode string `json:"join_code"`
 }
 
 // ExportResult contains information about the export operation
@@ -82,6 +121,30 @@ type ImportResult struct {
 	Timestamp   string `json:"timestamp"`
 }
 
+// recordCount counts the records of all exported tables
+func (export *DatabaseExport) recordCount() int {
+	return len(export.Users) + len(export.Characters) +
+		len(export.Eigenschaften) + len(export.Lps) + len(export.Aps) +
+		len(export.Bs) + len(export.Merkmale) + len(export.Erfahrungsschatze) +
+		len(export.Bennies) + len(export.Vermoegen) +
+		len(export.SkFertigkeiten) + len(export.SkWaffenfertigkeiten) + len(export.SkZauber) +
+		len(export.EqAusruestungen) + len(export.EqWaffen) + len(export.EqContainers) +
+		len(export.GsmSkills) + len(export.GsmWeaponSkills) + len(export.GsmSpells) +
+		len(export.GsmEquipment) + len(export.GsmWeapons) + len(export.GsmContainers) +
+		len(export.GsmTransportations) + len(export.GsmBelieves) +
+		len(export.Sources) + len(export.CharacterClasses) + len(export.SkillCategories) +
+		len(export.SkillDifficulties) + len(export.SpellSchools) +
+		len(export.ClassCategoryEPCosts) + len(export.ClassSpellSchoolEPCosts) +
+		len(export.SpellLevelLECosts) + len(export.SkillCategoryDifficulties) +
+		len(export.SkillImprovementCosts) + len(export.AuditLogEntries) +
+		len(export.CharacterCreationSessions) + len(export.MediaFiles) +
+		len(export.MasterDataChanges) + len(export.MasterDataProposals) + len(export.MasterDataProposalComments) +
+		len(export.Campaigns) + len(export.GameCalendars) + len(export.SourceSelections) +
+		len(export.DiceRolls) + len(export.CharNotes) + len(export.DowntimeEntries) +
+		len(export.AdvancementPlans) + len(export.CharTemplates) +
+		len(export.Encounters) + len(export.EncounterParticipants)
+}
+
 // ExportDatabase exports all database content to a JSON file
 func ExportDatabase(exportDir string) (*ExportResult, error) {
 	// Create export directory if it doesn't exist
@@ -142,22 +205,35 @@ func ExportDatabase(exportDir string) (*ExportResult, error) {
 	database.DB.Find(&export.SkillImprovementCosts)
 	database.DB.Find(&export.AuditLogEntries)
 
+	database.DB.Find(&export.MasterDataChanges)
+	database.DB.Find(&export.MasterDataProposals)
+	database.DB.Find(&export.MasterDataProposalComments)
+
+	var campaigns []models.Campaign
+	database.DB.Find(&campaigns)
+	for _, campaign := range campaigns {
+		export.Campaigns = append(export.Campaigns, CampaignExport{Campaign: campaign, JoinCode: campaign.JoinCode})
+	}
+	database.DB.Find(&export.GameCalendars)
+	database.DB.Find(&export.SourceSelections)
+
+	database.DB.Find(&export.DiceRolls)
+	database.DB.Find(&export.CharNotes)
+	database.DB.Find(&export.DowntimeEntries)
+	database.DB.Find(&export.AdvancementPlans)
+	database.DB.Find(&export.CharTemplates)
+
+	database.DB.Find(&export.Encounters)
+	database.DB.Find(&export.EncounterParticipants)
+
+	var mediaFiles []models.MediaFile
+	database.DB.Find(&mediaFiles)
+	for _, file := range mediaFiles {
+		export.MediaFiles = append(export.MediaFiles, MediaFileExport{MediaFile: file, Data: file.Data, Thumbnail: file.Thumbnail})
+	}
+
 	// Count total records
-	recordCount := len(export.Users) + len(export.Characters) +
-		len(export.Eigenschaften) + len(export.Lps) + len(export.Aps) +
-		len(export.Bs) + len(export.Merkmale) + len(export.Erfahrungsschatze) +
-		len(export.Bennies) + len(export.Vermoegen) +
-		len(export.SkFertigkeiten) + len(export.SkWaffenfertigkeiten) + len(export.SkZauber) +
-		len(export.EqAusruestungen) + len(export.EqWaffen) + len(export.EqContainers) +
-		len(export.GsmSkills) + len(export.GsmWeaponSkills) + len(export.GsmSpells) +
-		len(export.GsmEquipment) + len(export.GsmWeapons) + len(export.GsmContainers) +
-		len(export.GsmTransportations) + len(export.GsmBelieves) +
-		len(export.Sources) + len(export.CharacterClasses) + len(export.SkillCategories) +
-		len(export.SkillDifficulties) + len(export.SpellSchools) +
-		len(export.ClassCategoryEPCosts) + len(export.ClassSpellSchoolEPCosts) +
-		len(export.SpellLevelLECosts) + len(export.SkillCategoryDifficulties) +
-		len(export.SkillImprovementCosts) + len(export.AuditLogEntries) +
-		len(export.CharacterCreationSessions)
+	recordCount := export.recordCount()
 
 	// Generate filename with timestamp
 	filename := fmt.Sprintf("database_export_%s.json", time.Now().Format("20060102_150405"))
@@ -196,8 +272,10 @@ func ImportDatabase(filePath string) (*ImportResult, error) {
 		return nil, fmt.Errorf("failed to unmarshal import data: %w", err)
 	}
 
-	// Import all tables in transaction
-	err = database.DB.Transaction(func(tx *gorm.DB) error {
+	// Import all tables in transaction. Hooks are skipped: the export already
+	// carries the resolved game system fields, and a restore must not record
+	// master data history entries of its own
+	err = database.DB.Session(&gorm.Session{SkipHooks: true}).Transaction(func(tx *gorm.DB) error {
 		// Import users (upsert to handle existing IDs)
 		for _, item := range export.Users {
 			if err := tx.Save(&item).Error; err != nil {
@@ -205,6 +283,16 @@ func ImportDatabase(filePath string) (*ImportResult, error) {
 			}
 		}
 
+		// Import campaigns and calendars first, characters refer to them
+		for _, item := range export.Campaigns {
+			campaign := item.Campaign
+			campaign.JoinCode = item.JoinCode
+			tx.Save(&campaign)
+		}
+		for _, item := range export.GameCalendars {
+			tx.Save(&item)
+		}
+
 		// Import characters (upsert)
 		for _, item := range export.Characters {
 			if err := tx.Save(&item).Error; err != nil {
@@ -323,6 +411,52 @@ func ImportDatabase(filePath string) (*ImportResult, error) {
 		for _, item := range export.AuditLogEntries {
 			tx.Save(&item)
 		}
+		for _, item := range export.SourceSelections {
+			tx.Save(&item)
+		}
+
+		// Import master data history and proposals
+		for _, item := range export.MasterDataChanges {
+			tx.Save(&item)
+		}
+		for _, item := range export.MasterDataProposals {
+			tx.Save(&item)
+		}
+		for _, item := range export.MasterDataProposalComments {
+			tx.Save(&item)
+		}
+
+		// Import character play data
+		for _, item := range export.DiceRolls {
+			tx.Save(&item)
+		}
+		for _, item := range export.CharNotes {
+			tx.Save(&item)
+		}
+		for _, item := range export.DowntimeEntries {
+			tx.Save(&item)
+		}
+		for _, item := range export.AdvancementPlans {
+			tx.Save(&item)
+		}
+		for _, item := range export.CharTemplates {
+			tx.Save(&item)
+		}
+
+		// Import encounters
+		for _, item := range export.Encounters {
+			tx.Omit("Participants").Save(&item)
+		}
+		for _, item := range export.EncounterParticipants {
+			tx.Save(&item)
+		}
+
+		for _, item := range export.MediaFiles {
+			file := item.MediaFile
+			file.Data = item.Data
+			file.Thumbnail = item.Thumbnail
+			tx.Save(&file)
+		}
 
 		return nil
 	})
@@ -331,21 +465,7 @@ func ImportDatabase(filePath string) (*ImportResult, error) {
 		return nil, err
 	}
 
-	recordCount := len(export.Users) + len(export.Characters) +
-		len(export.Eigenschaften) + len(export.Lps) + len(export.Aps) +
-		len(export.Bs) + len(export.Merkmale) + len(export.Erfahrungsschatze) +
-		len(export.Bennies) + len(export.Vermoegen) +
-		len(export.SkFertigkeiten) + len(export.SkWaffenfertigkeiten) + len(export.SkZauber) +
-		len(export.EqAusruestungen) + len(export.EqWaffen) + len(export.EqContainers) +
-		len(export.GsmSkills) + len(export.GsmWeaponSkills) + len(export.GsmSpells) +
-		len(export.GsmEquipment) + len(export.GsmWeapons) + len(export.GsmContainers) +
-		len(export.GsmTransportations) + len(export.GsmBelieves) +
-		len(export.Sources) + len(export.CharacterClasses) + len(export.SkillCategories) +
-		len(export.SkillDifficulties) + len(export.SpellSchools) +
-		len(export.ClassCategoryEPCosts) + len(export.ClassSpellSchoolEPCosts) +
-		len(export.SpellLevelLECosts) + len(export.SkillCategoryDifficulties) +
-		len(export.SkillImprovementCosts) + len(export.AuditLogEntries) +
-		len(export.CharacterCreationSessions)
+	recordCount := export.recordCount()
 
 	return &ImportResult{
 		RecordCount: recordCount,
@@ -152,3 +152,32 @@ func TestExportImportRoundtrip(t *testing.T) {
 	assert.Equal(t, originalCount, importResult.RecordCount,
 		"Import should restore same number of records")
 }
+
+func TestExportImportRoundtrip_PlayData(t *testing.T) {
+	db := setupTestDB(t)
+	if db.Error != nil {
+		t.Fatalf("Failed to setup test DB: %v", db.Error)
+	}
+
+	campaign := models.Campaign{Name: "Roundtrip", GameSystem: "M5", UserID: 1, JoinCode: "abc123"}
+	require.NoError(t, db.Create(&campaign).Error)
+	note := models.CharNote{CharacterID: 18, UserID: 1, Title: "Roundtrip", Text: "Notiz", Visibility: "private"}
+	require.NoError(t, db.Create(&note).Error)
+
+	exportResult, err := ExportDatabase(t.TempDir())
+	require.NoError(t, err, "Export should succeed")
+
+	require.NoError(t, db.Unscoped().Delete(&models.CharNote{}, note.ID).Error)
+	require.NoError(t, db.Unscoped().Delete(&models.Campaign{}, campaign.ID).Error)
+
+	_, err = ImportDatabase(exportResult.FilePath)
+	require.NoError(t, err, "Import should succeed")
+
+	var restoredNote models.CharNote
+	require.NoError(t, db.First(&restoredNote, note.ID).Error)
+	assert.Equal(t, "Notiz", restoredNote.Text)
+
+	var restoredCampaign models.Campaign
+	require.NoError(t, db.First(&restoredCampaign, campaign.ID).Error)
+	assert.Equal(t, "abc123", restoredCampaign.JoinCode, "join code must survive a backup")
+}
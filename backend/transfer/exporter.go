@@ -1,7 +1,9 @@
 package transfer
 
 import (
+	"bamort/character"
 	"bamort/database"
+	"bamort/media"
 	"bamort/models"
 	"fmt"
 	"time"
@@ -18,6 +20,7 @@ type CharacterExport struct {
 	GSMContainers   []models.Container     `json:"gsm_containers"`
 	LearningData    LearningDataExport     `json:"learning_data"`
 	AuditLogEntries []models.AuditLogEntry `json:"audit_log_entries"`
+	Notes           []models.CharNote      `json:"notes"`
 }
 
 // LearningDataExport contains all learning-related master data
@@ -35,7 +38,8 @@ type LearningDataExport struct {
 }
 
 // ExportCharacter exports a complete character with all related data
-func ExportCharacter(characterID uint) (*CharacterExport, error) {
+// Notes are limited to the visibilities the exporting user may read.
+func ExportCharacter(characterID, userID uint) (*CharacterExport, error) {
 	var char models.Char
 
 	// Load character with all relations
@@ -68,10 +72,18 @@ func ExportCharacter(characterID uint) (*CharacterExport, error) {
 	char.User.ResetPwHash = nil
 	char.User.ResetPwHashExpires = nil
 
+	// Das Charakterbild wird eingebettet, damit der Export ohne die Medientabelle auskommt
+	char.Image = media.PortraitDataURI(&char)
+
 	export := &CharacterExport{
 		Character: char,
 	}
 
+	// Stammdaten werden im Geltungsbereich des Charakters gesucht, damit seine Hausregeln
+	// mitkommen und gleichnamige Hausregeln anderer Spieler nicht
+	scope := models.CharacterContentScope(&char)
+	gs := models.GetGameSystem(0, "")
+
 	// Collect GSM skill data
 	export.GSMSkills = make([]models.Skill, 0)
 	export.GSMWeaponSkills = make([]models.WeaponSkill, 0)
@@ -80,7 +92,7 @@ func ExportCharacter(characterID uint) (*CharacterExport, error) {
 	for _, skill := range char.Fertigkeiten {
 		if !skillNames[skill.Name] {
 			var gsmSkill models.Skill
-			err := gsmSkill.First(skill.Name)
+			err := models.FirstVisible(&gsmSkill, skill.Name, gs, scope)
 			if err == nil && gsmSkill.ID != 0 {
 				export.GSMSkills = append(export.GSMSkills, gsmSkill)
 				skillNames[skill.Name] = true
@@ -92,7 +104,7 @@ func ExportCharacter(characterID uint) (*CharacterExport, error) {
 	for _, skill := range char.Waffenfertigkeiten {
 		if !weaponSkillNames[skill.Name] {
 			var weaponSkill models.WeaponSkill
-			err := weaponSkill.First(skill.Name)
+			err := models.FirstVisible(&weaponSkill, skill.Name, gs, scope)
 			if err == nil && weaponSkill.ID != 0 {
 				export.GSMWeaponSkills = append(export.GSMWeaponSkills, weaponSkill)
 				weaponSkillNames[skill.Name] = true
@@ -106,7 +118,7 @@ func ExportCharacter(characterID uint) (*CharacterExport, error) {
 	for _, spell := range char.Zauber {
 		if !spellNames[spell.Name] {
 			var gsmSpell models.Spell
-			err := gsmSpell.First(spell.Name)
+			err := models.FirstVisible(&gsmSpell, spell.Name, gs, scope)
 			if err == nil && gsmSpell.ID != 0 {
 				export.GSMSpells = append(export.GSMSpells, gsmSpell)
 				spellNames[spell.Name] = true
@@ -120,7 +132,7 @@ func ExportCharacter(characterID uint) (*CharacterExport, error) {
 	for _, weapon := range char.Waffen {
 		if !weaponNames[weapon.Name] {
 			var gsmWeapon models.Weapon
-			err := gsmWeapon.First(weapon.Name)
+			err := models.FirstVisible(&gsmWeapon, weapon.Name, gs, scope)
 			if err == nil && gsmWeapon.ID != 0 {
 				export.GSMWeapons = append(export.GSMWeapons, gsmWeapon)
 				weaponNames[weapon.Name] = true
@@ -134,7 +146,7 @@ func ExportCharacter(characterID uint) (*CharacterExport, error) {
 	for _, equip := range char.Ausruestung {
 		if !equipmentNames[equip.Name] {
 			var gsmEquip models.Equipment
-			err := gsmEquip.First(equip.Name)
+			err := models.FirstVisible(&gsmEquip, equip.Name, gs, scope)
 			if err == nil && gsmEquip.ID != 0 {
 				export.GSMEquipment = append(export.GSMEquipment, gsmEquip)
 				equipmentNames[equip.Name] = true
@@ -148,7 +160,7 @@ func ExportCharacter(characterID uint) (*CharacterExport, error) {
 	for _, container := range char.Behaeltnisse {
 		if !containerNames[container.Name] {
 			var gsmContainer models.Container
-			err := gsmContainer.First(container.Name)
+			err := models.FirstVisible(&gsmContainer, container.Name, gs, scope)
 			if err == nil && gsmContainer.ID != 0 {
 				export.GSMContainers = append(export.GSMContainers, gsmContainer)
 				containerNames[container.Name] = true
@@ -158,7 +170,7 @@ func ExportCharacter(characterID uint) (*CharacterExport, error) {
 	for _, container := range char.Transportmittel {
 		if !containerNames[container.Name] {
 			var gsmContainer models.Container
-			err := gsmContainer.First(container.Name)
+			err := models.FirstVisible(&gsmContainer, container.Name, gs, scope)
 			if err == nil && gsmContainer.ID != 0 {
 				export.GSMContainers = append(export.GSMContainers, gsmContainer)
 				containerNames[container.Name] = true
@@ -189,11 +201,25 @@ func ExportCharacter(characterID uint) (*CharacterExport, error) {
 	database.DB.Preload("CharacterClass").Preload("SpellSchool").Find(&export.LearningData.ClassSpellSchoolEPCosts)
 	database.DB.Find(&export.LearningData.SpellLevelLECosts)
 	database.DB.Preload("Skill").Preload("SkillCategory").Preload("SkillDifficulty").Find(&export.LearningData.SkillCategoryDifficulties)
+	// Lernkosten fremder Hausregeln gehören nicht in den Export
+	visibleCategories := export.LearningData.SkillCategoryDifficulties[:0]
+	for _, scd := range export.LearningData.SkillCategoryDifficulties {
+		if scd.Skill.VisibleIn(scope) {
+			visibleCategories = append(visibleCategories, scd)
+		}
+	}
+	export.LearningData.SkillCategoryDifficulties = visibleCategories
 	database.DB.Find(&export.LearningData.SkillImprovementCosts)
 
 	// Load audit log entries
 	export.AuditLogEntries = make([]models.AuditLogEntry, 0)
 	database.DB.Where("character_id = ?", characterID).Order("timestamp ASC").Find(&export.AuditLogEntries)
 
+	// Load notes the exporting user may read (the owner gets all of them)
+	export.Notes = make([]models.CharNote, 0)
+	if levels := character.VisibleNoteLevels(&char, userID); len(levels) > 0 {
+		database.DB.Where("character_id = ? AND visibility IN ?", characterID, levels).Order("created_at ASC, id ASC").Find(&export.Notes)
+	}
+
 	return export, nil
 }
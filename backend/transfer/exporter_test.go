@@ -29,7 +29,7 @@ func TestExportCharacter(t *testing.T) {
 	// Test with character ID 18 (Fanjo Vetrani - exists in test DB)
 	characterID := uint(18)
 
-	exportData, err := ExportCharacter(characterID)
+	exportData, err := ExportCharacter(characterID, 1)
 	if err != nil {
 		t.Fatalf("ExportCharacter failed: %v", err)
 	}
@@ -78,7 +78,7 @@ func TestExportCharacterIncludesSkills(t *testing.T) {
 	setupTestEnvironment(t)
 
 	characterID := uint(18)
-	exportData, err := ExportCharacter(characterID)
+	exportData, err := ExportCharacter(characterID, 1)
 	if err != nil {
 		t.Fatalf("ExportCharacter failed: %v", err)
 	}
@@ -98,7 +98,7 @@ func TestExportCharacterIncludesSpells(t *testing.T) {
 	setupTestEnvironment(t)
 
 	characterID := uint(18)
-	exportData, err := ExportCharacter(characterID)
+	exportData, err := ExportCharacter(characterID, 1)
 	if err != nil {
 		t.Fatalf("ExportCharacter failed: %v", err)
 	}
@@ -113,7 +113,7 @@ func TestExportCharacterIncludesEquipment(t *testing.T) {
 	setupTestEnvironment(t)
 
 	characterID := uint(18)
-	exportData, err := ExportCharacter(characterID)
+	exportData, err := ExportCharacter(characterID, 1)
 	if err != nil {
 		t.Fatalf("ExportCharacter failed: %v", err)
 	}
@@ -128,7 +128,7 @@ func TestExportCharacterIncludesLearningData(t *testing.T) {
 	setupTestEnvironment(t)
 
 	characterID := uint(18)
-	exportData, err := ExportCharacter(characterID)
+	exportData, err := ExportCharacter(characterID, 1)
 	if err != nil {
 		t.Fatalf("ExportCharacter failed: %v", err)
 	}
@@ -143,7 +143,7 @@ func TestExportCharacterIncludesAuditLog(t *testing.T) {
 	setupTestEnvironment(t)
 
 	characterID := uint(18)
-	exportData, err := ExportCharacter(characterID)
+	exportData, err := ExportCharacter(characterID, 1)
 	if err != nil {
 		t.Fatalf("ExportCharacter failed: %v", err)
 	}
@@ -158,8 +158,57 @@ func TestExportNonExistentCharacter(t *testing.T) {
 	setupTestEnvironment(t)
 
 	// Try to export non-existent character
-	_, err := ExportCharacter(uint(999999))
+	_, err := ExportCharacter(uint(999999), 1)
 	if err == nil {
 		t.Error("Expected error when exporting non-existent character")
 	}
 }
+
+func TestExportImportCharacterNotes(t *testing.T) {
+	setupTestEnvironment(t)
+
+	char := models.Char{BamortBase: models.BamortBase{Name: "Notizträger"}, UserID: 1, Rasse: "Mensch", Typ: "Krieger", Grad: 1}
+	if err := database.DB.Create(&char).Error; err != nil {
+		t.Fatalf("Failed to create character: %v", err)
+	}
+	characterID := char.ID
+	notes := []models.CharNote{
+		{CharacterID: characterID, UserID: 1, Type: models.NoteTypeBackstory, Title: "Herkunft", Text: "Aufgewachsen in *Corrinis*", Visibility: models.NoteVisibilityPrivate},
+		{CharacterID: characterID, UserID: 1, Type: models.NoteTypeContact, Title: "Händler Jorg", Text: "Schuldet uns 20 GS", Visibility: models.NoteVisibilityPublic},
+	}
+	if err := database.DB.Create(&notes).Error; err != nil {
+		t.Fatalf("Failed to create notes: %v", err)
+	}
+
+	exportData, err := ExportCharacter(characterID, 1)
+	if err != nil {
+		t.Fatalf("ExportCharacter failed: %v", err)
+	}
+	if len(exportData.Notes) != 2 {
+		t.Fatalf("Expected 2 notes in export, got %d", len(exportData.Notes))
+	}
+
+	// Other readers of a public character only get its public notes
+	database.DB.Model(&char).Update("public", true)
+	readerExport, err := ExportCharacter(characterID, 4)
+	if err != nil {
+		t.Fatalf("ExportCharacter as reader failed: %v", err)
+	}
+	if len(readerExport.Notes) != 1 || readerExport.Notes[0].Visibility != models.NoteVisibilityPublic {
+		t.Fatalf("Expected only the public note for a reader, got %+v", readerExport.Notes)
+	}
+
+	importedCharID, err := ImportCharacter(exportData, 1)
+	if err != nil {
+		t.Fatalf("ImportCharacter failed: %v", err)
+	}
+
+	var imported []models.CharNote
+	database.DB.Where("character_id = ?", importedCharID).Order("id ASC").Find(&imported)
+	if len(imported) != 2 {
+		t.Fatalf("Expected 2 imported notes, got %d", len(imported))
+	}
+	if imported[0].Text != "Aufgewachsen in *Corrinis*" || imported[0].Visibility != models.NoteVisibilityPrivate {
+		t.Errorf("Imported note does not match: %+v", imported[0])
+	}
+}
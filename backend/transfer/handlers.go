@@ -1,7 +1,10 @@
 package transfer
 
 import (
+	"bamort/character"
 	"bamort/config"
+	"bamort/database"
+	"bamort/models"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -10,18 +13,34 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// ExportCharacterHandler handles character export requests
-func ExportCharacterHandler(c *gin.Context) {
-	// Get character ID from URL parameter
-	charIDStr := c.Param("id")
-	charID, err := strconv.ParseUint(charIDStr, 10, 32)
+// readableCharacterID parses the character ID and checks that the user may read the character
+func readableCharacterID(c *gin.Context) (uint, bool) {
+	charID, err := strconv.ParseUint(c.Param("id"), 10, 32)
 	if err != nil {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid character ID"})
+		return 0, false
+	}
+	var char models.Char
+	if err := database.DB.Select("id", "user_id", "public").First(&char, charID).Error; err != nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Character not found"})
+		return 0, false
+	}
+	if !character.CanReadCharacter(&char, c.GetUint("userID")) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You are not authorized to export this character"})
+		return 0, false
+	}
+	return char.ID, true
+}
+
+// ExportCharacterHandler handles character export requests
+func ExportCharacterHandler(c *gin.Context) {
+	charID, ok := readableCharacterID(c)
+	if !ok {
 		return
 	}
 
 	// Export character
-	exportData, err := ExportCharacter(uint(charID))
+	exportData, err := ExportCharacter(charID, c.GetUint("userID"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to export character: %v", err)})
 		return
@@ -33,16 +52,13 @@ func ExportCharacterHandler(c *gin.Context) {
 
 // DownloadCharacterHandler exports character as downloadable JSON file
 func DownloadCharacterHandler(c *gin.Context) {
-	// Get character ID from URL parameter
-	charIDStr := c.Param("id")
-	charID, err := strconv.ParseUint(charIDStr, 10, 32)
-	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid character ID"})
+	charID, ok := readableCharacterID(c)
+	if !ok {
 		return
 	}
 
 	// Export character
-	exportData, err := ExportCharacter(uint(charID))
+	exportData, err := ExportCharacter(charID, c.GetUint("userID"))
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": fmt.Sprintf("Failed to export character: %v", err)})
 		return
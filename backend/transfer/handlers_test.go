@@ -39,7 +39,7 @@ func TestExportCharacterHandlerAPI(t *testing.T) {
 	r := setupHandlerTestEnvironment(t)
 
 	// Register routes
-	api := r.Group("/api")
+	api := r.Group("/api", func(c *gin.Context) { c.Set("userID", uint(1)) })
 	RegisterRoutes(api)
 
 	// Test export endpoint
@@ -63,10 +63,27 @@ func TestExportCharacterHandlerAPI(t *testing.T) {
 	}
 }
 
+func TestExportCharacterHandlerRequiresReadAccess(t *testing.T) {
+	r := setupHandlerTestEnvironment(t)
+
+	// Character 18 belongs to user 1 and is not shared with user 4
+	api := r.Group("/api", func(c *gin.Context) { c.Set("userID", uint(4)) })
+	RegisterRoutes(api)
+
+	for _, path := range []string{"/api/transfer/export/18", "/api/transfer/download/18"} {
+		req, _ := http.NewRequest("GET", path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusForbidden {
+			t.Errorf("%s: expected status 403, got %d", path, w.Code)
+		}
+	}
+}
+
 func TestDownloadCharacterHandlerAPI(t *testing.T) {
 	r := setupHandlerTestEnvironment(t)
 
-	api := r.Group("/api")
+	api := r.Group("/api", func(c *gin.Context) { c.Set("userID", uint(1)) })
 	RegisterRoutes(api)
 
 	// Test download endpoint
@@ -98,7 +115,7 @@ func TestImportCharacterHandlerAPI(t *testing.T) {
 	RegisterRoutes(api)
 
 	// First export a character
-	exportData, err := ExportCharacter(uint(18))
+	exportData, err := ExportCharacter(uint(18), 1)
 	if err != nil {
 		t.Fatalf("Failed to export character: %v", err)
 	}
@@ -152,8 +169,8 @@ func TestExportNonExistentCharacterAPI(t *testing.T) {
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 
-	if w.Code != http.StatusInternalServerError {
-		t.Errorf("Expected status 500, got %d", w.Code)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404, got %d", w.Code)
 	}
 }
 
@@ -2,6 +2,7 @@ package transfer
 
 import (
 	"bamort/database"
+	"bamort/media"
 	"bamort/models"
 	"fmt"
 	"strings"
@@ -15,7 +16,7 @@ func ImportCharacter(exportData *CharacterExport, userID uint) (uint, error) {
 
 	err := database.DB.Transaction(func(tx *gorm.DB) error {
 		// Import GSM master data first
-		if err := importGSMData(tx, exportData); err != nil {
+		if err := importGSMData(tx, exportData, userID); err != nil {
 			return fmt.Errorf("failed to import GSM data: %w", err)
 		}
 
@@ -28,6 +29,10 @@ func ImportCharacter(exportData *CharacterExport, userID uint) (uint, error) {
 		char := exportData.Character
 		char.ID = 0 // Reset ID for new character
 		char.UserID = userID
+		// Kampagne und Spielkalender gehören zur Gruppe des Exports, nicht zum importierenden Benutzer
+		char.CampaignID = 0
+		char.CalendarID = 0
+		char.BirthDay = nil
 
 		// Reset all related IDs
 		if char.Lp.ID != 0 {
@@ -95,6 +100,13 @@ func ImportCharacter(exportData *CharacterExport, userID uint) (uint, error) {
 
 		importedCharID = char.ID
 
+		// Eingebettetes Charakterbild in die Medientabelle übernehmen
+		if strings.HasPrefix(char.Image, "data:") {
+			if err := media.MigrateCharacterImage(tx, &char); err != nil {
+				return fmt.Errorf("failed to import character image: %w", err)
+			}
+		}
+
 		// Import audit log entries
 		if len(exportData.AuditLogEntries) > 0 {
 			for i := range exportData.AuditLogEntries {
@@ -106,6 +118,18 @@ func ImportCharacter(exportData *CharacterExport, userID uint) (uint, error) {
 			}
 		}
 
+		// Import notes
+		if len(exportData.Notes) > 0 {
+			for i := range exportData.Notes {
+				exportData.Notes[i].ID = 0
+				exportData.Notes[i].CharacterID = importedCharID
+				exportData.Notes[i].UserID = userID
+			}
+			if err := tx.Create(&exportData.Notes).Error; err != nil {
+				return fmt.Errorf("failed to import notes: %w", err)
+			}
+		}
+
 		return nil
 	})
 
@@ -117,9 +141,16 @@ func ImportCharacter(exportData *CharacterExport, userID uint) (uint, error) {
 }
 
 // importGSMData imports or updates GSM master data
-func importGSMData(tx *gorm.DB, exportData *CharacterExport) error {
+// Homebrew records of the export become homebrew of the importing user.
+func importGSMData(tx *gorm.DB, exportData *CharacterExport, userID uint) error {
 	// Import skills
 	for _, skill := range exportData.GSMSkills {
+		if skill.IsHomebrew() {
+			if err := importHomebrewSkill(tx, &skill, exportData.LearningData.SkillCategoryDifficulties, userID); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := importOrUpdateSkill(tx, &skill); err != nil {
 			return err
 		}
@@ -127,6 +158,12 @@ func importGSMData(tx *gorm.DB, exportData *CharacterExport) error {
 
 	// Import weapon skills
 	for _, weaponSkill := range exportData.GSMWeaponSkills {
+		if weaponSkill.IsHomebrew() {
+			if _, _, err := importHomebrew(tx, &weaponSkill, &weaponSkill.HomebrewScope, &weaponSkill.ID, weaponSkill.Name, weaponSkill.GameSystem, userID); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := importOrUpdateWeaponSkill(tx, &weaponSkill); err != nil {
 			return err
 		}
@@ -134,6 +171,12 @@ func importGSMData(tx *gorm.DB, exportData *CharacterExport) error {
 
 	// Import spells
 	for _, spell := range exportData.GSMSpells {
+		if spell.IsHomebrew() {
+			if _, _, err := importHomebrew(tx, &spell, &spell.HomebrewScope, &spell.ID, spell.Name, spell.GameSystem, userID); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := importOrUpdateSpell(tx, &spell); err != nil {
 			return err
 		}
@@ -141,6 +184,12 @@ func importGSMData(tx *gorm.DB, exportData *CharacterExport) error {
 
 	// Import weapons
 	for _, weapon := range exportData.GSMWeapons {
+		if weapon.IsHomebrew() {
+			if _, _, err := importHomebrew(tx, &weapon, &weapon.HomebrewScope, &weapon.ID, weapon.Name, weapon.GameSystem, userID); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := importOrUpdateWeapon(tx, &weapon); err != nil {
 			return err
 		}
@@ -148,6 +197,12 @@ func importGSMData(tx *gorm.DB, exportData *CharacterExport) error {
 
 	// Import equipment
 	for _, equipment := range exportData.GSMEquipment {
+		if equipment.IsHomebrew() {
+			if _, _, err := importHomebrew(tx, &equipment, &equipment.HomebrewScope, &equipment.ID, equipment.Name, equipment.GameSystem, userID); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := importOrUpdateEquipment(tx, &equipment); err != nil {
 			return err
 		}
@@ -155,6 +210,12 @@ func importGSMData(tx *gorm.DB, exportData *CharacterExport) error {
 
 	// Import containers
 	for _, container := range exportData.GSMContainers {
+		if container.IsHomebrew() {
+			if _, _, err := importHomebrew(tx, &container, &container.HomebrewScope, &container.ID, container.Name, container.GameSystem, userID); err != nil {
+				return err
+			}
+			continue
+		}
 		if err := importOrUpdateContainer(tx, &container); err != nil {
 			return err
 		}
@@ -163,6 +224,74 @@ func importGSMData(tx *gorm.DB, exportData *CharacterExport) error {
 	return nil
 }
 
+// globalRecords restricts a master data query to global records, homebrew of other users is never merged
+func globalRecords(tx *gorm.DB) *gorm.DB {
+	condition, args := models.ContentScope{}.Condition("")
+	return tx.Where(condition, args...)
+}
+
+// importHomebrew creates an exported homebrew record as homebrew of the importing user
+// If a record with the same name is already visible to the user, that record is kept.
+// Returns the ID of the record in use and whether it was created.
+func importHomebrew[T any](tx *gorm.DB, record *T, scope *models.HomebrewScope, id *uint, name, gameSystem string, userID uint) (uint, bool, error) {
+	condition, args := models.ContentScope{UserID: userID}.Condition("")
+	var ids []uint
+	err := tx.Model(new(T)).Where("name = ? AND game_system = ?", name, gameSystem).Where(condition, args...).
+		Order("id ASC").Limit(1).Pluck("id", &ids).Error
+	if err != nil {
+		return 0, false, err
+	}
+	if len(ids) > 0 {
+		return ids[0], false, nil
+	}
+
+	*id = 0
+	*scope = models.HomebrewScope{Scope: models.ScopeUser, ScopeUserID: userID}
+	if err := tx.Create(record).Error; err != nil {
+		return 0, false, fmt.Errorf("failed to import homebrew %s: %w", name, err)
+	}
+	return *id, true, nil
+}
+
+// importHomebrewSkill imports a homebrew skill together with its learning categories
+func importHomebrewSkill(tx *gorm.DB, skill *models.Skill, categories []models.SkillCategoryDifficulty, userID uint) error {
+	exportedID := skill.ID
+	gs := models.GetGameSystem(skill.GameSystemId, skill.GameSystem)
+	skill.GameSystem = gs.Name
+	skill.GameSystemId = gs.ID
+
+	skillID, created, err := importHomebrew(tx, skill, &skill.HomebrewScope, &skill.ID, skill.Name, skill.GameSystem, userID)
+	if err != nil || !created {
+		return err
+	}
+
+	for _, scd := range categories {
+		if scd.SkillID != exportedID {
+			continue
+		}
+		var category models.SkillCategory
+		if err := tx.Where("name = ?", scd.SCategory).First(&category).Error; err != nil {
+			return fmt.Errorf("unknown skill category %s for homebrew skill %s: %w", scd.SCategory, skill.Name, err)
+		}
+		var difficulty models.SkillDifficulty
+		if err := tx.Where("name = ?", scd.SDifficulty).First(&difficulty).Error; err != nil {
+			return fmt.Errorf("unknown skill difficulty %s for homebrew skill %s: %w", scd.SDifficulty, skill.Name, err)
+		}
+		imported := models.SkillCategoryDifficulty{
+			SkillID:           skillID,
+			SkillCategoryID:   category.ID,
+			SkillDifficultyID: difficulty.ID,
+			LearnCost:         scd.LearnCost,
+			SCategory:         category.Name,
+			SDifficulty:       difficulty.Name,
+		}
+		if err := tx.Omit("Skill", "SkillCategory", "SkillDifficulty").Create(&imported).Error; err != nil {
+			return fmt.Errorf("failed to import learning category of homebrew skill %s: %w", skill.Name, err)
+		}
+	}
+	return nil
+}
+
 // importOrUpdateSkill imports or updates a skill based on name
 func importOrUpdateSkill(tx *gorm.DB, skill *models.Skill) error {
 	// Set default source_id if 0
@@ -176,7 +305,7 @@ func importOrUpdateSkill(tx *gorm.DB, skill *models.Skill) error {
 	skill.GameSystemId = gs.ID
 
 	var existing models.Skill
-	err := tx.Where("name = ? AND game_system = ?", skill.Name, skill.GameSystem).First(&existing).Error
+	err := globalRecords(tx).Where("name = ? AND game_system = ?", skill.Name, skill.GameSystem).First(&existing).Error
 
 	if err == gorm.ErrRecordNotFound {
 		// Create new skill
@@ -221,7 +350,7 @@ func importOrUpdateWeaponSkill(tx *gorm.DB, weaponSkill *models.WeaponSkill) err
 	}
 
 	var existing models.WeaponSkill
-	err := tx.Where("name = ? AND game_system = ?", weaponSkill.Name, weaponSkill.GameSystem).First(&existing).Error
+	err := globalRecords(tx).Where("name = ? AND game_system = ?", weaponSkill.Name, weaponSkill.GameSystem).First(&existing).Error
 
 	if err == gorm.ErrRecordNotFound {
 		weaponSkill.ID = 0
@@ -254,7 +383,7 @@ func importOrUpdateSpell(tx *gorm.DB, spell *models.Spell) error {
 	}
 
 	var existing models.Spell
-	err := tx.Where("name = ? AND game_system = ?", spell.Name, spell.GameSystem).First(&existing).Error
+	err := globalRecords(tx).Where("name = ? AND game_system = ?", spell.Name, spell.GameSystem).First(&existing).Error
 
 	if err == gorm.ErrRecordNotFound {
 		spell.ID = 0
@@ -295,7 +424,7 @@ func importOrUpdateWeapon(tx *gorm.DB, weapon *models.Weapon) error {
 	}
 
 	var existing models.Weapon
-	err := tx.Where("name = ? AND game_system = ?", weapon.Name, weapon.GameSystem).First(&existing).Error
+	err := globalRecords(tx).Where("name = ? AND game_system = ?", weapon.Name, weapon.GameSystem).First(&existing).Error
 
 	if err == gorm.ErrRecordNotFound {
 		weapon.ID = 0
@@ -324,7 +453,7 @@ func importOrUpdateEquipment(tx *gorm.DB, equipment *models.Equipment) error {
 	}
 
 	var existing models.Equipment
-	err := tx.Where("name = ? AND game_system = ?", equipment.Name, equipment.GameSystem).First(&existing).Error
+	err := globalRecords(tx).Where("name = ? AND game_system = ?", equipment.Name, equipment.GameSystem).First(&existing).Error
 
 	if err == gorm.ErrRecordNotFound {
 		equipment.ID = 0
@@ -353,7 +482,7 @@ func importOrUpdateContainer(tx *gorm.DB, container *models.Container) error {
 	}
 
 	var existing models.Container
-	err := tx.Where("name = ? AND game_system = ?", container.Name, container.GameSystem).First(&existing).Error
+	err := globalRecords(tx).Where("name = ? AND game_system = ?", container.Name, container.GameSystem).First(&existing).Error
 
 	if err == gorm.ErrRecordNotFound {
 		container.ID = 0
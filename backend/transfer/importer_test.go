@@ -27,7 +27,7 @@ func TestImportCharacter(t *testing.T) {
 	setupImportTestEnvironment(t)
 
 	// First export character 18
-	exportData, err := ExportCharacter(uint(18))
+	exportData, err := ExportCharacter(uint(18), 1)
 	if err != nil {
 		t.Fatalf("Failed to export character: %v", err)
 	}
@@ -68,7 +68,7 @@ func TestImportCharacterWithExistingGSMData(t *testing.T) {
 	setupImportTestEnvironment(t)
 
 	// Export character 18
-	exportData, err := ExportCharacter(uint(18))
+	exportData, err := ExportCharacter(uint(18), 1)
 	if err != nil {
 		t.Fatalf("Failed to export character: %v", err)
 	}
@@ -239,7 +239,7 @@ func TestImportCharacterAsJSON(t *testing.T) {
 	setupImportTestEnvironment(t)
 
 	// Export character 18 to JSON
-	exportData, err := ExportCharacter(uint(18))
+	exportData, err := ExportCharacter(uint(18), 1)
 	if err != nil {
 		t.Fatalf("Failed to export character: %v", err)
 	}
@@ -268,3 +268,62 @@ func TestImportCharacterAsJSON(t *testing.T) {
 		t.Error("Expected non-zero character ID")
 	}
 }
+
+func TestExportImportCharacterHomebrewSpell(t *testing.T) {
+	setupImportTestEnvironment(t)
+	if err := database.DB.Create(&models.GameSystem{Code: "M5", Name: "midgard", IsActive: true}).Error; err != nil {
+		t.Fatalf("Failed to create game system: %v", err)
+	}
+
+	var char models.Char
+	if err := database.DB.First(&char, 18).Error; err != nil {
+		t.Fatalf("Failed to load character: %v", err)
+	}
+	homebrew := models.Spell{Name: "Sternenstaub", GameSystem: "midgard", Stufe: 2,
+		HomebrewScope: models.HomebrewScope{Scope: models.ScopeUser, ScopeUserID: char.UserID}}
+	if err := database.DB.Create(&homebrew).Error; err != nil {
+		t.Fatalf("Failed to create homebrew spell: %v", err)
+	}
+	spell := models.SkZauber{BamortCharTrait: models.BamortCharTrait{BamortBase: models.BamortBase{Name: "Sternenstaub"}, CharacterID: char.ID, UserID: char.UserID}}
+	if err := database.DB.Create(&spell).Error; err != nil {
+		t.Fatalf("Failed to add spell to character: %v", err)
+	}
+
+	exportData, err := ExportCharacter(char.ID, char.UserID)
+	if err != nil {
+		t.Fatalf("Failed to export character: %v", err)
+	}
+	var exported *models.Spell
+	for i := range exportData.GSMSpells {
+		if exportData.GSMSpells[i].Name == "Sternenstaub" {
+			exported = &exportData.GSMSpells[i]
+		}
+	}
+	if exported == nil || exported.Scope != models.ScopeUser {
+		t.Fatalf("Expected homebrew spell in export, got %+v", exported)
+	}
+
+	// Import für einen anderen Benutzer: die Hausregel wird dessen eigene Hausregel
+	exportData.Character.ID = 0
+	if _, err := ImportCharacter(exportData, 4); err != nil {
+		t.Fatalf("ImportCharacter failed: %v", err)
+	}
+	var copies []models.Spell
+	database.DB.Where("name = ?", "Sternenstaub").Order("id ASC").Find(&copies)
+	if len(copies) != 2 {
+		t.Fatalf("Expected original and imported homebrew spell, got %d", len(copies))
+	}
+	if copies[1].Scope != models.ScopeUser || copies[1].ScopeUserID != 4 || copies[1].Stufe != 2 {
+		t.Errorf("Imported spell should belong to the importing user, got %+v", copies[1].HomebrewScope)
+	}
+
+	// Ein zweiter Import verwendet die vorhandene Hausregel wieder
+	if _, err := ImportCharacter(exportData, 4); err != nil {
+		t.Fatalf("Second ImportCharacter failed: %v", err)
+	}
+	var count int64
+	database.DB.Model(&models.Spell{}).Where("name = ?", "Sternenstaub").Count(&count)
+	if count != 2 {
+		t.Errorf("Expected homebrew spell to be reused, got %d copies", count)
+	}
+}